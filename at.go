@@ -0,0 +1,175 @@
+package rrule
+
+import (
+	"time"
+)
+
+// At returns the nth occurrence of the pattern, counting from 0. If the pattern
+// ends before the nth occurrence, or n is negative, false is returned.
+//
+// Simple patterns, those with only FREQ, INTERVAL, COUNT and UNTIL, are
+// computed directly. Others are iterated. OccurrenceCount and Expand do the
+// same.
+//
+// The pattern must be valid or At will panic.
+func (rrule RRule) At(n int) (time.Time, bool) {
+	if n < 0 {
+		return time.Time{}, false
+	}
+	if rrule.Count != 0 && uint64(n) >= rrule.Count {
+		return time.Time{}, false
+	}
+
+	if rrule.simple() {
+		err := rrule.Validate()
+		if err != nil {
+			panic(err)
+		}
+
+		t := rrule.nthSimple(n)
+		if rrule.pastUntil(t) {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+
+	it := rrule.Iterator()
+	defer release(it)
+	it.Skip(n)
+	t := it.Next()
+	if t == nil {
+		return time.Time{}, false
+	}
+	return *t, true
+}
+
+// simple returns true if every occurrence of the pattern is exactly one
+// interval after the previous one.
+func (rrule RRule) simple() bool {
+	if len(rrule.BySeconds) > 0 ||
+		len(rrule.ByMinutes) > 0 ||
+		len(rrule.ByHours) > 0 ||
+		len(rrule.ByWeekdays) > 0 ||
+		len(rrule.ByMonthDays) > 0 ||
+		len(rrule.ByWeekNumbers) > 0 ||
+		len(rrule.ByMonths) > 0 ||
+		len(rrule.ByLeapMonths) > 0 ||
+		len(rrule.ByYearDays) > 0 ||
+		len(rrule.BySetPos) > 0 {
+		return false
+	}
+
+	if rrule.hasLocalTimePolicy() {
+		return false
+	}
+
+	start := rrule.dtstart()
+	switch rrule.Frequency {
+	case Monthly:
+		if rrule.RScale.calendar() != nil {
+			return false
+		}
+		// not every month has these days.
+		return start.Day() <= 28
+	case Yearly:
+		return rrule.RScale.calendar() == nil && (start.Month() != time.February || start.Day() != 29)
+	}
+	return rrule.Frequency >= Secondly && rrule.Frequency < Monthly
+}
+
+// nthSimple returns the nth occurrence of a simple pattern, ignoring COUNT and
+// UNTIL.
+func (rrule RRule) nthSimple(n int) time.Time {
+	return rrule.stepSimple(rrule.dtstart(), n)
+}
+
+// stepSimple returns the time n intervals of a simple pattern after t.
+func (rrule RRule) stepSimple(start time.Time, n int) time.Time {
+	steps := n
+	if rrule.Interval > 1 {
+		steps *= rrule.Interval
+	}
+
+	switch rrule.Frequency {
+	case Secondly:
+		return addSeconds(start, int64(steps))
+	case Minutely:
+		return addSeconds(start, int64(steps)*60)
+	case Hourly:
+		return addSeconds(start, int64(steps)*60*60)
+	case Daily:
+		return start.AddDate(0, 0, steps)
+	case Weekly:
+		return start.AddDate(0, 0, steps*7)
+	case Monthly:
+		return start.AddDate(0, steps, 0)
+	default:
+		return start.AddDate(steps, 0, 0)
+	}
+}
+
+// indexSimple returns the number of occurrences of a simple pattern before t,
+// ignoring COUNT and UNTIL.
+func (rrule RRule) indexSimple(t time.Time) int {
+	start := rrule.dtstart()
+	if !start.Before(t) {
+		return 0
+	}
+
+	// estimate the number of steps from the elapsed time, and then correct
+	// the estimate, which daylight saving and the lengths of months make off
+	// by a step or so.
+	var steps int64
+	switch rrule.Frequency {
+	case Secondly:
+		steps = t.Unix() - start.Unix()
+	case Minutely:
+		steps = (t.Unix() - start.Unix()) / 60
+	case Hourly:
+		steps = (t.Unix() - start.Unix()) / (60 * 60)
+	case Daily:
+		steps = (t.Unix() - start.Unix()) / (24 * 60 * 60)
+	case Weekly:
+		steps = (t.Unix() - start.Unix()) / (7 * 24 * 60 * 60)
+	case Monthly:
+		t := t.In(start.Location())
+		steps = int64(t.Year()-start.Year())*12 + int64(t.Month()-start.Month())
+	default:
+		steps = int64(t.In(start.Location()).Year() - start.Year())
+	}
+	if rrule.Interval > 1 {
+		steps /= int64(rrule.Interval)
+	}
+
+	n := int(steps)
+	for n > 0 && !rrule.nthSimple(n-1).Before(t) {
+		n--
+	}
+	for rrule.nthSimple(n).Before(t) {
+		n++
+	}
+	return n
+}
+
+// lenSimple returns the number of occurrences of a simple pattern allowed by
+// COUNT and UNTIL, or false if it has neither and never ends.
+func (rrule RRule) lenSimple() (int, bool) {
+	n, finite := 0, false
+	if !rrule.Until.IsZero() {
+		until := rrule.withFloatingUntilIn(rrule.dtstart().Location()).Until
+		if !rrule.UntilExclusive {
+			// count the occurrence at until, too.
+			until = until.Add(time.Nanosecond)
+		}
+		n, finite = rrule.indexSimple(until), true
+	}
+	if rrule.Count != 0 && (!finite || uint64(n) > rrule.Count) {
+		n, finite = int(rrule.Count), true
+	}
+	return n, finite
+}
+
+// addSeconds adds seconds to t without the range limits of time.Duration.
+func addSeconds(t time.Time, seconds int64) time.Time {
+	return time.Unix(t.Unix()+seconds, int64(t.Nanosecond())).In(t.Location())
+}
@@ -0,0 +1,66 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAt(t *testing.T) {
+	for _, tc := range cases {
+		if tc.NoTest {
+			continue
+		}
+
+		t.Run(tc.Name, func(t *testing.T) {
+			for i, expected := range tc.Dates {
+				got, ok := tc.RRule.At(i)
+				require.True(t, ok)
+				assert.Equal(t, expected, got.Format(time.RFC3339))
+			}
+
+			if tc.Terminal {
+				_, ok := tc.RRule.At(len(tc.Dates))
+				assert.False(t, ok)
+			}
+		})
+	}
+}
+
+func TestAtSimple(t *testing.T) {
+	rrule := RRule{Frequency: Hourly, Interval: 3, Dtstart: now}
+	got, ok := rrule.At(200000)
+	require.True(t, ok)
+	assert.Equal(t, now.Add(300000*time.Hour).Add(300000*time.Hour), got)
+
+	rrule = RRule{Frequency: Monthly, Interval: 2, Until: time.Date(2019, time.February, 25, 9, 8, 7, 6, time.UTC), Dtstart: now}
+	got, ok = rrule.At(3)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2019, time.February, 25, 9, 8, 7, 6, time.UTC), got)
+
+	_, ok = rrule.At(4)
+	assert.False(t, ok)
+
+	_, ok = rrule.At(-1)
+	assert.False(t, ok)
+}
+
+func TestAtSimpleDaylightSaving(t *testing.T) {
+	// 2:30 a.m. doesn't exist on March 10th, so that occurrence is moved,
+	// but the ones after it are still at 2:30.
+	rrule := RRule{Frequency: Daily, Count: 4, Dtstart: time.Date(2019, time.March, 9, 2, 30, 0, 0, NewYork())}
+	want := []string{"2019-03-09T02:30:00-05:00", "2019-03-10T01:30:00-05:00", "2019-03-11T02:30:00-04:00", "2019-03-12T02:30:00-04:00"}
+
+	assert.Equal(t, want, rfcAll(All(rrule.Iterator(), 0)))
+	for i, expected := range want {
+		got, ok := rrule.At(i)
+		require.True(t, ok)
+		assert.Equal(t, expected, got.Format(time.RFC3339))
+	}
+
+	it := rrule.Iterator()
+	it.Seek(time.Date(2019, time.March, 11, 0, 0, 0, 0, NewYork()))
+	assert.Equal(t, want[2:], rfcAll(All(it, 0)))
+}
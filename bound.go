@@ -0,0 +1,40 @@
+package rrule
+
+import (
+	"time"
+)
+
+// boundedIterator is an iterator that can stop looking for times once they
+// would be past a bound, so that a sparse pattern, like
+// FREQ=SECONDLY;BYYEARDAY=366, doesn't search on past the end of a range.
+type boundedIterator interface {
+	setBound(end time.Time)
+}
+
+// withBound stops the iterators of this package looking for times after end,
+// or lifts the bound if end is zero. Times already found past end may still be
+// returned, so callers check them against end as before. Iterators from other
+// packages are unchanged.
+func withBound(it Iterator, end time.Time) {
+	if bi, ok := it.(boundedIterator); ok {
+		bi.setBound(end)
+	}
+}
+
+func (i *iterator) setBound(end time.Time) {
+	i.bound = end
+	i.pastBound = false
+}
+
+func (gi *groupIterator) setBound(end time.Time) {
+	for _, it := range gi.iters {
+		withBound(it, end)
+	}
+}
+
+func (ri *recurrenceIterator) setBound(end time.Time) {
+	ri.rrules.setBound(end)
+	ri.exrules.setBound(end)
+}
+
+func (li *locationIterator) setBound(end time.Time) { withBound(li.it, end) }
@@ -0,0 +1,96 @@
+package rrule
+
+import (
+	"time"
+)
+
+// Budget limits the work an iterator does looking for each time, so that a
+// pattern whose occurrences are very sparse, like FREQ=SECONDLY;BYYEARDAY=366,
+// ends the iterator rather than holding up the caller. The zero Budget is
+// unlimited.
+type Budget struct {
+	// MaxCandidates is the most key times, like the days of a DAILY
+	// pattern, examined looking for one time. 0 is unlimited.
+	MaxCandidates int
+
+	// MaxDuration is the longest spent looking for one time. 0 is
+	// unlimited.
+	MaxDuration time.Duration
+}
+
+// WithBudget limits the iterators of this package to b, and returns it. When
+// looking for a time exceeds b, the iterator ends, and IteratorErr reports an
+// ErrIterationBudgetExceeded error. Reset clears the error. Iterators from
+// other packages are returned unchanged.
+func WithBudget(it Iterator, b Budget) Iterator {
+	if bi, ok := it.(budgetedIterator); ok {
+		bi.setBudget(b)
+	}
+	return it
+}
+
+// IteratorErr returns the error that ended it early, like an
+// ErrIterationBudgetExceeded error, or nil if it hasn't ended or simply ran out
+// of times.
+func IteratorErr(it Iterator) error {
+	if bi, ok := it.(budgetedIterator); ok {
+		return bi.err()
+	}
+	return nil
+}
+
+// budgetedIterator is an iterator that can be limited by a Budget.
+type budgetedIterator interface {
+	setBudget(b Budget)
+	err() error
+}
+
+// spend reports an ErrIterationBudgetExceeded error if examining another key
+// time, having already examined the given number since started, exceeds b.
+func (b Budget) spend(examined int, started time.Time) error {
+	if b.MaxCandidates > 0 && examined >= b.MaxCandidates {
+		return kindErrorf(ErrIterationBudgetExceeded, "no time found in %d candidates", examined)
+	}
+	if b.MaxDuration > 0 && time.Since(started) > b.MaxDuration {
+		return kindErrorf(ErrIterationBudgetExceeded, "no time found in %v", b.MaxDuration)
+	}
+	return nil
+}
+
+// firstErr returns the first error that ended one of the iterators early.
+func firstErr(iters []Iterator) error {
+	for _, it := range iters {
+		if err := IteratorErr(it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *iterator) setBudget(b Budget) { i.budget = b }
+
+func (i *iterator) err() error { return i.budgetErr }
+
+func (gi *groupIterator) setBudget(b Budget) {
+	for _, it := range gi.iters {
+		WithBudget(it, b)
+	}
+}
+
+func (gi *groupIterator) err() error { return firstErr(gi.iters) }
+
+func (ri *recurrenceIterator) setBudget(b Budget) {
+	ri.rrules.setBudget(b)
+	ri.exrules.setBudget(b)
+}
+
+func (ri *recurrenceIterator) err() error {
+	if err := ri.rrules.err(); err != nil {
+		return err
+	}
+	return ri.exrules.err()
+}
+
+func (li *locationIterator) setBudget(b Budget) { WithBudget(li.it, b) }
+
+func (li *locationIterator) err() error { return IteratorErr(li.it) }
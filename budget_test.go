@@ -0,0 +1,78 @@
+package rrule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetCandidates(t *testing.T) {
+	// the 366th day of the year is only in leap years, so each occurrence
+	// is found within 4 years.
+	rrule := MustRRule("FREQ=YEARLY;BYYEARDAY=366")
+	rrule.Dtstart = time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	it := WithBudget(rrule.Iterator(), Budget{MaxCandidates: 4})
+	assert.Equal(t, []string{"2020-12-31T00:00:00Z", "2024-12-31T00:00:00Z"}, rfcAll(it.Take(2)))
+	assert.NoError(t, IteratorErr(it))
+
+	it = WithBudget(rrule.Iterator(), Budget{MaxCandidates: 3})
+	assert.Nil(t, it.Next())
+	assert.Nil(t, it.Peek())
+	err := IteratorErr(it)
+	assert.True(t, errors.Is(err, ErrIterationBudgetExceeded), "%v", err)
+
+	// Reset starts over, with the same budget.
+	it.Reset()
+	assert.NoError(t, IteratorErr(it))
+	assert.Nil(t, it.Next())
+	assert.Error(t, IteratorErr(it))
+}
+
+func TestBudgetDuration(t *testing.T) {
+	// only one second in each leap year occurs.
+	rrule := MustRRule("FREQ=SECONDLY;BYYEARDAY=366")
+	rrule.Dtstart = now
+
+	it := WithBudget(InLocation(rrule.Iterator(), NewYork()), Budget{MaxDuration: 10 * time.Millisecond})
+	assert.Nil(t, it.Next())
+	assert.True(t, errors.Is(IteratorErr(it), ErrIterationBudgetExceeded))
+
+	all, err := AllContext(context.Background(), it, 0)
+	assert.Empty(t, all)
+	assert.True(t, errors.Is(err, ErrIterationBudgetExceeded))
+}
+
+func TestBudgetRecurrence(t *testing.T) {
+	dtstart := time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sparse := MustRRule("FREQ=YEARLY;BYYEARDAY=366")
+
+	// the dates would follow the sparse pattern's first time, so the
+	// budget ends the recurrence before them.
+	r := Recurrence{
+		Dtstart: dtstart,
+		RRules:  []RRule{sparse},
+		RDates:  []time.Time{dtstart.AddDate(5, 0, 0)},
+	}
+	it := WithBudget(r.Iterator(), Budget{MaxCandidates: 2})
+	assert.Nil(t, it.Next())
+	assert.True(t, errors.Is(IteratorErr(it), ErrIterationBudgetExceeded))
+
+	// a time that an exception might remove isn't returned either.
+	r = Recurrence{
+		Dtstart: dtstart,
+		RDates:  []time.Time{dtstart.AddDate(5, 0, 0)},
+		ExRules: []RRule{sparse},
+	}
+	it = WithBudget(r.Iterator(), Budget{MaxCandidates: 2})
+	assert.Nil(t, it.Next())
+	assert.True(t, errors.Is(IteratorErr(it), ErrIterationBudgetExceeded))
+
+	it = WithBudget(r.Iterator(), Budget{MaxCandidates: 4})
+	require.NotNil(t, it.Next())
+	assert.NoError(t, IteratorErr(it))
+}
@@ -0,0 +1,231 @@
+package rrule
+
+import (
+	"time"
+)
+
+// Weekdays for Builder.ByDay. Nth qualifies them, so MO.Nth(-1) is the last
+// Monday.
+var (
+	MO = QualifiedWeekday{WD: time.Monday}
+	TU = QualifiedWeekday{WD: time.Tuesday}
+	WE = QualifiedWeekday{WD: time.Wednesday}
+	TH = QualifiedWeekday{WD: time.Thursday}
+	FR = QualifiedWeekday{WD: time.Friday}
+	SA = QualifiedWeekday{WD: time.Saturday}
+	SU = QualifiedWeekday{WD: time.Sunday}
+)
+
+// Nth returns the n-th instance of the weekday, counted from the end if n is
+// negative.
+func (wd QualifiedWeekday) Nth(n int) QualifiedWeekday {
+	return QualifiedWeekday{N: n, WD: wd.WD}
+}
+
+// Builder constructs an RRule one part at a time, so that callers needn't
+// know the conventions of its zero values, like an Interval of 0 meaning 1.
+// Each method sets a part and returns the Builder, so that they can be
+// chained:
+//
+//	rrule.New(rrule.Weekly).Interval(2).ByDay(rrule.MO, rrule.WE).Count(10).Build()
+//
+// Problems are reported by Build.
+type Builder struct {
+	rrule RRule
+	err   error
+}
+
+// New returns a Builder for a pattern of frequency freq.
+func New(freq Frequency) *Builder {
+	b := &Builder{rrule: RRule{Frequency: freq}}
+	if freq < Secondly || freq > Yearly {
+		b.fail(kindErrorf(ErrUnsupportedFrequency, "invalid frequency %d", freq))
+	}
+	return b
+}
+
+// fail records err, unless a problem was recorded already.
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Dtstart sets the start of the pattern. If not set, Now is used when an
+// iterator is generated.
+func (b *Builder) Dtstart(t time.Time) *Builder {
+	b.rrule.Dtstart = t
+	return b
+}
+
+// Interval sets how many periods of the frequency pass between occurrences,
+// which must be at least 1.
+func (b *Builder) Interval(n int) *Builder {
+	if n < 1 {
+		b.fail(kindErrorf(ErrOutOfRange, "INTERVAL must be at least 1"))
+	}
+	b.rrule.Interval = n
+	return b
+}
+
+// Count limits the pattern to n occurrences, which must be at least 1.
+func (b *Builder) Count(n int) *Builder {
+	if n < 1 {
+		b.fail(kindErrorf(ErrOutOfRange, "COUNT must be at least 1"))
+		n = 0
+	}
+	b.rrule.Count = uint64(n)
+	return b
+}
+
+// Until ends the pattern at t, which must not be zero.
+func (b *Builder) Until(t time.Time) *Builder {
+	if t.IsZero() {
+		b.fail(kindErrorf(ErrOutOfRange, "UNTIL must not be zero"))
+	}
+	b.rrule.Until = t
+	return b
+}
+
+// ByDay limits or expands the pattern to the weekdays wds.
+func (b *Builder) ByDay(wds ...QualifiedWeekday) *Builder {
+	b.rrule.ByWeekdays = append(b.rrule.ByWeekdays, wds...)
+	return b
+}
+
+// ByMonthDay limits or expands the pattern to the days of the month days,
+// counted from the end if negative.
+func (b *Builder) ByMonthDay(days ...int) *Builder {
+	b.rrule.ByMonthDays = append(b.rrule.ByMonthDays, days...)
+	return b
+}
+
+// ByMonth limits or expands the pattern to the months ms.
+func (b *Builder) ByMonth(ms ...time.Month) *Builder {
+	b.rrule.ByMonths = append(b.rrule.ByMonths, ms...)
+	return b
+}
+
+// ByYearDay limits or expands the pattern to the days of the year days,
+// counted from the end if negative.
+func (b *Builder) ByYearDay(days ...int) *Builder {
+	b.rrule.ByYearDays = append(b.rrule.ByYearDays, days...)
+	return b
+}
+
+// ByWeekNo expands the pattern to the weeks of the year weeks, counted from
+// the end if negative.
+func (b *Builder) ByWeekNo(weeks ...int) *Builder {
+	b.rrule.ByWeekNumbers = append(b.rrule.ByWeekNumbers, weeks...)
+	return b
+}
+
+// ByHour limits or expands the pattern to the hours hours.
+func (b *Builder) ByHour(hours ...int) *Builder {
+	b.rrule.ByHours = append(b.rrule.ByHours, hours...)
+	return b
+}
+
+// ByMinute limits or expands the pattern to the minutes minutes.
+func (b *Builder) ByMinute(minutes ...int) *Builder {
+	b.rrule.ByMinutes = append(b.rrule.ByMinutes, minutes...)
+	return b
+}
+
+// BySecond limits or expands the pattern to the seconds seconds.
+func (b *Builder) BySecond(seconds ...int) *Builder {
+	b.rrule.BySeconds = append(b.rrule.BySeconds, seconds...)
+	return b
+}
+
+// BySetPos picks the occurrences at positions within each period, counted
+// from the end if negative.
+func (b *Builder) BySetPos(positions ...int) *Builder {
+	b.rrule.BySetPos = append(b.rrule.BySetPos, positions...)
+	return b
+}
+
+// WeekStart sets the day weeks start on, which is DefaultWeekStart if not set.
+func (b *Builder) WeekStart(wd time.Weekday) *Builder {
+	b.rrule.WeekStart = WeekStartOn(wd)
+	return b
+}
+
+// Skip sets how dates that don't exist, like February 30th, are treated.
+func (b *Builder) Skip(ib InvalidBehavior) *Builder {
+	b.rrule.InvalidBehavior = ib
+	return b
+}
+
+// Build returns the pattern, or the first problem with it, checked as by
+// Validate.
+func (b *Builder) Build() (RRule, error) {
+	if b.err != nil {
+		return RRule{}, b.err
+	}
+	if err := b.rrule.Validate(); err != nil {
+		return RRule{}, err
+	}
+	return b.rrule, nil
+}
+
+// Option sets a part of the pattern made by NewRRule. Each is the Builder
+// method of the same name, so the same checks apply.
+type Option func(*Builder)
+
+// NewRRule returns a pattern of frequency freq with opts applied, or the first
+// problem with it, as Build does.
+//
+//	rrule.NewRRule(rrule.Weekly, rrule.WithInterval(2), rrule.WithByDay(rrule.MO, rrule.WE), rrule.WithCount(10))
+func NewRRule(freq Frequency, opts ...Option) (RRule, error) {
+	b := New(freq)
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b.Build()
+}
+
+// WithDtstart sets the start of the pattern, as Builder.Dtstart does.
+func WithDtstart(t time.Time) Option { return func(b *Builder) { b.Dtstart(t) } }
+
+// WithInterval sets the interval of the pattern, as Builder.Interval does.
+func WithInterval(n int) Option { return func(b *Builder) { b.Interval(n) } }
+
+// WithCount limits the number of occurrences, as Builder.Count does.
+func WithCount(n int) Option { return func(b *Builder) { b.Count(n) } }
+
+// WithUntil sets the end of the pattern, as Builder.Until does.
+func WithUntil(t time.Time) Option { return func(b *Builder) { b.Until(t) } }
+
+// WithByDay adds weekdays, as Builder.ByDay does.
+func WithByDay(wds ...QualifiedWeekday) Option { return func(b *Builder) { b.ByDay(wds...) } }
+
+// WithByMonthDay adds days of the month, as Builder.ByMonthDay does.
+func WithByMonthDay(days ...int) Option { return func(b *Builder) { b.ByMonthDay(days...) } }
+
+// WithByMonth adds months, as Builder.ByMonth does.
+func WithByMonth(ms ...time.Month) Option { return func(b *Builder) { b.ByMonth(ms...) } }
+
+// WithByYearDay adds days of the year, as Builder.ByYearDay does.
+func WithByYearDay(days ...int) Option { return func(b *Builder) { b.ByYearDay(days...) } }
+
+// WithByWeekNo adds weeks of the year, as Builder.ByWeekNo does.
+func WithByWeekNo(weeks ...int) Option { return func(b *Builder) { b.ByWeekNo(weeks...) } }
+
+// WithByHour adds hours, as Builder.ByHour does.
+func WithByHour(hours ...int) Option { return func(b *Builder) { b.ByHour(hours...) } }
+
+// WithByMinute adds minutes, as Builder.ByMinute does.
+func WithByMinute(minutes ...int) Option { return func(b *Builder) { b.ByMinute(minutes...) } }
+
+// WithBySecond adds seconds, as Builder.BySecond does.
+func WithBySecond(seconds ...int) Option { return func(b *Builder) { b.BySecond(seconds...) } }
+
+// WithBySetPos adds set positions, as Builder.BySetPos does.
+func WithBySetPos(positions ...int) Option { return func(b *Builder) { b.BySetPos(positions...) } }
+
+// WithWeekStart sets the day weeks start on, as Builder.WeekStart does.
+func WithWeekStart(wd time.Weekday) Option { return func(b *Builder) { b.WeekStart(wd) } }
+
+// WithSkip sets how dates that don't exist are treated, as Builder.Skip does.
+func WithSkip(ib InvalidBehavior) Option { return func(b *Builder) { b.Skip(ib) } }
@@ -0,0 +1,62 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	rrule, err := New(Weekly).Interval(2).ByDay(MO, WE).Count(10).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=10;INTERVAL=2;BYDAY=MO,WE", rrule.String())
+
+	rrule, err = New(Monthly).Dtstart(now).ByDay(FR.Nth(-1)).ByMonth(time.March, time.June).WeekStart(time.Sunday).Skip(NextInvalid).Until(now.AddDate(1, 0, 0)).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;UNTIL=20190825T090807Z;BYDAY=-1FR;BYMONTH=3,6;WKST=SU;SKIP=FORWARD;RSCALE=GREGORIAN", rrule.String())
+	assert.Equal(t, []string{"2019-03-29T09:08:07Z", "2019-06-28T09:08:07Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule, err = New(Daily).ByHour(9).ByMinute(30).BySecond(0).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;BYSECOND=0;BYMINUTE=30;BYHOUR=9", rrule.String())
+}
+
+func TestBuilderErrors(t *testing.T) {
+	for _, tc := range []struct {
+		builder *Builder
+		kind    error
+		msg     string
+	}{
+		{New(Frequency(9)), ErrUnsupportedFrequency, "invalid frequency 9"},
+		{New(Daily).Interval(0), ErrOutOfRange, "INTERVAL must be at least 1"},
+		{New(Daily).Count(0), ErrOutOfRange, "COUNT must be at least 1"},
+		{New(Daily).Until(time.Time{}), ErrOutOfRange, "UNTIL must not be zero"},
+		{New(Daily).Count(2).Until(now), ErrCountAndUntil, ErrCountAndUntil.Error()},
+		{New(Weekly).ByMonthDay(1), ErrWeeklyByMonthDay, ErrWeeklyByMonthDay.Error()},
+		{New(Yearly).ByMonth(time.February).ByMonthDay(30), ErrNoOccurrences, "BYMONTHDAY=30 never falls in BYMONTH=2"},
+	} {
+		_, err := tc.builder.Build()
+		assert.True(t, errors.Is(err, tc.kind), "%v", err)
+		assert.EqualError(t, err, tc.msg)
+	}
+}
+
+func TestNewRRule(t *testing.T) {
+	rrule, err := NewRRule(Weekly, WithInterval(2), WithByDay(MO, WE), WithCount(10))
+	require.NoError(t, err)
+	built, _ := New(Weekly).Interval(2).ByDay(MO, WE).Count(10).Build()
+	assert.Equal(t, built, rrule)
+
+	rrule, err = NewRRule(Monthly, WithDtstart(now), WithUntil(now.AddDate(0, 3, 0)), WithByMonthDay(1, -1), WithBySetPos(-1), WithWeekStart(time.Sunday))
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;UNTIL=20181125T090807Z;BYMONTHDAY=1,-1;BYSETPOS=-1;WKST=SU", rrule.String())
+	assert.Equal(t, []string{"2018-08-31T09:08:07Z", "2018-09-30T09:08:07Z", "2018-10-31T09:08:07Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	_, err = NewRRule(Daily, WithCount(2), WithUntil(now))
+	assert.Equal(t, ErrCountAndUntil, err)
+	_, err = NewRRule(Daily, WithInterval(-1))
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
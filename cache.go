@@ -0,0 +1,152 @@
+package rrule
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// ExpansionCache memoizes the instances of patterns and recurrences in a
+// window, for RRule.ExpandCached and Recurrence.ExpandCached. Implementations
+// must be safe for concurrent use. NewLRUCache returns one kept in memory.
+type ExpansionCache interface {
+	// Get returns the instances added for key, and whether there were any.
+	Get(key ExpansionKey) ([]time.Time, bool)
+
+	// Add records the instances for key. The cache owns tt.
+	Add(key ExpansionKey, tt []time.Time)
+}
+
+// ExpansionKey identifies the expansion of a pattern or recurrence in a
+// window. Keys can be compared with ==.
+type ExpansionKey struct {
+	// Fingerprint identifies the pattern or recurrence, including its
+	// Dtstart. Those with the same fingerprint have the same instances.
+	Fingerprint string
+
+	// Start and End are the window, in UTC.
+	Start, End time.Time
+}
+
+func newExpansionKey(fingerprint []byte, start, end time.Time) ExpansionKey {
+	// times compare with == only in the same location and without a
+	// monotonic clock reading.
+	return ExpansionKey{
+		Fingerprint: string(fingerprint),
+		Start:       start.Round(0).UTC(),
+		End:         end.Round(0).UTC(),
+	}
+}
+
+// ExpandCached returns the instances of the pattern at or after start and
+// before end, like Expand, remembering them in cache for the next call with an
+// identical pattern and window. The returned slice is the caller's own. A
+// pattern without Dtstart starts at the time of the call, so its instances are
+// never cached.
+func (rrule RRule) ExpandCached(cache ExpansionCache, start, end time.Time) []time.Time {
+	if rrule.Dtstart.IsZero() {
+		return rrule.Expand(start, end)
+	}
+
+	key := newExpansionKey(rrule.appendProto(nil), start, end)
+	return expandCached(cache, key, func() []time.Time {
+		return rrule.Expand(start, end)
+	})
+}
+
+// ExpandCached returns the instances of the recurrence at or after start and
+// before end, like Expand, remembering them in cache for the next call with an
+// identical recurrence and window. The returned slice is the caller's own. A
+// recurrence without Dtstart starts at the time of the call, so its instances
+// are never cached.
+func (r Recurrence) ExpandCached(cache ExpansionCache, start, end time.Time) []time.Time {
+	if r.Dtstart.IsZero() {
+		return r.Expand(start, end)
+	}
+
+	fingerprint, err := r.ToProto()
+	if err != nil {
+		// the recurrence is invalid, which Expand reports.
+		return r.Expand(start, end)
+	}
+	return expandCached(cache, newExpansionKey(fingerprint, start, end), func() []time.Time {
+		return r.Expand(start, end)
+	})
+}
+
+func expandCached(cache ExpansionCache, key ExpansionKey, expand func() []time.Time) []time.Time {
+	tt, ok := cache.Get(key)
+	if !ok {
+		tt = expand()
+		cache.Add(key, tt)
+	}
+	if len(tt) == 0 {
+		return nil
+	}
+	return append([]time.Time(nil), tt...)
+}
+
+// LRUCache is an ExpansionCache that keeps the most recently used expansions
+// in memory, up to a fixed number of them.
+type LRUCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[ExpansionKey]*list.Element
+
+	// order holds the entries, most recently used first.
+	order *list.List
+}
+
+type lruEntry struct {
+	key ExpansionKey
+	tt  []time.Time
+}
+
+// NewLRUCache returns an LRUCache holding up to size expansions.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    size,
+		entries: make(map[ExpansionKey]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get returns the instances added for key, and whether there were any.
+func (c *LRUCache) Get(key ExpansionKey) ([]time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).tt, true
+}
+
+// Add records the instances for key, evicting the least recently used
+// expansion if the cache is full.
+func (c *LRUCache) Add(key ExpansionKey, tt []time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).tt = tt
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, tt: tt})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of expansions in the cache.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
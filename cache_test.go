@@ -0,0 +1,119 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCache counts the expansions looked up in an LRUCache.
+type countingCache struct {
+	*LRUCache
+	hits, misses int
+}
+
+func (c *countingCache) Get(key ExpansionKey) ([]time.Time, bool) {
+	tt, ok := c.LRUCache.Get(key)
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return tt, ok
+}
+
+func TestExpandCached(t *testing.T) {
+	cache := &countingCache{LRUCache: NewLRUCache(10)}
+	rrule := MustRRule("FREQ=WEEKLY;BYDAY=MO,FR")
+	rrule.Dtstart = now
+
+	start := time.Date(2018, time.September, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	want := rrule.Expand(start, end)
+	require.NotEmpty(t, want)
+
+	assert.Equal(t, want, rrule.ExpandCached(cache, start, end))
+	got := rrule.ExpandCached(cache, start.In(NewYork()), end)
+	assert.Equal(t, want, got)
+	assert.Equal(t, 1, cache.hits)
+	assert.Equal(t, 1, cache.misses)
+
+	// the caller's slice is its own.
+	got[0] = time.Time{}
+	assert.Equal(t, want, rrule.ExpandCached(cache, start, end))
+
+	// a different window or pattern is expanded again.
+	assert.Equal(t, rrule.Expand(start, end.AddDate(0, 1, 0)), rrule.ExpandCached(cache, start, end.AddDate(0, 1, 0)))
+	rrule.Dtstart = now.In(NewYork())
+	assert.Equal(t, rrule.Expand(start, end), rrule.ExpandCached(cache, start, end))
+	rrule.UntilExclusive = true
+	assert.Equal(t, rrule.Expand(start, end), rrule.ExpandCached(cache, start, end))
+	assert.Equal(t, 2, cache.hits)
+	assert.Equal(t, 4, cache.misses)
+
+	// without Dtstart, the pattern starts now, so isn't cached.
+	rrule.Dtstart = time.Time{}
+	rrule.ExpandCached(cache, start, end)
+	assert.Equal(t, 4, cache.Len())
+}
+
+func TestExpandCachedRecurrence(t *testing.T) {
+	cache := &countingCache{LRUCache: NewLRUCache(10)}
+	r := Recurrence{
+		Dtstart: now,
+		RRules:  []RRule{MustRRule("FREQ=DAILY;COUNT=10")},
+		ExRules: []RRule{MustRRule("FREQ=DAILY;INTERVAL=3")},
+	}
+
+	start, end := now, now.AddDate(0, 1, 0)
+	want := r.Expand(start, end)
+	assert.Equal(t, want, r.ExpandCached(cache, start, end))
+	assert.Equal(t, want, r.ExpandCached(cache, start, end))
+	assert.Equal(t, 1, cache.hits)
+
+	r.RDates = []time.Time{end.AddDate(0, 0, -1)}
+	assert.Equal(t, r.Expand(start, end), r.ExpandCached(cache, start, end))
+	assert.Equal(t, 1, cache.hits)
+}
+
+func TestLRUCache(t *testing.T) {
+	cache := NewLRUCache(2)
+	key := func(n int) ExpansionKey {
+		return ExpansionKey{Fingerprint: "pattern", Start: now.AddDate(0, n, 0), End: now.AddDate(0, n+1, 0)}
+	}
+
+	cache.Add(key(0), []time.Time{now})
+	cache.Add(key(1), nil)
+	_, ok := cache.Get(key(0))
+	assert.True(t, ok)
+
+	// the least recently used key is evicted.
+	cache.Add(key(2), nil)
+	assert.Equal(t, 2, cache.Len())
+	_, ok = cache.Get(key(1))
+	assert.False(t, ok)
+	tt, ok := cache.Get(key(0))
+	assert.True(t, ok)
+	assert.Equal(t, []time.Time{now}, tt)
+
+	cache.Add(key(0), nil)
+	tt, ok = cache.Get(key(0))
+	assert.True(t, ok)
+	assert.Empty(t, tt)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func BenchmarkExpandCached(b *testing.B) {
+	rrule := MustRRule("FREQ=WEEKLY;BYDAY=MO,WE,FR;BYHOUR=9,13")
+	rrule.Dtstart = now
+	start := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	cache := NewLRUCache(10)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rrule.ExpandCached(cache, start, end)
+	}
+}
@@ -0,0 +1,316 @@
+package rrule
+
+import (
+	"sort"
+	"time"
+)
+
+// calendar is a calendar scale other than Gregorian, as named by RSCALE.
+// Dates are converted to and from Gregorian through Julian day numbers.
+type calendar interface {
+	// date returns the calendar date of the day with Julian day number jdn.
+	date(jdn int) calendarDate
+
+	// months returns the months of year, in order.
+	months(year int) []calendarMonth
+
+	// monthStart returns the Julian day number of the first day of month in
+	// year. The month must be one of months(year).
+	monthStart(year int, month calendarMonth) int
+
+	// monthLength returns the number of days in month of year. The month must
+	// be one of months(year).
+	monthLength(year int, month calendarMonth) int
+}
+
+// calendarMonth identifies a month within a calendar year. Leap is set for
+// months, like the Chinese leap months, that share a number with the month
+// before them.
+type calendarMonth struct {
+	number int
+	leap   bool
+}
+
+type calendarDate struct {
+	year  int
+	month calendarMonth
+	day   int
+}
+
+// unixEpochJDN is the Julian day number of January 1st, 1970.
+const unixEpochJDN = 2440588
+
+// julianDay returns the Julian day number of the date of t, as observed in t's
+// location.
+func julianDay(t time.Time) int {
+	y, m, d := t.Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix()/(24*60*60)) + unixEpochJDN
+}
+
+// fromJulianDay returns the time on the day with Julian day number jdn that has
+// the same clock time and location as clock.
+func fromJulianDay(jdn int, clock time.Time) time.Time {
+	g := time.Unix(int64(jdn-unixEpochJDN)*24*60*60, 0).UTC()
+	return time.Date(g.Year(), g.Month(), g.Day(), clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), clock.Location())
+}
+
+// monthIndex returns the position of month within the months of year, or -1 if
+// year doesn't have that month.
+func monthIndex(cal calendar, year int, month calendarMonth) int {
+	for i, m := range cal.months(year) {
+		if m == month {
+			return i
+		}
+	}
+	return -1
+}
+
+// resolveMonth returns month if year has it. Otherwise, if month is a leap
+// month, ib decides whether the month before or after it is used instead, as
+// in RFC 7529.
+func resolveMonth(cal calendar, year int, month calendarMonth, ib InvalidBehavior) (calendarMonth, bool) {
+	if monthIndex(cal, year, month) >= 0 {
+		return month, true
+	}
+	if !month.leap {
+		return month, false
+	}
+
+	switch ib {
+	case PrevInvalid:
+		month = calendarMonth{number: month.number}
+	case NextInvalid:
+		month = calendarMonth{number: month.number + 1}
+	default:
+		return month, false
+	}
+
+	return month, monthIndex(cal, year, month) >= 0
+}
+
+// resolveMonthDay returns the Julian day number of day in month of year. A
+// negative day counts back from the end of the month. If the month doesn't
+// have that day, ib decides what is used instead, as in RFC 7529.
+func resolveMonthDay(cal calendar, year int, month calendarMonth, day int, ib InvalidBehavior) (int, bool) {
+	start := cal.monthStart(year, month)
+	length := cal.monthLength(year, month)
+
+	if day < 0 {
+		day += length + 1
+	}
+
+	switch {
+	case day >= 1 && day <= length:
+		return start + day - 1, true
+	case ib == PrevInvalid && day < 1:
+		return start - 1, true
+	case ib == PrevInvalid:
+		return start + length - 1, true
+	case ib == NextInvalid && day < 1:
+		return start, true
+	case ib == NextInvalid:
+		return start + length, true
+	}
+
+	return 0, false
+}
+
+// monthWeekdays returns the Julian day numbers of the days in month of year
+// that match weekdays, where a numeric component picks the nth such weekday of
+// the month.
+func monthWeekdays(cal calendar, year int, month calendarMonth, weekdays []QualifiedWeekday) []int {
+	start := cal.monthStart(year, month)
+	length := cal.monthLength(year, month)
+
+	var days []int
+	for _, wd := range weekdays {
+		var matches []int
+		for jdn := start; jdn < start+length; jdn++ {
+			// Julian day 0 was a Monday.
+			if time.Weekday((jdn+1)%7) == wd.WD {
+				matches = append(matches, jdn)
+			}
+		}
+
+		switch {
+		case wd.N == 0:
+			days = append(days, matches...)
+		case wd.N > 0 && wd.N <= len(matches):
+			days = append(days, matches[wd.N-1])
+		case wd.N < 0 && -wd.N <= len(matches):
+			days = append(days, matches[len(matches)+wd.N])
+		}
+	}
+
+	return days
+}
+
+// setCalendar returns an iterator for a YEARLY or MONTHLY pattern whose months
+// and days are counted in cal.
+func setCalendar(rrule RRule, cal calendar) *iterator {
+	start := rrule.dtstart()
+
+	interval := 1
+	if rrule.Interval > 0 {
+		interval = rrule.Interval
+	}
+
+	startDate := cal.date(julianDay(start))
+
+	var byMonths []calendarMonth
+	for _, m := range rrule.ByMonths {
+		byMonths = append(byMonths, calendarMonth{number: int(m)})
+	}
+	for _, m := range rrule.ByLeapMonths {
+		byMonths = append(byMonths, calendarMonth{number: m, leap: true})
+	}
+
+	byMonthDays := rrule.ByMonthDays
+	if len(byMonthDays) == 0 && len(rrule.ByWeekdays) == 0 {
+		byMonthDays = []int{startDate.day}
+	}
+
+	s := &calendarSteps{
+		rrule:       rrule,
+		cal:         cal,
+		start:       start,
+		startDate:   startDate,
+		interval:    interval,
+		byMonths:    byMonths,
+		byMonthDays: byMonthDays,
+	}
+	s.reset()
+
+	return &iterator{
+		minTime:  start,
+		maxTime:  timeOrMax(rrule.Until),
+		queueCap: rrule.Count,
+		steps:    s,
+	}
+}
+
+// calendarSteps steps through the years or months of a pattern in a calendar
+// other than the Gregorian.
+type calendarSteps struct {
+	rrule     RRule
+	cal       calendar
+	start     time.Time
+	startDate calendarDate
+	interval  int
+
+	byMonths    []calendarMonth
+	byMonthDays []int
+
+	// year and monthIdx track the start of the next period.
+	year     int
+	monthIdx int
+}
+
+func (s *calendarSteps) next() (time.Time, bool) {
+	// the calendar may not cover every year.
+	months := s.cal.months(s.year)
+	if len(months) == 0 || s.monthIdx < 0 {
+		return time.Time{}, false
+	}
+
+	var jdn int
+	if s.rrule.Frequency == Yearly {
+		jdn = s.cal.monthStart(s.year, months[0])
+		s.year += s.interval
+	} else {
+		jdn = s.cal.monthStart(s.year, months[s.monthIdx])
+		s.monthIdx += s.interval
+		for s.monthIdx >= len(months) && len(months) > 0 {
+			s.monthIdx -= len(months)
+			s.year++
+			months = s.cal.months(s.year)
+		}
+	}
+
+	return fromJulianDay(jdn, s.start), true
+}
+
+func (s *calendarSteps) valid(t time.Time) bool { return true }
+
+func (s *calendarSteps) variations(t time.Time) []time.Time {
+	rrule, cal := s.rrule, s.cal
+	period := cal.date(julianDay(t))
+
+	months := s.byMonths
+	if rrule.Frequency == Monthly {
+		months = []calendarMonth{period.month}
+		if len(s.byMonths) > 0 && !containsMonth(s.byMonths, period.month) {
+			return nil
+		}
+	} else if len(months) == 0 {
+		months = []calendarMonth{s.startDate.month}
+	}
+
+	var days []int
+	for _, month := range months {
+		month, ok := resolveMonth(cal, period.year, month, rrule.InvalidBehavior)
+		if !ok {
+			continue
+		}
+
+		if len(s.byMonthDays) == 0 {
+			days = append(days, monthWeekdays(cal, period.year, month, rrule.ByWeekdays)...)
+			continue
+		}
+
+		for _, day := range s.byMonthDays {
+			if jdn, ok := resolveMonthDay(cal, period.year, month, day, rrule.InvalidBehavior); ok {
+				days = append(days, jdn)
+			}
+		}
+	}
+
+	isWeekday := validWeekday(rrule.ByWeekdays)
+
+	tt := make([]time.Time, 0, len(days))
+	for _, jdn := range days {
+		day := fromJulianDay(jdn, s.start)
+		if len(rrule.ByMonthDays) > 0 && !isWeekday(&day) {
+			continue
+		}
+		tt = append(tt, day)
+	}
+
+	tt = expandByHours(tt, rrule.ByHours...)
+	tt = expandByMinutes(tt, rrule.ByMinutes...)
+	tt = expandBySeconds(tt, rrule.BySeconds...)
+
+	sort.Slice(tt, func(i, j int) bool { return tt[i].Before(tt[j]) })
+	tt = uniqueTimes(tt)
+
+	return limitBySetPos(tt, rrule.BySetPos)
+}
+
+func (s *calendarSteps) reset() {
+	s.year = s.startDate.year
+	s.monthIdx = monthIndex(s.cal, s.year, s.startDate.month)
+}
+
+func containsMonth(months []calendarMonth, month calendarMonth) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueTimes removes repeated times from sorted tt.
+func uniqueTimes(tt []time.Time) []time.Time {
+	if len(tt) < 2 {
+		return tt
+	}
+
+	unique := tt[:1]
+	for _, t := range tt[1:] {
+		if !t.Equal(unique[len(unique)-1]) {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,276 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIslamicCivil(t *testing.T) {
+	cal := islamicCivil{}
+
+	assert.Equal(t, islamicCivilEpoch, julianDay(time.Date(622, time.July, 19, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, calendarDate{year: 1, month: calendarMonth{number: 1}, day: 1}, cal.date(islamicCivilEpoch))
+	assert.Equal(t, calendarDate{year: 1445, month: calendarMonth{number: 9}, day: 1}, cal.date(julianDay(time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC))))
+
+	var lengths []int
+	for year := 1440; year < 1450; year++ {
+		lengths = append(lengths, cal.monthLength(year, calendarMonth{number: 12}))
+	}
+	assert.Equal(t, []int{29, 29, 30, 29, 29, 30, 29, 30, 29, 29}, lengths)
+
+	for jdn := islamicCivilEpoch - 400; jdn < islamicCivilEpoch+40000; jdn += 13 {
+		d := cal.date(jdn)
+		assert.Equal(t, jdn, cal.monthStart(d.year, d.month)+d.day-1)
+	}
+}
+
+func TestRScaleIslamicCivil(t *testing.T) {
+	rrule, err := ParseRRule("RSCALE=ISLAMIC-CIVIL;FREQ=YEARLY;BYMONTH=9;BYMONTHDAY=1;COUNT=4")
+	require.NoError(t, err)
+	assert.Equal(t, IslamicCivil, rrule.RScale)
+	assert.Equal(t, "FREQ=YEARLY;COUNT=4;BYMONTHDAY=1;BYMONTH=9;RSCALE=ISLAMIC-CIVIL", rrule.String())
+
+	rrule.Dtstart = time.Date(2024, time.March, 11, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{
+		"2024-03-11T09:00:00Z",
+		"2025-03-01T09:00:00Z",
+		"2026-02-18T09:00:00Z",
+		"2027-02-08T09:00:00Z",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	// the 30th of every month, or the 29th in months that are shorter.
+	rrule, err = ParseRRule("FREQ=MONTHLY;BYMONTHDAY=30;SKIP=BACKWARD;RSCALE=ISLAMIC-CIVIL;COUNT=4")
+	require.NoError(t, err)
+	rrule.Dtstart = time.Date(2024, time.March, 11, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{
+		"2024-04-09T09:00:00Z",
+		"2024-05-08T09:00:00Z",
+		"2024-06-07T09:00:00Z",
+		"2024-07-07T09:00:00Z",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule.InvalidBehavior = OmitInvalid
+	assert.Equal(t, []string{
+		"2024-04-09T09:00:00Z",
+		"2024-06-07T09:00:00Z",
+		"2024-07-07T09:00:00Z",
+		"2024-08-06T09:00:00Z",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	// the first Friday of Ramadan.
+	rrule, err = ParseRRule("RSCALE=ISLAMIC-CIVIL;FREQ=YEARLY;BYMONTH=9;BYDAY=1FR;COUNT=2")
+	require.NoError(t, err)
+	rrule.Dtstart = time.Date(2024, time.March, 11, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-03-15T09:00:00Z", "2025-03-07T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	for _, str := range []string{
+		"RSCALE=ISLAMIC-CIVIL;FREQ=YEARLY;BYYEARDAY=1",
+		"RSCALE=ISLAMIC-CIVIL;FREQ=YEARLY;BYDAY=FR",
+		"RSCALE=ISLAMIC-CIVIL;FREQ=DAILY;BYMONTH=9",
+	} {
+		_, err := ParseRRule(str)
+		assert.Error(t, err, str)
+	}
+}
+
+func TestChinese(t *testing.T) {
+	cal := chinese{}
+
+	assert.Equal(t, calendarDate{year: 2024, month: calendarMonth{number: 1}, day: 1}, cal.date(julianDay(time.Date(2024, time.February, 10, 0, 0, 0, 0, time.UTC))))
+	assert.Equal(t, calendarDate{year: 2023, month: calendarMonth{number: 2, leap: true}, day: 1}, cal.date(julianDay(time.Date(2023, time.March, 22, 0, 0, 0, 0, time.UTC))))
+	assert.Equal(t, calendarDate{}, cal.date(julianDay(time.Date(1899, time.December, 31, 0, 0, 0, 0, time.UTC))))
+	assert.Len(t, cal.months(2023), 13)
+	assert.Len(t, cal.months(2024), 12)
+
+	for jdn := chineseFirstNewYear; jdn < chineseNewYears[len(chineseYears)]; jdn += 7 {
+		d := cal.date(jdn)
+		assert.Equal(t, jdn, cal.monthStart(d.year, d.month)+d.day-1)
+	}
+}
+
+func TestRScaleChinese(t *testing.T) {
+	// the lunar new year.
+	rrule, err := ParseRRule("RSCALE=CHINESE;FREQ=YEARLY;COUNT=3")
+	require.NoError(t, err)
+	rrule.Dtstart = time.Date(2024, time.February, 10, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-02-10T09:00:00Z", "2025-01-29T09:00:00Z", "2026-02-17T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule, err = ParseRRule("RSCALE=CHINESE;FREQ=YEARLY;BYMONTH=6L;BYMONTHDAY=1;SKIP=BACKWARD;COUNT=3")
+	require.NoError(t, err)
+	assert.Equal(t, []int{6}, rrule.ByLeapMonths)
+	assert.Empty(t, rrule.ByMonths)
+	assert.Equal(t, "FREQ=YEARLY;COUNT=3;BYMONTHDAY=1;BYMONTH=6L;SKIP=BACKWARD;RSCALE=CHINESE", rrule.String())
+
+	rrule.Dtstart = time.Date(2025, time.January, 29, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2025-07-25T09:00:00Z", "2026-07-14T09:00:00Z", "2027-07-04T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule.InvalidBehavior = OmitInvalid
+	rrule.Count = 2
+	rrule.Dtstart = time.Date(1930, time.January, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"1930-07-26T09:00:00Z", "1941-07-24T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule, err = ParseRRule("RSCALE=CHINESE;FREQ=YEARLY;BYMONTH=1,6L;BYMONTHDAY=1")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=YEARLY;BYMONTHDAY=1;BYMONTH=1,6L;RSCALE=CHINESE", rrule.String())
+
+	// the supported years end with 2100.
+	rrule, err = ParseRRule("RSCALE=CHINESE;FREQ=MONTHLY")
+	require.NoError(t, err)
+	rrule.Dtstart = time.Date(2100, time.December, 1, 9, 0, 0, 0, time.UTC)
+	assert.Len(t, All(rrule.Iterator(), 0), 2)
+
+}
+
+func TestRScaleBuddhistJapanese(t *testing.T) {
+	rrule, err := ParseRRule("RSCALE=BUDDHIST;FREQ=MONTHLY;BYMONTHDAY=31;SKIP=BACKWARD;UNTIL=20240501T000000Z")
+	require.NoError(t, err)
+	assert.Equal(t, Buddhist, rrule.RScale)
+	assert.Equal(t, "FREQ=MONTHLY;UNTIL=20240501T000000Z;BYMONTHDAY=31;SKIP=BACKWARD;RSCALE=BUDDHIST", rrule.String())
+
+	// UNTIL is Gregorian, whatever the calendar scale.
+	rrule.Dtstart = time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-01-31T09:00:00Z", "2024-02-29T09:00:00Z", "2024-03-31T09:00:00Z", "2024-04-30T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule, err = ParseRRule("RSCALE=JAPANESE;FREQ=YEARLY;COUNT=2")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=YEARLY;COUNT=2;RSCALE=JAPANESE", rrule.String())
+
+	cases := []struct {
+		RScale RScale
+		Time   time.Time
+		Era    string
+		Year   int
+	}{
+		{Gregorian, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "", 2024},
+		{Buddhist, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "", 2567},
+		{Japanese, time.Date(2019, time.April, 30, 23, 0, 0, 0, time.UTC), "Heisei", 31},
+		{Japanese, time.Date(2019, time.May, 1, 0, 0, 0, 0, time.UTC), "Reiwa", 1},
+		{Japanese, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), "Reiwa", 6},
+		{Japanese, time.Date(1926, time.December, 24, 0, 0, 0, 0, time.UTC), "Taisho", 15},
+		{Japanese, time.Date(1850, time.January, 1, 0, 0, 0, 0, time.UTC), "", 1850},
+		{IslamicCivil, time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC), "", 1445},
+		{Chinese, time.Date(2024, time.February, 9, 0, 0, 0, 0, time.UTC), "", 2023},
+	}
+
+	for _, tc := range cases {
+		era, year := tc.RScale.Year(tc.Time)
+		assert.Equal(t, tc.Era, era, "%v %v", tc.RScale, tc.Time)
+		assert.Equal(t, tc.Year, year, "%v %v", tc.RScale, tc.Time)
+	}
+}
+
+func TestLeapMonthsWithoutLeapMonths(t *testing.T) {
+	rrule, err := ParseRRule("FREQ=YEARLY;BYMONTH=2L;BYMONTHDAY=1;COUNT=2")
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, rrule.ByLeapMonths)
+	assert.Equal(t, "FREQ=YEARLY;COUNT=2;BYMONTHDAY=1;BYMONTH=2L", rrule.String())
+
+	rrule.Dtstart = time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	assert.Empty(t, All(rrule.Iterator(), 0))
+	assert.Empty(t, All(Recurrence{Dtstart: rrule.Dtstart, RRules: []RRule{rrule}}.Iterator(), 0))
+
+	onlyLeap := MustRRule("FREQ=YEARLY;BYMONTH=5L;COUNT=3")
+	onlyLeap.Dtstart = rrule.Dtstart
+	assert.Empty(t, All(Recurrence{Dtstart: onlyLeap.Dtstart, RRules: []RRule{onlyLeap}}.Iterator(), 0))
+
+	rrule.InvalidBehavior = PrevInvalid
+	rrule.Dtstart = time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-02-01T09:00:00Z", "2025-02-01T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule.InvalidBehavior = NextInvalid
+	rrule.Dtstart = time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-03-01T09:00:00Z", "2025-03-01T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule, err = ParseRRule("RSCALE=ISLAMIC-CIVIL;FREQ=YEARLY;BYMONTH=9L;SKIP=BACKWARD;BYMONTHDAY=1;COUNT=1")
+	require.NoError(t, err)
+	rrule.Dtstart = time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-03-11T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+}
+
+func TestEthiopic(t *testing.T) {
+	cal := ethiopic{}
+
+	assert.Equal(t, calendarDate{year: 2017, month: calendarMonth{number: 1}, day: 1}, cal.date(julianDay(time.Date(2024, time.September, 11, 0, 0, 0, 0, time.UTC))))
+	assert.Equal(t, calendarDate{year: 2016, month: calendarMonth{number: 1}, day: 1}, cal.date(julianDay(time.Date(2023, time.September, 12, 0, 0, 0, 0, time.UTC))))
+	assert.Equal(t, calendarDate{year: 2015, month: calendarMonth{number: 13}, day: 6}, cal.date(julianDay(time.Date(2023, time.September, 11, 0, 0, 0, 0, time.UTC))))
+	assert.Equal(t, calendarDate{year: 2016, month: calendarMonth{number: 4}, day: 28}, cal.date(julianDay(time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC))))
+
+	for jdn := ethiopicEpoch - 400; jdn < ethiopicEpoch+800000; jdn += 11 {
+		d := cal.date(jdn)
+		assert.True(t, d.day >= 1 && d.day <= cal.monthLength(d.year, d.month))
+		assert.Equal(t, jdn, cal.monthStart(d.year, d.month)+d.day-1)
+	}
+}
+
+func TestRScaleEthiopic(t *testing.T) {
+	// the last day of Pagume, which is the 6th in the year before a leap
+	// year.
+	rrule, err := ParseRRule("RSCALE=ETHIOPIC;FREQ=YEARLY;BYMONTH=13;BYMONTHDAY=-1;COUNT=4")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=YEARLY;COUNT=4;BYMONTHDAY=-1;BYMONTH=13;RSCALE=ETHIOPIC", rrule.String())
+
+	rrule.Dtstart = time.Date(2022, time.January, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{
+		"2022-09-10T09:00:00Z",
+		"2023-09-11T09:00:00Z",
+		"2024-09-10T09:00:00Z",
+		"2025-09-10T09:00:00Z",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule, err = ParseRRule("RSCALE=ETHIOPIC;FREQ=MONTHLY;BYMONTHDAY=30;SKIP=BACKWARD;COUNT=4")
+	require.NoError(t, err)
+	rrule.Dtstart = time.Date(2024, time.July, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-07-07T09:00:00Z", "2024-08-06T09:00:00Z", "2024-09-05T09:00:00Z", "2024-09-10T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+}
+
+func TestRScaleDateTime(t *testing.T) {
+	cases := []struct {
+		RScale RScale
+		Date   CalendarDate
+		Time   string
+		String string
+	}{
+		{Gregorian, CalendarDate{Year: 2024, Month: 2, Day: 29}, "2024-02-29T09:00:00Z", "2024-02-29"},
+		{Buddhist, CalendarDate{Year: 2567, Month: 2, Day: 29}, "2024-02-29T09:00:00Z", "2567-02-29"},
+		{Japanese, CalendarDate{Era: "Reiwa", Year: 1, Month: 5, Day: 1}, "2019-05-01T09:00:00Z", "Reiwa 0001-05-01"},
+		{IslamicCivil, CalendarDate{Year: 1445, Month: 9, Day: 1}, "2024-03-11T09:00:00Z", "1445-09-01"},
+		{Chinese, CalendarDate{Year: 2023, Month: 2, Leap: true, Day: 1}, "2023-03-22T09:00:00Z", "2023-02L-01"},
+		{Ethiopic, CalendarDate{Year: 2015, Month: 13, Day: 6}, "2023-09-11T09:00:00Z", "2015-13-06"},
+	}
+
+	for _, tc := range cases {
+		tm, err := tc.RScale.Time(tc.Date, 9, 0, 0, time.UTC)
+		require.NoError(t, err, "%v %v", tc.RScale, tc.Date)
+		assert.Equal(t, tc.Time, tm.Format(time.RFC3339), "%v %v", tc.RScale, tc.Date)
+		assert.Equal(t, tc.Date, tc.RScale.Date(tm), "%v %v", tc.RScale, tc.Date)
+		assert.Equal(t, tc.String, tc.Date.String())
+	}
+
+	for _, tc := range []struct {
+		RScale RScale
+		Date   CalendarDate
+	}{
+		{Gregorian, CalendarDate{Year: 2023, Month: 2, Day: 29}},
+		{Gregorian, CalendarDate{Year: 2023, Month: 2, Leap: true, Day: 1}},
+		{Japanese, CalendarDate{Era: "Heisei", Year: 31, Month: 5, Day: 1}},
+		{Japanese, CalendarDate{Era: "Edo", Year: 1, Month: 1, Day: 1}},
+		{IslamicCivil, CalendarDate{Year: 1445, Month: 10, Day: 30}},
+		{Chinese, CalendarDate{Year: 2024, Month: 2, Leap: true, Day: 1}},
+		{Chinese, CalendarDate{Year: 2200, Month: 1, Day: 1}},
+		{Ethiopic, CalendarDate{Year: 2016, Month: 13, Day: 6}},
+	} {
+		_, err := tc.RScale.Time(tc.Date, 0, 0, 0, time.UTC)
+		assert.Error(t, err, "%v %v", tc.RScale, tc.Date)
+	}
+
+	// an UNTIL given as the last day of Ramadan 1446.
+	until, err := IslamicCivil.Time(CalendarDate{Year: 1446, Month: 9, Day: 30}, 23, 59, 59, time.UTC)
+	require.NoError(t, err)
+
+	rrule := RRule{Frequency: Monthly, RScale: IslamicCivil, Until: until, Dtstart: time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)}
+	assert.Equal(t, CalendarDate{Year: 1446, Month: 9, Day: 1}, IslamicCivil.Date(All(rrule.Iterator(), 0)[2]))
+	assert.Len(t, All(rrule.Iterator(), 0), 3)
+}
@@ -0,0 +1,128 @@
+package rrule
+
+import "sort"
+
+// chinese is the Chinese lunisolar calendar, for the years 1900 through 2100,
+// where years are numbered by the Gregorian year in which they begin. Dates
+// outside those years are not supported.
+type chinese struct{}
+
+// chineseYears describes each year from 1900, using the encoding common to
+// most published tables. Bits 15 through 4 are set for each of months 1
+// through 12 that has 30 days rather than 29. The low 4 bits are the number
+// of the month followed by a leap month, or 0 if there is none, and bit 16 is
+// set if the leap month has 30 days.
+var chineseYears = [...]uint32{
+	0x04bd8, 0x04ae0, 0x0a570, 0x054d5, 0x0d260, 0x0d950, 0x16554, 0x056a0, 0x09ad0, 0x055d2, // 1900
+	0x04ae0, 0x0a5b6, 0x0a4d0, 0x0d250, 0x1d255, 0x0b540, 0x0d6a0, 0x0ada2, 0x095b0, 0x14977, // 1910
+	0x04970, 0x0a4b0, 0x0b4b5, 0x06a50, 0x06d40, 0x1ab54, 0x02b60, 0x09570, 0x052f2, 0x04970, // 1920
+	0x06566, 0x0d4a0, 0x0ea50, 0x16a95, 0x05ad0, 0x02b60, 0x186e3, 0x092e0, 0x1c8d7, 0x0c950, // 1930
+	0x0d4a0, 0x1d8a6, 0x0b550, 0x056a0, 0x1a5b4, 0x025d0, 0x092d0, 0x0d2b2, 0x0a950, 0x0b557, // 1940
+	0x06ca0, 0x0b550, 0x15355, 0x04da0, 0x0a5b0, 0x14573, 0x052b0, 0x0a9a8, 0x0e950, 0x06aa0, // 1950
+	0x0aea6, 0x0ab50, 0x04b60, 0x0aae4, 0x0a570, 0x05260, 0x0f263, 0x0d950, 0x05b57, 0x056a0, // 1960
+	0x096d0, 0x04dd5, 0x04ad0, 0x0a4d0, 0x0d4d4, 0x0d250, 0x0d558, 0x0b540, 0x0b6a0, 0x195a6, // 1970
+	0x095b0, 0x049b0, 0x0a974, 0x0a4b0, 0x0b27a, 0x06a50, 0x06d40, 0x0af46, 0x0ab60, 0x09570, // 1980
+	0x04af5, 0x04970, 0x064b0, 0x074a3, 0x0ea50, 0x06b58, 0x05ac0, 0x0ab60, 0x096d5, 0x092e0, // 1990
+	0x0c960, 0x0d954, 0x0d4a0, 0x0da50, 0x07552, 0x056a0, 0x0abb7, 0x025d0, 0x092d0, 0x0cab5, // 2000
+	0x0a950, 0x0b4a0, 0x0baa4, 0x0ad50, 0x055d9, 0x04ba0, 0x0a5b0, 0x15176, 0x052b0, 0x0a930, // 2010
+	0x07954, 0x06aa0, 0x0ad50, 0x05b52, 0x04b60, 0x0a6e6, 0x0a4e0, 0x0d260, 0x0ea65, 0x0d530, // 2020
+	0x05aa0, 0x076a3, 0x096d0, 0x04afb, 0x04ad0, 0x0a4d0, 0x1d0b6, 0x0d250, 0x0d520, 0x0dd45, // 2030
+	0x0b5a0, 0x056d0, 0x055b2, 0x049b0, 0x0a577, 0x0a4b0, 0x0aa50, 0x1b255, 0x06d20, 0x0ada0, // 2040
+	0x14b63, 0x09370, 0x049f8, 0x04970, 0x064b0, 0x168a6, 0x0ea50, 0x06b20, 0x1a6c4, 0x0aae0, // 2050
+	0x092e0, 0x0d2e3, 0x0c960, 0x0d557, 0x0d4a0, 0x0da50, 0x05d55, 0x056a0, 0x0a6d0, 0x055d4, // 2060
+	0x052d0, 0x0a9b8, 0x0a950, 0x0b4a0, 0x0b6a6, 0x0ad50, 0x055a0, 0x0aba4, 0x0a5b0, 0x052b0, // 2070
+	0x0b273, 0x06930, 0x07337, 0x06aa0, 0x0ad50, 0x14b55, 0x04b60, 0x0a570, 0x054e4, 0x0d160, // 2080
+	0x0e968, 0x0d520, 0x0daa0, 0x16aa6, 0x056d0, 0x04ae0, 0x0a9d4, 0x0a2d0, 0x0d150, 0x0f252, // 2090
+	0x0d520, // 2100
+}
+
+const (
+	chineseFirstYear = 1900
+
+	// chineseFirstNewYear is the Julian day number of January 31st, 1900,
+	// the first day of the first year in chineseYears.
+	chineseFirstNewYear = 2415051
+)
+
+// chineseNewYears holds the Julian day number of the first day of each year in
+// chineseYears, followed by the day after the last one.
+var chineseNewYears = func() []int {
+	starts := []int{chineseFirstNewYear}
+	for i := range chineseYears {
+		days := 0
+		for _, m := range chineseMonths(chineseFirstYear + i) {
+			days += chineseMonthLength(chineseFirstYear+i, m)
+		}
+		starts = append(starts, starts[i]+days)
+	}
+	return starts
+}()
+
+func chineseMonths(year int) []calendarMonth {
+	if year < chineseFirstYear || year >= chineseFirstYear+len(chineseYears) {
+		return nil
+	}
+
+	leap := int(chineseYears[year-chineseFirstYear] & 0xf)
+
+	months := make([]calendarMonth, 0, 13)
+	for m := 1; m <= 12; m++ {
+		months = append(months, calendarMonth{number: m})
+		if m == leap {
+			months = append(months, calendarMonth{number: m, leap: true})
+		}
+	}
+	return months
+}
+
+func chineseMonthLength(year int, month calendarMonth) int {
+	info := chineseYears[year-chineseFirstYear]
+
+	bit := uint32(0x10000) >> uint(month.number)
+	if month.leap {
+		bit = 0x10000
+	}
+
+	if info&bit != 0 {
+		return 30
+	}
+	return 29
+}
+
+func (chinese) months(year int) []calendarMonth {
+	return chineseMonths(year)
+}
+
+func (chinese) monthStart(year int, month calendarMonth) int {
+	jdn := chineseNewYears[year-chineseFirstYear]
+	for _, m := range chineseMonths(year) {
+		if m == month {
+			break
+		}
+		jdn += chineseMonthLength(year, m)
+	}
+	return jdn
+}
+
+func (chinese) monthLength(year int, month calendarMonth) int {
+	return chineseMonthLength(year, month)
+}
+
+func (c chinese) date(jdn int) calendarDate {
+	i := sort.Search(len(chineseNewYears), func(i int) bool { return chineseNewYears[i] > jdn }) - 1
+	if i < 0 || i >= len(chineseYears) {
+		return calendarDate{}
+	}
+
+	year := chineseFirstYear + i
+	day := jdn - chineseNewYears[i] + 1
+	for _, m := range chineseMonths(year) {
+		length := chineseMonthLength(year, m)
+		if day <= length {
+			return calendarDate{year: year, month: m, day: day}
+		}
+		day -= length
+	}
+
+	return calendarDate{}
+}
@@ -0,0 +1,22 @@
+package rrule
+
+import "time"
+
+// Now returns the current time. It is used in place of a zero Dtstart when
+// iterators are generated, and defaults to the standard library's time.Now.
+// Set this to a fixed clock to make such patterns deterministic, e.g. in
+// tests.
+var Now = time.Now
+
+// dtstart returns the Dtstart used when iterating the pattern, which is
+// midnight of its date for all-day patterns.
+func (rrule RRule) dtstart() time.Time {
+	start := rrule.Dtstart
+	if start.IsZero() {
+		start = Now()
+	}
+	if rrule.AllDay {
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	}
+	return start
+}
@@ -0,0 +1,70 @@
+package rrule
+
+import "time"
+
+// Clone returns a deep copy of the pattern, whose rule part lists and
+// Extensions can be changed without changing the original's. Copies made by
+// assignment share them.
+func (rrule RRule) Clone() RRule {
+	rrule.BySeconds = cloneInts(rrule.BySeconds)
+	rrule.ByMinutes = cloneInts(rrule.ByMinutes)
+	rrule.ByHours = cloneInts(rrule.ByHours)
+	rrule.ByMonthDays = cloneInts(rrule.ByMonthDays)
+	rrule.ByWeekNumbers = cloneInts(rrule.ByWeekNumbers)
+	rrule.ByLeapMonths = cloneInts(rrule.ByLeapMonths)
+	rrule.ByYearDays = cloneInts(rrule.ByYearDays)
+	rrule.BySetPos = cloneInts(rrule.BySetPos)
+	if rrule.ByWeekdays != nil {
+		rrule.ByWeekdays = append(make([]QualifiedWeekday, 0, len(rrule.ByWeekdays)), rrule.ByWeekdays...)
+	}
+	if rrule.ByMonths != nil {
+		rrule.ByMonths = append(make([]time.Month, 0, len(rrule.ByMonths)), rrule.ByMonths...)
+	}
+	if rrule.Extensions != nil {
+		extensions := make(map[string]string, len(rrule.Extensions))
+		for name, value := range rrule.Extensions {
+			extensions[name] = value
+		}
+		rrule.Extensions = extensions
+	}
+	// source is never changed, so it can be shared.
+	return rrule
+}
+
+// Clone returns a deep copy of the recurrence, whose patterns and dates can be
+// changed without changing the original's.
+func (r Recurrence) Clone() Recurrence {
+	r.RRules = cloneRRules(r.RRules)
+	r.ExRules = cloneRRules(r.ExRules)
+	r.RDates = cloneTimes(r.RDates)
+	r.ExDates = cloneTimes(r.ExDates)
+	return r
+}
+
+// The clone functions copy a slice, keeping nil slices nil, so that clones
+// are equal to their originals.
+
+func cloneInts(ints []int) []int {
+	if ints == nil {
+		return nil
+	}
+	return append(make([]int, 0, len(ints)), ints...)
+}
+
+func cloneTimes(tt []time.Time) []time.Time {
+	if tt == nil {
+		return nil
+	}
+	return append(make([]time.Time, 0, len(tt)), tt...)
+}
+
+func cloneRRules(rrules []RRule) []RRule {
+	if rrules == nil {
+		return nil
+	}
+	cloned := make([]RRule, len(rrules))
+	for i, rrule := range rrules {
+		cloned[i] = rrule.Clone()
+	}
+	return cloned
+}
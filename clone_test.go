@@ -0,0 +1,63 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	rrule := RRule{
+		Frequency:   Monthly,
+		Dtstart:     now,
+		ByWeekdays:  []QualifiedWeekday{MO, FR.Nth(-1)},
+		ByMonthDays: []int{1, 15},
+		ByMonths:    []time.Month{time.March},
+		ByHours:     []int{},
+		WeekStart:   WeekStartOn(time.Sunday),
+		Extensions:  map[string]string{"X-NAME": "payday"},
+	}
+	clone := rrule.Clone()
+	assert.Equal(t, rrule, clone)
+
+	clone.ByWeekdays[0] = TU
+	clone.ByMonthDays[1] = 16
+	clone.ByMonths[0] = time.April
+	clone.Extensions["X-NAME"] = "rent"
+	assert.Equal(t, []QualifiedWeekday{MO, FR.Nth(-1)}, rrule.ByWeekdays)
+	assert.Equal(t, []int{1, 15}, rrule.ByMonthDays)
+	assert.Equal(t, []time.Month{time.March}, rrule.ByMonths)
+	assert.Equal(t, "payday", rrule.Extensions["X-NAME"])
+
+	// appending to a clone doesn't write into the original's spare capacity.
+	rrule.BySetPos = make([]int, 1, 4)
+	clone = rrule.Clone()
+	clone.BySetPos = append(clone.BySetPos, 2)
+	assert.Equal(t, []int{0, 2}, clone.BySetPos)
+	assert.Equal(t, 0, rrule.BySetPos[:2][1])
+
+	// the text a pattern was parsed from is kept.
+	parsed, err := ParseRRulePreserving("freq=daily;count=3")
+	assert.NoError(t, err)
+	assert.Equal(t, "freq=daily;count=3", parsed.Clone().Format(StringOptions{Preserve: true}))
+}
+
+func TestCloneRecurrence(t *testing.T) {
+	r := Recurrence{
+		Dtstart: now,
+		RRules:  []RRule{{Frequency: Daily, Count: 5, ByHours: []int{9}}},
+		ExRules: []RRule{{Frequency: Monthly, ByWeekdays: []QualifiedWeekday{TU.Nth(-1)}}},
+		ExDates: []time.Time{now.AddDate(0, 0, 2)},
+	}
+	want := r.String()
+	clone := r.Clone()
+	assert.Equal(t, r, clone)
+
+	clone.RRules[0].Count = 99
+	clone.RRules[0].ByHours[0] = 12
+	clone.ExRules[0].ByWeekdays[0] = WE
+	clone.ExDates[0] = now
+	assert.Equal(t, want, r.String())
+	assert.Nil(t, clone.RDates)
+}
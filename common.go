@@ -0,0 +1,73 @@
+package rrule
+
+import "time"
+
+// The patterns below are common ones, with the parts that are easy to get
+// wrong by hand filled in. They have no Dtstart, which sets the time of day of
+// their occurrences and, where the pattern doesn't pick one, their day; set it
+// before iterating them.
+
+// EveryWeekday returns a pattern of every Monday through Friday.
+func EveryWeekday() RRule {
+	return RRule{
+		Frequency:  Weekly,
+		ByWeekdays: []QualifiedWeekday{MO, TU, WE, TH, FR},
+	}
+}
+
+// LastDayOfMonth returns a pattern of the last day of every month, however
+// long it is.
+func LastDayOfMonth() RRule {
+	return RRule{
+		Frequency:   Monthly,
+		ByMonthDays: []int{-1},
+	}
+}
+
+// FirstWeekdayOfMonth returns a pattern of the first wd of every month.
+func FirstWeekdayOfMonth(wd time.Weekday) RRule {
+	return RRule{
+		Frequency:  Monthly,
+		ByWeekdays: []QualifiedWeekday{{N: 1, WD: wd}},
+	}
+}
+
+// NthWeekdayOfMonth returns a pattern of the n-th wd of every month, counted
+// from the end if n is negative, so -1 is the last. n must be 1 to 5 or -5 to
+// -1; months without a fifth wd are skipped.
+func NthWeekdayOfMonth(n int, wd time.Weekday) (RRule, error) {
+	// months have five of a weekday at most, so later ones would never
+	// occur.
+	if err := checkRange("BYDAY ordinal", []int{n}, -5, 5, ErrInvalidByDay); err != nil {
+		return RRule{}, err
+	}
+	return NewRRule(Monthly, WithByDay(QualifiedWeekday{N: n, WD: wd}))
+}
+
+// Quarterly returns a pattern of every third month, on the day of Dtstart.
+// Like other MONTHLY patterns, months without that day are skipped unless
+// InvalidBehavior says otherwise.
+func Quarterly() RRule {
+	return RRule{
+		Frequency: Monthly,
+		Interval:  3,
+	}
+}
+
+// BiWeekly returns a pattern of every other wd, starting with the week of
+// Dtstart.
+func BiWeekly(wd time.Weekday) RRule {
+	return RRule{
+		Frequency:  Weekly,
+		Interval:   2,
+		ByWeekdays: []QualifiedWeekday{{WD: wd}},
+	}
+}
+
+// AnnualOn returns a pattern of the day of month every year, like an
+// anniversary, counted from the end of the month if day is negative. February
+// 29th only occurs in leap years unless InvalidBehavior says otherwise. Days
+// that month never has are an error.
+func AnnualOn(month time.Month, day int) (RRule, error) {
+	return NewRRule(Yearly, WithByMonth(month), WithByMonthDay(day))
+}
@@ -0,0 +1,74 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommonPatterns(t *testing.T) {
+	// a Friday.
+	dtstart := time.Date(2019, time.May, 31, 9, 0, 0, 0, NewYork())
+
+	for _, tc := range []struct {
+		rrule  RRule
+		String string
+		Dates  []string
+	}{{
+		rrule:  EveryWeekday(),
+		String: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		Dates:  []string{"2019-05-31T09:00:00-04:00", "2019-06-03T09:00:00-04:00", "2019-06-04T09:00:00-04:00", "2019-06-05T09:00:00-04:00"},
+	}, {
+		rrule:  LastDayOfMonth(),
+		String: "FREQ=MONTHLY;BYMONTHDAY=-1",
+		Dates:  []string{"2019-05-31T09:00:00-04:00", "2019-06-30T09:00:00-04:00", "2019-07-31T09:00:00-04:00", "2019-08-31T09:00:00-04:00"},
+	}, {
+		rrule:  FirstWeekdayOfMonth(time.Monday),
+		String: "FREQ=MONTHLY;BYDAY=1MO",
+		Dates:  []string{"2019-06-03T09:00:00-04:00", "2019-07-01T09:00:00-04:00", "2019-08-05T09:00:00-04:00", "2019-09-02T09:00:00-04:00"},
+	}, {
+		rrule:  must(NthWeekdayOfMonth(-1, time.Friday)),
+		String: "FREQ=MONTHLY;BYDAY=-1FR",
+		Dates:  []string{"2019-05-31T09:00:00-04:00", "2019-06-28T09:00:00-04:00", "2019-07-26T09:00:00-04:00", "2019-08-30T09:00:00-04:00"},
+	}, {
+		rrule:  must(NthWeekdayOfMonth(5, time.Friday)),
+		String: "FREQ=MONTHLY;BYDAY=5FR",
+		Dates:  []string{"2019-05-31T09:00:00-04:00", "2019-08-30T09:00:00-04:00", "2019-11-29T09:00:00-05:00", "2020-01-31T09:00:00-05:00"},
+	}, {
+		rrule:  Quarterly(),
+		String: "FREQ=MONTHLY;INTERVAL=3",
+		Dates:  []string{"2019-05-31T09:00:00-04:00", "2019-08-31T09:00:00-04:00", "2020-05-31T09:00:00-04:00", "2020-08-31T09:00:00-04:00"},
+	}, {
+		rrule:  BiWeekly(time.Tuesday),
+		String: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+		Dates:  []string{"2019-06-11T09:00:00-04:00", "2019-06-25T09:00:00-04:00", "2019-07-09T09:00:00-04:00", "2019-07-23T09:00:00-04:00"},
+	}, {
+		rrule:  must(AnnualOn(time.February, 29)),
+		String: "FREQ=YEARLY;BYMONTHDAY=29;BYMONTH=2",
+		Dates:  []string{"2020-02-29T09:00:00-05:00", "2024-02-29T09:00:00-05:00", "2028-02-29T09:00:00-05:00", "2032-02-29T09:00:00-05:00"},
+	}} {
+		assert.NoError(t, tc.rrule.Validate(), tc.String)
+		assert.Equal(t, tc.String, tc.rrule.String())
+
+		tc.rrule.Dtstart = dtstart
+		assert.Equal(t, tc.Dates, rfcAll(All(tc.rrule.Iterator(), 4)), tc.String)
+	}
+
+	for _, n := range []int{0, 6, -6} {
+		_, err := NthWeekdayOfMonth(n, time.Monday)
+		assert.True(t, errors.Is(err, ErrInvalidByDay), "%d", n)
+	}
+	_, err := AnnualOn(time.April, 31)
+	assert.True(t, errors.Is(err, ErrNoOccurrences))
+	_, err = AnnualOn(time.April, 32)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
+
+func must(rrule RRule, err error) RRule {
+	if err != nil {
+		panic(err)
+	}
+	return rrule
+}
@@ -0,0 +1,423 @@
+package rrule
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// IsFinite reports whether the pattern is terminal: it has COUNT or UNTIL, so
+// its iterator is guaranteed to end and it is safe to expand in full.
+func (rrule RRule) IsFinite() bool {
+	return rrule.Count != 0 || !rrule.Until.IsZero()
+}
+
+// IsFinite reports whether all of the recurrence's RRules are finite, so its
+// iterator is guaranteed to end. Its RDates are always finite, and ExRules
+// only remove occurrences.
+func (r *Recurrence) IsFinite() bool {
+	for _, rrule := range r.RRules {
+		if !rrule.IsFinite() {
+			return false
+		}
+	}
+	return true
+}
+
+// OccurrenceCount returns the number of occurrences of the pattern.
+//
+// For terminal patterns, those with COUNT or UNTIL, the exact number is
+// returned along with true. Other patterns never end, so instead an estimate
+// of the number of occurrences in the window following Dtstart is returned
+// along with false. The estimate is extrapolated from a sample of the pattern
+// no longer than the window, and is exact when the window is short enough to
+// be sampled completely. Simple patterns, as described by At, are counted
+// exactly without iterating, as they have every occurrence COUNT allows;
+// others are iterated, so COUNT is not returned unless it is reached.
+//
+// The pattern must be valid or OccurrenceCount will panic.
+func (rrule RRule) OccurrenceCount(window time.Duration) (int, bool) {
+	if err := rrule.Validate(); err != nil {
+		panic(err)
+	}
+
+	if rrule.simple() {
+		rrule.Dtstart = rrule.dtstart()
+		if n, ok := rrule.lenSimple(); ok {
+			return n, true
+		}
+		if window <= 0 {
+			return 0, false
+		}
+		// every occurrence is computed, so the count is exact.
+		return rrule.indexSimple(rrule.Dtstart.Add(window)), false
+	}
+
+	// COUNT is only a limit: occurrences the pattern omits, like leap
+	// seconds it skips, don't fill it, so terminal patterns are iterated.
+	it := rrule.Iterator()
+	defer release(it)
+	if rrule.IsFinite() {
+		n := 0
+		for it.Next() != nil {
+			n++
+		}
+		return n, true
+	}
+
+	if window <= 0 {
+		return 0, false
+	}
+
+	start := it.Peek()
+	if start == nil {
+		return 0, false
+	}
+
+	sample := rrule.sampleSpan()
+	if sample > window {
+		sample = window
+	}
+
+	end := start.Add(sample)
+	n := 0
+	for {
+		next := it.Next()
+		if next == nil || !next.Before(end) {
+			break
+		}
+		n++
+	}
+
+	if sample == window {
+		return n, false
+	}
+
+	return int(float64(n) * float64(window) / float64(sample)), false
+}
+
+// sampleSpan returns a span of time long enough to observe a representative
+// cycle of the pattern, but short enough to iterate cheaply.
+func (rrule RRule) sampleSpan() time.Duration {
+	const day = 24 * time.Hour
+
+	var base time.Duration
+	switch rrule.Frequency {
+	case Secondly:
+		base = time.Hour
+	case Minutely:
+		base = day
+	case Hourly:
+		base = 31 * day
+	case Daily, Weekly:
+		base = 366 * day
+	case Monthly:
+		base = 4 * 366 * day
+	default:
+		base = 8 * 366 * day
+	}
+
+	interval := time.Duration(1)
+	if rrule.Interval > 1 {
+		interval = time.Duration(rrule.Interval)
+	}
+
+	if interval > maxDuration/base {
+		return maxDuration
+	}
+	return interval * base
+}
+
+const maxDuration = time.Duration(1<<63 - 1)
+
+// maxSizeHint bounds the slices sized by sizeHint, so a poor estimate can't
+// reserve much more memory than growing the slice would have.
+const maxSizeHint = 1 << 14
+
+const (
+	daysPerYear   = 365.2425
+	daysPerMonth  = daysPerYear / 12
+	weeksPerYear  = daysPerYear / 7
+	weeksPerMonth = weeksPerYear / 12
+)
+
+// sizeHint estimates the number of occurrences of the pattern at or after start
+// and before end, from its frequency, interval and BYxxx parts, to size the
+// slice they're collected in. It's no more than COUNT or maxSizeHint.
+func (rrule RRule) sizeHint(start, end time.Time) int {
+	if dtstart := rrule.dtstart(); start.Before(dtstart) {
+		start = dtstart
+	}
+	if !rrule.Until.IsZero() && rrule.Until.Before(end) {
+		// UNTIL itself may occur.
+		end = rrule.Until.Add(time.Nanosecond)
+	}
+	if !start.Before(end) {
+		return 0
+	}
+
+	var period float64
+	switch rrule.Frequency {
+	case Secondly:
+		period = float64(time.Second)
+	case Minutely:
+		period = float64(time.Minute)
+	case Hourly:
+		period = float64(time.Hour)
+	case Daily:
+		period = float64(24 * time.Hour)
+	case Weekly:
+		period = float64(7 * 24 * time.Hour)
+	case Monthly:
+		period = daysPerMonth * float64(24*time.Hour)
+	default:
+		period = daysPerYear * float64(24*time.Hour)
+	}
+	if rrule.Interval > 1 {
+		period *= float64(rrule.Interval)
+	}
+
+	n := math.Ceil(float64(end.Sub(start)) / period * rrule.perPeriod())
+	if rrule.Count > 0 && n > float64(rrule.Count) {
+		n = float64(rrule.Count)
+	}
+	if n > maxSizeHint {
+		n = maxSizeHint
+	}
+	return int(n)
+}
+
+// sizeHint estimates the number of instances of the recurrence at or after
+// start and before end, as RRule.sizeHint does for each of its patterns.
+// Exceptions aren't counted.
+func (r Recurrence) sizeHint(start, end time.Time) int {
+	n := 0
+	for _, rrule := range r.RRules {
+		// the patterns start with the recurrence.
+		rrule.Dtstart = r.Dtstart
+		n += rrule.sizeHint(start, end)
+	}
+	for _, t := range r.RDates {
+		if !t.Before(start) && t.Before(end) {
+			n++
+		}
+	}
+	if n > maxSizeHint {
+		n = maxSizeHint
+	}
+	return n
+}
+
+// perPeriod estimates the number of occurrences of the pattern in each period of
+// its frequency, like each month of a MONTHLY pattern. Each BYxxx part either
+// expands the period into more times, if it's for a shorter unit of time than
+// the period, or limits its times to the fraction of that unit it names.
+func (rrule RRule) perPeriod() float64 {
+	// n counts the times in each period that has any, which BYSETPOS
+	// picks from, and share is the fraction of periods that do.
+	n, share := 1.0, 1.0
+	limit := func(values int, per float64) {
+		if values > 0 {
+			n *= float64(values) / per
+		}
+	}
+	only := func(values int, per float64) {
+		if values > 0 {
+			share *= float64(values) / per
+		}
+	}
+	part := func(values int, unit Frequency, per float64) {
+		if values > 0 && unit < rrule.Frequency {
+			n *= float64(values)
+		} else {
+			only(values, per)
+		}
+	}
+	part(len(rrule.BySeconds), Secondly, 60)
+	part(len(rrule.ByMinutes), Minutely, 60)
+	part(len(rrule.ByHours), Hourly, 24)
+
+	// weekdays counts the days of BYDAY, with each unqualified weekday
+	// falling on the given number of days.
+	weekdays := func(days float64) float64 {
+		n := 0.0
+		for _, wd := range rrule.ByWeekdays {
+			if wd.N == 0 {
+				n += days
+			} else {
+				n++
+			}
+		}
+		return n
+	}
+	months, monthDays, weekNumbers, yearDays := len(rrule.ByMonths), len(rrule.ByMonthDays), len(rrule.ByWeekNumbers), len(rrule.ByYearDays)
+
+	switch rrule.Frequency {
+	case Yearly:
+		switch {
+		case yearDays > 0:
+			n *= float64(yearDays)
+			limit(months, 12)
+			limit(monthDays, daysPerMonth)
+			limit(len(rrule.ByWeekdays), 7)
+		case weekNumbers > 0:
+			n *= float64(weekNumbers)
+			if len(rrule.ByWeekdays) > 0 {
+				n *= float64(len(rrule.ByWeekdays))
+			} else {
+				n *= 7
+			}
+			limit(months, 12)
+			limit(monthDays, daysPerMonth)
+		case monthDays > 0:
+			// without BYMONTH, the days are in the month of Dtstart.
+			if months == 0 {
+				months = 1
+			}
+			n *= float64(months * monthDays)
+			limit(len(rrule.ByWeekdays), 7)
+		case len(rrule.ByWeekdays) > 0 && months > 0:
+			n *= float64(months) * weekdays(weeksPerMonth)
+		case len(rrule.ByWeekdays) > 0:
+			n *= weekdays(weeksPerYear)
+		case months > 0:
+			n *= float64(months)
+		}
+	case Monthly:
+		only(months, 12)
+		if monthDays > 0 {
+			n *= float64(monthDays)
+			limit(len(rrule.ByWeekdays), 7)
+		} else if len(rrule.ByWeekdays) > 0 {
+			n *= weekdays(weeksPerMonth)
+		}
+	case Weekly:
+		only(months, 12)
+		if len(rrule.ByWeekdays) > 0 {
+			n *= float64(len(rrule.ByWeekdays))
+		}
+	default:
+		only(months, 12)
+		only(monthDays, daysPerMonth)
+		only(len(rrule.ByWeekdays), 7)
+		only(weekNumbers, weeksPerYear)
+		only(yearDays, daysPerYear)
+	}
+
+	if len(rrule.BySetPos) > 0 && n > float64(len(rrule.BySetPos)) {
+		n = float64(len(rrule.BySetPos))
+	}
+	return n * share
+}
+
+// presize returns the capacity of a slice for the times of it, having taken the
+// first, when no more than limit are collected.
+func presize(it Iterator, limit int) int {
+	n := sizeHint(it) + 1
+	if limit > 0 && n > limit {
+		n = limit
+	}
+	return n
+}
+
+// sizeHinter is an iterator that can estimate how many times it has left.
+type sizeHinter interface {
+	sizeHint() int
+}
+
+// sizeHint estimates how many times it has left, to size the slice they're
+// collected in, or returns 0 if there's no telling.
+func sizeHint(it Iterator) int {
+	if sh, ok := it.(sizeHinter); ok {
+		return sh.sizeHint()
+	}
+	return 0
+}
+
+func (i *iterator) sizeHint() int {
+	if i.size == 0 || i.index < 0 || i.index >= i.size {
+		return 0
+	}
+	return i.size - i.index
+}
+
+func (gi *groupIterator) sizeHint() int {
+	n := 0
+	for _, it := range gi.iters {
+		n += sizeHint(it)
+	}
+	return n
+}
+
+// sizeHint is an upper bound, as exceptions aren't counted.
+func (ri *recurrenceIterator) sizeHint() int { return ri.rrules.sizeHint() }
+
+func (li *locationIterator) sizeHint() int { return sizeHint(li.it) }
+
+// WithUntilFromCount returns the pattern ended by UNTIL rather than COUNT, with
+// Until set to its final occurrence, so it has the same occurrences. Some
+// systems accept only one of the two.
+//
+// The pattern must have COUNT and a Dtstart, since otherwise its final
+// occurrence depends on when it's expanded.
+func (rrule RRule) WithUntilFromCount() (RRule, error) {
+	if rrule.Count == 0 {
+		return RRule{}, fmt.Errorf("pattern has no COUNT to convert")
+	}
+	if rrule.Dtstart.IsZero() {
+		return RRule{}, fmt.Errorf("pattern has no DTSTART to convert COUNT from")
+	}
+	if err := rrule.Validate(); err != nil {
+		return RRule{}, err
+	}
+
+	var last time.Time
+	if rrule.simple() {
+		last = rrule.nthSimple(int(rrule.Count) - 1)
+	} else {
+		it := rrule.Iterator()
+		ForEach(it, func(t time.Time) bool {
+			last = t
+			return true
+		})
+		release(it)
+	}
+	if last.IsZero() {
+		return RRule{}, kindErrorf(ErrNoOccurrences, "pattern has no occurrences to end at")
+	}
+
+	rrule.Count = 0
+	rrule.Until = last
+	rrule.UntilFloating = false
+	rrule.UntilExclusive = false
+	return rrule, nil
+}
+
+// WithCountFromUntil returns the pattern ended by COUNT rather than UNTIL, with
+// Count set to the number of its occurrences, so it has the same occurrences.
+// It's the reverse of WithUntilFromCount.
+//
+// The pattern must have UNTIL and a Dtstart, and at least one occurrence,
+// since a COUNT of 0 doesn't end it.
+func (rrule RRule) WithCountFromUntil() (RRule, error) {
+	if rrule.Until.IsZero() {
+		return RRule{}, fmt.Errorf("pattern has no UNTIL to convert")
+	}
+	if rrule.Dtstart.IsZero() {
+		return RRule{}, fmt.Errorf("pattern has no DTSTART to convert UNTIL from")
+	}
+	if err := rrule.Validate(); err != nil {
+		return RRule{}, err
+	}
+
+	n, _ := rrule.OccurrenceCount(0)
+	if n == 0 {
+		return RRule{}, kindErrorf(ErrNoOccurrences, "pattern has no occurrences before UNTIL")
+	}
+
+	rrule.Count = uint64(n)
+	rrule.Until = time.Time{}
+	rrule.UntilFloating = false
+	rrule.UntilExclusive = false
+	return rrule, nil
+}
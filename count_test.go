@@ -0,0 +1,193 @@
+package rrule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOccurrenceCount(t *testing.T) {
+	for _, tc := range cases {
+		if tc.NoTest || !tc.Terminal {
+			continue
+		}
+
+		t.Run(tc.Name, func(t *testing.T) {
+			n, exact := tc.RRule.OccurrenceCount(0)
+			assert.True(t, exact)
+			assert.Equal(t, len(tc.Dates), n)
+		})
+	}
+}
+
+func TestOccurrenceCountEstimate(t *testing.T) {
+	rrule := RRule{Frequency: Daily, Dtstart: now}
+	n, exact := rrule.OccurrenceCount(10 * 24 * time.Hour)
+	assert.False(t, exact)
+	assert.Equal(t, 10, n)
+
+	rrule = RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Thursday}}, Dtstart: now}
+	n, exact = rrule.OccurrenceCount(100 * 366 * 24 * time.Hour)
+	assert.False(t, exact)
+	assert.InDelta(t, 100*366*2/7, n, 100)
+}
+
+func TestIsFinite(t *testing.T) {
+	for _, tc := range cases {
+		assert.Equal(t, tc.Terminal, tc.RRule.IsFinite(), tc.Name)
+	}
+
+	r := &Recurrence{RRules: []RRule{{Frequency: Daily, Count: 3}}, RDates: []time.Time{now}}
+	assert.True(t, r.IsFinite())
+
+	r.RRules = append(r.RRules, RRule{Frequency: Weekly})
+	assert.False(t, r.IsFinite())
+
+	r.RRules = nil
+	assert.True(t, r.IsFinite())
+}
+
+func TestOccurrenceCountSimple(t *testing.T) {
+	rrule := RRule{Frequency: Hourly, Interval: 3, Until: now.AddDate(30, 0, 0), Dtstart: now}
+	n, exact := rrule.OccurrenceCount(0)
+	assert.True(t, exact)
+	assert.Equal(t, len(All(rrule.Iterator(), 0)), n)
+
+	rrule = RRule{Frequency: Minutely, Interval: 7, Dtstart: now}
+	n, exact = rrule.OccurrenceCount(100 * 366 * 24 * time.Hour)
+	assert.False(t, exact)
+	assert.Equal(t, 100*366*24*60/7+1, n)
+}
+
+func TestOccurrenceCountOmitted(t *testing.T) {
+	// COUNT is only reached when the pattern has that many occurrences.
+	start := time.Date(2016, time.December, 31, 23, 58, 0, 0, time.UTC)
+	for str, want := range map[string]int{
+		"FREQ=MINUTELY;BYSECOND=60;COUNT=3":           0,
+		"FREQ=MINUTELY;BYSECOND=30,60;COUNT=3":        3,
+		"FREQ=YEARLY;BYMONTH=5L;COUNT=3":              0,
+		"FREQ=YEARLY;BYMONTH=2L;BYMONTHDAY=1;COUNT=2": 0,
+	} {
+		rrule := MustRRule(str)
+		rrule.Dtstart = start
+		n, exact := rrule.OccurrenceCount(0)
+		assert.True(t, exact, str)
+		assert.Equal(t, want, n, str)
+		assert.Len(t, All(rrule.Iterator(), 0), want, str)
+	}
+
+	assert.Panics(t, func() { RRule{Frequency: Daily, Count: 3, ByMonths: []time.Month{13}}.OccurrenceCount(0) })
+}
+
+func TestSizeHint(t *testing.T) {
+	start := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for _, str := range []string{
+		"FREQ=HOURLY;INTERVAL=3;BYMONTH=1,7",
+		"FREQ=DAILY;BYHOUR=9,17;BYDAY=MO,TU,WE,TH,FR",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1,-1",
+		"FREQ=MONTHLY;BYMONTHDAY=1,15;BYDAY=MO",
+		"FREQ=YEARLY;BYMONTH=3,11;BYDAY=-1SU;BYHOUR=1,2",
+		"FREQ=YEARLY;BYYEARDAY=1,100,200",
+		"FREQ=YEARLY;BYWEEKNO=10,20;BYDAY=SA,SU",
+	} {
+		rrule := MustRRule(str)
+		rrule.Dtstart = now
+
+		for _, end := range []time.Time{start.AddDate(1, 0, 0), start.AddDate(3, 0, 0)} {
+			n := len(rrule.Expand(start, end))
+			hint := rrule.sizeHint(start, end)
+			assert.True(t, hint >= n*2/3 && hint <= n*3/2, "%s: %d times, hint %d", str, n, hint)
+		}
+	}
+
+	// the window is cut to the pattern's times.
+	rrule := MustRRule("FREQ=DAILY;COUNT=10")
+	rrule.Dtstart = now
+	assert.Equal(t, 10, rrule.sizeHint(start, start.AddDate(1, 0, 0)))
+	assert.Equal(t, 0, rrule.sizeHint(start, now))
+	rrule = MustRRule("FREQ=DAILY")
+	rrule.Dtstart, rrule.Until = now, now.AddDate(0, 0, 20)
+	assert.Equal(t, 21, rrule.sizeHint(now, now.AddDate(1, 0, 0)))
+	rrule.Until = time.Time{}
+	assert.Equal(t, maxSizeHint, rrule.sizeHint(now, now.AddDate(100, 0, 0)))
+}
+
+func TestPresize(t *testing.T) {
+	rrule := MustRRule("FREQ=WEEKLY;COUNT=30;BYDAY=MO,FR")
+	rrule.Dtstart = now
+	all := All(rrule.Iterator(), 0)
+	assert.Len(t, all, 30)
+	assert.Equal(t, 30, cap(all))
+	assert.Equal(t, 12, cap(All(rrule.Iterator(), 12)))
+
+	// the series is counted from where the iterator is.
+	it := rrule.Iterator()
+	it.Skip(5)
+	assert.Equal(t, 25, cap(All(it, 0)))
+
+	r := Recurrence{
+		Dtstart: now,
+		RRules:  []RRule{rrule},
+		RDates:  []time.Time{now.Add(time.Hour), now.Add(2 * time.Hour)},
+	}
+	all, err := AllContext(context.Background(), r.Iterator(), 0)
+	assert.NoError(t, err)
+	assert.Len(t, all, 32)
+	assert.Equal(t, 32, cap(all))
+
+	tt := rrule.Expand(now, now.AddDate(0, 1, 0))
+	assert.Len(t, tt, 9)
+	assert.Equal(t, 9, cap(tt))
+}
+
+func TestUntilCountConversion(t *testing.T) {
+	for _, tc := range cases {
+		if tc.NoTest || !tc.Terminal || tc.RRule.Dtstart.IsZero() {
+			continue
+		}
+
+		t.Run(tc.Name, func(t *testing.T) {
+			want := All(tc.RRule.Iterator(), 0)
+
+			var converted RRule
+			var err error
+			if tc.RRule.Count != 0 {
+				converted, err = tc.RRule.WithUntilFromCount()
+				require.NoError(t, err)
+				assert.Zero(t, converted.Count)
+				assert.Equal(t, want[len(want)-1], converted.Until)
+			} else {
+				converted, err = tc.RRule.WithCountFromUntil()
+				require.NoError(t, err)
+				assert.True(t, converted.Until.IsZero())
+				assert.Equal(t, uint64(len(want)), converted.Count)
+			}
+			assert.Equal(t, want, All(converted.Iterator(), 0))
+		})
+	}
+}
+
+func TestUntilCountConversionErrors(t *testing.T) {
+	_, err := RRule{Frequency: Daily, Dtstart: now}.WithUntilFromCount()
+	assert.Error(t, err)
+	_, err = RRule{Frequency: Daily, Count: 3}.WithUntilFromCount()
+	assert.Error(t, err)
+	_, err = RRule{Frequency: Daily, Dtstart: now}.WithCountFromUntil()
+	assert.Error(t, err)
+	_, err = RRule{Frequency: Daily, Until: now}.WithCountFromUntil()
+	assert.Error(t, err)
+
+	_, err = RRule{Frequency: Daily, Dtstart: now, Until: now.Add(-time.Hour)}.WithCountFromUntil()
+	assert.True(t, errors.Is(err, ErrNoOccurrences))
+
+	rrule := RRule{Frequency: Daily, Dtstart: now, Until: now.AddDate(0, 0, 3), UntilExclusive: true}
+	converted, err := rrule.WithCountFromUntil()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), converted.Count)
+	assert.False(t, converted.UntilExclusive)
+}
@@ -0,0 +1,389 @@
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField describes one field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronSeconds = cronField{name: "second", min: 0, max: 59}
+	cronMinutes = cronField{name: "minute", min: 0, max: 59}
+	cronHours   = cronField{name: "hour", min: 0, max: 23}
+	cronDays    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonths  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// Both 0 and 7 are Sunday.
+	cronWeekdays = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// cronMacros are the nonstandard shorthands supported by most cron
+// implementations.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// FromCron converts a cron expression to the pattern of its schedule. The
+// expression has the five standard fields, minute, hour, day of month, month
+// and day of week, optionally preceded by a sixth field for seconds. Fields
+// may be lists of values, ranges and steps, months and weekdays may be given by
+// their three letter names, and ? is the same as *. The @yearly, @monthly,
+// @weekly, @daily and @hourly shorthands are accepted too.
+//
+// Cron matches days that satisfy either the day of month or the day of week
+// when both are restricted, which a single pattern can't express, so that
+// returns an error. As in cron, a field beginning with * doesn't count as
+// restricted, so */2 for the day of month with MON for the day of week
+// matches odd days that are Mondays.
+//
+// Dtstart is not set; the schedule is followed from whenever it is set to.
+func FromCron(spec string) (RRule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "@") {
+		macro, ok := cronMacros[strings.ToLower(fields[0])]
+		if !ok {
+			return RRule{}, fmt.Errorf("cron shorthand %q is not supported", fields[0])
+		}
+		fields = strings.Fields(macro)
+	}
+
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return RRule{}, fmt.Errorf("cron expression %q must have 5 or 6 fields, not %d", spec, len(fields))
+	}
+
+	const (
+		second = iota
+		minute
+		hour
+		monthDay
+		month
+		weekday
+	)
+
+	var values [6][]int
+	var full [6]bool
+	for i, f := range []cronField{cronSeconds, cronMinutes, cronHours, cronDays, cronMonths, cronWeekdays} {
+		var err error
+		values[i], err = parseCronField(fields[i], f)
+		if err != nil {
+			return RRule{}, err
+		}
+
+		// Sunday may be given as 7.
+		if i == weekday {
+			for j, v := range values[i] {
+				values[i][j] = v % 7
+			}
+			values[i] = uniqueSortedInts(values[i])
+			full[i] = len(values[i]) == 7
+		} else {
+			full[i] = len(values[i]) == f.max-f.min+1
+		}
+	}
+
+	useMonthDays, useWeekdays := !full[monthDay], !full[weekday]
+	if !isCronStar(fields[monthDay]) && !isCronStar(fields[weekday]) {
+		// Neither day field begins with *, so days matching either are used.
+		if full[monthDay] || full[weekday] {
+			useMonthDays, useWeekdays = false, false
+		} else {
+			return RRule{}, fmt.Errorf("cron day of month %q and day of week %q match days that satisfy either, which an RRULE can't express", fields[monthDay], fields[weekday])
+		}
+	}
+
+	rrule := RRule{}
+	switch {
+	case full[second]:
+		rrule.Frequency = Secondly
+	case full[minute]:
+		rrule.Frequency = Minutely
+	case full[hour]:
+		rrule.Frequency = Hourly
+	case !useMonthDays && !useWeekdays:
+		rrule.Frequency = Daily
+	case full[month] && !useMonthDays:
+		rrule.Frequency = Weekly
+	default:
+		rrule.Frequency = Monthly
+	}
+
+	if !full[second] {
+		rrule.BySeconds = values[second]
+	}
+	if !full[minute] {
+		rrule.ByMinutes = values[minute]
+	}
+	if !full[hour] {
+		rrule.ByHours = values[hour]
+	}
+	if useMonthDays {
+		rrule.ByMonthDays = values[monthDay]
+	}
+	if !full[month] {
+		for _, m := range values[month] {
+			rrule.ByMonths = append(rrule.ByMonths, time.Month(m))
+		}
+	}
+	if useWeekdays {
+		for _, wd := range values[weekday] {
+			rrule.ByWeekdays = append(rrule.ByWeekdays, QualifiedWeekday{WD: time.Weekday(wd)})
+		}
+	}
+
+	return rrule, rrule.Validate()
+}
+
+func isCronStar(field string) bool {
+	return strings.HasPrefix(field, "*") || strings.HasPrefix(field, "?")
+}
+
+// parseCronField returns the sorted values matched by a cron field, like
+// 1-5,10 or */15.
+func parseCronField(str string, f cronField) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(str, ",") {
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			rng = item[:i]
+
+			var err error
+			step, err = strconv.Atoi(item[i+1:])
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("cron %s step in %q is not a positive number", f.name, item)
+			}
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" && rng != "?" {
+			bounds := strings.SplitN(rng, "-", 2)
+
+			var err error
+			if lo, err = parseCronValue(bounds[0], f); err != nil {
+				return nil, err
+			}
+
+			switch {
+			case len(bounds) == 2:
+				if hi, err = parseCronValue(bounds[1], f); err != nil {
+					return nil, err
+				}
+			case step == 1:
+				hi = lo
+			}
+
+			if lo > hi {
+				return nil, fmt.Errorf("cron %s range %q is backwards", f.name, item)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			values = append(values, v)
+		}
+	}
+
+	return uniqueSortedInts(values), nil
+}
+
+func parseCronValue(str string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToUpper(str)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("cron %s %q is not supported", f.name, str)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron %s %d is not between %d and %d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+func uniqueSortedInts(ints []int) []int {
+	sort.Ints(ints)
+
+	unique := ints[:0]
+	for _, v := range ints {
+		if len(unique) == 0 || v != unique[len(unique)-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// CronError describes why a pattern can't be converted to a cron expression.
+type CronError struct {
+	// Part is the name of the rule part that prevented conversion, like
+	// BYSETPOS, or DTSTART when a field depends on Dtstart but it is zero.
+	Part string
+
+	// Reason describes the problem.
+	Reason string
+}
+
+func (e *CronError) Error() string {
+	return fmt.Sprintf("cannot convert %s to cron: %s", e.Part, e.Reason)
+}
+
+// ToCron returns a cron expression for the pattern's schedule, if cron can
+// express it, or a *CronError naming the rule part that prevents it. Fields
+// that the pattern doesn't restrict are taken from Dtstart as RFC 5545
+// describes. The expression has five fields, unless the pattern fires at
+// seconds other than 0, when seconds are added as a sixth field at the start.
+//
+// Cron has no time zone, so the schedule is in the location of Dtstart.
+func (rrule RRule) ToCron() (string, error) {
+	switch {
+	case rrule.Count != 0:
+		return "", &CronError{Part: "COUNT", Reason: "cron schedules don't end"}
+	case !rrule.Until.IsZero():
+		return "", &CronError{Part: "UNTIL", Reason: "cron schedules don't end"}
+	case rrule.Interval > 1:
+		return "", &CronError{Part: "INTERVAL", Reason: "cron steps aren't counted from a start time"}
+	case len(rrule.BySetPos) > 0:
+		return "", &CronError{Part: "BYSETPOS", Reason: "cron has no set positions"}
+	case len(rrule.ByWeekNumbers) > 0:
+		return "", &CronError{Part: "BYWEEKNO", Reason: "cron has no week numbers"}
+	case len(rrule.ByYearDays) > 0:
+		return "", &CronError{Part: "BYYEARDAY", Reason: "cron has no days of the year"}
+	case len(rrule.ByLeapMonths) > 0:
+		return "", &CronError{Part: "BYMONTH", Reason: "cron has no leap months"}
+	case rrule.RScale != Gregorian:
+		return "", &CronError{Part: "RSCALE", Reason: "cron only uses the Gregorian calendar"}
+	case rrule.InvalidBehavior != OmitInvalid:
+		return "", &CronError{Part: "SKIP", Reason: "cron always omits invalid dates"}
+	case len(rrule.ByMonthDays) > 0 && len(rrule.ByWeekdays) > 0:
+		return "", &CronError{Part: "BYMONTHDAY", Reason: "cron matches days in either BYMONTHDAY or BYDAY, not both"}
+	}
+
+	for _, wd := range rrule.ByWeekdays {
+		if wd.N != 0 {
+			return "", &CronError{Part: "BYDAY", Reason: fmt.Sprintf("cron has no ordinal weekdays like %s", wd)}
+		}
+	}
+	for _, d := range rrule.ByMonthDays {
+		if d < 0 {
+			return "", &CronError{Part: "BYMONTHDAY", Reason: fmt.Sprintf("cron can't count %d days from the end of the month", d)}
+		}
+	}
+
+	// fromDtstart returns the field for a part that the pattern doesn't
+	// restrict, which is * if the frequency is at least as fine as the
+	// part, or Dtstart's value otherwise.
+	var errNoDtstart error
+	fromDtstart := func(part string, freq Frequency, value func(time.Time) int) string {
+		if rrule.Frequency <= freq {
+			return "*"
+		}
+		if rrule.Dtstart.IsZero() {
+			errNoDtstart = &CronError{Part: "DTSTART", Reason: fmt.Sprintf("%s is taken from Dtstart, which is not set", part)}
+			return ""
+		}
+		return strconv.Itoa(value(rrule.Dtstart))
+	}
+
+	second := cronList(rrule.BySeconds)
+	if second == "" {
+		second = fromDtstart("the second", Secondly, time.Time.Second)
+	}
+	minute := cronList(rrule.ByMinutes)
+	if minute == "" {
+		minute = fromDtstart("the minute", Minutely, time.Time.Minute)
+	}
+	hour := cronList(rrule.ByHours)
+	if hour == "" {
+		hour = fromDtstart("the hour", Hourly, time.Time.Hour)
+	}
+
+	weekdays := make([]int, len(rrule.ByWeekdays))
+	for i, wd := range rrule.ByWeekdays {
+		weekdays[i] = int(wd.WD)
+	}
+	monthDay, weekday := cronList(rrule.ByMonthDays), cronList(weekdays)
+
+	// A YEARLY pattern with BYDAY matches those weekdays in every month,
+	// but with BYMONTHDAY, only in Dtstart's month.
+	months := make([]int, len(rrule.ByMonths))
+	for i, m := range rrule.ByMonths {
+		months[i] = int(m)
+	}
+	month := cronList(months)
+	switch {
+	case month != "":
+	case weekday != "":
+		month = "*"
+	default:
+		month = fromDtstart("the month", Monthly, func(t time.Time) int { return int(t.Month()) })
+	}
+
+	switch {
+	case monthDay != "":
+		weekday = "*"
+	case weekday != "":
+		monthDay = "*"
+	case rrule.Frequency == Weekly:
+		monthDay = "*"
+		weekday = fromDtstart("the day of the week", Daily, func(t time.Time) int { return int(t.Weekday()) })
+	default:
+		weekday = "*"
+		monthDay = fromDtstart("the day of the month", Daily, time.Time.Day)
+	}
+
+	if errNoDtstart != nil {
+		return "", errNoDtstart
+	}
+
+	fields := []string{minute, hour, monthDay, month, weekday}
+	if second != "0" {
+		fields = append([]string{second}, fields...)
+	}
+	return strings.Join(fields, " "), nil
+}
+
+// cronList returns a cron field matching ints, with runs of three or more
+// written as ranges, or "" if there are none.
+func cronList(ints []int) string {
+	ints = uniqueSortedInts(append([]int(nil), ints...))
+
+	var items []string
+	for i := 0; i < len(ints); {
+		j := i
+		for j+1 < len(ints) && ints[j+1] == ints[j]+1 {
+			j++
+		}
+
+		if j-i >= 2 {
+			items = append(items, fmt.Sprintf("%d-%d", ints[i], ints[j]))
+		} else {
+			for _, v := range ints[i : j+1] {
+				items = append(items, strconv.Itoa(v))
+			}
+		}
+		i = j + 1
+	}
+
+	return strings.Join(items, ",")
+}
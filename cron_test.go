@@ -0,0 +1,133 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromCron(t *testing.T) {
+	for spec, want := range map[string]string{
+		"* * * * *":           "FREQ=MINUTELY;BYSECOND=0",
+		"* * * * * *":         "FREQ=SECONDLY",
+		"*/15 * * * *":        "FREQ=HOURLY;BYSECOND=0;BYMINUTE=0,15,30,45",
+		"30 9 * * 1-5":        "FREQ=WEEKLY;BYSECOND=0;BYMINUTE=30;BYHOUR=9;BYDAY=MO,TU,WE,TH,FR",
+		"30 9 * * mon-fri":    "FREQ=WEEKLY;BYSECOND=0;BYMINUTE=30;BYHOUR=9;BYDAY=MO,TU,WE,TH,FR",
+		"0 0 1,15 * *":        "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=0;BYMONTHDAY=1,15",
+		"0 0 * * 0,7":         "FREQ=WEEKLY;BYSECOND=0;BYMINUTE=0;BYHOUR=0;BYDAY=SU",
+		"0 0 */2 * MON":       "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=0;BYDAY=MO;BYMONTHDAY=1,3,5,7,9,11,13,15,17,19,21,23,25,27,29,31",
+		"0 0 ? JAN,JUL SUN":   "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=0;BYDAY=SU;BYMONTH=1,7",
+		"0 12 * 6-8 *":        "FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYMONTH=6,7,8",
+		"0 * 9-17 * * *":      "FREQ=MINUTELY;BYSECOND=0;BYHOUR=9,10,11,12,13,14,15,16,17",
+		"0 0 1-31 * 1":        "FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=0",
+		"5/20 0-59/1 * * * *": "FREQ=MINUTELY;BYSECOND=5,25,45",
+		"@yearly":             "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=0;BYMONTHDAY=1;BYMONTH=1",
+		"@hourly":             "FREQ=HOURLY;BYSECOND=0;BYMINUTE=0",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			rrule, err := FromCron(spec)
+			require.NoError(t, err)
+			assert.Equal(t, want, rrule.String())
+		})
+	}
+}
+
+func TestFromCronInstances(t *testing.T) {
+	rrule, err := FromCron("30 9 * * 1-5")
+	require.NoError(t, err)
+
+	// a Saturday
+	rrule.Dtstart = time.Date(2018, time.August, 25, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{
+		"2018-08-27T09:30:00Z",
+		"2018-08-28T09:30:00Z",
+		"2018-08-29T09:30:00Z",
+		"2018-08-30T09:30:00Z",
+		"2018-08-31T09:30:00Z",
+		"2018-09-03T09:30:00Z",
+	}, rfcAll(All(rrule.Iterator(), 6)))
+}
+
+func TestFromCronErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * L * *",
+		"* * * * MON#2",
+		"0 0 1 * MON",
+		"@reboot",
+	} {
+		_, err := FromCron(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestToCron(t *testing.T) {
+	dtstart := time.Date(2018, time.August, 25, 9, 30, 0, 0, time.UTC) // a Saturday
+
+	for _, tc := range []struct {
+		RRule RRule
+		Cron  string
+	}{
+		{RRule{Frequency: Minutely, BySeconds: []int{0}}, "* * * * *"},
+		{RRule{Frequency: Secondly}, "* * * * * *"},
+		{RRule{Frequency: Hourly, ByMinutes: []int{0, 15, 30, 45}, BySeconds: []int{0}}, "0,15,30,45 * * * *"},
+		{RRule{Frequency: Daily, Dtstart: dtstart}, "30 9 * * *"},
+		{RRule{Frequency: Daily, Dtstart: dtstart.Add(5 * time.Second)}, "5 30 9 * * *"},
+		{RRule{Frequency: Weekly, Dtstart: dtstart}, "30 9 * * 6"},
+		{RRule{Frequency: Weekly, Dtstart: dtstart, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Tuesday}, {WD: time.Wednesday}, {WD: time.Friday}}}, "30 9 * * 1-3,5"},
+		{RRule{Frequency: Monthly, Dtstart: dtstart}, "30 9 25 * *"},
+		{RRule{Frequency: Monthly, ByMonthDays: []int{1, 15}, ByHours: []int{0}, ByMinutes: []int{0}, BySeconds: []int{0}}, "0 0 1,15 * *"},
+		{RRule{Frequency: Yearly, Dtstart: dtstart}, "30 9 25 8 *"},
+		{RRule{Frequency: Yearly, Dtstart: dtstart, ByMonthDays: []int{1}}, "30 9 1 8 *"},
+		{RRule{Frequency: Yearly, Dtstart: dtstart, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}}, "30 9 * * 1"},
+		{RRule{Frequency: Yearly, Dtstart: dtstart, ByMonths: []time.Month{time.August, time.December}}, "30 9 25 8,12 *"},
+		{RRule{Frequency: Daily, Dtstart: dtstart, ByMonths: []time.Month{time.June, time.July, time.August}}, "30 9 * 6-8 *"},
+	} {
+		t.Run(tc.Cron, func(t *testing.T) {
+			spec, err := tc.RRule.ToCron()
+			require.NoError(t, err)
+			assert.Equal(t, tc.Cron, spec)
+
+			// The converted expression fires at the same times.
+			converted, err := FromCron(spec)
+			require.NoError(t, err)
+			rrule := tc.RRule
+			if rrule.Dtstart.IsZero() {
+				rrule.Dtstart = dtstart
+			}
+			converted.Dtstart = rrule.Dtstart
+			assert.Equal(t, rfcAll(All(rrule.Iterator(), 10)), rfcAll(All(converted.Iterator(), 10)))
+		})
+	}
+}
+
+func TestToCronErrors(t *testing.T) {
+	dtstart := time.Date(2018, time.August, 25, 9, 30, 0, 0, time.UTC)
+
+	for part, rrule := range map[string]RRule{
+		"COUNT":      {Frequency: Daily, Count: 3},
+		"UNTIL":      {Frequency: Daily, Until: dtstart},
+		"INTERVAL":   {Frequency: Daily, Interval: 2},
+		"BYSETPOS":   {Frequency: Monthly, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}, BySetPos: []int{1}},
+		"BYDAY":      {Frequency: Monthly, ByWeekdays: []QualifiedWeekday{{N: 2, WD: time.Monday}}},
+		"BYMONTHDAY": {Frequency: Monthly, ByMonthDays: []int{-1}},
+		"RSCALE":     {Frequency: Monthly, RScale: Chinese},
+		"DTSTART":    {Frequency: Daily},
+	} {
+		_, err := rrule.ToCron()
+		require.Error(t, err, part)
+		require.IsType(t, &CronError{}, err, part)
+		assert.Equal(t, part, err.(*CronError).Part)
+	}
+}
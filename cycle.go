@@ -0,0 +1,116 @@
+package rrule
+
+import (
+	"sort"
+	"time"
+)
+
+// cycle steps through values that repeat every period, like the weekdays of
+// BYDAY every 7 days, so that an iterator can move straight from one to the
+// next rather than stepping through and rejecting everything between.
+type cycle struct {
+	// first is the step from the initial value to the first value after it.
+	first int
+
+	// steps[i] is the step from the i-th value to the one after it.
+	steps []int
+
+	// firstIdx is the index of the first value after the initial one.
+	firstIdx int
+
+	idx     int
+	started bool
+}
+
+// newCycle returns a cycle through values, each in [0, period), starting from
+// initial, which needn't be one of them.
+func newCycle(values []int, initial, period int) *cycle {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	unique := sorted[:0]
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			unique = append(unique, v)
+		}
+	}
+
+	c := &cycle{steps: make([]int, len(unique))}
+	for i, v := range unique {
+		if i+1 < len(unique) {
+			c.steps[i] = unique[i+1] - v
+		} else {
+			c.steps[i] = period + unique[0] - v
+		}
+	}
+
+	// the first value after initial, wrapping around to the next period if
+	// they're all before it.
+	c.first = period + unique[0] - initial
+	for i, v := range unique {
+		if v > initial {
+			c.firstIdx = i
+			c.first = v - initial
+			break
+		}
+	}
+
+	c.reset()
+	return c
+}
+
+// next returns the step from the current value to the next one.
+func (c *cycle) next() int {
+	if !c.started {
+		c.started = true
+		return c.first
+	}
+
+	step := c.steps[c.idx]
+	c.idx++
+	if c.idx == len(c.steps) {
+		c.idx = 0
+	}
+	return step
+}
+
+// reset rewinds the cycle to the initial value.
+func (c *cycle) reset() {
+	c.idx = c.firstIdx
+	c.started = false
+}
+
+// clockGaps returns, for each value of a clock field that counts up to period,
+// like the minute of the hour, the number of units from it to the next of
+// values.
+func clockGaps(values []int, period int) []int {
+	allowed := make([]bool, period)
+	for _, v := range values {
+		if v < 0 {
+			v += period
+		}
+		allowed[v] = true
+	}
+
+	gaps := make([]int, period)
+	for v := range gaps {
+		for gap := 1; gap <= period; gap++ {
+			if allowed[(v+gap)%period] {
+				gaps[v] = gap
+				break
+			}
+		}
+	}
+	return gaps
+}
+
+// stepClock returns t advanced by gap, or by only one unit if that would cross
+// a change in t's UTC offset, which moves the clock out of step with elapsed
+// time.
+func stepClock(t time.Time, gap, unit time.Duration) time.Time {
+	next := t.Add(gap)
+	_, offset := t.Zone()
+	if _, nextOffset := next.Zone(); nextOffset != offset {
+		return t.Add(unit)
+	}
+	return next
+}
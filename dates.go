@@ -0,0 +1,126 @@
+package rrule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Period is a span of time, as used by RDATE properties with VALUE=PERIOD.
+type Period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// DateList is a parsed RDATE or EXDATE property.
+type DateList struct {
+	// Name is either RDATE or EXDATE.
+	Name string `json:"name"`
+
+	// Times holds the listed times. For VALUE=PERIOD lists, it holds the
+	// start of each period.
+	Times []time.Time `json:"times"`
+
+	// Periods holds the listed periods when the list has VALUE=PERIOD.
+	Periods []Period `json:"periods,omitempty"`
+
+	// AllDay is true when the list has VALUE=DATE. Each time is midnight of
+	// its date.
+	AllDay bool `json:"all_day"`
+
+	// Floating is true when the times had neither a TZID nor a UTC
+	// designator, and so were interpreted in the default location.
+	Floating bool `json:"floating"`
+}
+
+// ParseDateList parses an RDATE or EXDATE property, like
+//
+//	EXDATE;TZID=America/New_York:19970902T090000,19970903T090000
+//	RDATE;VALUE=DATE:19970101,19970120
+//	RDATE;VALUE=PERIOD:19960403T020000Z/19960403T040000Z,19960404T010000Z/PT3H
+//
+// loc defines what "local" means to the parsed times, as in ParseRecurrence.
+// If nil, time.UTC will be used.
+func ParseDateList(line string, loc *time.Location) (*DateList, error) {
+	prop, err := parseContentLine(strings.TrimSpace(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if prop.Name != "RDATE" && prop.Name != "EXDATE" {
+		return nil, fmt.Errorf("%q is not an RDATE or EXDATE property", prop.Name)
+	}
+
+	list := &DateList{Name: prop.Name}
+
+	valueType := strings.ToUpper(prop.Param("VALUE"))
+	switch valueType {
+	case "", "DATE-TIME":
+	case "DATE":
+		list.AllDay = true
+	case "PERIOD":
+		if prop.Name != "RDATE" {
+			return nil, fmt.Errorf("%s must not have VALUE=PERIOD", prop.Name)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported %s value type %q", prop.Name, valueType)
+	}
+
+	// parseTime expects the whole property, so rebuild one for each value.
+	prefix := "X:"
+	if tzid := prop.Param("TZID"); tzid != "" {
+		prefix = "X;TZID=" + tzid + ":"
+	}
+
+	for _, v := range strings.Split(prop.Value, ",") {
+		if valueType == "DATE" && len(v) != len(rfc5545Date) {
+			return nil, fmt.Errorf("%q is not a DATE", v)
+		}
+
+		end := ""
+		if valueType == "PERIOD" {
+			slash := strings.IndexByte(v, '/')
+			if slash < 0 {
+				return nil, fmt.Errorf("%q is not a PERIOD", v)
+			}
+			v, end = v[:slash], v[slash+1:]
+		}
+
+		t, floating, err := parseTime(prefix+v, loc)
+		if err != nil {
+			return nil, err
+		}
+		list.Times = append(list.Times, t)
+		list.Floating = floating
+
+		if valueType == "PERIOD" {
+			p := Period{Start: t}
+			if strings.HasPrefix(end, "P") || strings.HasPrefix(end, "+P") {
+				d, err := parseDuration(end)
+				if err != nil {
+					return nil, err
+				}
+				p.End = t.Add(d)
+			} else {
+				p.End, _, err = parseTime(prefix+end, loc)
+				if err != nil {
+					return nil, err
+				}
+			}
+			list.Periods = append(list.Periods, p)
+		}
+	}
+
+	return list, nil
+}
+
+// AddDateList adds the times in an RDATE or EXDATE list to the recurrence's
+// RDates or ExDates, respectively.
+func (r *Recurrence) AddDateList(list *DateList) {
+	switch list.Name {
+	case "RDATE":
+		r.RDates = append(r.RDates, list.Times...)
+	case "EXDATE":
+		r.ExDates = append(r.ExDates, list.Times...)
+	}
+}
@@ -0,0 +1,55 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDateList(t *testing.T) {
+	list, err := ParseDateList("EXDATE;TZID=America/New_York:19970902T090000,19970903T090000", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "EXDATE", list.Name)
+	assert.Equal(t, []string{"1997-09-02T09:00:00-04:00", "1997-09-03T09:00:00-04:00"}, rfcAll(list.Times))
+	assert.False(t, list.Floating)
+	assert.False(t, list.AllDay)
+
+	list, err = ParseDateList("RDATE;VALUE=DATE:19970101,19970120", NewYork())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1997-01-01T00:00:00-05:00", "1997-01-20T00:00:00-05:00"}, rfcAll(list.Times))
+	assert.True(t, list.Floating)
+	assert.True(t, list.AllDay)
+
+	list, err = ParseDateList("RDATE;VALUE=PERIOD:19960403T020000Z/19960403T040000Z,19960404T010000Z/PT3H", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1996-04-03T02:00:00Z", "1996-04-04T01:00:00Z"}, rfcAll(list.Times))
+	assert.Equal(t, []Period{
+		{Start: time.Date(1996, time.April, 3, 2, 0, 0, 0, time.UTC), End: time.Date(1996, time.April, 3, 4, 0, 0, 0, time.UTC)},
+		{Start: time.Date(1996, time.April, 4, 1, 0, 0, 0, time.UTC), End: time.Date(1996, time.April, 4, 4, 0, 0, 0, time.UTC)},
+	}, list.Periods)
+
+	for _, line := range []string{
+		"DTSTART:19970902T090000Z",
+		"EXDATE;VALUE=PERIOD:19960403T020000Z/PT1H",
+		"RDATE;VALUE=DATE:19970902T090000Z",
+		"RDATE;VALUE=PERIOD:19960403T020000Z",
+		"RDATE;VALUE=BINARY:abc",
+		"RDATE:notatime",
+	} {
+		_, err := ParseDateList(line, nil)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestParseRecurrenceDateLists(t *testing.T) {
+	src := "DTSTART;TZID=America/New_York:19970902T090000\n" +
+		"RRULE:FREQ=DAILY;COUNT=3\n" +
+		"RDATE;TZID=America/New_York:19970910T090000,19970911T090000\n" +
+		"EXDATE;TZID=America/New_York:19970903T090000\n"
+
+	r, err := ParseRecurrence([]byte(src), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1997-09-02T09:00:00-04:00", "1997-09-04T09:00:00-04:00", "1997-09-10T09:00:00-04:00", "1997-09-11T09:00:00-04:00"}, rfcAll(All(r.Iterator(), 0)))
+}
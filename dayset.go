@@ -0,0 +1,67 @@
+package rrule
+
+import (
+	"math/bits"
+	"time"
+)
+
+// daySet is a set of the days of a year or a month, numbered from 1 for its
+// first day. Besides those days, it holds the week or so either side, which
+// invalid days and BYWEEKNO can reach.
+type daySet [6]uint64
+
+// daySetOffset is the number of days before the year that a daySet holds.
+const daySetOffset = 7
+
+func (s *daySet) add(day int) {
+	if i := day + daySetOffset; i >= 0 && i < len(s)*64 {
+		s[i/64] |= 1 << uint(i%64)
+	}
+}
+
+// addRange adds the days from from up to, but not including, to.
+func (s *daySet) addRange(from, to int) {
+	for day := from; day < to; day++ {
+		s.add(day)
+	}
+}
+
+func (s *daySet) intersect(other daySet) {
+	for i := range s {
+		s[i] &= other[i]
+	}
+}
+
+// days returns the days in the set, in order.
+func (s *daySet) days() []int {
+	n := 0
+	for _, word := range s {
+		n += bits.OnesCount64(word)
+	}
+	return s.appendDays(make([]int, 0, n))
+}
+
+// appendDays appends the days in the set to dst, in order.
+func (s *daySet) appendDays(dst []int) []int {
+	for i, word := range s {
+		for word != 0 {
+			dst = append(dst, i*64+bits.TrailingZeros64(word)-daySetOffset)
+			word &= word - 1
+		}
+	}
+	return dst
+}
+
+// weekdaySet returns the days that fall on weekdays, ignoring their numbers,
+// given that day 1 falls on first.
+func weekdaySet(first time.Weekday, weekdays []QualifiedWeekday) daySet {
+	var s daySet
+	lowest := -daySetOffset
+	for _, wd := range weekdays {
+		day := lowest + ((int(wd.WD)-int(first)-lowest+1)%7+7)%7
+		for ; day < len(s)*64-daySetOffset; day += 7 {
+			s.add(day)
+		}
+	}
+	return s
+}
@@ -30,13 +30,16 @@ func (rrule RRule) Describe() string {
 		}
 		fmt.Fprintf(b, ", for %d occurrence%s", rrule.Count, plural)
 	}
-	if rrule.WeekStart != nil {
-		fmt.Fprintf(b, ", with weeks starting on %v", rrule.WeekStart)
+	if wd, ok := rrule.WeekStart.Weekday(); ok {
+		fmt.Fprintf(b, ", with weeks starting on %v", wd)
 	}
 	if !rrule.Until.IsZero() {
 		fmt.Fprintf(b, ", until %v", rrule.Until.Format(time.UnixDate))
 	}
 	byMonthDesc(b, rrule.ByMonths)
+	if len(rrule.ByLeapMonths) > 0 {
+		fmt.Fprintf(b, ", in the leap month after the %s month", ordinalList(rrule.ByLeapMonths, ", ", "or"))
+	}
 	byTimeDesc(b, rrule.ByMonthDays, "day of the month")
 	byTimeDesc(b, rrule.ByYearDays, "day of the year")
 	byTimeDesc(b, rrule.ByWeekNumbers, "week of the yar")
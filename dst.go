@@ -0,0 +1,282 @@
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NonexistentBehavior specifies how to behave when a pattern generates a local
+// time that doesn't exist because it falls in the gap left when clocks are
+// set forward, like 02:30 on the day daylight saving time begins in New York.
+type NonexistentBehavior int
+
+const (
+	// DefaultNonexistent leaves the time as time.Date interprets it, which
+	// is not guaranteed to be consistent. Later occurrences of DAILY and
+	// coarser patterns with BYXXX parts may also keep the clock time it was
+	// moved to. Those of simple patterns, as described by At, don't.
+	DefaultNonexistent NonexistentBehavior = iota
+
+	// SkipNonexistent omits the occurrence.
+	SkipNonexistent
+
+	// ForwardNonexistent moves the time later by the length of the gap, so
+	// 02:30 becomes 03:30. This is the interpretation RFC 5545 requires.
+	ForwardNonexistent
+
+	// BackwardNonexistent moves the time earlier by the length of the gap,
+	// so 02:30 becomes 01:30.
+	BackwardNonexistent
+)
+
+// AmbiguousBehavior specifies how to behave when a pattern generates a local
+// time that happens twice because clocks are set back, like 01:30 on the day
+// daylight saving time ends in New York.
+type AmbiguousBehavior int
+
+const (
+	// DefaultAmbiguous leaves the time as time.Date interprets it, which is
+	// not guaranteed to be consistent.
+	DefaultAmbiguous AmbiguousBehavior = iota
+
+	// FirstAmbiguous chooses the earlier of the two times, which uses the
+	// offset in effect before the transition. This is the interpretation
+	// RFC 5545 requires.
+	FirstAmbiguous
+
+	// SecondAmbiguous chooses the later of the two times, which uses the
+	// offset in effect after the transition.
+	SecondAmbiguous
+)
+
+// Stepping specifies how HOURLY, MINUTELY and SECONDLY patterns advance when
+// the clocks in the location of Dtstart change for daylight saving time.
+type Stepping int
+
+const (
+	// ElapsedStepping advances by elapsed time, so an HOURLY pattern occurs
+	// every 60 minutes, and its clock times shift by an hour across a
+	// transition.
+	ElapsedStepping Stepping = iota
+
+	// WallClockStepping advances by clock time, so an HOURLY pattern occurs
+	// at the same clock times every day. Clock times that don't exist are
+	// skipped, and those that happen twice occur once, at the first, unless
+	// Nonexistent or Ambiguous say otherwise.
+	WallClockStepping
+)
+
+// hasLocalTimePolicy reports whether the pattern's occurrences are generated
+// as clock times and then resolved in Dtstart's location. Patterns finer than
+// DAILY step by elapsed time unless they use WallClockStepping, so their
+// occurrences always exist.
+func (rrule RRule) hasLocalTimePolicy() bool {
+	if rrule.Frequency < Daily {
+		return rrule.Stepping == WallClockStepping
+	}
+	return rrule.Nonexistent != DefaultNonexistent || rrule.Ambiguous != DefaultAmbiguous
+}
+
+// withLocalTimePolicy returns an iterator that generates the pattern's clock
+// times in UTC, where no times are skipped or repeated, from build, and then
+// resolves them in Dtstart's location by the pattern's policies.
+func (rrule RRule) withLocalTimePolicy(build func(RRule) (*iterator, error)) (*iterator, error) {
+	start := rrule.dtstart()
+	loc := start.Location()
+
+	floating := rrule
+	floating.Dtstart = wallClockIn(start, time.UTC)
+	floating.Until = time.Time{}
+	it, err := build(floating)
+	if err != nil {
+		return nil, err
+	}
+
+	it.minTime = start
+	it.maxTime = timeOrMax(rrule.Until)
+
+	nonexistent, ambiguous := rrule.Nonexistent, rrule.Ambiguous
+	if rrule.Frequency < Daily {
+		// stepping through every clock time would otherwise land in gaps
+		// and overlaps every time.
+		if nonexistent == DefaultNonexistent {
+			nonexistent = SkipNonexistent
+		}
+		if ambiguous == DefaultAmbiguous {
+			ambiguous = FirstAmbiguous
+		}
+	}
+
+	if it.steps != nil {
+		it.steps = &localTimeSteps{
+			stepper:     it.steps,
+			loc:         loc,
+			nonexistent: nonexistent,
+			ambiguous:   ambiguous,
+		}
+	}
+	return it, nil
+}
+
+// localTimeSteps resolves the clock times another stepper generates in UTC in
+// loc.
+type localTimeSteps struct {
+	stepper
+	loc         *time.Location
+	nonexistent NonexistentBehavior
+	ambiguous   AmbiguousBehavior
+
+	// last is the latest time generated, since moving times in a gap can
+	// land them on or before times already generated.
+	last time.Time
+}
+
+func (s *localTimeSteps) variations(t time.Time) []time.Time {
+	var tt []time.Time
+	for _, w := range s.stepper.variations(t) {
+		if lt, ok := localTime(w, s.loc, s.nonexistent, s.ambiguous); ok {
+			tt = append(tt, lt)
+		}
+	}
+
+	sort.Slice(tt, func(i, j int) bool { return tt[i].Before(tt[j]) })
+	deduped := tt[:0]
+	for _, t := range tt {
+		if s.last.IsZero() || t.After(s.last) {
+			deduped = append(deduped, t)
+			s.last = t
+		}
+	}
+	return deduped
+}
+
+func (s *localTimeSteps) reset() {
+	s.last = time.Time{}
+	s.stepper.reset()
+}
+
+func (s *localTimeSteps) fastForward(t time.Time) bool {
+	ff, ok := s.stepper.(fastForwarder)
+	if !ok {
+		return false
+	}
+	// a day earlier covers any difference between the offset and UTC.
+	return ff.fastForward(wallClockIn(t.In(s.loc), time.UTC).AddDate(0, 0, -1))
+}
+
+// localTime returns the time in loc with the clock time of w. ok is false if
+// that time doesn't exist and nonexistent is SkipNonexistent.
+func localTime(w time.Time, loc *time.Location, nonexistent NonexistentBehavior, ambiguous AmbiguousBehavior) (t time.Time, ok bool) {
+	u := wallClockIn(w, time.UTC)
+
+	// the offsets in effect a day either side of the time, which differ
+	// if it is near a transition.
+	_, before := u.AddDate(0, 0, -1).In(loc).Zone()
+	_, after := u.AddDate(0, 0, 1).In(loc).Zone()
+	first := u.Add(-time.Duration(before) * time.Second).In(loc)
+	second := u.Add(-time.Duration(after) * time.Second).In(loc)
+	if first.After(second) {
+		first, second = second, first
+	}
+
+	firstOK := sameClock(first, u)
+	secondOK := sameClock(second, u)
+	switch {
+	case firstOK && secondOK && !first.Equal(second):
+		switch ambiguous {
+		case FirstAmbiguous:
+			return first, true
+		case SecondAmbiguous:
+			return second, true
+		}
+	case firstOK:
+		return first, true
+	case secondOK:
+		return second, true
+	default:
+		switch nonexistent {
+		case SkipNonexistent:
+			return time.Time{}, false
+		case ForwardNonexistent:
+			return u.Add(-time.Duration(before) * time.Second).In(loc), true
+		case BackwardNonexistent:
+			return u.Add(-time.Duration(after) * time.Second).In(loc), true
+		}
+	}
+
+	return wallClockIn(w, loc), true
+}
+
+// sameClock reports whether t has the date and clock time of u.
+func sameClock(t, u time.Time) bool {
+	ty, tm, td := t.Date()
+	uy, um, ud := u.Date()
+	return ty == uy && tm == um && td == ud &&
+		t.Hour() == u.Hour() && t.Minute() == u.Minute() && t.Second() == u.Second()
+}
+
+func nonexistentString(nonexistent NonexistentBehavior) string {
+	switch nonexistent {
+	case SkipNonexistent:
+		return "SKIP"
+	case ForwardNonexistent:
+		return "FORWARD"
+	case BackwardNonexistent:
+		return "BACKWARD"
+	}
+	return ""
+}
+
+func parseNonexistent(str string) (NonexistentBehavior, error) {
+	switch strings.ToLower(str) {
+	case "skip":
+		return SkipNonexistent, nil
+	case "forward":
+		return ForwardNonexistent, nil
+	case "backward":
+		return BackwardNonexistent, nil
+	}
+	return DefaultNonexistent, fmt.Errorf("nonexistent time behavior %v is not valid", str)
+}
+
+func steppingString(stepping Stepping) string {
+	switch stepping {
+	case ElapsedStepping:
+		return "ELAPSED"
+	case WallClockStepping:
+		return "WALL_CLOCK"
+	}
+	return ""
+}
+
+func parseStepping(str string) (Stepping, error) {
+	switch strings.ToLower(str) {
+	case "elapsed":
+		return ElapsedStepping, nil
+	case "wall_clock":
+		return WallClockStepping, nil
+	}
+	return ElapsedStepping, fmt.Errorf("stepping %v is not valid", str)
+}
+
+func ambiguousString(ambiguous AmbiguousBehavior) string {
+	switch ambiguous {
+	case FirstAmbiguous:
+		return "FIRST"
+	case SecondAmbiguous:
+		return "SECOND"
+	}
+	return ""
+}
+
+func parseAmbiguous(str string) (AmbiguousBehavior, error) {
+	switch strings.ToLower(str) {
+	case "first":
+		return FirstAmbiguous, nil
+	case "second":
+		return SecondAmbiguous, nil
+	}
+	return DefaultAmbiguous, fmt.Errorf("ambiguous time behavior %v is not valid", str)
+}
@@ -0,0 +1,172 @@
+package rrule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonexistentBehavior(t *testing.T) {
+	// clocks in New York skip from 02:00 to 03:00 on 2018-03-11.
+	dtstart := time.Date(2018, time.March, 10, 2, 30, 0, 0, NewYork())
+
+	for nonexistent, want := range map[NonexistentBehavior][]string{
+		DefaultNonexistent: {
+			"2018-03-10T02:30:00-05:00",
+			"2018-03-11T01:30:00-05:00",
+			"2018-03-12T02:30:00-04:00",
+		},
+		SkipNonexistent: {
+			"2018-03-10T02:30:00-05:00",
+			"2018-03-12T02:30:00-04:00",
+			"2018-03-13T02:30:00-04:00",
+		},
+		ForwardNonexistent: {
+			"2018-03-10T02:30:00-05:00",
+			"2018-03-11T03:30:00-04:00",
+			"2018-03-12T02:30:00-04:00",
+		},
+		BackwardNonexistent: {
+			"2018-03-10T02:30:00-05:00",
+			"2018-03-11T01:30:00-05:00",
+			"2018-03-12T02:30:00-04:00",
+		},
+	} {
+		rrule := RRule{Frequency: Daily, Count: 3, Dtstart: dtstart, Nonexistent: nonexistent}
+		assert.Equal(t, want, rfcAll(All(rrule.Iterator(), 0)), "%d", nonexistent)
+	}
+}
+
+func TestAmbiguousBehavior(t *testing.T) {
+	// clocks in New York go back from 02:00 to 01:00 on 2018-11-04.
+	dtstart := time.Date(2018, time.November, 3, 1, 30, 0, 0, NewYork())
+
+	for ambiguous, want := range map[AmbiguousBehavior][]string{
+		FirstAmbiguous: {
+			"2018-11-03T01:30:00-04:00",
+			"2018-11-04T01:30:00-04:00",
+			"2018-11-05T01:30:00-05:00",
+		},
+		SecondAmbiguous: {
+			"2018-11-03T01:30:00-04:00",
+			"2018-11-04T01:30:00-05:00",
+			"2018-11-05T01:30:00-05:00",
+		},
+	} {
+		rrule := RRule{Frequency: Daily, Count: 3, Dtstart: dtstart, Ambiguous: ambiguous}
+		assert.Equal(t, want, rfcAll(All(rrule.Iterator(), 0)), "%d", ambiguous)
+	}
+}
+
+func TestLocalTimePolicyPatterns(t *testing.T) {
+	dtstart := time.Date(2018, time.March, 1, 2, 30, 0, 0, NewYork())
+
+	// the second Sunday of March is when clocks are set forward.
+	rrule := RRule{
+		Frequency:   Yearly,
+		ByMonths:    []time.Month{time.March},
+		ByWeekdays:  []QualifiedWeekday{{N: 2, WD: time.Sunday}},
+		Dtstart:     dtstart,
+		Until:       time.Date(2020, time.March, 8, 7, 30, 0, 0, time.UTC),
+		Nonexistent: ForwardNonexistent,
+	}
+	assert.Equal(t, []string{
+		"2018-03-11T03:30:00-04:00",
+		"2019-03-10T03:30:00-04:00",
+		"2020-03-08T03:30:00-04:00",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	it := rrule.Iterator()
+	it.Seek(time.Date(2019, time.March, 10, 7, 30, 0, 0, time.UTC))
+	assert.Equal(t, "2019-03-10T03:30:00-04:00", rfcAll(it.Take(1))[0])
+
+	// two times on one day that become the same instant are merged.
+	rrule = RRule{
+		Frequency:   Daily,
+		ByHours:     []int{1, 2},
+		Dtstart:     time.Date(2018, time.March, 11, 1, 30, 0, 0, NewYork()),
+		Count:       2,
+		Nonexistent: BackwardNonexistent,
+	}
+	assert.Equal(t, []string{
+		"2018-03-11T01:30:00-05:00",
+		"2018-03-12T01:30:00-04:00",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+}
+
+func TestStepping(t *testing.T) {
+	// clocks in New York skip from 02:00 to 03:00 on 2018-03-11.
+	spring := RRule{Frequency: Hourly, Interval: 12, Count: 4, Dtstart: time.Date(2018, time.March, 10, 2, 0, 0, 0, NewYork())}
+	assert.Equal(t, []string{
+		"2018-03-10T02:00:00-05:00",
+		"2018-03-10T14:00:00-05:00",
+		"2018-03-11T03:00:00-04:00",
+		"2018-03-11T15:00:00-04:00",
+	}, rfcAll(All(spring.Iterator(), 0)))
+
+	spring.Stepping = WallClockStepping
+	assert.Equal(t, []string{
+		"2018-03-10T02:00:00-05:00",
+		"2018-03-10T14:00:00-05:00",
+		"2018-03-11T14:00:00-04:00",
+		"2018-03-12T02:00:00-04:00",
+	}, rfcAll(All(spring.Iterator(), 0)))
+
+	spring.Nonexistent = ForwardNonexistent
+	assert.Equal(t, []string{
+		"2018-03-10T02:00:00-05:00",
+		"2018-03-10T14:00:00-05:00",
+		"2018-03-11T03:00:00-04:00",
+		"2018-03-11T14:00:00-04:00",
+	}, rfcAll(All(spring.Iterator(), 0)))
+
+	// clocks in New York go back from 02:00 to 01:00 on 2018-11-04.
+	fall := RRule{Frequency: Hourly, Count: 3, Dtstart: time.Date(2018, time.November, 4, 0, 30, 0, 0, NewYork())}
+	assert.Equal(t, []string{
+		"2018-11-04T00:30:00-04:00",
+		"2018-11-04T01:30:00-04:00",
+		"2018-11-04T01:30:00-05:00",
+	}, rfcAll(All(fall.Iterator(), 0)))
+
+	fall.Stepping = WallClockStepping
+	it := fall.Iterator()
+	assert.Equal(t, []string{
+		"2018-11-04T00:30:00-04:00",
+		"2018-11-04T01:30:00-04:00",
+		"2018-11-04T02:30:00-05:00",
+	}, rfcAll(All(it, 0)))
+	it.Reset()
+	assert.Equal(t, 3, len(All(it, 0)))
+
+	fall.Ambiguous = SecondAmbiguous
+	assert.Equal(t, []string{
+		"2018-11-04T00:30:00-04:00",
+		"2018-11-04T01:30:00-05:00",
+		"2018-11-04T02:30:00-05:00",
+	}, rfcAll(All(fall.Iterator(), 0)))
+}
+
+func TestLocalTimePolicyEncoding(t *testing.T) {
+	rrule := RRule{Frequency: Daily, Nonexistent: ForwardNonexistent, Ambiguous: SecondAmbiguous, Stepping: WallClockStepping}
+
+	b, err := json.Marshal(rrule)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"frequency": "DAILY", "nonexistent": "FORWARD", "ambiguous": "SECOND", "stepping": "WALL_CLOCK"}`, string(b))
+
+	var decoded RRule
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, rrule, decoded)
+	assert.Error(t, json.Unmarshal([]byte(`{"frequency": "DAILY", "ambiguous": "THIRD"}`), &decoded))
+
+	b, err = rrule.ToProto()
+	require.NoError(t, err)
+	decoded, err = FromProto(b)
+	require.NoError(t, err)
+	assert.Equal(t, rrule, decoded)
+
+	_, err = FromProto([]byte{0x08, 0x03, 0xa8, 0x01, 0x04})
+	assert.Error(t, err)
+}
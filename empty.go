@@ -0,0 +1,275 @@
+package rrule
+
+import "time"
+
+// validateOccurrences returns an ErrNoOccurrences error if the pattern's
+// parts exclude each other, so that it can never have an occurrence and
+// iterating it would never end.
+func (rrule RRule) validateOccurrences() error {
+	// BYSETPOS picks from the occurrences of each period, so positions
+	// past the most a period can have never match.
+	if len(rrule.BySetPos) > 0 && rrule.RScale == Gregorian {
+		most := rrule.maxSetSize()
+		possible := false
+		for _, pos := range rrule.BySetPos {
+			if pos < 0 {
+				pos = -pos
+			}
+			possible = possible || pos <= most
+		}
+		if !possible {
+			return kindErrorf(ErrNoOccurrences, "BYSETPOS=%s is beyond the occurrences of each %s period, which has at most %d", intlist(rrule.BySetPos), rrule.Frequency, most)
+		}
+	}
+
+	if rrule.RScale != Gregorian || rrule.InvalidBehavior != OmitInvalid || len(rrule.ByLeapMonths) > 0 {
+		return nil
+	}
+
+	if len(rrule.ByMonths) > 0 && len(rrule.ByMonthDays) > 0 {
+		possible := false
+		for _, day := range rrule.ByMonthDays {
+			if day < 0 {
+				day = -day
+			}
+			for _, m := range rrule.ByMonths {
+				if _, max := monthLengths(m); day <= max {
+					possible = true
+				}
+			}
+		}
+		if !possible {
+			return kindErrorf(ErrNoOccurrences, "BYMONTHDAY=%s never falls in BYMONTH=%s", intlist(rrule.ByMonthDays), monthlist(rrule.ByMonths, nil))
+		}
+	}
+
+	// Without BYMONTHDAY or BYDAY, MONTHLY rules fall on the day of
+	// Dtstart.
+	if rrule.Frequency == Monthly && !rrule.Dtstart.IsZero() && len(rrule.ByMonths) > 0 && len(rrule.ByMonthDays) == 0 && len(rrule.ByWeekdays) == 0 {
+		possible := false
+		for _, m := range rrule.ByMonths {
+			if _, max := monthLengths(m); rrule.Dtstart.Day() <= max {
+				possible = true
+			}
+		}
+		if !possible {
+			return kindErrorf(ErrNoOccurrences, "DTSTART on day %d never falls in BYMONTH=%s", rrule.Dtstart.Day(), monthlist(rrule.ByMonths, nil))
+		}
+	}
+
+	// Numbered weekdays are counted within months in MONTHLY rules, and in
+	// YEARLY rules with BYMONTH. Weekdays without numbers fall on every day
+	// of the month eventually.
+	if len(rrule.ByMonthDays) > 0 && len(rrule.ByWeekdays) > 0 && (rrule.Frequency == Monthly || rrule.Frequency == Yearly && len(rrule.ByMonths) > 0) {
+		possible := false
+		for _, wd := range rrule.ByWeekdays {
+			for _, day := range rrule.ByMonthDays {
+				possible = possible || wd.N == 0 || monthDayCanBeOrdinal(day, wd.N)
+			}
+		}
+		if !possible {
+			return kindErrorf(ErrNoOccurrences, "BYDAY=%s never falls on BYMONTHDAY=%s", weekdaylist(rrule.ByWeekdays), intlist(rrule.ByMonthDays))
+		}
+	}
+
+	// No month has more than five of a weekday.
+	if len(rrule.ByWeekdays) > 0 && (rrule.Frequency == Monthly || rrule.Frequency == Yearly && len(rrule.ByMonths) > 0) {
+		possible := false
+		for _, wd := range rrule.ByWeekdays {
+			possible = possible || wd.N >= -5 && wd.N <= 5
+		}
+		if !possible {
+			return kindErrorf(ErrNoOccurrences, "BYDAY=%s never falls in a month, which has at most 5 of each weekday", weekdaylist(rrule.ByWeekdays))
+		}
+	}
+
+	if len(rrule.ByMonths) > 0 && len(rrule.ByYearDays) > 0 {
+		possible := false
+		for _, day := range rrule.ByYearDays {
+			for _, m := range rrule.ByMonths {
+				possible = possible || yearDayCanBeInMonth(day, m)
+			}
+		}
+		if !possible {
+			return kindErrorf(ErrNoOccurrences, "BYYEARDAY=%s never falls in BYMONTH=%s", intlist(rrule.ByYearDays), monthlist(rrule.ByMonths, nil))
+		}
+	}
+
+	return nil
+}
+
+// maxSetSize returns the most occurrences a period of the pattern's frequency
+// can have, which BYSETPOS picks from: the days BYxxx parts expand it to, each
+// with the times the clock parts expand it to. Parts that only limit the
+// period are ignored, so the result may be more than the period ever has.
+func (rrule RRule) maxSetSize() int {
+	clock := 1
+	if rrule.Frequency > Hourly {
+		clock *= distinct(rrule.ByHours, 24)
+	}
+	if rrule.Frequency > Minutely {
+		clock *= distinct(rrule.ByMinutes, 60)
+	}
+	if rrule.Frequency > Secondly {
+		clock *= distinct(rrule.BySeconds, 60)
+	}
+
+	// weekdays counts the days BYDAY picks, with each weekday without a
+	// number falling on up to perWeekday days.
+	weekdays := func(perWeekday int) int {
+		n := 0
+		for i, wd := range rrule.ByWeekdays {
+			repeated := false
+			for _, prev := range rrule.ByWeekdays[:i] {
+				repeated = repeated || prev == wd
+			}
+			switch {
+			case repeated:
+			case wd.N == 0:
+				n += perWeekday
+			default:
+				n++
+			}
+		}
+		return n
+	}
+	months := distinct(monthInts(rrule.ByMonths), 12)
+
+	days := 1
+	switch rrule.Frequency {
+	case Weekly:
+		if len(rrule.ByWeekdays) > 0 {
+			days = distinctWeekdays(rrule.ByWeekdays)
+		}
+	case Monthly:
+		if len(rrule.ByMonthDays) > 0 {
+			days = distinct(rrule.ByMonthDays, 0)
+		} else if len(rrule.ByWeekdays) > 0 {
+			days = weekdays(5)
+		}
+	case Yearly:
+		switch {
+		case len(rrule.ByYearDays) > 0:
+			days = distinct(rrule.ByYearDays, 0)
+		case len(rrule.ByWeekNumbers) > 0:
+			days = distinct(rrule.ByWeekNumbers, 0) * 7
+		case len(rrule.ByMonthDays) > 0:
+			days = months * distinct(rrule.ByMonthDays, 0)
+		case len(rrule.ByWeekdays) > 0 && len(rrule.ByMonths) > 0:
+			days = months * weekdays(5)
+		case len(rrule.ByWeekdays) > 0:
+			days = weekdays(53)
+		default:
+			days = months
+		}
+		if days > 366 {
+			days = 366
+		}
+	}
+	if days > 31 && rrule.Frequency == Monthly {
+		days = 31
+	}
+	return days * clock
+}
+
+// distinct returns the number of different values, with negative values
+// counted back from period unless it's 0. An empty list counts as one value,
+// as a part that isn't given takes its value from DTSTART.
+func distinct(values []int, period int) int {
+	n := 0
+	for i, v := range values {
+		if v < 0 && period > 0 {
+			v += period
+		}
+		repeated := false
+		for _, prev := range values[:i] {
+			if prev < 0 && period > 0 {
+				prev += period
+			}
+			repeated = repeated || prev == v
+		}
+		if !repeated {
+			n++
+		}
+	}
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+// distinctWeekdays returns the number of different weekdays in wds.
+func distinctWeekdays(wds []QualifiedWeekday) int {
+	var seen [7]bool
+	n := 0
+	for _, wd := range wds {
+		if !seen[wd.WD] {
+			seen[wd.WD] = true
+			n++
+		}
+	}
+	return n
+}
+
+// monthInts returns months as ints, for distinct.
+func monthInts(months []time.Month) []int {
+	ints := make([]int, len(months))
+	for i, m := range months {
+		ints[i] = int(m)
+	}
+	return ints
+}
+
+// monthDayCanBeOrdinal reports whether day of some month, counted from the end
+// if negative, can be the n-th of its weekday in the month.
+func monthDayCanBeOrdinal(day, n int) bool {
+	for length := 28; length <= 31; length++ {
+		d := day
+		if d < 0 {
+			d = length + 1 + d
+		}
+		if d < 1 || d > length {
+			continue
+		}
+
+		nth := (d-1)/7 + 1
+		if n < 0 {
+			nth = -((length-d)/7 + 1)
+		}
+		if nth == n {
+			return true
+		}
+	}
+	return false
+}
+
+// monthLengths returns the fewest and most days that month m has.
+func monthLengths(m time.Month) (min, max int) {
+	switch m {
+	case time.February:
+		return 28, 29
+	case time.April, time.June, time.September, time.November:
+		return 30, 30
+	}
+	return 31, 31
+}
+
+// yearDayCanBeInMonth reports whether day of some year, counted from the end
+// if negative, can be in month m.
+func yearDayCanBeInMonth(day int, m time.Month) bool {
+	for _, year := range []int{2001, 2004} {
+		length := 365
+		if year == 2004 {
+			length = 366
+		}
+
+		d := day
+		if d < 0 {
+			d = length + 1 + d
+		}
+		if d >= 1 && d <= length && time.Date(year, time.January, d, 0, 0, 0, 0, time.UTC).Month() == m {
+			return true
+		}
+	}
+	return false
+}
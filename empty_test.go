@@ -0,0 +1,70 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoOccurrences(t *testing.T) {
+	for str, msg := range map[string]string{
+		"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30":              "BYMONTHDAY=30 never falls in BYMONTH=2",
+		"FREQ=DAILY;BYMONTH=4,6;BYMONTHDAY=-31,31":         "BYMONTHDAY=-31,31 never falls in BYMONTH=4,6",
+		"FREQ=MONTHLY;BYDAY=2MO,-1FR;BYMONTHDAY=1,2,3":     "BYDAY=2MO,-1FR never falls on BYMONTHDAY=1,2,3",
+		"FREQ=YEARLY;BYDAY=1MO;BYMONTH=1;BYMONTHDAY=8":     "BYDAY=1MO never falls on BYMONTHDAY=8",
+		"FREQ=YEARLY;BYYEARDAY=1,-1;BYMONTH=2":             "BYYEARDAY=1,-1 never falls in BYMONTH=2",
+		"FREQ=MONTHLY;BYSETPOS=2,5;BYMONTH=5,1;COUNT=10":   "BYSETPOS=2,5 is beyond the occurrences of each MONTHLY period, which has at most 1",
+		"FREQ=MONTHLY;BYSETPOS=4;BYHOUR=5,6;BYMONTH=6,4,7": "BYSETPOS=4 is beyond the occurrences of each MONTHLY period, which has at most 2",
+		"FREQ=WEEKLY;BYDAY=MO,MO,TU;BYSETPOS=3":            "BYSETPOS=3 is beyond the occurrences of each WEEKLY period, which has at most 2",
+		"FREQ=DAILY;BYSETPOS=5,-3;BYDAY=TH;BYHOUR=2,10":    "BYSETPOS=5,-3 is beyond the occurrences of each DAILY period, which has at most 2",
+		"FREQ=MONTHLY;BYDAY=6MO":                           "BYDAY=6MO never falls in a month, which has at most 5 of each weekday",
+		"FREQ=YEARLY;BYDAY=-6FR,10SU;BYMONTH=3":            "BYDAY=-6FR,10SU never falls in a month, which has at most 5 of each weekday",
+	} {
+		_, err := ParseRRule(str)
+		require.Error(t, err, str)
+		assert.True(t, errors.Is(err, ErrNoOccurrences), str)
+		assert.EqualError(t, err, msg)
+
+		rrule, _, _ := ParseRRuleLenient(str)
+		_, err = rrule.IteratorE()
+		assert.True(t, errors.Is(err, ErrNoOccurrences), str)
+	}
+
+	for _, str := range []string{
+		"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+		"FREQ=YEARLY;BYMONTH=2,3;BYMONTHDAY=30",
+		"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30;SKIP=BACKWARD",
+		"FREQ=MONTHLY;BYDAY=2MO,FR;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=-1FR;BYMONTHDAY=-7",
+		"FREQ=YEARLY;BYDAY=1MO;BYMONTHDAY=8",
+		"FREQ=YEARLY;BYYEARDAY=60;BYMONTH=3",
+		"FREQ=YEARLY;BYYEARDAY=-307;BYMONTH=2",
+		"FREQ=MONTHLY;BYSETPOS=-2;BYHOUR=5,6",
+		"FREQ=MONTHLY;BYDAY=MO;BYSETPOS=5",
+		"FREQ=YEARLY;BYMONTH=1,2;BYSETPOS=-2",
+		"FREQ=MONTHLY;BYDAY=6MO,-5TU",
+		"FREQ=YEARLY;BYDAY=20MO",
+	} {
+		_, err := ParseRRule(str)
+		assert.NoError(t, err, str)
+	}
+
+	// without BYMONTHDAY, MONTHLY rules fall on the day of Dtstart.
+	rrule := MustRRule("FREQ=MONTHLY;BYMONTH=2,4")
+	rrule.Dtstart = time.Date(2019, time.January, 31, 9, 0, 0, 0, time.UTC)
+	_, err := rrule.IteratorE()
+	assert.True(t, errors.Is(err, ErrNoOccurrences))
+	assert.EqualError(t, err, "DTSTART on day 31 never falls in BYMONTH=2,4")
+
+	rrule.Dtstart = rrule.Dtstart.AddDate(0, 0, -1)
+	assert.Equal(t, []string{"2019-04-30T09:00:00Z", "2020-04-30T09:00:00Z"}, rfcAll(rrule.Expand(rrule.Dtstart, rrule.Dtstart.AddDate(2, 0, 0))))
+
+	// a day moved into the next month is still limited by the month it
+	// was moved out of.
+	rrule = MustRRule("FREQ=MONTHLY;BYMONTH=2;SKIP=FORWARD;COUNT=2")
+	rrule.Dtstart = time.Date(2019, time.January, 30, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2019-03-01T09:00:00Z", "2020-03-01T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+}
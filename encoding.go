@@ -0,0 +1,351 @@
+package rrule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MarshalText returns the RFC 5545 representation of the pattern, as String
+// does. Dtstart is not included.
+func (rrule RRule) MarshalText() ([]byte, error) {
+	return rrule.appendFormat(nil, StringOptions{}), nil
+}
+
+// AppendText appends the RFC 5545 representation of the pattern, as
+// MarshalText returns it, to b. It doesn't allocate if b has room, which suits
+// building cache keys.
+func (rrule RRule) AppendText(b []byte) ([]byte, error) {
+	return rrule.appendFormat(b, StringOptions{}), nil
+}
+
+// UnmarshalText parses the pattern from its RFC 5545 representation, as
+// ParseRRule does.
+func (rrule *RRule) UnmarshalText(text []byte) error {
+	parsed, err := ParseRRule(string(text))
+	if err != nil {
+		return err
+	}
+	*rrule = parsed
+	return nil
+}
+
+// rruleJSON is the JSON object form of RRule, with enumerations written by
+// their RFC 5545 names and empty fields omitted.
+type rruleJSON struct {
+	Frequency      string     `json:"frequency"`
+	Until          *time.Time `json:"until,omitempty"`
+	UntilFloating  bool       `json:"until_floating,omitempty"`
+	UntilExclusive bool       `json:"until_exclusive,omitempty"`
+	AllDay         bool       `json:"all_day,omitempty"`
+	Count          uint64     `json:"count,omitempty"`
+	Dtstart        *time.Time `json:"dtstart,omitempty"`
+	Interval       int        `json:"interval,omitempty"`
+
+	BySeconds     []int        `json:"by_seconds,omitempty"`
+	ByMinutes     []int        `json:"by_minutes,omitempty"`
+	ByHours       []int        `json:"by_hours,omitempty"`
+	ByWeekdays    []string     `json:"by_weekdays,omitempty"`
+	ByMonthDays   []int        `json:"by_month_days,omitempty"`
+	ByWeekNumbers []int        `json:"by_week_numbers,omitempty"`
+	ByMonths      []time.Month `json:"by_months,omitempty"`
+	ByLeapMonths  []int        `json:"by_leap_months,omitempty"`
+	ByYearDays    []int        `json:"by_year_days,omitempty"`
+	BySetPos      []int        `json:"by_set_pos,omitempty"`
+
+	InvalidBehavior string `json:"invalid_behavior,omitempty"`
+	Nonexistent     string `json:"nonexistent,omitempty"`
+	Ambiguous       string `json:"ambiguous,omitempty"`
+	Stepping        string `json:"stepping,omitempty"`
+	LeapSecond      string `json:"leap_second,omitempty"`
+	RScale          string `json:"rscale,omitempty"`
+	WeekStart       string `json:"week_start,omitempty"`
+
+	Extensions map[string]string `json:"extensions,omitempty"`
+}
+
+// rruleJSONInput decodes both rruleJSON and the original encoding, in which
+// enumerations and weekdays were numbers.
+type rruleJSONInput struct {
+	Frequency      json.RawMessage `json:"frequency"`
+	Until          time.Time       `json:"until"`
+	UntilFloating  bool            `json:"until_floating"`
+	UntilExclusive bool            `json:"until_exclusive"`
+	AllDay         bool            `json:"all_day"`
+	Count          uint64          `json:"count"`
+	Dtstart        time.Time       `json:"dtstart"`
+	Interval       int             `json:"interval"`
+
+	BySeconds     []int             `json:"by_seconds"`
+	ByMinutes     []int             `json:"by_minutes"`
+	ByHours       []int             `json:"by_hours"`
+	ByWeekdays    []json.RawMessage `json:"by_weekdays"`
+	ByMonthDays   []int             `json:"by_month_days"`
+	ByWeekNumbers []int             `json:"by_week_numbers"`
+	ByMonths      []time.Month      `json:"by_months"`
+	ByLeapMonths  []int             `json:"by_leap_months"`
+	ByYearDays    []int             `json:"by_year_days"`
+	BySetPos      []int             `json:"by_set_pos"`
+
+	InvalidBehavior json.RawMessage `json:"invalid_behavior"`
+	Nonexistent     json.RawMessage `json:"nonexistent"`
+	Ambiguous       json.RawMessage `json:"ambiguous"`
+	Stepping        json.RawMessage `json:"stepping"`
+	LeapSecond      json.RawMessage `json:"leap_second"`
+	RScale          json.RawMessage `json:"rscale"`
+	WeekStart       json.RawMessage `json:"week_start"`
+
+	Extensions map[string]string `json:"extensions"`
+}
+
+// MarshalJSON encodes the pattern as an object with a field for each part
+// that is set. Frequency, SKIP, RSCALE and weekdays are written as in RFC
+// 5545, like "WEEKLY" and "2TU", Nonexistent, Ambiguous, Stepping and
+// LeapSecond by names like "FORWARD", "FIRST", "WALL_CLOCK" and "CLAMP", and
+// times as RFC 3339. Without it, the JSON
+// encoding would use MarshalText.
+func (rrule RRule) MarshalJSON() ([]byte, error) {
+	out := rruleJSON{
+		Frequency:      rrule.Frequency.String(),
+		UntilFloating:  rrule.UntilFloating,
+		UntilExclusive: rrule.UntilExclusive,
+		AllDay:         rrule.AllDay,
+		Count:          rrule.Count,
+		Interval:       rrule.Interval,
+		BySeconds:      rrule.BySeconds,
+		ByMinutes:      rrule.ByMinutes,
+		ByHours:        rrule.ByHours,
+		ByMonthDays:    rrule.ByMonthDays,
+		ByWeekNumbers:  rrule.ByWeekNumbers,
+		ByMonths:       rrule.ByMonths,
+		ByLeapMonths:   rrule.ByLeapMonths,
+		ByYearDays:     rrule.ByYearDays,
+		BySetPos:       rrule.BySetPos,
+		Extensions:     rrule.Extensions,
+	}
+
+	if !rrule.Until.IsZero() {
+		out.Until = &rrule.Until
+	}
+	if !rrule.Dtstart.IsZero() {
+		out.Dtstart = &rrule.Dtstart
+	}
+	for _, wd := range rrule.ByWeekdays {
+		out.ByWeekdays = append(out.ByWeekdays, qualifiedWeekdayString(wd))
+	}
+	if rrule.InvalidBehavior != OmitInvalid {
+		out.InvalidBehavior = skipString(rrule.InvalidBehavior)
+	}
+	if rrule.Nonexistent != DefaultNonexistent {
+		out.Nonexistent = nonexistentString(rrule.Nonexistent)
+	}
+	if rrule.Ambiguous != DefaultAmbiguous {
+		out.Ambiguous = ambiguousString(rrule.Ambiguous)
+	}
+	if rrule.Stepping != ElapsedStepping {
+		out.Stepping = steppingString(rrule.Stepping)
+	}
+	if rrule.LeapSecond != SkipLeapSecond {
+		out.LeapSecond = leapSecondString(rrule.LeapSecond)
+	}
+	if rrule.RScale != Gregorian {
+		out.RScale = rrule.RScale.String()
+	}
+	out.WeekStart = rrule.WeekStart.String()
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes the object produced by MarshalJSON. It also accepts
+// the original encoding, in which frequency, invalid_behavior, rscale and
+// week_start were numbers and weekdays were objects like {"n":2,"wd":2}, and
+// a string holding the RFC 5545 representation, like "FREQ=WEEKLY;BYDAY=MO",
+// which is parsed as UnmarshalText does.
+func (rrule *RRule) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err == nil {
+		return rrule.UnmarshalText([]byte(text))
+	}
+
+	var in rruleJSONInput
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+
+	decoded := RRule{
+		Until:          in.Until,
+		UntilFloating:  in.UntilFloating,
+		UntilExclusive: in.UntilExclusive,
+		AllDay:         in.AllDay,
+		Count:          in.Count,
+		Dtstart:        in.Dtstart,
+		Interval:       in.Interval,
+		BySeconds:      in.BySeconds,
+		ByMinutes:      in.ByMinutes,
+		ByHours:        in.ByHours,
+		ByMonthDays:    in.ByMonthDays,
+		ByWeekNumbers:  in.ByWeekNumbers,
+		ByMonths:       in.ByMonths,
+		ByLeapMonths:   in.ByLeapMonths,
+		ByYearDays:     in.ByYearDays,
+		BySetPos:       in.BySetPos,
+		Extensions:     in.Extensions,
+	}
+
+	freq, err := jsonEnum(in.Frequency, func(s string) (int, error) {
+		f, err := strToFreq(s)
+		return int(f), err
+	})
+	if err != nil {
+		return err
+	}
+	decoded.Frequency = Frequency(freq)
+
+	skip, err := jsonEnum(in.InvalidBehavior, func(s string) (int, error) {
+		ib, err := parseSkip(s)
+		return int(ib), err
+	})
+	if err != nil {
+		return err
+	}
+	decoded.InvalidBehavior = InvalidBehavior(skip)
+
+	nonexistent, err := jsonEnum(in.Nonexistent, func(s string) (int, error) {
+		nb, err := parseNonexistent(s)
+		return int(nb), err
+	})
+	if err != nil {
+		return err
+	}
+	decoded.Nonexistent = NonexistentBehavior(nonexistent)
+
+	ambiguous, err := jsonEnum(in.Ambiguous, func(s string) (int, error) {
+		ab, err := parseAmbiguous(s)
+		return int(ab), err
+	})
+	if err != nil {
+		return err
+	}
+	decoded.Ambiguous = AmbiguousBehavior(ambiguous)
+
+	stepping, err := jsonEnum(in.Stepping, func(s string) (int, error) {
+		st, err := parseStepping(s)
+		return int(st), err
+	})
+	if err != nil {
+		return err
+	}
+	decoded.Stepping = Stepping(stepping)
+
+	leapSecond, err := jsonEnum(in.LeapSecond, func(s string) (int, error) {
+		ls, err := parseLeapSecond(s)
+		return int(ls), err
+	})
+	if err != nil {
+		return err
+	}
+	decoded.LeapSecond = LeapSecondBehavior(leapSecond)
+
+	rscale, err := jsonEnum(in.RScale, func(s string) (int, error) {
+		rs, err := parseRScale(s)
+		return int(rs), err
+	})
+	if err != nil {
+		return err
+	}
+	decoded.RScale = RScale(rscale)
+
+	if len(in.WeekStart) > 0 && string(in.WeekStart) != "null" {
+		wkst, err := jsonEnum(in.WeekStart, func(s string) (int, error) {
+			wd, err := parseWeekday(s)
+			return int(wd), err
+		})
+		if err != nil {
+			return err
+		}
+		decoded.WeekStart = WeekStartOn(time.Weekday(wkst))
+	}
+
+	for _, raw := range in.ByWeekdays {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			var wd QualifiedWeekday
+			if err := json.Unmarshal(raw, &wd); err != nil {
+				return err
+			}
+			decoded.ByWeekdays = append(decoded.ByWeekdays, wd)
+			continue
+		}
+
+		wds, err := parseQualifiedWeekdays(s)
+		if err != nil {
+			return err
+		}
+		decoded.ByWeekdays = append(decoded.ByWeekdays, wds...)
+	}
+
+	*rrule = decoded
+	return nil
+}
+
+// jsonEnum decodes an enumeration from either its number or its name, which
+// parse converts. Numbers in strings are accepted too. Missing values are
+// zero.
+func jsonEnum(raw json.RawMessage, parse func(string) (int, error)) (int, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+
+	var n int
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return 0, err
+	}
+
+	v, err := parse(s)
+	if err != nil {
+		if n, nerr := strconv.Atoi(s); nerr == nil {
+			return n, nil
+		}
+		return 0, err
+	}
+	return v, nil
+}
+
+// binaryVersion is the first byte of the MarshalBinary encoding, so that the
+// format can change without misreading older data.
+const binaryVersion = 1
+
+// MarshalBinary encodes the pattern, including Dtstart, compactly: a version
+// byte followed by the RRule message of rrule.proto in the protocol buffer wire
+// format. The pattern is validated first.
+func (rrule RRule) MarshalBinary() ([]byte, error) {
+	if err := rrule.Validate(); err != nil {
+		return nil, err
+	}
+	return rrule.appendProto([]byte{binaryVersion}), nil
+}
+
+// UnmarshalBinary decodes and validates the encoding produced by
+// MarshalBinary.
+func (rrule *RRule) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty binary RRule")
+	}
+	if data[0] != binaryVersion {
+		return fmt.Errorf("unsupported binary RRule version %d", data[0])
+	}
+
+	decoded, err := FromProto(data[1:])
+	if err != nil {
+		return err
+	}
+	*rrule = decoded
+	return nil
+}
@@ -0,0 +1,286 @@
+package rrule
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRRuleText(t *testing.T) {
+	rrule := RRule{Frequency: Weekly, Count: 3, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}}
+
+	text, err := rrule.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=3;BYDAY=MO", string(text))
+
+	var parsed RRule
+	require.NoError(t, parsed.UnmarshalText(text))
+	assert.Equal(t, rrule, parsed)
+
+	assert.Error(t, parsed.UnmarshalText([]byte("FREQ=FORTNIGHTLY")))
+
+	appended, err := rrule.AppendText([]byte("key:"))
+	require.NoError(t, err)
+	assert.Equal(t, "key:FREQ=WEEKLY;COUNT=3;BYDAY=MO", string(appended))
+
+	var _ encoding.TextMarshaler = rrule
+	var _ encoding.TextUnmarshaler = &parsed
+}
+
+func TestRRuleJSONObject(t *testing.T) {
+	rrule := RRule{Frequency: Weekly, Count: 3}
+
+	b, err := json.Marshal(rrule)
+	require.NoError(t, err)
+	assert.Equal(t, `{"frequency":"WEEKLY","count":3}`, string(b))
+
+	var decoded RRule
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, rrule, decoded)
+
+	rrule = RRule{
+		Frequency:       Monthly,
+		Dtstart:         time.Date(2018, time.January, 1, 9, 0, 0, 0, time.UTC),
+		Until:           time.Date(2019, time.March, 1, 9, 0, 0, 0, time.UTC),
+		ByWeekdays:      []QualifiedWeekday{{WD: time.Monday}, {N: 2, WD: time.Tuesday}},
+		ByLeapMonths:    []int{5},
+		InvalidBehavior: NextInvalid,
+		RScale:          Chinese,
+		WeekStart:       WeekStartOn(time.Sunday),
+	}
+
+	b, err = json.Marshal(rrule)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"frequency": "MONTHLY",
+		"until": "2019-03-01T09:00:00Z",
+		"dtstart": "2018-01-01T09:00:00Z",
+		"by_weekdays": ["MO", "2TU"],
+		"by_leap_months": [5],
+		"invalid_behavior": "FORWARD",
+		"rscale": "CHINESE",
+		"week_start": "SU"
+	}`, string(b))
+
+	decoded = RRule{}
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, rrule, decoded)
+}
+
+func TestRRuleJSONNumeric(t *testing.T) {
+	var decoded RRule
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"frequency": 5,
+		"until": "0001-01-01T00:00:00Z",
+		"until_floating": false,
+		"count": 3,
+		"dtstart": "0001-01-01T00:00:00Z",
+		"interval": 0,
+		"by_weekdays": [{"n": 0, "wd": 1}, {"n": 2, "wd": 2}],
+		"invalid_behavior": 2,
+		"rscale": 2,
+		"week_start": 0
+	}`), &decoded))
+
+	assert.Equal(t, RRule{
+		Frequency:       Monthly,
+		Count:           3,
+		ByWeekdays:      []QualifiedWeekday{{WD: time.Monday}, {N: 2, WD: time.Tuesday}},
+		InvalidBehavior: PrevInvalid,
+		RScale:          Chinese,
+		WeekStart:       WeekStartOn(time.Sunday),
+	}, decoded)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"frequency": "4", "week_start": null}`), &decoded))
+	assert.Equal(t, RRule{Frequency: Weekly}, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"frequency": "FORTNIGHTLY"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"frequency": "DAILY", "by_weekdays": ["XX"]}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"frequency": "DAILY", "rscale": "MARTIAN"}`), &decoded))
+}
+
+func TestRRuleJSONString(t *testing.T) {
+	var payload struct {
+		RRule RRule `json:"rrule"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"rrule": "FREQ=WEEKLY;BYDAY=MO"}`), &payload))
+	assert.Equal(t, RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}}, payload.RRule)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"rrule": "FREQ=FORTNIGHTLY"}`), &payload))
+	assert.Error(t, json.Unmarshal([]byte(`{"rrule": 4}`), &payload))
+}
+
+func TestRRuleBinary(t *testing.T) {
+	rrule := RRule{
+		Frequency:  Weekly,
+		Count:      3,
+		Dtstart:    time.Date(2018, time.January, 1, 9, 0, 0, 0, NewYork()),
+		ByWeekdays: []QualifiedWeekday{{WD: time.Monday}},
+	}
+
+	b, err := rrule.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, byte(1), b[0])
+
+	var decoded RRule
+	require.NoError(t, decoded.UnmarshalBinary(b))
+	assert.Equal(t, rrule.String(), decoded.String())
+	assert.True(t, rrule.Dtstart.Equal(decoded.Dtstart))
+	assert.Equal(t, "America/New_York", decoded.Dtstart.Location().String())
+
+	assert.Error(t, decoded.UnmarshalBinary(nil))
+	assert.Error(t, decoded.UnmarshalBinary([]byte{2, 0x08, 0x04}))
+	assert.Error(t, decoded.UnmarshalBinary([]byte{1, 0x08}))
+
+	_, err = RRule{Frequency: Daily, BySetPos: []int{1}}.MarshalBinary()
+	assert.Error(t, err)
+
+	var _ encoding.BinaryMarshaler = rrule
+	var _ encoding.BinaryUnmarshaler = &decoded
+}
+
+func TestQualifiedWeekdayText(t *testing.T) {
+	for str, wd := range map[string]QualifiedWeekday{
+		"MO":   {WD: time.Monday},
+		"2TU":  {N: 2, WD: time.Tuesday},
+		"-1FR": {N: -1, WD: time.Friday},
+		"53SU": {N: 53, WD: time.Sunday},
+	} {
+		parsed, err := ParseQualifiedWeekday(str)
+		require.NoError(t, err, str)
+		assert.Equal(t, wd, parsed)
+		assert.Equal(t, str, wd.String())
+
+		text, err := wd.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, str, string(text))
+	}
+
+	parsed, err := ParseQualifiedWeekday("+3we")
+	require.NoError(t, err)
+	assert.Equal(t, WE.Nth(3), parsed)
+
+	for _, str := range []string{"", "XX", "2", "MO,TU", "1.5MO"} {
+		_, err := ParseQualifiedWeekday(str)
+		assert.Error(t, err, str)
+	}
+	_, err = QualifiedWeekday{WD: 7}.MarshalText()
+	assert.Error(t, err)
+}
+
+func TestQualifiedWeekdayJSON(t *testing.T) {
+	b, err := json.Marshal(map[string][]QualifiedWeekday{"days": {MO, FR.Nth(-1)}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"days": ["MO", "-1FR"]}`, string(b))
+
+	var wds []QualifiedWeekday
+	require.NoError(t, json.Unmarshal([]byte(`["MO", "-1FR", {"n": 2, "wd": 2}]`), &wds))
+	assert.Equal(t, []QualifiedWeekday{MO, FR.Nth(-1), TU.Nth(2)}, wds)
+
+	assert.Error(t, json.Unmarshal([]byte(`["XX"]`), &wds))
+	assert.Error(t, json.Unmarshal([]byte(`[4]`), &wds))
+}
+
+func TestInvalidBehaviorText(t *testing.T) {
+	for str, ib := range map[string]InvalidBehavior{"OMIT": OmitInvalid, "FORWARD": NextInvalid, "BACKWARD": PrevInvalid} {
+		assert.Equal(t, str, ib.String())
+		parsed, err := ParseInvalidBehavior(str)
+		require.NoError(t, err)
+		assert.Equal(t, ib, parsed)
+	}
+
+	parsed, err := ParseInvalidBehavior("backward")
+	require.NoError(t, err)
+	assert.Equal(t, PrevInvalid, parsed)
+	_, err = ParseInvalidBehavior("SIDEWAYS")
+	assert.Error(t, err)
+	_, err = InvalidBehavior(3).MarshalText()
+	assert.Error(t, err)
+	assert.Equal(t, "InvalidBehavior(7)", InvalidBehavior(7).String())
+
+	var config struct {
+		Skip InvalidBehavior `json:"skip"`
+	}
+	// JSON keeps the numbers, but accepts names too.
+	config.Skip = NextInvalid
+	b, err := json.Marshal(config)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"skip": 1}`, string(b))
+	config.Skip = InvalidBehavior(7)
+	b, err = json.Marshal(config)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"skip": 7}`, string(b))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"skip": "BACKWARD"}`), &config))
+	assert.Equal(t, PrevInvalid, config.Skip)
+	require.NoError(t, json.Unmarshal([]byte(`{"skip": 1}`), &config))
+	assert.Equal(t, NextInvalid, config.Skip)
+	assert.Error(t, json.Unmarshal([]byte(`{"skip": "SIDEWAYS"}`), &config))
+}
+
+func TestFrequencyText(t *testing.T) {
+	for freq := Secondly; freq <= Yearly; freq++ {
+		text, err := freq.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, freq.String(), string(text))
+
+		var parsed Frequency
+		require.NoError(t, parsed.UnmarshalText(text))
+		assert.Equal(t, freq, parsed)
+	}
+
+	parsed, err := ParseFrequency("weekly")
+	require.NoError(t, err)
+	assert.Equal(t, Weekly, parsed)
+	_, err = ParseFrequency("FORTNIGHTLY")
+	assert.True(t, errors.Is(err, ErrUnsupportedFrequency))
+
+	assert.Equal(t, "UNKNOWN(42)", Frequency(42).String())
+	_, err = Frequency(42).MarshalText()
+	assert.True(t, errors.Is(err, ErrUnsupportedFrequency))
+
+	// JSON keeps the numbers, but accepts names too.
+	b, err := json.Marshal(Weekly)
+	require.NoError(t, err)
+	assert.Equal(t, "4", string(b))
+	for _, in := range []string{`4`, `"4"`, `"WEEKLY"`, `"weekly"`} {
+		var freq Frequency
+		require.NoError(t, json.Unmarshal([]byte(in), &freq), in)
+		assert.Equal(t, Weekly, freq, in)
+	}
+	assert.Error(t, json.Unmarshal([]byte(`"FORTNIGHTLY"`), &parsed))
+}
+
+func TestWeekStartText(t *testing.T) {
+	var config struct {
+		WeekStart WeekStart `json:"week_start"`
+	}
+	b, err := json.Marshal(config)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"week_start": ""}`, string(b))
+
+	config.WeekStart = WeekStartOn(time.Sunday)
+	b, err = json.Marshal(config)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"week_start": "SU"}`, string(b))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"week_start": "mo"}`), &config))
+	wd, ok := config.WeekStart.Weekday()
+	assert.True(t, ok)
+	assert.Equal(t, time.Monday, wd)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"week_start": ""}`), &config))
+	assert.False(t, config.WeekStart.IsSet())
+	wd, ok = config.WeekStart.Weekday()
+	assert.False(t, ok)
+	assert.Equal(t, DefaultWeekStart, wd)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"week_start": "XX"}`), &config))
+	_, err = WeekStartOn(7).MarshalText()
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of errors returned when validating, parsing or iterating patterns.
+// Most errors are of one of these kinds but carry a more specific message, so
+// test for them with errors.Is rather than comparing them directly.
+var (
+	// ErrCountAndUntil is returned for patterns with both COUNT and UNTIL.
+	ErrCountAndUntil = errors.New("COUNT and UNTIL must not appear in the same RRULE")
+
+	// ErrInvalidBySetPos is returned when BYSETPOS is out of range or has no
+	// other BYXXX rule part to select from.
+	ErrInvalidBySetPos = errors.New("invalid BYSETPOS")
+
+	// ErrInvalidByDay is returned when BYDAY has an unknown weekday, or an
+	// ordinal that is out of range or not allowed by the frequency.
+	ErrInvalidByDay = errors.New("invalid BYDAY")
+
+	// ErrWeeklyByMonthDay is returned for WEEKLY patterns with BYMONTHDAY.
+	ErrWeeklyByMonthDay = errors.New("WEEKLY recurrences must not include BYMONTHDAY")
+
+	// ErrOutOfRange is returned when a BYXXX rule part has a value outside
+	// its range.
+	ErrOutOfRange = errors.New("rule part value out of range")
+
+	// ErrUnsupportedFrequency is returned for unknown frequencies.
+	ErrUnsupportedFrequency = errors.New("unsupported frequency")
+
+	// ErrUnsupportedRScale is returned for unknown calendar scales, and for
+	// rule parts that aren't supported in a pattern's calendar scale.
+	ErrUnsupportedRScale = errors.New("unsupported RSCALE")
+
+	// ErrNoOccurrences is returned for patterns whose parts exclude each
+	// other, like BYMONTH=2;BYMONTHDAY=30, so that they can never have an
+	// occurrence.
+	ErrNoOccurrences = errors.New("pattern has no possible occurrences")
+
+	// ErrAllDay is returned for all-day patterns that are finer than DAILY
+	// or have BYHOUR, BYMINUTE or BYSECOND, which would need times of day.
+	ErrAllDay = errors.New("all-day patterns must not include times of day")
+
+	// ErrUnsupportedPart is returned when parsing a rule part that isn't
+	// supported and isn't an X- extension.
+	ErrUnsupportedPart = errors.New("not a supported RRULE part")
+
+	// ErrIterationBudgetExceeded is reported by IteratorErr for an iterator
+	// that ended because it exceeded its Budget looking for a time.
+	ErrIterationBudgetExceeded = errors.New("iteration budget exceeded")
+)
+
+// kindError is an error of one or more of the kinds above, with its own
+// message.
+type kindError struct {
+	msg   string
+	kinds []error
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+// Is reports whether the error is of kind target, for errors.Is.
+func (e *kindError) Is(target error) bool {
+	for _, kind := range e.kinds {
+		if kind == target {
+			return true
+		}
+	}
+	return false
+}
+
+// errorKind returns the kind of err, if it is one of the kinds above, or nil.
+func errorKind(err error) error {
+	if err, ok := err.(*kindError); ok {
+		return err.kinds[0]
+	}
+	for _, kind := range []error{ErrCountAndUntil, ErrInvalidBySetPos, ErrInvalidByDay, ErrWeeklyByMonthDay, ErrOutOfRange, ErrUnsupportedFrequency, ErrUnsupportedRScale, ErrNoOccurrences, ErrAllDay, ErrUnsupportedPart, ErrIterationBudgetExceeded} {
+		if err == kind {
+			return kind
+		}
+	}
+	return nil
+}
+
+// kindErrorf returns an error of kind, formatted as by fmt.Sprintf.
+func kindErrorf(kind error, format string, args ...interface{}) error {
+	return &kindError{msg: fmt.Sprintf(format, args...), kinds: []error{kind}}
+}
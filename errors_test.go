@@ -0,0 +1,55 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorKinds(t *testing.T) {
+	for _, tc := range []struct {
+		RRule RRule
+		Kinds []error
+	}{
+		{RRule{Frequency: Daily, Count: 1, Until: now}, []error{ErrCountAndUntil}},
+		{RRule{Frequency: Monthly, BySetPos: []int{1}}, []error{ErrInvalidBySetPos}},
+		{RRule{Frequency: Monthly, ByMonthDays: []int{1}, BySetPos: []int{400}}, []error{ErrInvalidBySetPos, ErrOutOfRange}},
+		{RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{N: 1, WD: time.Monday}}}, []error{ErrInvalidByDay}},
+		{RRule{Frequency: Yearly, ByWeekdays: []QualifiedWeekday{{N: 60, WD: time.Monday}}}, []error{ErrInvalidByDay, ErrOutOfRange}},
+		{RRule{Frequency: Weekly, ByMonthDays: []int{1}}, []error{ErrWeeklyByMonthDay}},
+		{RRule{Frequency: Daily, ByHours: []int{24}}, []error{ErrOutOfRange}},
+		{RRule{Frequency: Daily, ByMonths: []time.Month{1}, RScale: Chinese}, []error{ErrUnsupportedRScale}},
+	} {
+		err := tc.RRule.Validate()
+		require.Error(t, err)
+		for _, kind := range tc.Kinds {
+			assert.True(t, errors.Is(err, kind), "%s is %s", err, kind)
+			assert.True(t, errors.Is(tc.RRule.ValidateAll(), kind), "%s is %s", err, kind)
+		}
+		assert.False(t, errors.Is(err, ErrUnsupportedFrequency), err.Error())
+	}
+
+	_, err := RRule{Frequency: Frequency(42)}.IteratorE()
+	assert.True(t, errors.Is(err, ErrUnsupportedFrequency))
+}
+
+func TestParseErrorKinds(t *testing.T) {
+	for str, kind := range map[string]error{
+		"FREQ=FORTNIGHTLY":                          ErrUnsupportedFrequency,
+		"FREQ=DAILY;FOO=bar":                        ErrUnsupportedPart,
+		"FREQ=DAILY;RSCALE=MARTIAN":                 ErrUnsupportedRScale,
+		"FREQ=DAILY;COUNT=3;UNTIL=20181027T183615Z": ErrCountAndUntil,
+		"FREQ=DAILY;BYSETPOS=1":                     ErrInvalidBySetPos,
+	} {
+		_, err := ParseRRule(str)
+		assert.True(t, errors.Is(err, kind), "%s: %v", str, err)
+	}
+
+	_, err := ParseRRule("FREQ=FORTNIGHTLY")
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, "FREQ", parseErr.Part)
+}
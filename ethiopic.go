@@ -0,0 +1,46 @@
+package rrule
+
+// ethiopic is the Ethiopian calendar, with years counted in the Amete Mihret
+// era. It has twelve months of 30 days followed by Pagume, which has 5 days,
+// or 6 in the year before a Julian leap year.
+type ethiopic struct{}
+
+// ethiopicEpoch is the Julian day number of Meskerem 1, 1 EE, August 29th, 8
+// in the Julian calendar.
+const ethiopicEpoch = 1724221
+
+var ethiopicMonths = []calendarMonth{
+	{number: 1}, {number: 2}, {number: 3}, {number: 4}, {number: 5}, {number: 6}, {number: 7},
+	{number: 8}, {number: 9}, {number: 10}, {number: 11}, {number: 12}, {number: 13},
+}
+
+func (ethiopic) months(year int) []calendarMonth {
+	return ethiopicMonths
+}
+
+func (ethiopic) monthStart(year int, month calendarMonth) int {
+	return ethiopicEpoch + 365*(year-1) + floorDiv(year, 4) + 30*(month.number-1)
+}
+
+func (ethiopic) monthLength(year int, month calendarMonth) int {
+	switch {
+	case month.number < 13:
+		return 30
+	case year-4*floorDiv(year, 4) == 3:
+		return 6
+	default:
+		return 5
+	}
+}
+
+func (c ethiopic) date(jdn int) calendarDate {
+	year := floorDiv(4*(jdn-ethiopicEpoch)+1463, 1461)
+	start := c.monthStart(year, ethiopicMonths[0])
+
+	month := (jdn-start)/30 + 1
+	return calendarDate{
+		year:  year,
+		month: calendarMonth{number: month},
+		day:   jdn - start - 30*(month-1) + 1,
+	}
+}
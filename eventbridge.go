@@ -0,0 +1,103 @@
+package rrule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FromEventBridge converts an AWS EventBridge schedule expression, either
+// cron(...) or rate(...), to the pattern of its schedule.
+//
+// The cron form has the fields of FromQuartz without seconds, and its year
+// must be *. A rate like rate(5 minutes) becomes a MINUTELY, HOURLY or DAILY
+// pattern with that interval; as in EventBridge, it is counted from Dtstart,
+// which isn't set.
+func FromEventBridge(expr string) (RRule, error) {
+	expr = strings.TrimSpace(expr)
+
+	switch {
+	case strings.HasPrefix(expr, "cron(") && strings.HasSuffix(expr, ")"):
+		fields := strings.Fields(expr[len("cron(") : len(expr)-1])
+		if len(fields) != 6 {
+			return RRule{}, fmt.Errorf("EventBridge cron expression %q must have 6 fields, not %d", expr, len(fields))
+		}
+		return FromQuartz("0 " + strings.Join(fields, " "))
+
+	case strings.HasPrefix(expr, "rate(") && strings.HasSuffix(expr, ")"):
+		fields := strings.Fields(expr[len("rate(") : len(expr)-1])
+		if len(fields) != 2 {
+			return RRule{}, fmt.Errorf("EventBridge rate expression %q must have a value and a unit", expr)
+		}
+
+		n, err := strconv.Atoi(fields[0])
+		if err != nil || n < 1 {
+			return RRule{}, fmt.Errorf("EventBridge rate %q is not a positive number", fields[0])
+		}
+
+		rrule := RRule{Interval: n}
+		switch strings.TrimSuffix(fields[1], "s") {
+		case "minute":
+			rrule.Frequency = Minutely
+		case "hour":
+			rrule.Frequency = Hourly
+		case "day":
+			rrule.Frequency = Daily
+		default:
+			return RRule{}, fmt.Errorf("EventBridge rate unit %q is not minutes, hours or days", fields[1])
+		}
+		if n == 1 {
+			rrule.Interval = 0
+		}
+		return rrule, nil
+	}
+
+	return RRule{}, fmt.Errorf("EventBridge schedule expression %q is not cron(...) or rate(...)", expr)
+}
+
+// ToEventBridge returns an AWS EventBridge schedule expression for the
+// pattern's schedule, or a *CronError naming the rule part that prevents it.
+// Patterns that ToQuartz converts are written as cron(...), if they fire at
+// second 0. Otherwise, MINUTELY, HOURLY and DAILY patterns without other
+// parts are written as rate(...), which counts from when the schedule is
+// created rather than from Dtstart.
+func (rrule RRule) ToEventBridge() (string, error) {
+	spec, err := rrule.ToQuartz()
+	if err == nil {
+		fields := strings.Fields(spec)
+		if fields[0] != "0" {
+			return "", &CronError{Part: "BYSECOND", Reason: "EventBridge schedules fire at the start of a minute"}
+		}
+		return "cron(" + strings.Join(fields[1:], " ") + " *)", nil
+	}
+
+	units := map[Frequency]string{Minutely: "minute", Hourly: "hour", Daily: "day"}
+	unit, ok := units[rrule.Frequency]
+	if !ok || !rrule.onlyFrequency() {
+		return "", err
+	}
+
+	n := 1
+	if rrule.Interval > 1 {
+		n = rrule.Interval
+		unit += "s"
+	}
+	return fmt.Sprintf("rate(%d %s)", n, unit), nil
+}
+
+// onlyFrequency reports whether the pattern has no parts but its frequency
+// and interval.
+func (rrule RRule) onlyFrequency() bool {
+	return rrule.Count == 0 && rrule.Until.IsZero() &&
+		len(rrule.BySeconds) == 0 &&
+		len(rrule.ByMinutes) == 0 &&
+		len(rrule.ByHours) == 0 &&
+		len(rrule.ByWeekdays) == 0 &&
+		len(rrule.ByMonthDays) == 0 &&
+		len(rrule.ByWeekNumbers) == 0 &&
+		len(rrule.ByMonths) == 0 &&
+		len(rrule.ByLeapMonths) == 0 &&
+		len(rrule.ByYearDays) == 0 &&
+		len(rrule.BySetPos) == 0 &&
+		rrule.RScale == Gregorian
+}
@@ -0,0 +1,75 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromEventBridge(t *testing.T) {
+	for expr, want := range map[string]string{
+		"cron(0 12 * * ? *)":         "FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=12",
+		"cron(15 10 ? * 6L *)":       "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=15;BYHOUR=10;BYDAY=-1FR",
+		"cron(0/15 * ? * MON-FRI *)": "FREQ=HOURLY;BYSECOND=0;BYMINUTE=0,15,30,45;BYDAY=MO,TU,WE,TH,FR",
+		"rate(1 minute)":             "FREQ=MINUTELY",
+		"rate(5 minutes)":            "FREQ=MINUTELY;INTERVAL=5",
+		"rate(12 hours)":             "FREQ=HOURLY;INTERVAL=12",
+		"rate(7 days)":               "FREQ=DAILY;INTERVAL=7",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			rrule, err := FromEventBridge(expr)
+			require.NoError(t, err)
+			assert.Equal(t, want, rrule.String())
+		})
+	}
+
+	for _, expr := range []string{
+		"cron(0 12 * * ?)",
+		"cron(0 12 * * ? 2030)",
+		"cron(0 12 * * MON *)",
+		"rate(0 minutes)",
+		"rate(5 weeks)",
+		"rate(minutes)",
+		"at(2022-11-20T13:00:00)",
+	} {
+		_, err := FromEventBridge(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestToEventBridge(t *testing.T) {
+	dtstart := time.Date(2018, time.August, 25, 9, 30, 0, 0, time.UTC)
+
+	for want, rrule := range map[string]RRule{
+		"cron(30 9 * * ? *)":    {Frequency: Daily, Dtstart: dtstart},
+		"cron(30 9 ? * 2#2 *)":  {Frequency: Monthly, Dtstart: dtstart, ByWeekdays: []QualifiedWeekday{{N: 2, WD: time.Monday}}},
+		"cron(0,30 * * * ? *)":  {Frequency: Hourly, BySeconds: []int{0}, ByMinutes: []int{0, 30}},
+		"rate(1 minute)":        {Frequency: Minutely},
+		"rate(15 minutes)":      {Frequency: Minutely, Interval: 15, Dtstart: dtstart},
+		"rate(2 hours)":         {Frequency: Hourly, Interval: 2},
+		"rate(3 days)":          {Frequency: Daily, Interval: 3},
+		"cron(30 9 L 1,7 ? *)":  {Frequency: Monthly, Dtstart: dtstart, ByMonthDays: []int{-1}, ByMonths: []time.Month{time.January, time.July}},
+		"cron(30 9 25 8 ? *)":   {Frequency: Yearly, Dtstart: dtstart},
+		"cron(30 9 ? * 2-6 *)":  {Frequency: Weekly, Dtstart: dtstart, ByWeekdays: workdays},
+		"cron(30 9 1W * ? *)":   {Frequency: Monthly, Dtstart: dtstart, ByWeekdays: workdays, BySetPos: []int{1}},
+		"cron(30 9 * 6-8 ? *)":  {Frequency: Daily, Dtstart: dtstart, ByMonths: []time.Month{time.June, time.July, time.August}},
+		"cron(30 9 1,15 * ? *)": {Frequency: Monthly, Dtstart: dtstart, ByMonthDays: []int{1, 15}},
+	} {
+		expr, err := rrule.ToEventBridge()
+		require.NoError(t, err, want)
+		assert.Equal(t, want, expr)
+	}
+
+	for part, rrule := range map[string]RRule{
+		"BYSECOND": {Frequency: Minutely, Dtstart: dtstart.Add(time.Second)},
+		"COUNT":    {Frequency: Minutely, Count: 3},
+		"INTERVAL": {Frequency: Weekly, Interval: 2},
+	} {
+		_, err := rrule.ToEventBridge()
+		require.Error(t, err, part)
+		require.IsType(t, &CronError{}, err, part)
+		assert.Equal(t, part, err.(*CronError).Part)
+	}
+}
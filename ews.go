@@ -0,0 +1,285 @@
+package rrule
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EWSRecurrence is an Exchange Web Services Recurrence element, which holds
+// one pattern element and one range element. Like a Microsoft Graph
+// recurrence, which it mirrors, it has no time of day. The element is in the
+// EWS types namespace.
+type EWSRecurrence struct {
+	XMLName xml.Name `xml:"http://schemas.microsoft.com/exchange/services/2006/types Recurrence"`
+
+	RelativeYearly  *EWSPattern `xml:"RelativeYearlyRecurrence"`
+	AbsoluteYearly  *EWSPattern `xml:"AbsoluteYearlyRecurrence"`
+	RelativeMonthly *EWSPattern `xml:"RelativeMonthlyRecurrence"`
+	AbsoluteMonthly *EWSPattern `xml:"AbsoluteMonthlyRecurrence"`
+	Weekly          *EWSPattern `xml:"WeeklyRecurrence"`
+	Daily           *EWSPattern `xml:"DailyRecurrence"`
+
+	NoEnd    *EWSRange `xml:"NoEndRecurrence"`
+	EndDate  *EWSRange `xml:"EndDateRecurrence"`
+	Numbered *EWSRange `xml:"NumberedRecurrence"`
+}
+
+// EWSPattern is the content of an EWS recurrence pattern element. Each kind of
+// pattern uses some of its fields, in this order.
+type EWSPattern struct {
+	Interval int `xml:"Interval,omitempty"`
+
+	// DaysOfWeek is a space separated list of English weekday names, like
+	// Monday. Relative patterns have a single day, or one of Day, Weekday or
+	// WeekendDay.
+	DaysOfWeek string `xml:"DaysOfWeek,omitempty"`
+
+	// DayOfWeekIndex is First, Second, Third, Fourth or Last.
+	DayOfWeekIndex string `xml:"DayOfWeekIndex,omitempty"`
+
+	DayOfMonth int `xml:"DayOfMonth,omitempty"`
+
+	// Month is an English month name, like January.
+	Month string `xml:"Month,omitempty"`
+
+	FirstDayOfWeek string `xml:"FirstDayOfWeek,omitempty"`
+}
+
+// EWSRange is the content of an EWS recurrence range element. Dates may have
+// a time zone offset, which is ignored.
+type EWSRange struct {
+	StartDate           string `xml:"StartDate"`
+	EndDate             string `xml:"EndDate,omitempty"`
+	NumberOfOccurrences int    `xml:"NumberOfOccurrences,omitempty"`
+}
+
+// ewsDays are the EWS names for sets of weekdays that relative patterns
+// accept in place of a single day.
+var ewsDays = map[string][]time.Weekday{
+	"Day":        {time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	"Weekday":    {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"WeekendDay": {time.Saturday, time.Sunday},
+}
+
+// ewsIndexes are the values of EWSPattern.DayOfWeekIndex, by Pattern.Index.
+var ewsIndexes = map[int]string{1: "First", 2: "Second", 3: "Third", 4: "Fourth", -1: "Last"}
+
+// ToEWS returns the Exchange Web Services recurrence of the pattern, or a
+// *PatternError if it can't be expressed as a Pattern or in EWS, whose yearly
+// patterns have no interval and whose relative patterns pick from a single
+// day or one of the sets of days named in ewsDays.
+func (rrule RRule) ToEWS() (EWSRecurrence, error) {
+	p, err := rrule.Pattern()
+	if err != nil {
+		return EWSRecurrence{}, err
+	}
+
+	pattern := &EWSPattern{Interval: p.Interval, DayOfMonth: p.DayOfMonth, DayOfWeekIndex: ewsIndexes[p.Index]}
+
+	var days []string
+	for _, wd := range p.Weekdays {
+		days = append(days, wd.String())
+	}
+	pattern.DaysOfWeek = strings.Join(days, " ")
+
+	if p.Index != 0 && len(p.Weekdays) > 1 {
+		pattern.DaysOfWeek = ""
+		for name, set := range ewsDays {
+			if sameWeekdaySet(set, p.Weekdays) {
+				pattern.DaysOfWeek = name
+			}
+		}
+		if pattern.DaysOfWeek == "" {
+			return EWSRecurrence{}, &PatternError{Part: "BYDAY", Reason: "EWS picks from a single day, or every day, weekday or weekend day"}
+		}
+	}
+
+	if p.Month != 0 {
+		if p.Interval != 1 {
+			return EWSRecurrence{}, &PatternError{Part: "INTERVAL", Reason: "EWS yearly patterns repeat every year"}
+		}
+		pattern.Interval = 0
+		pattern.Month = p.Month.String()
+	}
+
+	var rec EWSRecurrence
+	switch p.Kind {
+	case DailyPattern:
+		rec.Daily = pattern
+	case WeeklyPattern:
+		pattern.FirstDayOfWeek = p.FirstDayOfWeek.String()
+		rec.Weekly = pattern
+	case AbsoluteMonthlyPattern:
+		rec.AbsoluteMonthly = pattern
+	case RelativeMonthlyPattern:
+		rec.RelativeMonthly = pattern
+	case AbsoluteYearlyPattern:
+		rec.AbsoluteYearly = pattern
+	case RelativeYearlyPattern:
+		rec.RelativeYearly = pattern
+	}
+
+	rng := &EWSRange{StartDate: p.Start.Format(recurDate), NumberOfOccurrences: p.Occurrences}
+	switch p.End {
+	case NoEnd:
+		rec.NoEnd = rng
+	case EndByDate:
+		rng.EndDate = p.EndDate.Format(recurDate)
+		rec.EndDate = rng
+	case EndAfterOccurrences:
+		rec.Numbered = rng
+	}
+
+	return rec, nil
+}
+
+// FromEWS returns the pattern of an Exchange Web Services recurrence of an
+// item that starts at start, as FromGraph does.
+func FromEWS(rec EWSRecurrence, start time.Time) (RRule, error) {
+	p, err := rec.pattern(start)
+	if err != nil {
+		return RRule{}, err
+	}
+	return p.RRule()
+}
+
+// pattern returns the recurrence as a Pattern of an item that starts at
+// start, as FromGraph describes.
+func (rec EWSRecurrence) pattern(start time.Time) (Pattern, error) {
+	var p Pattern
+
+	var pattern *EWSPattern
+	for _, ep := range []struct {
+		kind    PatternKind
+		pattern *EWSPattern
+	}{
+		{RelativeYearlyPattern, rec.RelativeYearly},
+		{AbsoluteYearlyPattern, rec.AbsoluteYearly},
+		{RelativeMonthlyPattern, rec.RelativeMonthly},
+		{AbsoluteMonthlyPattern, rec.AbsoluteMonthly},
+		{WeeklyPattern, rec.Weekly},
+		{DailyPattern, rec.Daily},
+	} {
+		if ep.pattern == nil {
+			continue
+		}
+		if pattern != nil {
+			return Pattern{}, fmt.Errorf("EWS recurrence has more than one pattern")
+		}
+		p.Kind, pattern = ep.kind, ep.pattern
+	}
+	if pattern == nil {
+		return Pattern{}, fmt.Errorf("EWS recurrence has no pattern")
+	}
+
+	p.Interval = pattern.Interval
+	p.DayOfMonth = pattern.DayOfMonth
+
+	for _, day := range strings.Fields(pattern.DaysOfWeek) {
+		if set, ok := ewsDays[day]; ok {
+			p.Weekdays = append(p.Weekdays, set...)
+			continue
+		}
+		wd, err := parseEWSName(day, "day of week", 7, func(i int) string { return time.Weekday(i).String() })
+		if err != nil {
+			return Pattern{}, err
+		}
+		p.Weekdays = append(p.Weekdays, time.Weekday(wd))
+	}
+
+	if pattern.FirstDayOfWeek != "" {
+		wd, err := parseEWSName(pattern.FirstDayOfWeek, "day of week", 7, func(i int) string { return time.Weekday(i).String() })
+		if err != nil {
+			return Pattern{}, err
+		}
+		p.FirstDayOfWeek = time.Weekday(wd)
+	}
+
+	if pattern.DayOfWeekIndex != "" {
+		for i, index := range ewsIndexes {
+			if strings.EqualFold(index, pattern.DayOfWeekIndex) {
+				p.Index = i
+			}
+		}
+		if p.Index == 0 {
+			return Pattern{}, fmt.Errorf("EWS day of week index %q is not supported", pattern.DayOfWeekIndex)
+		}
+	}
+
+	if pattern.Month != "" {
+		m, err := parseEWSName(pattern.Month, "month", 12, func(i int) string { return time.Month(i + 1).String() })
+		if err != nil {
+			return Pattern{}, err
+		}
+		p.Month = time.Month(m + 1)
+	}
+
+	var rng *EWSRange
+	switch {
+	case rec.NoEnd != nil:
+		p.End, rng = NoEnd, rec.NoEnd
+	case rec.EndDate != nil:
+		p.End, rng = EndByDate, rec.EndDate
+	case rec.Numbered != nil:
+		p.End, rng = EndAfterOccurrences, rec.Numbered
+	default:
+		return Pattern{}, fmt.Errorf("EWS recurrence has no range")
+	}
+	p.Occurrences = rng.NumberOfOccurrences
+
+	date, err := time.ParseInLocation(recurDate, ewsDate(rng.StartDate), start.Location())
+	if err != nil {
+		return Pattern{}, fmt.Errorf("EWS StartDate: %v", err)
+	}
+	p.Start = time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+
+	if p.End == EndByDate {
+		if p.EndDate, err = time.ParseInLocation(recurDate, ewsDate(rng.EndDate), start.Location()); err != nil {
+			return Pattern{}, fmt.Errorf("EWS EndDate: %v", err)
+		}
+	}
+
+	return p, nil
+}
+
+// parseEWSName returns the i, less than n, whose name(i) is str, ignoring
+// case.
+func parseEWSName(str, what string, n int, name func(int) string) (int, error) {
+	for i := 0; i < n; i++ {
+		if strings.EqualFold(str, name(i)) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("EWS %s %q is not supported", what, str)
+}
+
+// ewsDate returns the date of an xs:date value, without its time zone
+// offset.
+func ewsDate(date string) string {
+	date = strings.TrimSpace(date)
+	if len(date) > len(recurDate) {
+		return date[:len(recurDate)]
+	}
+	return date
+}
+
+// sameWeekdaySet reports whether a and b hold the same weekdays, in any
+// order.
+func sameWeekdaySet(a, b []time.Weekday) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	var seen [7]int
+	for _, wd := range a {
+		seen[wd]++
+	}
+	for _, wd := range b {
+		seen[wd]--
+		if seen[wd] < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package rrule
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEWS(t *testing.T) {
+	start := time.Date(2017, time.September, 4, 12, 0, 0, 0, NewYork()) // a Monday
+
+	for _, tc := range []struct {
+		Name  string
+		RRule RRule
+		XML   string
+	}{
+		{
+			Name:  "weekly",
+			RRule: RRule{Frequency: Weekly, Interval: 2, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Wednesday}}},
+			XML:   `<Recurrence xmlns="http://schemas.microsoft.com/exchange/services/2006/types"><WeeklyRecurrence><Interval>2</Interval><DaysOfWeek>Monday Wednesday</DaysOfWeek><FirstDayOfWeek>Monday</FirstDayOfWeek></WeeklyRecurrence><NoEndRecurrence><StartDate>2017-09-04</StartDate></NoEndRecurrence></Recurrence>`,
+		},
+		{
+			Name:  "relative monthly",
+			RRule: RRule{Frequency: Monthly, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Tuesday}, {WD: time.Wednesday}, {WD: time.Thursday}, {WD: time.Friday}}, BySetPos: []int{-1}, Count: 4},
+			XML:   `<Recurrence xmlns="http://schemas.microsoft.com/exchange/services/2006/types"><RelativeMonthlyRecurrence><Interval>1</Interval><DaysOfWeek>Weekday</DaysOfWeek><DayOfWeekIndex>Last</DayOfWeekIndex></RelativeMonthlyRecurrence><NumberedRecurrence><StartDate>2017-09-04</StartDate><NumberOfOccurrences>4</NumberOfOccurrences></NumberedRecurrence></Recurrence>`,
+		},
+		{
+			Name:  "relative yearly",
+			RRule: RRule{Frequency: Yearly, ByMonths: []time.Month{time.September}, ByWeekdays: []QualifiedWeekday{{N: 1, WD: time.Monday}}, Until: time.Date(2020, time.September, 30, 0, 0, 0, 0, NewYork())},
+			XML:   `<Recurrence xmlns="http://schemas.microsoft.com/exchange/services/2006/types"><RelativeYearlyRecurrence><DaysOfWeek>Monday</DaysOfWeek><DayOfWeekIndex>First</DayOfWeekIndex><Month>September</Month></RelativeYearlyRecurrence><EndDateRecurrence><StartDate>2017-09-04</StartDate><EndDate>2020-09-30</EndDate></EndDateRecurrence></Recurrence>`,
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			rrule := tc.RRule
+			rrule.Dtstart = start
+
+			rec, err := rrule.ToEWS()
+			require.NoError(t, err)
+			b, err := xml.Marshal(rec)
+			require.NoError(t, err)
+			assert.Equal(t, tc.XML, string(b))
+
+			var decoded EWSRecurrence
+			require.NoError(t, xml.Unmarshal([]byte(tc.XML), &decoded))
+			converted, err := FromEWS(decoded, start)
+			require.NoError(t, err)
+			assert.Equal(t, rfcAll(All(rrule.Iterator(), 10)), rfcAll(All(converted.Iterator(), 10)))
+		})
+	}
+}
+
+func TestFromEWS(t *testing.T) {
+	start := time.Date(2017, time.September, 4, 12, 0, 0, 0, time.UTC)
+
+	var rec EWSRecurrence
+	require.NoError(t, xml.Unmarshal([]byte(`<t:Recurrence xmlns:t="http://schemas.microsoft.com/exchange/services/2006/types">
+		<t:AbsoluteMonthlyRecurrence>
+			<t:Interval>3</t:Interval>
+			<t:DayOfMonth>15</t:DayOfMonth>
+		</t:AbsoluteMonthlyRecurrence>
+		<t:NumberedRecurrence>
+			<t:StartDate>2017-09-15-07:00</t:StartDate>
+			<t:NumberOfOccurrences>3</t:NumberOfOccurrences>
+		</t:NumberedRecurrence>
+	</t:Recurrence>`), &rec))
+
+	rrule, err := FromEWS(rec, start)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;COUNT=3;INTERVAL=3;BYMONTHDAY=15", rrule.String())
+	assert.Equal(t, []string{
+		"2017-09-15T12:00:00Z",
+		"2017-12-15T12:00:00Z",
+		"2018-03-15T12:00:00Z",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	for name, rec := range map[string]EWSRecurrence{
+		"no pattern": {NoEnd: &EWSRange{StartDate: "2017-09-04"}},
+		"no range":   {Daily: &EWSPattern{Interval: 1}},
+		"patterns":   {Daily: &EWSPattern{Interval: 1}, Weekly: &EWSPattern{Interval: 1, DaysOfWeek: "Monday"}, NoEnd: &EWSRange{StartDate: "2017-09-04"}},
+		"month":      {AbsoluteYearly: &EWSPattern{DayOfMonth: 1, Month: "Smarch"}, NoEnd: &EWSRange{StartDate: "2017-09-04"}},
+		"day":        {Weekly: &EWSPattern{Interval: 1, DaysOfWeek: "Caturday"}, NoEnd: &EWSRange{StartDate: "2017-09-04"}},
+	} {
+		_, err := FromEWS(rec, start)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestToEWSErrors(t *testing.T) {
+	start := time.Date(2017, time.September, 4, 12, 0, 0, 0, time.UTC)
+
+	for name, rrule := range map[string]RRule{
+		"hourly":   {Frequency: Hourly, Dtstart: start},
+		"interval": {Frequency: Yearly, Dtstart: start, Interval: 2},
+		"days":     {Frequency: Monthly, Dtstart: start, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Friday}}, BySetPos: []int{1}},
+	} {
+		_, err := rrule.ToEWS()
+		assert.Error(t, err, name)
+	}
+}
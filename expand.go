@@ -0,0 +1,246 @@
+package rrule
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Expand returns the occurrences of the pattern at or after start and before
+// end. Expand holds no state between calls, so it is safe to call
+// concurrently on a shared RRule.
+//
+// The pattern must be valid or Expand will panic.
+func (rrule RRule) Expand(start, end time.Time) []time.Time {
+	if rrule.simple() {
+		return rrule.expandSimple(start, end)
+	}
+	return expand(rrule.Iterator(), start, end, rrule.sizeHint(start, end))
+}
+
+// expandSimple returns the occurrences of a simple pattern at or after start
+// and before end, computing them rather than iterating to them.
+func (rrule RRule) expandSimple(start, end time.Time) []time.Time {
+	if err := rrule.Validate(); err != nil {
+		panic(err)
+	}
+
+	rrule.Dtstart = rrule.dtstart()
+	from, to := rrule.indexSimple(start), rrule.indexSimple(end)
+	if n, ok := rrule.lenSimple(); ok && to > n {
+		to = n
+	}
+
+	var tt []time.Time
+	if to > from {
+		tt = make([]time.Time, 0, to-from)
+	}
+	for n := from; n < to; n++ {
+		tt = append(tt, rrule.nthSimple(n))
+	}
+	return tt
+}
+
+// ExpandOptions controls how Recurrence.ExpandWith expands a recurrence.
+type ExpandOptions struct {
+	// Workers is the most patterns expanded at once, each in its own
+	// goroutine, before their instances are merged. 0 uses GOMAXPROCS, and
+	// 1 expands the recurrence in the calling goroutine, without merging.
+	Workers int
+}
+
+// Expand returns the instances of the recurrence at or after start and before
+// end. Expand holds no state between calls, so it is safe to call
+// concurrently on a shared Recurrence.
+//
+// A recurrence with several patterns has them expanded in parallel; see
+// ExpandWith.
+func (r Recurrence) Expand(start, end time.Time) []time.Time {
+	return r.ExpandWith(start, end, ExpandOptions{})
+}
+
+// ExpandWith returns the instances of the recurrence at or after start and
+// before end, like Expand, as controlled by opts.
+func (r Recurrence) ExpandWith(start, end time.Time, opts ExpandOptions) []time.Time {
+	workers := opts.Workers
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(r.RRules)+len(r.ExRules) {
+		workers = len(r.RRules) + len(r.ExRules)
+	}
+	if workers <= 1 {
+		return expand(r.Iterator(), start, end, r.sizeHint(start, end))
+	}
+
+	r.RRules = append([]RRule(nil), r.RRules...)
+	r.ExRules = append([]RRule(nil), r.ExRules...)
+	r.setDtstart()
+
+	patterns := append(append([]RRule(nil), r.RRules...), r.ExRules...)
+	expanded := expandAll(patterns, start, end, workers)
+
+	included := mergeInstances(append(expanded[:len(r.RRules):len(r.RRules)], inRange(r.RDates, start, end)))
+	excluded := mergeInstances(append(expanded[len(r.RRules):], inRange(r.ExDates, start, end)))
+
+	// like recurrenceIterator, only the exact instants are excluded.
+	tt := included[:0]
+	for _, t := range included {
+		for len(excluded) > 0 && excluded[0].Before(t) {
+			excluded = excluded[1:]
+		}
+		if len(excluded) == 0 || !excluded[0].Equal(t) {
+			tt = append(tt, t)
+		}
+	}
+	if len(tt) == 0 {
+		return nil
+	}
+	return tt
+}
+
+// expandAll expands each of the patterns using the given number of goroutines.
+// A pattern that panics, because it's invalid, panics in the caller.
+func expandAll(patterns []RRule, start, end time.Time, workers int) [][]time.Time {
+	expanded := make([][]time.Time, len(patterns))
+	panics := make([]interface{}, len(patterns))
+
+	jobs := make(chan int, len(patterns))
+	for i := range patterns {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				func() {
+					defer func() { panics[i] = recover() }()
+					expanded[i] = patterns[i].Expand(start, end)
+				}()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, p := range panics {
+		if p != nil {
+			panic(p)
+		}
+	}
+	return expanded
+}
+
+// inRange returns the dates at or after start and before end, sorted.
+func inRange(dates []time.Time, start, end time.Time) []time.Time {
+	var tt []time.Time
+	for _, t := range dates {
+		if !t.Before(start) && t.Before(end) {
+			tt = append(tt, t)
+		}
+	}
+	sort.SliceStable(tt, func(i, j int) bool { return tt[i].Before(tt[j]) })
+	return tt
+}
+
+// mergeInstances merges sorted lists of instances into one. Like
+// groupIterator, instances in the same second are included only once, the
+// earliest one, or the one in the first list.
+func mergeInstances(lists [][]time.Time) []time.Time {
+	var all []time.Time
+	for _, tt := range lists {
+		all = append(all, tt...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Before(all[j]) })
+
+	var merged []time.Time
+	for _, t := range all {
+		if len(merged) > 0 && t.Truncate(time.Second).Equal(merged[len(merged)-1].Truncate(time.Second)) {
+			continue
+		}
+		merged = append(merged, t)
+	}
+	return merged
+}
+
+// expand returns the times of it at or after start and before end, about hint
+// of them, then releases it.
+func expand(it Iterator, start, end time.Time, hint int) []time.Time {
+	defer release(it)
+	it.Seek(start)
+
+	var tt []time.Time
+	for {
+		next := it.Next()
+		if next == nil || !next.Before(end) {
+			return tt
+		}
+		if tt == nil {
+			tt = make([]time.Time, 0, hint)
+		}
+		tt = append(tt, *next)
+	}
+}
+
+// BetweenOptions controls which occurrences OccurrencesBetween returns.
+type BetweenOptions struct {
+	// Inclusive includes an occurrence at exactly end, so the range is
+	// [start, end] rather than [start, end).
+	Inclusive bool
+}
+
+// defaultPageSize is the limit of OccurrencesBetween for a range without an
+// end when neither its limit nor MaxOccurrences sets one.
+const defaultPageSize = 1000
+
+// OccurrencesBetween returns the times of it at or after start and before end,
+// or at end if opts.Inclusive is set, but no more than limit of them. A limit of
+// 0 uses MaxOccurrences, and a zero end returns every time until the iterator
+// ends or the limit is reached. A zero end with no limit at all returns a page
+// of 1000 times, so a pattern that never ends can't search forever. It also
+// reports whether the limit cut the times short, in which case the next page
+// of them can be had by calling it again with a start just after the last
+// time returned.
+//
+// If the iterator ends early, as one limited by WithBudget can, the times
+// found so far are returned, and IteratorErr reports why.
+func OccurrencesBetween(it Iterator, start, end time.Time, limit int, opts BetweenOptions) ([]time.Time, bool) {
+	if limit == 0 {
+		limit = MaxOccurrences
+	}
+	if limit <= 0 && end.IsZero() {
+		limit = defaultPageSize
+	}
+
+	inRange := func(t time.Time) bool {
+		return end.IsZero() || t.Before(end) || opts.Inclusive && t.Equal(end)
+	}
+
+	// the iterator is bounded at end only while looking, so the caller can
+	// go on to the next page with it.
+	if !end.IsZero() {
+		withBound(it, end)
+		defer withBound(it, time.Time{})
+	}
+
+	it.Seek(start)
+	var tt []time.Time
+	for {
+		next := it.Peek()
+		if next == nil || !inRange(*next) {
+			return tt, false
+		}
+		if limit > 0 && len(tt) == limit {
+			return tt, true
+		}
+		t := *it.Next()
+		if tt == nil {
+			tt = make([]time.Time, 0, presize(it, limit))
+		}
+		tt = append(tt, t)
+	}
+}
@@ -0,0 +1,239 @@
+package rrule
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	rrule := RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Friday}}, Dtstart: time.Date(1990, time.January, 1, 9, 0, 0, 0, time.UTC)}
+	start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, time.March, 11, 0, 0, 0, 0, time.UTC)
+	expected := []string{"2025-03-03T09:00:00Z", "2025-03-07T09:00:00Z", "2025-03-10T09:00:00Z"}
+
+	r := Recurrence{
+		Dtstart: rrule.Dtstart,
+		RRules:  []RRule{rrule},
+		ExDates: []time.Time{time.Date(2025, time.March, 7, 9, 0, 0, 0, time.UTC)},
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, expected, rfcAll(rrule.Expand(start, end)))
+			assert.Equal(t, []string{expected[0], expected[2]}, rfcAll(r.Expand(start, end)))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestExpandSimple(t *testing.T) {
+	dtstart := time.Date(2020, time.January, 31, 1, 30, 0, 0, NewYork())
+	rrules := []RRule{
+		{Frequency: Secondly, Interval: 7, Dtstart: dtstart},
+		{Frequency: Hourly, Interval: 5, Dtstart: dtstart},
+		{Frequency: Daily, Dtstart: dtstart.AddDate(0, 1, 0)},
+		{Frequency: Weekly, Interval: 2, Until: time.Date(2020, time.May, 1, 1, 30, 0, 0, NewYork()), Dtstart: dtstart},
+		{Frequency: Weekly, Until: time.Date(2020, time.March, 27, 1, 30, 0, 0, NewYork()), UntilExclusive: true, Dtstart: dtstart.AddDate(0, 0, -6)},
+		{Frequency: Monthly, Interval: 3, Count: 2, Dtstart: dtstart.AddDate(0, 0, -3)},
+		{Frequency: Yearly, Until: time.Date(2020, time.March, 10, 1, 30, 0, 0, time.UTC), UntilFloating: true, Dtstart: dtstart.AddDate(-3, 0, 0)},
+	}
+
+	ranges := [][2]time.Time{
+		{dtstart, dtstart.Add(time.Minute)},
+		{dtstart.AddDate(0, 1, 7), dtstart.AddDate(0, 1, 14)},
+		{dtstart.AddDate(-1, 0, 0), dtstart.AddDate(0, 3, 0)},
+		{dtstart.AddDate(0, 0, 5), dtstart},
+	}
+
+	for _, rrule := range rrules {
+		require.True(t, rrule.simple(), rrule.String())
+		for _, r := range ranges {
+			assert.Equal(t, expand(rrule.Iterator(), r[0], r[1], 0), rrule.Expand(r[0], r[1]), rrule.String())
+		}
+	}
+
+	// an occurrence moved out of the gap when clocks spring forward, on
+	// 2020-03-08 in New York, doesn't move the ones after it, whether they're
+	// computed or iterated to.
+	for _, rrule := range []RRule{
+		{Frequency: Daily, Count: 5, Dtstart: time.Date(2020, time.March, 6, 2, 30, 0, 0, NewYork())},
+		{Frequency: Weekly, Count: 3, Dtstart: time.Date(2020, time.March, 1, 2, 30, 0, 0, NewYork())},
+		{Frequency: Monthly, Count: 3, Dtstart: time.Date(2020, time.February, 8, 2, 30, 0, 0, NewYork())},
+		{Frequency: Yearly, Count: 3, Dtstart: time.Date(2019, time.March, 8, 2, 30, 0, 0, NewYork())},
+	} {
+		require.True(t, rrule.simple(), rrule.String())
+		all := All(rrule.Iterator(), 0)
+		assert.Equal(t, all, rrule.Expand(rrule.Dtstart, rrule.Dtstart.AddDate(5, 0, 0)), rrule.String())
+		assert.Equal(t, 2, all[len(all)-1].Hour(), rrule.String())
+	}
+}
+
+func TestExpandParallel(t *testing.T) {
+	dtstart := time.Date(2020, time.January, 31, 9, 30, 0, 0, NewYork())
+	r := Recurrence{
+		Dtstart: dtstart,
+		RRules: []RRule{
+			MustRRule("FREQ=WEEKLY;BYDAY=MO,WE,FR"),
+			MustRRule("FREQ=MONTHLY;BYMONTHDAY=1,15;BYHOUR=9,17;BYMINUTE=30"),
+			MustRRule("FREQ=DAILY;INTERVAL=3;COUNT=40"),
+			MustRRule("FREQ=YEARLY;BYMONTH=3,11;BYDAY=-1SU"),
+		},
+		RDates: []time.Time{dtstart.AddDate(0, 2, 0).Add(time.Hour), dtstart.AddDate(0, 0, 2)},
+		ExRules: []RRule{
+			MustRRule("FREQ=MONTHLY;BYDAY=1FR"),
+		},
+		ExDates: []time.Time{dtstart.AddDate(0, 0, 5), dtstart.AddDate(0, 1, 0)},
+	}
+
+	ranges := [][2]time.Time{
+		{dtstart, dtstart.AddDate(1, 0, 0)},
+		{dtstart.AddDate(0, 1, 7), dtstart.AddDate(0, 1, 14)},
+		{dtstart.AddDate(-1, 0, 0), dtstart.AddDate(0, 3, 0)},
+		{dtstart.AddDate(0, 0, 5), dtstart},
+	}
+
+	for _, rng := range ranges {
+		sequential := r.ExpandWith(rng[0], rng[1], ExpandOptions{Workers: 1})
+		assert.Equal(t, rfcAll(expand(r.Iterator(), rng[0], rng[1], 0)), rfcAll(sequential))
+		assert.Equal(t, rfcAll(sequential), rfcAll(r.ExpandWith(rng[0], rng[1], ExpandOptions{Workers: 3})))
+	}
+
+	// RDates are merged in order, even though they aren't given in order.
+	assert.Equal(t, []string{"2020-01-31T09:30:00-05:00", "2020-02-01T09:30:00-05:00", "2020-02-01T17:30:00-05:00", "2020-02-02T09:30:00-05:00"},
+		rfcAll(r.ExpandWith(dtstart, dtstart.AddDate(0, 0, 3), ExpandOptions{Workers: 3})))
+
+	// an invalid pattern panics in the caller.
+	r.RRules = append(r.RRules, RRule{Frequency: Weekly, ByMonthDays: []int{1}})
+	assert.Panics(t, func() { r.ExpandWith(ranges[0][0], ranges[0][1], ExpandOptions{Workers: 3}) })
+}
+
+func TestExpandReusesBuffers(t *testing.T) {
+	start := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 2, 0)
+	weekdays := MustRRule("FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,17")
+	weekdays.Dtstart = now
+	weekends := MustRRule("FREQ=YEARLY;BYDAY=SA,SU")
+	weekends.Dtstart = now
+
+	// the buffers of one expansion are reused by the next, which mustn't
+	// change the times already returned.
+	first := weekdays.Expand(start, end)
+	want := rfcAll(first)
+	require.Len(t, want, 86)
+	for i := 0; i < 3; i++ {
+		assert.Len(t, weekends.Expand(start, end), 18)
+		assert.Equal(t, want, rfcAll(weekdays.Expand(start, end)))
+	}
+	assert.Equal(t, want, rfcAll(first))
+}
+
+// BenchmarkExpand measures the month views of patterns with many times each
+// key time.
+func BenchmarkExpand(b *testing.B) {
+	start := time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)
+	for _, str := range []string{
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,17",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR,SA,SU",
+		"FREQ=YEARLY;BYDAY=MO,TU,WE,TH,FR",
+	} {
+		rrule := MustRRule(str)
+		rrule.Dtstart = now
+
+		b.Run(str, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				rrule.Expand(start, start.AddDate(0, 1, 0))
+			}
+		})
+	}
+}
+
+func BenchmarkRecurrenceExpand(b *testing.B) {
+	r := Recurrence{Dtstart: now}
+	for _, str := range []string{
+		"FREQ=WEEKLY;BYDAY=MO,WE,FR;BYHOUR=9,17",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"FREQ=DAILY;BYHOUR=9,12,17;BYMINUTE=0,30",
+		"FREQ=YEARLY;BYMONTH=3,11;BYDAY=-1SU;BYHOUR=1,2",
+	} {
+		r.RRules = append(r.RRules, MustRRule(str))
+	}
+	start, end := now, now.AddDate(1, 0, 0)
+
+	for _, workers := range []int{1, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.ExpandWith(start, end, ExpandOptions{Workers: workers})
+			}
+		})
+	}
+}
+
+func TestOccurrencesBetween(t *testing.T) {
+	rrule := RRule{Frequency: Daily, Dtstart: time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)}
+	day := func(d int) time.Time { return time.Date(2025, time.March, d, 9, 0, 0, 0, time.UTC) }
+
+	tt, more := OccurrencesBetween(rrule.Iterator(), day(3), day(6), 10, BetweenOptions{})
+	assert.Equal(t, []time.Time{day(3), day(4), day(5)}, tt)
+	assert.False(t, more)
+
+	tt, more = OccurrencesBetween(rrule.Iterator(), day(3), day(6), 10, BetweenOptions{Inclusive: true})
+	assert.Equal(t, []time.Time{day(3), day(4), day(5), day(6)}, tt)
+	assert.False(t, more)
+
+	// paging
+	tt, more = OccurrencesBetween(rrule.Iterator(), day(3), day(6), 2, BetweenOptions{Inclusive: true})
+	assert.Equal(t, []time.Time{day(3), day(4)}, tt)
+	assert.True(t, more)
+	tt, more = OccurrencesBetween(rrule.Iterator(), tt[1].Add(time.Nanosecond), day(6), 2, BetweenOptions{Inclusive: true})
+	assert.Equal(t, []time.Time{day(5), day(6)}, tt)
+	assert.False(t, more)
+
+	// an exact page isn't cut short.
+	tt, more = OccurrencesBetween(rrule.Iterator(), day(3), day(6), 3, BetweenOptions{})
+	assert.Len(t, tt, 3)
+	assert.False(t, more)
+
+	tt, more = OccurrencesBetween(rrule.Iterator(), day(3), time.Time{}, 5, BetweenOptions{})
+	assert.Equal(t, []time.Time{day(3), day(4), day(5), day(6), day(7)}, tt)
+	assert.True(t, more)
+
+	// without an end or a limit, a pattern that never ends is paged.
+	defer func(max int) { MaxOccurrences = max }(MaxOccurrences)
+	MaxOccurrences = 0
+	tt, more = OccurrencesBetween(rrule.Iterator(), day(3), time.Time{}, 0, BetweenOptions{})
+	assert.Len(t, tt, defaultPageSize)
+	assert.Equal(t, day(3), tt[0])
+	assert.True(t, more)
+
+	tt, more = OccurrencesBetween(rrule.Iterator(), day(6), day(3), 5, BetweenOptions{Inclusive: true})
+	assert.Empty(t, tt)
+	assert.False(t, more)
+
+	// the bound at end is lifted afterward, so the iterator goes on.
+	it := rrule.Iterator()
+	tt, _ = OccurrencesBetween(it, day(3), day(4), 0, BetweenOptions{})
+	assert.Equal(t, []time.Time{day(3)}, tt)
+	tt, _ = OccurrencesBetween(it, day(5), day(7), 0, BetweenOptions{})
+	assert.Equal(t, []time.Time{day(5), day(6)}, tt)
+}
+
+func TestOccurrencesBetweenSparse(t *testing.T) {
+	// no year has a 366th day until 2028, but the search stops at end.
+	rrule := MustRRule("FREQ=SECONDLY;BYYEARDAY=366")
+	rrule.Dtstart = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	tt, more := OccurrencesBetween(rrule.Iterator(), start, start.AddDate(0, 0, 1), 0, BetweenOptions{})
+	assert.Empty(t, tt)
+	assert.False(t, more)
+}
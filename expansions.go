@@ -5,23 +5,18 @@ import (
 	"time"
 )
 
+// The expansions below replace each time in tt with its variations in place,
+// growing tt only if it lacks the capacity, so an iterator can reuse one
+// buffer for every key time. With sorted values, sorted times stay sorted.
+
 func expandBySeconds(tt []time.Time, seconds ...int) []time.Time {
 	if len(seconds) == 0 {
 		return tt
 	}
 
-	e := make([]time.Time, 0, len(tt)*len(seconds))
-	for _, t := range tt {
-		tmpl := t.Add(time.Duration(-1*t.Second()) * time.Second)
-		for _, s := range seconds {
-			if s < 0 {
-				s += 60
-			}
-			e = append(e, tmpl.Add((time.Duration(s) * time.Second)))
-		}
-	}
-
-	return e
+	return expandEach(tt, len(seconds), func(t time.Time, i int) time.Time {
+		return atSecond(t, seconds[i])
+	})
 }
 
 func expandByMinutes(tt []time.Time, minutes ...int) []time.Time {
@@ -29,18 +24,9 @@ func expandByMinutes(tt []time.Time, minutes ...int) []time.Time {
 		return tt
 	}
 
-	e := make([]time.Time, 0, len(tt)*len(minutes))
-	for _, t := range tt {
-		tmpl := t.Add(time.Duration(-1*t.Minute()) * time.Minute)
-		for _, m := range minutes {
-			if m < 0 {
-				m += 60
-			}
-			e = append(e, tmpl.Add(time.Duration(m)*time.Minute))
-		}
-	}
-
-	return e
+	return expandEach(tt, len(minutes), func(t time.Time, i int) time.Time {
+		return atMinute(t, minutes[i])
+	})
 }
 
 func expandByHours(tt []time.Time, hours ...int) []time.Time {
@@ -48,196 +34,134 @@ func expandByHours(tt []time.Time, hours ...int) []time.Time {
 		return tt
 	}
 
-	e := make([]time.Time, 0, len(tt)*len(hours))
-	for _, t := range tt {
-		tmpl := t.Add(time.Duration(-1*t.Hour()) * time.Hour)
-		for _, h := range hours {
-			if h < 0 {
-				h += 24
-			}
-			e = append(e, tmpl.Add(time.Duration(h)*time.Hour))
-		}
-	}
-
-	return e
+	return expandEach(tt, len(hours), func(t time.Time, i int) time.Time {
+		return atHour(t, hours[i])
+	})
 }
 
-func expandByWeekdays(tt []time.Time, weekStart time.Weekday, weekdays ...QualifiedWeekday) []time.Time {
-	if len(weekdays) == 0 {
-		return tt
-	}
+// atSecond, atMinute and atHour move t to a second, minute or hour, which
+// counts back from the end of the minute, hour or day if negative.
 
-	e := make([]time.Time, 0, len(tt)*len(weekdays))
-	for _, t := range tt {
-		t = backToWeekday(t, weekStart)
-		for _, wd := range weekdays {
-			e = append(e, forwardToWeekday(t, wd.WD))
-		}
+func atSecond(t time.Time, s int) time.Time {
+	if s < 0 {
+		s += 60
 	}
-
-	return e
+	return t.Add(time.Duration(s-t.Second()) * time.Second)
 }
 
-func expandByMonthDays(tt []time.Time, monthdays ...int) []time.Time {
-	if len(monthdays) == 0 {
-		return tt
+func atMinute(t time.Time, m int) time.Time {
+	if m < 0 {
+		m += 60
 	}
-
-	e := make([]time.Time, 0, len(tt)*len(monthdays))
-	for _, t := range tt {
-		for _, md := range monthdays {
-			e = append(e, time.Date(t.Year(), t.Month(), md, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()))
-		}
-	}
-
-	return e
+	return t.Add(time.Duration(m-t.Minute()) * time.Minute)
 }
 
-func expandByYearDays(tt []time.Time, ib InvalidBehavior, yeardays ...int) []time.Time {
-	if len(yeardays) == 0 {
-		return tt
+func atHour(t time.Time, h int) time.Time {
+	if h < 0 {
+		h += 24
 	}
-
-	e := make([]time.Time, 0, len(tt)*len(yeardays))
-	for _, t := range tt {
-		yearStart := time.Date(t.Year(), time.January, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
-		startYear := yearStart.Year()
-
-		for _, yd := range yeardays {
-			added := yearStart.AddDate(0, 0, yd-1) // subtract one because we start on the 1st, so if we want yearday 1, we actually want to advance 0.
-			if added.Year() != startYear {
-				switch ib {
-				case OmitInvalid:
-					// do nothing
-				case NextInvalid:
-					e = append(e, added)
-				case PrevInvalid:
-					e = append(e, added.AddDate(0, 0, -1))
-				}
-			} else {
-				e = append(e, added)
-			}
-		}
-	}
-
-	return e
+	return t.Add(time.Duration(h-t.Hour()) * time.Hour)
 }
 
-func expandByWeekNumbers(tt []time.Time, ib InvalidBehavior, weekStarts time.Weekday, byWeekdays []time.Weekday, weekNumbers ...int) []time.Time {
-	if len(weekNumbers) == 0 {
+func expandByWeekdays(tt []time.Time, weekStart time.Weekday, weekdays ...QualifiedWeekday) []time.Time {
+	if len(weekdays) == 0 {
 		return tt
 	}
 
-	e := make([]time.Time, 0, len(tt)*len(weekNumbers))
-	for _, t := range tt {
-		ys := yearStart(t, weekStarts)
-
-		byWeekdays := byWeekdays
-		if len(byWeekdays) == 0 {
-			// NOTE: the spec is not 100% clear on what to do in this case.
-			// rrule.js, for instance, will default to returning the full
-			// week. lib-recur seems to copy the weekday from the input
-			// time. I'm going with the latter, since it seems more consistent
-			// with the behavior you'd get on a BYMONTH clause.
-			byWeekdays = []time.Weekday{t.Weekday()}
-		}
+	return expandEach(tt, len(weekdays), func(t time.Time, i int) time.Time {
+		return t.AddDate(0, 0, daysTil(weekStart, weekdays[i].WD)-daysFrom(t.Weekday(), weekStart))
+	})
+}
 
-		for _, w := range weekNumbers {
-			ws := ys.AddDate(0, 0, (w-1)*7)
-
-			if weekYearStart := yearStart(ws, weekStarts); weekYearStart.Year() != ys.Year() {
-				// check that the week we generated is still within the proper
-				// year, or if it ran over because the year did not have enough
-				// weeks
-
-				nextYearStart := yearStart(ys.AddDate(1, 0, 0), weekStarts)
-				switch ib {
-				case OmitInvalid:
-					// do nothing
-				case NextInvalid:
-					for _, wd := range byWeekdays {
-						e = append(e, forwardToWeekday(nextYearStart, wd))
-					}
-				case PrevInvalid:
-					for _, wd := range byWeekdays {
-						e = append(e, backToWeekday(nextYearStart, wd))
-					}
-				}
-				continue
-			}
-
-			for _, wd := range byWeekdays {
-				e = append(e, forwardToWeekday(ws, wd))
-			}
+// expandEach replaces each time in tt with n variations of it, in order.
+func expandEach(tt []time.Time, n int, variation func(t time.Time, i int) time.Time) []time.Time {
+	size := len(tt) * n
+	if cap(tt) < size {
+		grown := make([]time.Time, len(tt), size)
+		copy(grown, tt)
+		tt = grown
+	}
+	tt = tt[:size]
+
+	// filling from the back never overwrites a time before it's expanded.
+	for i := size/n - 1; i >= 0; i-- {
+		t := tt[i]
+		for j := n - 1; j >= 0; j-- {
+			tt[i*n+j] = variation(t, j)
 		}
 	}
-
-	return e
+	return tt
 }
 
-func expandByMonths(tt []time.Time, ib InvalidBehavior, months ...time.Month) []time.Time {
-	if len(months) == 0 {
-		return tt
+// expandByMonthDays appends the given days of the month of t to dst, sorted and
+// without repeats. Negative days count back from the end of the month. Days
+// the month doesn't have, like the 31st of April, are handled according to
+// ib, as in RFC 7529.
+func expandByMonthDays(dst []time.Time, t time.Time, ib InvalidBehavior, monthdays ...int) []time.Time {
+	length := lastOfMonth(t).Day()
+	first := len(dst)
+
+	for _, md := range monthdays {
+		// days 0 and length+1, where ib moves days the month doesn't
+		// have, fall in the months either side.
+		if day, ok := invalidDay(md, length, ib); ok {
+			dst = append(dst, time.Date(t.Year(), t.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()))
+		}
 	}
 
-	e := make([]time.Time, 0, len(tt)*len(months))
-	for _, t := range tt {
-		for _, m := range months {
-			set := time.Date(t.Year(), m, t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
-			if set.Month() != m {
-				switch ib {
-				case PrevInvalid:
-					set = time.Date(t.Year(), t.Month()+1, -1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
-					e = append(e, set)
-				case NextInvalid:
-					set = time.Date(t.Year(), t.Month()+1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
-					e = append(e, set)
-				case OmitInvalid:
-					// do nothing
-				}
-			} else {
-				e = append(e, set)
-			}
+	// the days are few, and sorting them by insertion avoids sort.Slice's
+	// allocations.
+	for i := first + 1; i < len(dst); i++ {
+		for j := i; j > first && dst[j].Before(dst[j-1]); j-- {
+			dst[j], dst[j-1] = dst[j-1], dst[j]
 		}
 	}
-
-	return e
+	return dst[:first+len(uniqueTimes(dst[first:]))]
 }
 
-// expandMonthByWeekdays does a special expansion of the month by weekdays. If
-// bySetPos is not nil, it is assumed tt is the full set of instances within the
-// monthly iteration, and only the instances matching the posisions of bySetPos
-// are returned. This is an optimization.
-func expandMonthByWeekdays(tt []time.Time, ib InvalidBehavior, bySetPos []int, weekdays ...QualifiedWeekday) []time.Time {
-	if len(weekdays) == 0 {
-		return tt
+// sortedClock returns values of a clock field that counts up to period, like
+// BYMINUTE, with negative values counted back from period, sorted and without
+// repeats.
+func sortedClock(values []int, period int) []int {
+	if len(values) == 0 {
+		return nil
 	}
 
-	e := make([]time.Time, 0, len(tt))
-	for _, t := range tt {
-		e = append(e, weekdaysInMonth(t, weekdays, bySetPos, ib)...)
+	sorted := make([]int, len(values))
+	for i, v := range values {
+		if v < 0 {
+			v += period
+		}
+		sorted[i] = v
 	}
+	sort.Ints(sorted)
 
-	return e
+	unique := sorted[:1]
+	for _, v := range sorted[1:] {
+		if v != unique[len(unique)-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
 }
 
-func expandYearByWeekdays(tt []time.Time, ib InvalidBehavior, weekdays ...QualifiedWeekday) []time.Time {
+// sortedWeekdays returns the weekdays in the order they fall in a week that
+// starts on weekStart, without repeats.
+func sortedWeekdays(weekdays []QualifiedWeekday, weekStart time.Weekday) []QualifiedWeekday {
 	if len(weekdays) == 0 {
-		return tt
+		return nil
 	}
 
-	e := make([]time.Time, 0, len(tt))
-	for _, t := range tt {
-		for _, wd := range weekdays {
-			res := weekdaysInYear(t, wd, ib)
-			e = append(e, res...)
-		}
-	}
-
-	sort.Slice(e, func(i, j int) bool {
-		return e[i].Before(e[j])
+	sorted := append([]QualifiedWeekday(nil), weekdays...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return daysTil(weekStart, sorted[i].WD) < daysTil(weekStart, sorted[j].WD)
 	})
 
-	return e
-
+	unique := sorted[:1]
+	for _, wd := range sorted[1:] {
+		if wd.WD != unique[len(unique)-1].WD {
+			unique = append(unique, wd)
+		}
+	}
+	return unique
 }
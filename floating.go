@@ -0,0 +1,74 @@
+package rrule
+
+import "time"
+
+// InLocation returns the pattern with its floating times observed in loc.
+// Dtstart, and Until if UntilFloating is set, keep their clock times but are
+// moved to loc, so a pattern stored without a time zone yields the same
+// wall-clock occurrences for users in different time zones. The RRule type
+// doesn't record whether Dtstart is floating, so it is always treated as
+// floating here; a zero Dtstart stays zero.
+func (rrule RRule) InLocation(loc *time.Location) RRule {
+	if !rrule.Dtstart.IsZero() {
+		rrule.Dtstart = wallClockIn(rrule.Dtstart, loc)
+	}
+	return rrule.withFloatingUntilIn(loc)
+}
+
+// Rebase returns the pattern moved to loc, keeping its clock times, as when
+// the person it belongs to moves to another city. Dtstart and Until keep the
+// clock times they have in the location of Dtstart, so unlike InLocation an
+// absolute Until moves too. A zero Dtstart stays zero, and Until is then left
+// as it is.
+func (rrule RRule) Rebase(loc *time.Location) RRule {
+	if rrule.Dtstart.IsZero() {
+		return rrule
+	}
+
+	if !rrule.Until.IsZero() && !rrule.UntilFloating {
+		rrule.Until = wallClockIn(rrule.Until.In(rrule.Dtstart.Location()), loc)
+	}
+	return rrule.InLocation(loc)
+}
+
+// InLocation returns a copy of the recurrence with its floating times
+// observed in loc. If FloatingLocation is set, Dtstart, RDates and ExDates,
+// along with any floating UNTIL, keep their clock times but are moved to loc.
+// Otherwise the recurrence is returned unchanged, since a floating UNTIL is
+// observed in the location of Dtstart.
+func (r Recurrence) InLocation(loc *time.Location) Recurrence {
+	if !r.FloatingLocation {
+		return r
+	}
+
+	if !r.Dtstart.IsZero() {
+		r.Dtstart = wallClockIn(r.Dtstart, loc)
+	}
+	r.RDates = wallClocksIn(r.RDates, loc)
+	r.ExDates = wallClocksIn(r.ExDates, loc)
+	r.RRules = append([]RRule(nil), r.RRules...)
+	for i, rr := range r.RRules {
+		r.RRules[i] = rr.withFloatingUntilIn(loc)
+	}
+	r.ExRules = append([]RRule(nil), r.ExRules...)
+	for i, rr := range r.ExRules {
+		r.ExRules[i] = rr.withFloatingUntilIn(loc)
+	}
+	return r
+}
+
+// wallClockIn returns t at the same clock time in loc.
+func wallClockIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+func wallClocksIn(times []time.Time, loc *time.Location) []time.Time {
+	if times == nil {
+		return nil
+	}
+	moved := make([]time.Time, len(times))
+	for i, t := range times {
+		moved[i] = wallClockIn(t, loc)
+	}
+	return moved
+}
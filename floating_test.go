@@ -0,0 +1,87 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRRuleInLocation(t *testing.T) {
+	rrule, err := ParseContentLine("DTSTART:20180101T090000\nRRULE:FREQ=DAILY;UNTIL=20180103T090000", nil)
+	require.NoError(t, err)
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"2018-01-01T09:00:00-05:00",
+		"2018-01-02T09:00:00-05:00",
+		"2018-01-03T09:00:00-05:00",
+	}, rfcAll(All(rrule.InLocation(NewYork()).Iterator(), 0)))
+	assert.Equal(t, []string{
+		"2018-01-01T09:00:00+09:00",
+		"2018-01-02T09:00:00+09:00",
+		"2018-01-03T09:00:00+09:00",
+	}, rfcAll(All(rrule.InLocation(tokyo).Iterator(), 0)))
+
+	// the stored pattern is unchanged.
+	assert.Equal(t, time.UTC, rrule.Dtstart.Location())
+	assert.Equal(t, "FREQ=DAILY;UNTIL=20180103T090000", rrule.String())
+
+	assert.True(t, RRule{Frequency: Daily}.InLocation(tokyo).Dtstart.IsZero())
+}
+
+func TestRecurrenceInLocation(t *testing.T) {
+	r, err := ParseRecurrence([]byte("DTSTART:20180101T090000\nRRULE:FREQ=DAILY;COUNT=3\nRDATE:20180105T090000\nEXDATE:20180102T090000"), nil)
+	require.NoError(t, err)
+	require.True(t, r.FloatingLocation)
+
+	ny := r.InLocation(NewYork())
+	assert.Equal(t, []string{
+		"2018-01-01T09:00:00-05:00",
+		"2018-01-03T09:00:00-05:00",
+		"2018-01-05T09:00:00-05:00",
+	}, rfcAll(All(ny.Iterator(), 0)))
+	assert.Equal(t, r.String(), ny.String())
+	assert.Equal(t, time.UTC, r.Dtstart.Location())
+
+	// times with a zone are not moved, and a floating UNTIL is observed
+	// in the location of DTSTART.
+	r, err = ParseRecurrence([]byte("DTSTART:20180101T090000Z\nRRULE:FREQ=DAILY;UNTIL=20180102T090000"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"2018-01-01T09:00:00Z",
+		"2018-01-02T09:00:00Z",
+	}, rfcAll(All(r.InLocation(NewYork()).Iterator(), 0)))
+}
+
+func TestRebase(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	rrule := RRule{
+		Frequency: Weekly,
+		Dtstart:   time.Date(2018, time.January, 1, 9, 0, 0, 0, tokyo),
+		Until:     time.Date(2018, time.January, 15, 0, 0, 0, 0, time.UTC), // 09:00 in Tokyo
+	}
+
+	rebased := rrule.Rebase(NewYork())
+	assert.Equal(t, NewYork(), rebased.Dtstart.Location())
+	assert.Equal(t, []string{
+		"2018-01-01T09:00:00-05:00",
+		"2018-01-08T09:00:00-05:00",
+		"2018-01-15T09:00:00-05:00",
+	}, rfcAll(All(rebased.Iterator(), 0)))
+
+	// InLocation keeps an absolute UNTIL, which has passed by 09:00 in New
+	// York.
+	assert.Len(t, All(rrule.InLocation(NewYork()).Iterator(), 0), 2)
+
+	rrule.Until = time.Date(2018, time.January, 15, 9, 0, 0, 0, time.UTC)
+	rrule.UntilFloating = true
+	assert.Equal(t, rfcAll(All(rebased.Iterator(), 0)), rfcAll(All(rrule.Rebase(NewYork()).Iterator(), 0)))
+
+	assert.Equal(t, RRule{Frequency: Daily, Until: rrule.Until}, RRule{Frequency: Daily, Until: rrule.Until}.Rebase(tokyo))
+}
@@ -3,15 +3,16 @@ package rrule
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/spf13/cast"
-	"log"
 	"strconv"
 )
 
 // Frequency defines a set of constants for a base factor for how often recurrences happen.
 type Frequency int
 
-// String returns the RFC 5545 string for supported frequencies, and panics otherwise.
+// String returns the RFC 5545 name of the frequency, like "WEEKLY", or
+// UNKNOWN(n) if it isn't one of the constants.
 func (f Frequency) String() string {
 	switch f {
 	case Secondly:
@@ -29,8 +30,7 @@ func (f Frequency) String() string {
 	case Yearly:
 		return "YEARLY"
 	}
-	log.Panicf("%d is not a supported frequency constant", f)
-	return ""
+	return fmt.Sprintf("UNKNOWN(%d)", int(f))
 }
 
 // Frequencies specified in RFC 5545.
@@ -44,22 +44,53 @@ const (
 	Yearly
 )
 
+// ParseFrequency parses the RFC 5545 name of a frequency, like "WEEKLY", in
+// any case.
+func ParseFrequency(str string) (Frequency, error) {
+	return strToFreq(str)
+}
+
+// MarshalText returns the RFC 5545 name of the frequency, or an error if it
+// isn't one of the constants.
+func (f Frequency) MarshalText() ([]byte, error) {
+	if f < Secondly || f > Yearly {
+		return nil, kindErrorf(ErrUnsupportedFrequency, "invalid frequency %d", int(f))
+	}
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText parses the frequency as ParseFrequency does.
+func (f *Frequency) UnmarshalText(text []byte) error {
+	parsed, err := ParseFrequency(string(text))
+	if err != nil {
+		return err
+	}
+	*f = parsed
+	return nil
+}
+
+// MarshalJSON encodes the frequency as its number, as it always has been.
 func (f Frequency) MarshalJSON() ([]byte, error) {
 	return json.Marshal(int(f))
 }
 
+// UnmarshalJSON decodes the frequency from its number, or from a string
+// holding its number or RFC 5545 name.
 func (d *Frequency) UnmarshalJSON(b []byte) error {
 	var v interface{}
 	if err := json.Unmarshal(b, &v); err != nil {
 		return err
 	}
 	switch value := v.(type) {
-	case int,int32,float64,float32,int64:
+	case int, int32, float64, float32, int64:
 		*d = Frequency(cast.ToInt(value))
 		return nil
 	case string:
-		var err error
-		i, err := strconv.Atoi(v.(string))
+		if f, err := ParseFrequency(value); err == nil {
+			*d = f
+			return nil
+		}
+		i, err := strconv.Atoi(value)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,46 @@
+package rrule
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// FromGoogleRecurrence builds a recurrence from the recurrence field of a
+// Google Calendar API event, which holds RRULE, EXRULE, RDATE and EXDATE lines
+// but no DTSTART, and the start of the event, which becomes Dtstart. Times in
+// the lines without a time zone are in start's location.
+func FromGoogleRecurrence(recurrence []string, start time.Time) (*Recurrence, error) {
+	r, err := ParseRecurrence([]byte(strings.Join(recurrence, "\n")), start.Location())
+	if err != nil {
+		return nil, err
+	}
+	if !r.Dtstart.IsZero() {
+		return nil, errors.New("Google Calendar recurrence must not include DTSTART; it is the event's start")
+	}
+
+	r.Dtstart = start
+	r.setDtstart()
+	return r, nil
+}
+
+// ToGoogleRecurrence returns the recurrence field of a Google Calendar API
+// event for the recurrence. Dtstart is not included, since it is the event's
+// start, and UNTIL is written in UTC unless it is floating, as Google
+// requires along with RFC 5545.
+func (r *Recurrence) ToGoogleRecurrence() []string {
+	var lines []string
+	for _, rrule := range r.RRules {
+		lines = append(lines, "RRULE:"+rrule.String())
+	}
+	for _, exrule := range r.ExRules {
+		lines = append(lines, "EXRULE:"+exrule.String())
+	}
+	for _, rdate := range r.RDates {
+		lines = append(lines, formatTime("RDATE", rdate, r.FloatingLocation))
+	}
+	for _, exdate := range r.ExDates {
+		lines = append(lines, formatTime("EXDATE", exdate, r.FloatingLocation))
+	}
+	return lines
+}
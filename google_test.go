@@ -0,0 +1,45 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleRecurrence(t *testing.T) {
+	start := time.Date(2011, time.June, 3, 10, 0, 0, 0, NewYork())
+	recurrence := []string{
+		"RRULE:FREQ=WEEKLY;UNTIL=20110701T170000Z",
+		"EXDATE;TZID=America/New_York:20110610T100000",
+		"RDATE:20110620T100000",
+	}
+
+	r, err := FromGoogleRecurrence(recurrence, start)
+	require.NoError(t, err)
+	assert.Equal(t, start, r.Dtstart)
+	assert.Equal(t, start, r.RRules[0].Dtstart)
+	assert.Equal(t, []string{
+		"2011-06-03T10:00:00-04:00",
+		"2011-06-17T10:00:00-04:00",
+		"2011-06-20T10:00:00-04:00",
+		"2011-06-24T10:00:00-04:00",
+		"2011-07-01T10:00:00-04:00",
+	}, rfcAll(All(r.Iterator(), 0)))
+
+	assert.Equal(t, []string{
+		"RRULE:FREQ=WEEKLY;UNTIL=20110701T170000Z",
+		"RDATE;TZID=America/New_York:20110620T100000",
+		"EXDATE;TZID=America/New_York:20110610T100000",
+	}, r.ToGoogleRecurrence())
+
+	r.RRules[0].Until = r.RRules[0].Until.In(NewYork())
+	assert.Equal(t, "RRULE:FREQ=WEEKLY;UNTIL=20110701T170000Z", r.ToGoogleRecurrence()[0])
+
+	_, err = FromGoogleRecurrence([]string{"DTSTART:20110603T100000Z", "RRULE:FREQ=DAILY"}, start)
+	assert.Error(t, err)
+
+	_, err = FromGoogleRecurrence([]string{"RRULE:FREQ=FORTNIGHTLY"}, start)
+	assert.Error(t, err)
+}
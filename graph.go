@@ -0,0 +1,187 @@
+package rrule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GraphRecurrence is a Microsoft Graph patternedRecurrence: how an event
+// repeats, and for how long. The time of day is not included; it is that of
+// the event's start.
+type GraphRecurrence struct {
+	Pattern GraphPattern `json:"pattern"`
+	Range   GraphRange   `json:"range"`
+}
+
+// GraphPattern is a Microsoft Graph recurrencePattern.
+type GraphPattern struct {
+	// Type is daily, weekly, absoluteMonthly, relativeMonthly,
+	// absoluteYearly or relativeYearly.
+	Type     string `json:"type"`
+	Interval int    `json:"interval"`
+
+	Month      int `json:"month,omitempty"`
+	DayOfMonth int `json:"dayOfMonth,omitempty"`
+
+	// DaysOfWeek and FirstDayOfWeek hold lower case English weekday names,
+	// like monday.
+	DaysOfWeek     []string `json:"daysOfWeek,omitempty"`
+	FirstDayOfWeek string   `json:"firstDayOfWeek,omitempty"`
+
+	// Index is first, second, third, fourth or last, picking which of the
+	// DaysOfWeek in the month is used by relative patterns.
+	Index string `json:"index,omitempty"`
+}
+
+// GraphRange is a Microsoft Graph recurrenceRange.
+type GraphRange struct {
+	// Type is endDate, noEnd or numbered.
+	Type string `json:"type"`
+
+	// StartDate and EndDate are dates, like 2017-09-04. EndDate is
+	// inclusive.
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate,omitempty"`
+
+	RecurrenceTimeZone  string `json:"recurrenceTimeZone,omitempty"`
+	NumberOfOccurrences int    `json:"numberOfOccurrences,omitempty"`
+}
+
+// graphIndexes are the values of GraphPattern.Index, by Pattern.Index.
+var graphIndexes = map[int]string{1: "first", 2: "second", 3: "third", 4: "fourth", -1: "last"}
+
+// ToGraph returns the Microsoft Graph recurrence of the pattern, or a
+// *PatternError if it can't be expressed as a Pattern.
+func (rrule RRule) ToGraph() (GraphRecurrence, error) {
+	p, err := rrule.Pattern()
+	if err != nil {
+		return GraphRecurrence{}, err
+	}
+
+	pattern := GraphPattern{
+		Type:       p.Kind.String(),
+		Interval:   p.Interval,
+		Month:      int(p.Month),
+		DayOfMonth: p.DayOfMonth,
+		Index:      graphIndexes[p.Index],
+	}
+	for _, wd := range p.Weekdays {
+		pattern.DaysOfWeek = append(pattern.DaysOfWeek, graphWeekday(wd))
+	}
+	if p.Kind == WeeklyPattern {
+		pattern.FirstDayOfWeek = graphWeekday(p.FirstDayOfWeek)
+	}
+
+	rng := GraphRange{
+		Type:                p.End.String(),
+		StartDate:           p.Start.Format(recurDate),
+		NumberOfOccurrences: p.Occurrences,
+	}
+	if p.End == EndByDate {
+		rng.EndDate = p.EndDate.Format(recurDate)
+	}
+	if loc := p.Start.Location(); loc != time.UTC && loc != time.Local {
+		rng.RecurrenceTimeZone = loc.String()
+	}
+
+	return GraphRecurrence{Pattern: pattern, Range: rng}, nil
+}
+
+// FromGraph returns the pattern of a Microsoft Graph recurrence of an event
+// that starts at start. Dtstart is the range's start date, at start's time of
+// day and in its location, which is also used for the end date.
+func FromGraph(rec GraphRecurrence, start time.Time) (RRule, error) {
+	p, err := rec.pattern(start)
+	if err != nil {
+		return RRule{}, err
+	}
+	return p.RRule()
+}
+
+// pattern returns the recurrence as a Pattern of an event that starts at
+// start, as FromGraph describes.
+func (rec GraphRecurrence) pattern(start time.Time) (Pattern, error) {
+	gp, rng := rec.Pattern, rec.Range
+	p := Pattern{Start: start, Interval: gp.Interval, DayOfMonth: gp.DayOfMonth, Month: time.Month(gp.Month)}
+
+	if rng.StartDate != "" {
+		date, err := time.ParseInLocation(recurDate, rng.StartDate, start.Location())
+		if err != nil {
+			return Pattern{}, fmt.Errorf("Graph range startDate: %v", err)
+		}
+		p.Start = time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	}
+
+	for _, name := range gp.DaysOfWeek {
+		wd, err := parseGraphWeekday(name)
+		if err != nil {
+			return Pattern{}, err
+		}
+		p.Weekdays = append(p.Weekdays, wd)
+	}
+
+	if gp.FirstDayOfWeek != "" {
+		var err error
+		if p.FirstDayOfWeek, err = parseGraphWeekday(gp.FirstDayOfWeek); err != nil {
+			return Pattern{}, err
+		}
+	}
+
+	p.Kind = -1
+	for kind := DailyPattern; kind <= RelativeYearlyPattern; kind++ {
+		if gp.Type == kind.String() {
+			p.Kind = kind
+		}
+	}
+	if p.Kind < 0 {
+		return Pattern{}, fmt.Errorf("Graph pattern type %q is not supported", gp.Type)
+	}
+
+	if p.Kind == RelativeMonthlyPattern || p.Kind == RelativeYearlyPattern {
+		// Graph's index is first by default.
+		p.Index = 1
+		if gp.Index != "" {
+			p.Index = 0
+			for i, index := range graphIndexes {
+				if index == gp.Index {
+					p.Index = i
+				}
+			}
+			if p.Index == 0 {
+				return Pattern{}, fmt.Errorf("Graph index %q is not first, second, third, fourth or last", gp.Index)
+			}
+		}
+	}
+
+	switch rng.Type {
+	case "noEnd", "":
+	case "numbered":
+		p.End = EndAfterOccurrences
+		p.Occurrences = rng.NumberOfOccurrences
+	case "endDate":
+		p.End = EndByDate
+		date, err := time.ParseInLocation(recurDate, rng.EndDate, start.Location())
+		if err != nil {
+			return Pattern{}, fmt.Errorf("Graph range endDate: %v", err)
+		}
+		p.EndDate = date
+	default:
+		return Pattern{}, fmt.Errorf("Graph range type %q is not supported", rng.Type)
+	}
+
+	return p, nil
+}
+
+func graphWeekday(wd time.Weekday) string {
+	return strings.ToLower(wd.String())
+}
+
+func parseGraphWeekday(name string) (time.Weekday, error) {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.EqualFold(name, wd.String()) {
+			return wd, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("Graph day of week %q is not supported", name)
+}
@@ -0,0 +1,124 @@
+package rrule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph(t *testing.T) {
+	start := time.Date(2017, time.September, 4, 12, 0, 0, 0, NewYork()) // a Monday
+
+	for _, tc := range []struct {
+		Name  string
+		RRule RRule
+		JSON  string
+	}{
+		{
+			Name:  "daily",
+			RRule: RRule{Frequency: Daily, Interval: 2, Count: 5},
+			JSON:  `{"pattern": {"type": "daily", "interval": 2}, "range": {"type": "numbered", "startDate": "2017-09-04", "numberOfOccurrences": 5, "recurrenceTimeZone": "America/New_York"}}`,
+		},
+		{
+			Name:  "weekly",
+			RRule: RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Thursday}}, Until: time.Date(2017, time.December, 31, 23, 59, 59, 0, NewYork())},
+			JSON:  `{"pattern": {"type": "weekly", "interval": 1, "daysOfWeek": ["monday", "thursday"], "firstDayOfWeek": "monday"}, "range": {"type": "endDate", "startDate": "2017-09-04", "endDate": "2017-12-31", "recurrenceTimeZone": "America/New_York"}}`,
+		},
+		{
+			Name:  "absolute monthly",
+			RRule: RRule{Frequency: Monthly, ByMonthDays: []int{15}, Count: 4},
+			JSON:  `{"pattern": {"type": "absoluteMonthly", "interval": 1, "dayOfMonth": 15}, "range": {"type": "numbered", "startDate": "2017-09-04", "numberOfOccurrences": 4, "recurrenceTimeZone": "America/New_York"}}`,
+		},
+		{
+			Name:  "relative monthly",
+			RRule: RRule{Frequency: Monthly, ByWeekdays: []QualifiedWeekday{{N: -1, WD: time.Friday}}, Count: 4},
+			JSON:  `{"pattern": {"type": "relativeMonthly", "interval": 1, "daysOfWeek": ["friday"], "index": "last"}, "range": {"type": "numbered", "startDate": "2017-09-04", "numberOfOccurrences": 4, "recurrenceTimeZone": "America/New_York"}}`,
+		},
+		{
+			Name:  "relative monthly set",
+			RRule: RRule{Frequency: Monthly, ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}, {WD: time.Sunday}}, BySetPos: []int{1}, Count: 4},
+			JSON:  `{"pattern": {"type": "relativeMonthly", "interval": 1, "daysOfWeek": ["saturday", "sunday"], "index": "first"}, "range": {"type": "numbered", "startDate": "2017-09-04", "numberOfOccurrences": 4, "recurrenceTimeZone": "America/New_York"}}`,
+		},
+		{
+			Name:  "absolute yearly",
+			RRule: RRule{Frequency: Yearly, ByMonths: []time.Month{time.September}, ByMonthDays: []int{4}, Count: 3},
+			JSON:  `{"pattern": {"type": "absoluteYearly", "interval": 1, "month": 9, "dayOfMonth": 4}, "range": {"type": "numbered", "startDate": "2017-09-04", "numberOfOccurrences": 3, "recurrenceTimeZone": "America/New_York"}}`,
+		},
+		{
+			Name:  "relative yearly",
+			RRule: RRule{Frequency: Yearly, ByMonths: []time.Month{time.September}, ByWeekdays: []QualifiedWeekday{{N: 1, WD: time.Monday}}, Count: 3},
+			JSON:  `{"pattern": {"type": "relativeYearly", "interval": 1, "month": 9, "daysOfWeek": ["monday"], "index": "first"}, "range": {"type": "numbered", "startDate": "2017-09-04", "numberOfOccurrences": 3, "recurrenceTimeZone": "America/New_York"}}`,
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			rrule := tc.RRule
+			rrule.Dtstart = start
+
+			rec, err := rrule.ToGraph()
+			require.NoError(t, err)
+			b, err := json.Marshal(rec)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.JSON, string(b))
+
+			var decoded GraphRecurrence
+			require.NoError(t, json.Unmarshal([]byte(tc.JSON), &decoded))
+			converted, err := FromGraph(decoded, start)
+			require.NoError(t, err)
+			assert.Equal(t, rfcAll(All(rrule.Iterator(), 10)), rfcAll(All(converted.Iterator(), 10)))
+		})
+	}
+}
+
+func TestFromGraph(t *testing.T) {
+	start := time.Date(2017, time.September, 4, 12, 0, 0, 0, time.UTC)
+
+	rrule, err := FromGraph(GraphRecurrence{
+		Pattern: GraphPattern{Type: "weekly", Interval: 2, DaysOfWeek: []string{"monday", "wednesday"}},
+		Range:   GraphRange{Type: "endDate", StartDate: "2017-09-06", EndDate: "2017-09-20"},
+	}, start)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;UNTIL=20170920T235959Z;INTERVAL=2;BYDAY=MO,WE;WKST=SU", rrule.String())
+	assert.Equal(t, []string{
+		"2017-09-06T12:00:00Z",
+		"2017-09-18T12:00:00Z",
+		"2017-09-20T12:00:00Z",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	for name, rec := range map[string]GraphRecurrence{
+		"pattern type": {Pattern: GraphPattern{Type: "hourly"}},
+		"day of week":  {Pattern: GraphPattern{Type: "weekly", DaysOfWeek: []string{"caturday"}}},
+		"day of month": {Pattern: GraphPattern{Type: "absoluteMonthly", DayOfMonth: 32}},
+		"month":        {Pattern: GraphPattern{Type: "absoluteYearly", DayOfMonth: 1}},
+		"index":        {Pattern: GraphPattern{Type: "relativeMonthly", DaysOfWeek: []string{"monday"}, Index: "fifth"}},
+		"no days":      {Pattern: GraphPattern{Type: "relativeMonthly"}},
+		"range type":   {Pattern: GraphPattern{Type: "daily"}, Range: GraphRange{Type: "forever"}},
+		"occurrences":  {Pattern: GraphPattern{Type: "daily"}, Range: GraphRange{Type: "numbered"}},
+		"end date":     {Pattern: GraphPattern{Type: "daily"}, Range: GraphRange{Type: "endDate", EndDate: "soon"}},
+	} {
+		_, err := FromGraph(rec, start)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestToGraphErrors(t *testing.T) {
+	start := time.Date(2017, time.September, 4, 12, 0, 0, 0, time.UTC)
+
+	for name, rrule := range map[string]RRule{
+		"dtstart":       {Frequency: Daily},
+		"hourly":        {Frequency: Hourly, Dtstart: start},
+		"byhour":        {Frequency: Daily, Dtstart: start, ByHours: []int{9, 17}},
+		"bymonth":       {Frequency: Yearly, Dtstart: start, ByMonths: []time.Month{time.June, time.September}},
+		"every monday":  {Frequency: Monthly, Dtstart: start, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}},
+		"fifth monday":  {Frequency: Monthly, Dtstart: start, ByWeekdays: []QualifiedWeekday{{N: 5, WD: time.Monday}}},
+		"last day":      {Frequency: Monthly, Dtstart: start, ByMonthDays: []int{-1}},
+		"bysetpos":      {Frequency: Monthly, Dtstart: start, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}, BySetPos: []int{1, 2}},
+		"rscale":        {Frequency: Monthly, Dtstart: start, RScale: Chinese},
+		"weekly months": {Frequency: Weekly, Dtstart: start, ByMonths: []time.Month{time.September}},
+	} {
+		_, err := rrule.ToGraph()
+		assert.Error(t, err, name)
+	}
+}
@@ -8,6 +8,10 @@ import (
 type groupIterator struct {
 	currentMin *int
 	iters      []Iterator
+
+	// index is the position of the next time in the series, or -1 if
+	// unknown.
+	index int
 }
 
 func groupIteratorFromRRules(rrules []RRule) *groupIterator {
@@ -17,7 +21,7 @@ func groupIteratorFromRRules(rrules []RRule) *groupIterator {
 		if iter == nil {
 			panic(fmt.Sprintf("rrule %q produced a nil iterator", rr))
 		}
-		if iter.(*iterator).next == nil {
+		if iter.(*iterator).steps == nil {
 			panic(fmt.Sprintf("rrule %q produced a faulty iterator", rr))
 		}
 
@@ -37,6 +41,10 @@ func (gi *groupIterator) Peek() *time.Time {
 
 	for i, iter := range gi.iters {
 		t := iter.Peek()
+		if t == nil && IteratorErr(iter) != nil {
+			// the iterator's times are unknown, so the group's are too.
+			return nil
+		}
 		if t != nil {
 			if min == nil {
 				min = t
@@ -73,5 +81,34 @@ func (gi *groupIterator) Next() *time.Time {
 
 	idx := *gi.currentMin
 	gi.currentMin = nil
+	if gi.index >= 0 {
+		gi.index++
+	}
 	return gi.iters[idx].Next()
 }
+
+func (gi *groupIterator) NextIndexed() (int, *time.Time) {
+	return nextIndexed(gi, gi.index)
+}
+
+func (gi *groupIterator) Seek(t time.Time) {
+	if next := gi.Peek(); next != nil && next.Before(t) {
+		gi.index = -1
+	}
+	gi.currentMin = nil
+	for _, iter := range gi.iters {
+		iter.Seek(t)
+	}
+}
+
+func (gi *groupIterator) Skip(n int) { skip(gi, n) }
+
+func (gi *groupIterator) Take(n int) []time.Time { return take(gi, n) }
+
+func (gi *groupIterator) Reset() {
+	gi.currentMin = nil
+	gi.index = 0
+	for _, iter := range gi.iters {
+		iter.Reset()
+	}
+}
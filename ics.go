@@ -0,0 +1,210 @@
+package rrule
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Property is a single content line of an iCalendar object, like
+//
+//	DTSTART;TZID=America/New_York:19970902T090000
+//
+// Names are always upper case. Values are left as they appear in the source.
+type Property struct {
+	Name   string
+	Params map[string][]string
+	Value  string
+}
+
+// Param returns the first value of the named parameter, or "" if it is not
+// present.
+func (p Property) Param(name string) string {
+	vals := p.Params[strings.ToUpper(name)]
+	if len(vals) == 0 {
+		return ""
+	}
+	return vals[0]
+}
+
+// String returns the property formatted as a content line, without folding.
+// Parameters are written in alphabetical order.
+func (p Property) String() string {
+	b := &strings.Builder{}
+	b.WriteString(p.Name)
+
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b.WriteString(";")
+		b.WriteString(name)
+		b.WriteString("=")
+		for i, v := range p.Params[name] {
+			if i != 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(quoteParamValue(v))
+		}
+	}
+
+	b.WriteString(":")
+	b.WriteString(p.Value)
+	return b.String()
+}
+
+// PropertyReader reads the properties of an iCalendar object, such as a .ics
+// file, one at a time. Folded lines are unfolded as described in RFC 5545,
+// section 3.1, and parameter values are unquoted and unescaped as described
+// in RFC 6868. BEGIN and END lines are returned as properties, too.
+type PropertyReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewPropertyReader returns a PropertyReader reading from r.
+func NewPropertyReader(r io.Reader) *PropertyReader {
+	return &PropertyReader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next property. At the end of the input, Read returns nil,
+// io.EOF.
+func (pr *PropertyReader) Read() (*Property, error) {
+	for {
+		line, err := pr.readUnfolded()
+		if line == "" {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		prop, perr := parseContentLine(line)
+		if perr != nil {
+			return nil, fmt.Errorf("line %d: %v", pr.line, perr)
+		}
+		return prop, nil
+	}
+}
+
+// readUnfolded reads the next logical line, joining any continuation lines.
+func (pr *PropertyReader) readUnfolded() (string, error) {
+	line, err := pr.readPhysical()
+	if err != nil {
+		return line, err
+	}
+
+	for {
+		next, perr := pr.r.Peek(1)
+		if perr != nil || (next[0] != ' ' && next[0] != '\t') {
+			return line, nil
+		}
+
+		// drop the leading whitespace, which is part of the fold.
+		pr.r.ReadByte()
+		cont, err := pr.readPhysical()
+		line += cont
+		if err != nil {
+			return line, nil
+		}
+	}
+}
+
+func (pr *PropertyReader) readPhysical() (string, error) {
+	line, err := pr.r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	pr.line++
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// parseContentLine parses an unfolded content line.
+func parseContentLine(line string) (*Property, error) {
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return nil, fmt.Errorf("misformatted line %q", line)
+	}
+
+	prop := &Property{Name: strings.ToUpper(line[:end])}
+	rest := line[end:]
+
+	for rest[0] == ';' {
+		rest = rest[1:]
+
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("misformatted parameter in %q", line)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var values []string
+		for {
+			var value string
+			if strings.HasPrefix(rest, `"`) {
+				closeIdx := strings.IndexByte(rest[1:], '"')
+				if closeIdx < 0 {
+					return nil, fmt.Errorf("unterminated quoted parameter value in %q", line)
+				}
+				value = rest[1 : closeIdx+1]
+				rest = rest[closeIdx+2:]
+			} else {
+				valEnd := strings.IndexAny(rest, ",;:")
+				if valEnd < 0 {
+					return nil, fmt.Errorf("misformatted line %q", line)
+				}
+				value = rest[:valEnd]
+				rest = rest[valEnd:]
+			}
+
+			values = append(values, unescapeParamValue(value))
+
+			if !strings.HasPrefix(rest, ",") {
+				break
+			}
+			rest = rest[1:]
+		}
+
+		if prop.Params == nil {
+			prop.Params = map[string][]string{}
+		}
+		prop.Params[name] = append(prop.Params[name], values...)
+
+		if rest == "" {
+			return nil, fmt.Errorf("misformatted line %q", line)
+		}
+	}
+
+	if rest[0] != ':' {
+		return nil, fmt.Errorf("misformatted line %q", line)
+	}
+	prop.Value = rest[1:]
+
+	return prop, nil
+}
+
+var (
+	paramUnescaper = strings.NewReplacer("^n", "\n", "^N", "\n", "^'", `"`, "^^", "^")
+	paramEscaper   = strings.NewReplacer("\n", "^n", `"`, "^'", "^", "^^")
+)
+
+func unescapeParamValue(v string) string {
+	if !strings.Contains(v, "^") {
+		return v
+	}
+	return paramUnescaper.Replace(v)
+}
+
+func quoteParamValue(v string) string {
+	v = paramEscaper.Replace(v)
+	if strings.ContainsAny(v, ",;:") {
+		return `"` + v + `"`
+	}
+	return v
+}
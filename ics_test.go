@@ -0,0 +1,63 @@
+package rrule
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropertyReader(t *testing.T) {
+	src := "BEGIN:VEVENT\r\n" +
+		"DTSTART;TZID=America/New_York:19970902T090000\r\n" +
+		"RRULE:FREQ=WEEKLY;\r\n" +
+		" BYDAY=MO,\r\n" +
+		"\tWE\r\n" +
+		"\r\n" +
+		"ATTENDEE;ROLE=REQ-PARTICIPANT;DELEGATED-FROM=\"mailto:a@example.com\",\"mailto:b@example.com\";CN=George ^'Bud^' Herman:mailto:c@example.com\r\n" +
+		"description:semi\\;colon\r\n" +
+		"END:VEVENT"
+
+	pr := NewPropertyReader(strings.NewReader(src))
+
+	var props []*Property
+	for {
+		prop, err := pr.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		props = append(props, prop)
+	}
+
+	require.Len(t, props, 6)
+	assert.Equal(t, &Property{Name: "BEGIN", Value: "VEVENT"}, props[0])
+	assert.Equal(t, "America/New_York", props[1].Param("tzid"))
+	assert.Equal(t, "19970902T090000", props[1].Value)
+	assert.Equal(t, "DTSTART;TZID=America/New_York:19970902T090000", props[1].String())
+	assert.Equal(t, &Property{Name: "RRULE", Value: "FREQ=WEEKLY;BYDAY=MO,WE"}, props[2])
+	assert.Equal(t, map[string][]string{
+		"ROLE":           {"REQ-PARTICIPANT"},
+		"DELEGATED-FROM": {"mailto:a@example.com", "mailto:b@example.com"},
+		"CN":             {`George "Bud" Herman`},
+	}, props[3].Params)
+	assert.Equal(t, "mailto:c@example.com", props[3].Value)
+	assert.Equal(t, `ATTENDEE;CN=George ^'Bud^' Herman;DELEGATED-FROM="mailto:a@example.com","mailto:b@example.com";ROLE=REQ-PARTICIPANT:mailto:c@example.com`, props[3].String())
+	assert.Equal(t, &Property{Name: "DESCRIPTION", Value: `semi\;colon`}, props[4])
+	assert.Equal(t, &Property{Name: "END", Value: "VEVENT"}, props[5])
+}
+
+func TestPropertyReaderErrors(t *testing.T) {
+	for _, src := range []string{
+		"NOVALUE\n",
+		";X=Y:VALUE\n",
+		"PROP;PARAM:VALUE\n",
+		"PROP;PARAM=\"unterminated:VALUE\n",
+		"PROP;PARAM=VALUE\n",
+	} {
+		_, err := NewPropertyReader(strings.NewReader(src)).Read()
+		assert.Error(t, err, src)
+	}
+}
@@ -1,5 +1,10 @@
 package rrule
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // InvalidBehavior specifies how to behave when a pattern generates a date that
 // wouldn't exist, like February 31st.
 type InvalidBehavior int
@@ -17,3 +22,57 @@ const (
 	// the result would be February 28th (or 29th on a leap year).
 	PrevInvalid
 )
+
+// String returns the RFC 7529 SKIP value of the behavior: OMIT, FORWARD or
+// BACKWARD. Other values are written like InvalidBehavior(7).
+func (ib InvalidBehavior) String() string {
+	if str := skipString(ib); str != "" {
+		return str
+	}
+	return fmt.Sprintf("InvalidBehavior(%d)", int(ib))
+}
+
+// ParseInvalidBehavior parses an RFC 7529 SKIP value, like "BACKWARD", in any
+// case.
+func ParseInvalidBehavior(str string) (InvalidBehavior, error) {
+	return parseSkip(str)
+}
+
+// MarshalText returns the SKIP value of the behavior, as String does.
+func (ib InvalidBehavior) MarshalText() ([]byte, error) {
+	str := skipString(ib)
+	if str == "" {
+		return nil, fmt.Errorf("skip value %d is not valid", int(ib))
+	}
+	return []byte(str), nil
+}
+
+// UnmarshalText parses the behavior as ParseInvalidBehavior does.
+func (ib *InvalidBehavior) UnmarshalText(text []byte) error {
+	parsed, err := ParseInvalidBehavior(string(text))
+	if err != nil {
+		return err
+	}
+	*ib = parsed
+	return nil
+}
+
+// MarshalJSON encodes the behavior as its number, as it always has been,
+// rather than as MarshalText does.
+func (ib InvalidBehavior) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(ib))
+}
+
+// UnmarshalJSON decodes the behavior from its number, or from a string
+// holding its number or SKIP value, like "FORWARD".
+func (ib *InvalidBehavior) UnmarshalJSON(b []byte) error {
+	n, err := jsonEnum(b, func(s string) (int, error) {
+		parsed, err := ParseInvalidBehavior(s)
+		return int(parsed), err
+	})
+	if err != nil {
+		return err
+	}
+	*ib = InvalidBehavior(n)
+	return nil
+}
@@ -0,0 +1,59 @@
+package rrule
+
+// islamicCivil is the tabular Islamic calendar with the civil (Friday) epoch,
+// July 16th, 622 in the Julian calendar. Odd months have 30 days and even
+// months 29, except that the last month has 30 days in 11 of every 30 years.
+type islamicCivil struct{}
+
+// islamicCivilEpoch is the Julian day number of 1 Muharram 1 AH.
+const islamicCivilEpoch = 1948440
+
+var islamicMonths = []calendarMonth{
+	{number: 1}, {number: 2}, {number: 3}, {number: 4}, {number: 5}, {number: 6},
+	{number: 7}, {number: 8}, {number: 9}, {number: 10}, {number: 11}, {number: 12},
+}
+
+func (islamicCivil) months(year int) []calendarMonth {
+	return islamicMonths
+}
+
+func (islamicCivil) monthStart(year int, month calendarMonth) int {
+	return islamicCivilEpoch +
+		(59*(month.number-1)+1)/2 +
+		(year-1)*354 +
+		floorDiv(3+11*year, 30)
+}
+
+func (c islamicCivil) monthLength(year int, month calendarMonth) int {
+	if month.number == 12 {
+		return c.monthStart(year+1, islamicMonths[0]) - c.monthStart(year, month)
+	}
+	return 30 - (month.number+1)%2
+}
+
+func (c islamicCivil) date(jdn int) calendarDate {
+	year := floorDiv(30*(jdn-islamicCivilEpoch)+10646, 10631)
+	for jdn < c.monthStart(year, islamicMonths[0]) {
+		year--
+	}
+	for jdn >= c.monthStart(year+1, islamicMonths[0]) {
+		year++
+	}
+
+	month := 1
+	for month < 12 && jdn >= c.monthStart(year, calendarMonth{number: month + 1}) {
+		month++
+	}
+
+	start := c.monthStart(year, calendarMonth{number: month})
+	return calendarDate{year: year, month: calendarMonth{number: month}, day: jdn - start + 1}
+}
+
+// floorDiv divides a by b, rounding toward negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -4,7 +4,9 @@ import (
 	"time"
 )
 
-// Iterator scans over a series of times.
+// Iterator scans over a series of times. Iterators hold state and are not safe
+// for concurrent use; see RRule.Expand and Recurrence.Expand for a stateless
+// alternative.
 type Iterator interface {
 	// Peek returns the next time without advancing the iterator, or nil if
 	// the iterator has ended.
@@ -13,6 +15,26 @@ type Iterator interface {
 	// Next returns the next time and advances the iterator. Nil is
 	// returned if the iterator has ended.
 	Next() *time.Time
+
+	// Seek advances the iterator so that the next time returned is the first
+	// one at or after t. Seeking backward has no effect.
+	Seek(t time.Time)
+
+	// Skip discards the next n times.
+	Skip(n int)
+
+	// Take returns the next n times and advances the iterator past them. Fewer
+	// than n times are returned if the iterator ends.
+	Take(n int) []time.Time
+
+	// Reset rewinds the iterator to its first time.
+	Reset()
+
+	// NextIndexed is like Next, but also returns the 0-based position of the
+	// time within the whole series. The position is -1 if the iterator has
+	// ended, or if it is no longer known because Seek jumped over
+	// occurrences without counting them.
+	NextIndexed() (int, *time.Time)
 }
 
 type iterator struct {
@@ -20,20 +42,65 @@ type iterator struct {
 	totalQueued uint64
 	queueCap    uint64
 	minTime     time.Time
-	maxTime     time.Time
+	maxTime     time.Time // the last time allowed, Until or absoluteMaxTime
 	pastMaxTime bool
 
-	// next finds the next key time.
-	next func() *time.Time
+	// untilExclusive excludes maxTime itself.
+	untilExclusive bool
+
+	// bound, if set by withBound, ends the search for times once a key
+	// time is after it. pastBound is set once one is.
+	bound     time.Time
+	pastBound bool
+
+	// steps generates the key times and their occurrences. An iterator
+	// without steps has no times beyond its queue.
+	steps stepper
+
+	// index is the position of the next time in the series, or -1 if
+	// unknown.
+	index int
+
+	// size estimates the number of times in the whole series, or is 0 if
+	// there's no telling.
+	size int
+
+	// budget limits the key times examined looking for each time. Once
+	// it's exceeded, budgetErr ends the iterator.
+	budget    Budget
+	budgetErr error
+}
+
+// stepper generates the key times of a pattern, like the first of each month
+// of a MONTHLY pattern, and the occurrences for each of them. Each kind of
+// pattern has its own.
+type stepper interface {
+	// next returns the next key time, or false if there are no more.
+	next() (time.Time, bool)
 
-	// variations returns all the possible variations
-	// of the key time t
-	variations func(t *time.Time) []time.Time
+	// valid reports whether the key time t can have occurrences.
+	valid(t time.Time) bool
 
-	// valid determines if a particular key time is a valid recurrence.
-	valid func(t *time.Time) bool
+	// variations returns the occurrences for the key time t, in order. The
+	// returned slice may be reused by the next call.
+	variations(t time.Time) []time.Time
 
-	setpos []int
+	// reset rewinds the key time back to the start.
+	reset()
+}
+
+// fastForwarder is a stepper that can move its key time forward by a whole
+// number of intervals, stopping early enough that no occurrence at or after t
+// is skipped. fastForward reports whether the key time moved.
+type fastForwarder interface {
+	fastForward(t time.Time) bool
+}
+
+// jumper is a stepper that can move its key time to the first occurrence at or
+// after t. jump returns how many occurrences it passed over, so unlike
+// fastForward, it's safe with COUNT.
+type jumper interface {
+	jump(t time.Time) int
 }
 
 func (i *iterator) Next() *time.Time {
@@ -43,9 +110,64 @@ func (i *iterator) Next() *time.Time {
 	} else if len(i.queue) == 1 {
 		i.queue = nil
 	}
+	if t != nil && i.index >= 0 {
+		i.index++
+	}
 	return t
 }
 
+func (i *iterator) NextIndexed() (int, *time.Time) {
+	return nextIndexed(i, i.index)
+}
+
+func (i *iterator) Skip(n int) { skip(i, n) }
+
+func (i *iterator) Take(n int) []time.Time { return take(i, n) }
+
+func (i *iterator) Reset() {
+	i.queue = nil
+	i.totalQueued = 0
+	i.pastMaxTime = false
+	i.pastBound = false
+	i.budgetErr = nil
+	i.index = 0
+	if i.steps != nil {
+		i.steps.reset()
+	}
+}
+
+func (i *iterator) Seek(t time.Time) {
+	for len(i.queue) > 0 && i.queue[0].Before(t) {
+		i.Next()
+	}
+	if len(i.queue) > 0 {
+		return
+	}
+
+	if j, ok := i.steps.(jumper); ok && !i.pastMaxTime {
+		n := j.jump(t)
+		i.totalQueued += uint64(n)
+		if i.index >= 0 {
+			i.index += n
+		}
+	} else if ff, ok := i.steps.(fastForwarder); ok && i.queueCap == 0 && !i.pastMaxTime {
+		// jumping ahead is only safe when we don't need to know how many
+		// occurrences were passed over.
+		if ff.fastForward(t) {
+			// the occurrences jumped over were never counted.
+			i.index = -1
+		}
+	}
+
+	for {
+		next := i.Peek()
+		if next == nil || !next.Before(t) {
+			return
+		}
+		i.Next()
+	}
+}
+
 func (i *iterator) Peek() *time.Time {
 	if len(i.queue) > 0 {
 		r := i.queue[0]
@@ -58,25 +180,42 @@ func (i *iterator) Peek() *time.Time {
 		}
 	}
 
-	if i.next == nil {
+	if i.steps == nil || i.budgetErr != nil {
 		return nil
 	}
 
-	for {
-		if i.pastMaxTime {
+	var started time.Time
+	if i.budget.MaxDuration > 0 {
+		started = time.Now()
+	}
+
+	for examined := 0; ; examined++ {
+		if i.pastMaxTime || i.pastBound {
 			return nil
 		}
 
-		key := i.next()
-		if key == nil {
+		if i.budget != (Budget{}) {
+			if i.budgetErr = i.budget.spend(examined, started); i.budgetErr != nil {
+				return nil
+			}
+		}
+
+		key, ok := i.steps.next()
+		if !ok {
 			return nil
 		}
 
-		if !i.valid(key) {
+		// each key time is in its own period, and the periods are in
+		// order, so no key after this one has times before the bound.
+		if !i.bound.IsZero() && key.After(i.bound) {
+			i.pastBound = true
+		}
+
+		if !i.steps.valid(key) {
 			continue
 		}
 
-		variations := i.variations(key)
+		variations := i.steps.variations(key)
 
 		// remove any variations before the min time
 		for len(variations) > 0 && variations[0].Before(i.minTime) {
@@ -86,7 +225,7 @@ func (i *iterator) Peek() *time.Time {
 		// remove any variations after the max time
 		if !i.maxTime.IsZero() {
 			for idx, v := range variations {
-				if v.After(i.maxTime) {
+				if v.After(i.maxTime) || i.untilExclusive && v.Equal(i.maxTime) {
 					variations = variations[:idx]
 					i.pastMaxTime = true
 					break
@@ -107,9 +246,143 @@ func (i *iterator) Peek() *time.Time {
 
 		i.totalQueued += uint64(len(variations))
 
-		i.queue = variations[:]
-		return &variations[0]
+		// variations may reuse its buffer for the next key time, so
+		// only a copy is handed out.
+		i.queue = variations
+		r := variations[0]
+		return &r
+	}
+}
+
+// InLocation returns an iterator yielding the times of it in loc. The
+// instants are unchanged, and it still generates them in its own location, so
+// BYSETPOS, UNTIL and daylight saving time are applied there.
+func InLocation(it Iterator, loc *time.Location) Iterator {
+	return &locationIterator{it: it, loc: loc}
+}
+
+type locationIterator struct {
+	it  Iterator
+	loc *time.Location
+}
+
+func (li *locationIterator) in(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	in := t.In(li.loc)
+	return &in
+}
+
+func (li *locationIterator) Peek() *time.Time { return li.in(li.it.Peek()) }
+
+func (li *locationIterator) Next() *time.Time { return li.in(li.it.Next()) }
+
+func (li *locationIterator) NextIndexed() (int, *time.Time) {
+	idx, t := li.it.NextIndexed()
+	return idx, li.in(t)
+}
+
+func (li *locationIterator) Seek(t time.Time) { li.it.Seek(t) }
+
+func (li *locationIterator) Skip(n int) { li.it.Skip(n) }
+
+func (li *locationIterator) Take(n int) []time.Time {
+	tt := li.it.Take(n)
+	for i, t := range tt {
+		tt[i] = t.In(li.loc)
+	}
+	return tt
+}
+
+func (li *locationIterator) Reset() { li.it.Reset() }
+
+func nextIndexed(it Iterator, index int) (int, *time.Time) {
+	t := it.Next()
+	if t == nil {
+		return -1, nil
+	}
+	return index, t
+}
+
+func skip(it Iterator, n int) {
+	for ; n > 0; n-- {
+		if it.Next() == nil {
+			return
+		}
+	}
+}
+
+func take(it Iterator, n int) []time.Time {
+	if n <= 0 {
+		return nil
+	}
+
+	tt := make([]time.Time, 0, n)
+	for len(tt) < n {
+		next := it.Next()
+		if next == nil {
+			break
+		}
+		tt = append(tt, *next)
+	}
+	return tt
+}
+
+// forwardDuration returns current advanced by a whole number of steps, leaving
+// it at least one step short of t.
+func forwardDuration(current, t time.Time, step time.Duration) (time.Time, bool) {
+	n := t.Sub(current) / step
+	if n < 2 {
+		return current, false
+	}
+	return current.Add((n - 1) * step), true
+}
+
+// nextMonthIn returns the start of the first month after t that is in months,
+// in t's location. ok is false if t's own month is in months, or months is
+// empty.
+func nextMonthIn(t time.Time, months []time.Month) (month time.Time, ok bool) {
+	if len(months) == 0 || hasMonth(months, t.Month()) {
+		return t, false
 	}
+
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	for i := 1; i < 12; i++ {
+		if month = first.AddDate(0, i, 0); hasMonth(months, month.Month()) {
+			return month, true
+		}
+	}
+	return t, false
+}
+
+func hasMonth(months []time.Month, m time.Month) bool {
+	for _, month := range months {
+		if month == m {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardDays returns current advanced by a whole number of steps of the given
+// number of days, leaving it at least one step short of t.
+func forwardDays(current, t time.Time, days int) (time.Time, bool) {
+	n := daysBetween(current, t) / days
+	if n < 2 {
+		return current, false
+	}
+	return current.AddDate(0, 0, (n-1)*days), true
+}
+
+// daysBetween returns the number of calendar days from a to b, as observed in
+// the location of a.
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.In(a.Location()).Date()
+	ua := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	ub := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int((ub.Unix() - ua.Unix()) / (24 * 60 * 60))
 }
 
 // https://stackoverflow.com/questions/25065055/what-is-the-maximum-time-time-in-go
@@ -0,0 +1,243 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var seekCases = []struct {
+	Name  string
+	RRule RRule
+	Seek  time.Time
+}{
+	{
+		Name:  "secondly",
+		RRule: RRule{Frequency: Secondly, Interval: 7, Dtstart: now},
+		Seek:  now.Add(36 * time.Hour),
+	},
+	{
+		Name:  "secondly by second",
+		RRule: RRule{Frequency: Secondly, BySeconds: []int{5, 40}, Dtstart: now},
+		Seek:  now.Add(36*time.Hour + 20*time.Second),
+	},
+	{
+		Name:  "minutely by second",
+		RRule: RRule{Frequency: Minutely, Interval: 13, BySeconds: []int{1, 59}, Dtstart: now},
+		Seek:  now.Add(240 * time.Hour),
+	},
+	{
+		Name:  "hourly by minute",
+		RRule: RRule{Frequency: Hourly, Interval: 5, ByMinutes: []int{0, 30}, Dtstart: now},
+		Seek:  now.AddDate(1, 2, 3),
+	},
+	{
+		Name:  "daily",
+		RRule: RRule{Frequency: Daily, Interval: 3, Dtstart: time.Date(1990, time.March, 4, 9, 0, 0, 0, NewYork())},
+		Seek:  time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:  "weekly by weekday",
+		RRule: RRule{Frequency: Weekly, Interval: 2, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Friday}}, Dtstart: time.Date(1990, time.March, 4, 9, 0, 0, 0, NewYork())},
+		Seek:  time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:  "monthly by weekday",
+		RRule: RRule{Frequency: Monthly, Interval: 5, ByWeekdays: []QualifiedWeekday{{N: -1, WD: time.Tuesday}}, Dtstart: time.Date(1990, time.March, 4, 9, 0, 0, 0, NewYork())},
+		Seek:  time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:  "monthly end of month",
+		RRule: RRule{Frequency: Monthly, Interval: 2, Dtstart: time.Date(1990, time.March, 31, 9, 0, 0, 0, time.UTC)},
+		Seek:  time.Date(2000, time.June, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:  "yearly by week number",
+		RRule: RRule{Frequency: Yearly, ByWeekNumbers: []int{1, 52}, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}, Dtstart: time.Date(1990, time.March, 4, 9, 0, 0, 0, time.UTC)},
+		Seek:  time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:  "daily by weekday",
+		RRule: RRule{Frequency: Daily, ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}, {WD: time.Sunday}}, ByMonths: []time.Month{time.May}, Dtstart: time.Date(1990, time.March, 4, 9, 0, 0, 0, time.UTC)},
+		Seek:  time.Date(2000, time.May, 17, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:  "yearly counted",
+		RRule: RRule{Frequency: Yearly, Count: 40, Dtstart: time.Date(1990, time.March, 4, 9, 0, 0, 0, time.UTC)},
+		Seek:  time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:  "hourly counted",
+		RRule: RRule{Frequency: Hourly, Interval: 5, Count: 100000, Dtstart: time.Date(1990, time.March, 4, 9, 0, 0, 0, NewYork())},
+		Seek:  time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:  "weekly until",
+		RRule: RRule{Frequency: Weekly, Until: time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC), Dtstart: time.Date(1990, time.March, 4, 9, 0, 0, 0, NewYork())},
+		Seek:  time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+func TestIteratorSeek(t *testing.T) {
+	for _, tc := range seekCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			expected := tc.RRule.Iterator()
+			for {
+				next := expected.Peek()
+				require.NotNil(t, next)
+				if !next.Before(tc.Seek) {
+					break
+				}
+				expected.Next()
+			}
+
+			it := tc.RRule.Iterator()
+			it.Seek(tc.Seek)
+			assert.Equal(t, rfcAll(All(expected, 5)), rfcAll(All(it, 5)))
+		})
+	}
+}
+
+func TestIteratorSeekBackward(t *testing.T) {
+	it := RRule{Frequency: Daily, Dtstart: now}.Iterator()
+	it.Seek(now.AddDate(0, 0, 10))
+	it.Seek(now)
+	assert.Equal(t, now.AddDate(0, 0, 10), *it.Next())
+}
+
+func TestRecurrenceSeek(t *testing.T) {
+	r := recurrenceCases[2].Recurrence
+	it := r.Iterator()
+	it.Seek(time.Date(2018, time.September, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, []string{"2018-09-04T09:08:07Z", "2018-09-08T09:08:07Z"}, rfcAll(All(it, 0)))
+}
+
+func TestIteratorSkipTake(t *testing.T) {
+	it := RRule{Frequency: Daily, Count: 6, Dtstart: now}.Iterator()
+
+	it.Skip(2)
+	assert.Equal(t, []string{"2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z"}, rfcAll(it.Take(2)))
+	assert.Equal(t, []string{"2018-08-29T09:08:07Z", "2018-08-30T09:08:07Z"}, rfcAll(it.Take(5)))
+	assert.Empty(t, it.Take(1))
+
+	it.Skip(1)
+	assert.Nil(t, it.Next())
+}
+
+func TestIteratorReset(t *testing.T) {
+	rrules := []RRule{
+		{Frequency: Secondly, Count: 5, BySeconds: []int{1, 30}, Dtstart: now},
+		{Frequency: Monthly, Count: 5, ByWeekdays: []QualifiedWeekday{{N: 2, WD: time.Friday}}, Dtstart: now},
+		{Frequency: Yearly, Until: now.AddDate(4, 0, 0), Dtstart: now},
+	}
+
+	for _, rrule := range rrules {
+		it := rrule.Iterator()
+		first := All(it, 0)
+		require.NotEmpty(t, first)
+
+		it.Reset()
+		assert.Equal(t, first, All(it, 0), rrule.String())
+
+		it.Reset()
+		it.Skip(2)
+		it.Reset()
+		assert.Equal(t, first, All(it, 0), rrule.String())
+	}
+
+	r := recurrenceCases[2].Recurrence
+	it := r.Iterator()
+	first := All(it, 0)
+	it.Reset()
+	assert.Equal(t, first, All(it, 0))
+}
+
+func TestIteratorNextIndexed(t *testing.T) {
+	it := RRule{Frequency: Daily, Count: 4, Dtstart: now}.Iterator()
+
+	idx, next := it.NextIndexed()
+	assert.Equal(t, 0, idx)
+	assert.Equal(t, now, *next)
+
+	it.Skip(1)
+	idx, next = it.NextIndexed()
+	assert.Equal(t, 2, idx)
+	assert.Equal(t, now.AddDate(0, 0, 2), *next)
+
+	// a counted rule is never fast-forwarded, so its position stays known.
+	it.Seek(now.AddDate(0, 0, 3))
+	idx, _ = it.NextIndexed()
+	assert.Equal(t, 3, idx)
+
+	idx, next = it.NextIndexed()
+	assert.Equal(t, -1, idx)
+	assert.Nil(t, next)
+
+	// a simple rule's position is computed when it jumps, even with COUNT.
+	it = RRule{Frequency: Daily, Dtstart: now}.Iterator()
+	it.Seek(now.AddDate(1, 0, 0))
+	idx, next = it.NextIndexed()
+	assert.Equal(t, 365, idx)
+	assert.Equal(t, now.AddDate(1, 0, 0), *next)
+
+	it = RRule{Frequency: Minutely, Count: 20000000, Dtstart: now}.Iterator()
+	it.Seek(now.AddDate(20, 0, 0))
+	idx, next = it.NextIndexed()
+	assert.Equal(t, 10519200, idx)
+	assert.Equal(t, now.AddDate(20, 0, 0), *next)
+
+	it.Seek(now.AddDate(40, 0, 0))
+	assert.Nil(t, it.Next())
+
+	it = RRule{Frequency: Daily, ByHours: []int{9}, Dtstart: now}.Iterator()
+	it.Seek(now.AddDate(1, 0, 0))
+	idx, next = it.NextIndexed()
+	assert.Equal(t, -1, idx)
+	assert.Equal(t, now.AddDate(1, 0, 0), *next)
+
+	it.Reset()
+	idx, _ = it.NextIndexed()
+	assert.Equal(t, 0, idx)
+
+	ri := recurrenceCases[2].Recurrence.Iterator()
+	for i := 0; i < 5; i++ {
+		idx, next = ri.NextIndexed()
+		require.NotNil(t, next)
+		assert.Equal(t, i, idx)
+	}
+}
+
+func TestInLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// the last weekday of the month in New York.
+	rrule := RRule{
+		Frequency:  Monthly,
+		Count:      3,
+		Dtstart:    time.Date(2018, time.January, 1, 20, 0, 0, 0, NewYork()),
+		ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Tuesday}, {WD: time.Wednesday}, {WD: time.Thursday}, {WD: time.Friday}},
+		BySetPos:   []int{-1},
+	}
+
+	it := InLocation(rrule.Iterator(), tokyo)
+	assert.Equal(t, "2018-02-01T10:00:00+09:00", it.Peek().Format(time.RFC3339))
+	assert.Equal(t, []string{
+		"2018-02-01T10:00:00+09:00",
+		"2018-03-01T10:00:00+09:00",
+		"2018-03-31T09:00:00+09:00",
+	}, rfcAll(All(it, 0)))
+
+	it.Reset()
+	idx, next := it.NextIndexed()
+	assert.Equal(t, 0, idx)
+	assert.Equal(t, tokyo, next.Location())
+	assert.Equal(t, []string{"2018-03-01T10:00:00+09:00"}, rfcAll(it.Take(1)))
+
+	it.Reset()
+	it.Seek(time.Date(2018, time.March, 2, 0, 0, 0, 0, tokyo))
+	assert.Equal(t, "2018-03-31T09:00:00+09:00", it.Next().Format(time.RFC3339))
+	assert.Nil(t, it.Next())
+}
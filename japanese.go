@@ -0,0 +1,50 @@
+package rrule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// japaneseEras lists the start of each modern Japanese era, latest first.
+var japaneseEras = []struct {
+	name  string
+	start time.Time
+}{
+	{"Reiwa", time.Date(2019, time.May, 1, 0, 0, 0, 0, time.UTC)},
+	{"Heisei", time.Date(1989, time.January, 8, 0, 0, 0, 0, time.UTC)},
+	{"Showa", time.Date(1926, time.December, 25, 0, 0, 0, 0, time.UTC)},
+	{"Taisho", time.Date(1912, time.July, 30, 0, 0, 0, 0, time.UTC)},
+	{"Meiji", time.Date(1868, time.September, 8, 0, 0, 0, 0, time.UTC)},
+}
+
+// japaneseToGregorianYear returns the Gregorian year in which year of era
+// falls, or its first part does. An empty era numbers years as Gregorian years,
+// as used before the Meiji era.
+func japaneseToGregorianYear(era string, year int) (int, error) {
+	if era == "" {
+		return year, nil
+	}
+
+	for _, e := range japaneseEras {
+		if strings.EqualFold(e.name, era) {
+			return e.start.Year() + year - 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown Japanese era %q", era)
+}
+
+// japaneseYear returns the era and year of the date of t, as observed in t's
+// location. Eras begin on the day an emperor takes the throne, so their first
+// years are usually shorter than a Gregorian year.
+func japaneseYear(t time.Time) (string, int) {
+	y, m, d := t.Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	for _, era := range japaneseEras {
+		if !date.Before(era.start) {
+			return era.name, y - era.start.Year() + 1
+		}
+	}
+	return "", y
+}
@@ -0,0 +1,197 @@
+package rrule
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jCal and xCal date and date-time formats, from RFC 7265 and RFC 6321.
+const (
+	recurDateTime = "2006-01-02T15:04:05"
+	recurDate     = "2006-01-02"
+)
+
+// ToJCal encodes the pattern as a jCal (RFC 7265) "recur" value: an object
+// keyed by lower case part names, with an array for each part that has more
+// than one value. Dtstart is not included.
+func (rrule RRule) ToJCal() ([]byte, error) {
+	recur := map[string]interface{}{}
+	for _, part := range rrule.recurParts() {
+		if len(part.values) == 1 {
+			recur[part.name] = part.values[0]
+		} else {
+			recur[part.name] = part.values
+		}
+	}
+	return json.Marshal(recur)
+}
+
+// recurPart is a rule part as it appears in the jCal and xCal "recur" values,
+// with its lower case name and integer values kept as numbers.
+type recurPart struct {
+	name   string
+	values []interface{}
+}
+
+// recurParts returns the parts of the pattern in the order that String writes
+// them, followed by its extensions.
+func (rrule RRule) recurParts() []recurPart {
+	parts := []recurPart{{name: "freq", values: []interface{}{rrule.Frequency.String()}}}
+	add := func(name string, values ...interface{}) {
+		if len(values) > 0 {
+			parts = append(parts, recurPart{name: name, values: values})
+		}
+	}
+	addInts := func(name string, ints []int) {
+		values := make([]interface{}, len(ints))
+		for i, n := range ints {
+			values[i] = n
+		}
+		add(name, values...)
+	}
+
+	if !rrule.Until.IsZero() {
+		if rrule.UntilFloating {
+			add("until", rrule.Until.Format(recurDateTime))
+		} else {
+			add("until", rrule.Until.UTC().Format(recurDateTime)+"Z")
+		}
+	}
+	if rrule.Count != 0 {
+		add("count", rrule.Count)
+	}
+	if rrule.Interval > 1 {
+		add("interval", rrule.Interval)
+	}
+
+	addInts("bysecond", rrule.BySeconds)
+	addInts("byminute", rrule.ByMinutes)
+	addInts("byhour", rrule.ByHours)
+
+	var days []interface{}
+	for _, wd := range rrule.ByWeekdays {
+		days = append(days, qualifiedWeekdayString(wd))
+	}
+	add("byday", days...)
+
+	addInts("bymonthday", rrule.ByMonthDays)
+	addInts("byyearday", rrule.ByYearDays)
+	addInts("byweekno", rrule.ByWeekNumbers)
+
+	var months []interface{}
+	for _, m := range rrule.ByMonths {
+		months = append(months, int(m))
+	}
+	for _, m := range rrule.ByLeapMonths {
+		months = append(months, strconv.Itoa(m)+"L")
+	}
+	add("bymonth", months...)
+
+	addInts("bysetpos", rrule.BySetPos)
+
+	if rrule.WeekStart.IsSet() {
+		add("wkst", rrule.WeekStart.String())
+	}
+	if rrule.InvalidBehavior != OmitInvalid {
+		add("skip", skipString(rrule.InvalidBehavior))
+	}
+	if rrule.InvalidBehavior != OmitInvalid || rrule.RScale != Gregorian {
+		add("rscale", rrule.RScale.String())
+	}
+
+	names := make([]string, 0, len(rrule.Extensions))
+	for name := range rrule.Extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(strings.ToLower(name), rrule.Extensions[name])
+	}
+
+	return parts
+}
+
+// FromJCal decodes a pattern from a jCal (RFC 7265) "recur" value, as produced
+// by ToJCal. Each part may have a single value or an array of them.
+func FromJCal(b []byte) (RRule, error) {
+	var recur map[string]json.RawMessage
+	if err := json.Unmarshal(b, &recur); err != nil {
+		return RRule{}, err
+	}
+
+	rrule := RRule{}
+	for name, raw := range recur {
+		value, err := jcalValue(raw)
+		if err != nil {
+			return rrule, fmt.Errorf("jCal %s: %v", name, err)
+		}
+
+		if err := rrule.setRecurPart(name, value); err != nil {
+			return rrule, fmt.Errorf("jCal %s: %v", name, err)
+		}
+	}
+
+	return rrule, rrule.Validate()
+}
+
+// jcalValue returns the RRULE text form of a single jCal value or an array of
+// them.
+func jcalValue(raw json.RawMessage) (string, error) {
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		values = []json.RawMessage{raw}
+	}
+
+	strs := make([]string, len(values))
+	for i, v := range values {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			strs[i] = s
+			continue
+		}
+
+		var n json.Number
+		d := json.NewDecoder(bytes.NewReader(v))
+		d.UseNumber()
+		if err := d.Decode(&n); err != nil {
+			return "", fmt.Errorf("%s is not a string or number", v)
+		}
+		strs[i] = n.String()
+	}
+
+	return strings.Join(strs, ","), nil
+}
+
+// setRecurPart sets the part with the lower case jCal or xCal name from its
+// RRULE text form, apart from UNTIL, which is a jCal or xCal date or date-time.
+func (rrule *RRule) setRecurPart(name, value string) error {
+	directive := strings.ToUpper(name)
+
+	if directive == "UNTIL" {
+		var err error
+		if value, err = recurUntil(value); err != nil {
+			return err
+		}
+	}
+
+	return rrule.setPart(directive, value)
+}
+
+// recurUntil converts a jCal or xCal date or date-time to its RRULE text form.
+func recurUntil(value string) (string, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC().Format(rfc5545WithOffset), nil
+	}
+	if t, err := time.Parse(recurDateTime, value); err == nil {
+		return t.Format(rfc5545WithoutOffset), nil
+	}
+	if t, err := time.Parse(recurDate, value); err == nil {
+		return t.Format(rfc5545Date), nil
+	}
+	return "", fmt.Errorf("%q is not a date or date-time", value)
+}
@@ -0,0 +1,78 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJCal(t *testing.T) {
+	rrule := RRule{
+		Frequency:   Monthly,
+		Interval:    2,
+		Until:       time.Date(2019, time.March, 1, 9, 0, 0, 0, time.UTC),
+		ByWeekdays:  []QualifiedWeekday{{N: 1, WD: time.Monday}, {N: -1, WD: time.Friday}},
+		ByMonths:    []time.Month{time.January},
+		ByMonthDays: []int{-1},
+	}
+
+	b, err := rrule.ToJCal()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"freq": "MONTHLY",
+		"interval": 2,
+		"until": "2019-03-01T09:00:00Z",
+		"byday": ["1MO", "-1FR"],
+		"bymonth": 1,
+		"bymonthday": -1
+	}`, string(b))
+
+	decoded, err := FromJCal(b)
+	require.NoError(t, err)
+	assert.Equal(t, rrule.String(), decoded.String())
+}
+
+func TestFromJCal(t *testing.T) {
+	_, err := FromJCal([]byte(`{
+		"freq": "YEARLY",
+		"count": 5,
+		"until": "2019-03-01",
+		"byhour": [9, "17"],
+		"bymonth": ["5L", 6],
+		"wkst": "SU",
+		"rscale": "CHINESE",
+		"skip": "FORWARD"
+	}`))
+	assert.Error(t, err, "COUNT and UNTIL may not both be set")
+
+	decoded, err := FromJCal([]byte(`{
+		"freq": "YEARLY",
+		"until": "2019-03-01T04:00:00-05:00",
+		"byhour": [9, "17"],
+		"bymonth": ["5L", 6],
+		"wkst": "SU",
+		"rscale": "CHINESE",
+		"skip": "FORWARD"
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=YEARLY;UNTIL=20190301T090000Z;BYHOUR=9,17;BYMONTH=6,5L;WKST=SU;SKIP=FORWARD;RSCALE=CHINESE", decoded.String())
+
+	decoded, err = FromJCal([]byte(`{"freq": "DAILY", "until": "2019-03-01T09:00:00"}`))
+	require.NoError(t, err)
+	assert.True(t, decoded.UntilFloating)
+
+	b, err := decoded.ToJCal()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"freq": "DAILY", "until": "2019-03-01T09:00:00"}`, string(b))
+
+	_, err = FromJCal([]byte(`{"freq": "DAILY", "byhour": [true]}`))
+	assert.Error(t, err)
+
+	_, err = FromJCal([]byte(`{"freq": "DAILY", "until": "yesterday"}`))
+	assert.Error(t, err)
+
+	_, err = FromJCal([]byte(`["freq", "DAILY"]`))
+	assert.Error(t, err)
+}
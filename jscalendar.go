@@ -0,0 +1,161 @@
+package rrule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSCalendarRule is a JSCalendar (RFC 8984) RecurrenceRule object, as used by
+// JMAP calendar servers.
+type JSCalendarRule struct {
+	Type           string `json:"@type"` // always RecurrenceRule
+	Frequency      string `json:"frequency"`
+	Interval       int    `json:"interval,omitempty"`
+	RScale         string `json:"rscale,omitempty"`
+	Skip           string `json:"skip,omitempty"`
+	FirstDayOfWeek string `json:"firstDayOfWeek,omitempty"`
+
+	ByDay         []JSCalendarNDay `json:"byDay,omitempty"`
+	ByMonthDay    []int            `json:"byMonthDay,omitempty"`
+	ByMonth       []string         `json:"byMonth,omitempty"` // like "5" or "5L"
+	ByYearDay     []int            `json:"byYearDay,omitempty"`
+	ByWeekNo      []int            `json:"byWeekNo,omitempty"`
+	ByHour        []int            `json:"byHour,omitempty"`
+	ByMinute      []int            `json:"byMinute,omitempty"`
+	BySecond      []int            `json:"bySecond,omitempty"`
+	BySetPosition []int            `json:"bySetPosition,omitempty"`
+
+	Count uint64 `json:"count,omitempty"`
+
+	// Until is a LocalDateTime, like 2019-03-01T09:00:00, in the time zone of
+	// the event.
+	Until string `json:"until,omitempty"`
+}
+
+// JSCalendarNDay is a JSCalendar (RFC 8984) NDay object: a day of the week,
+// and optionally which occurrence of it within the period.
+type JSCalendarNDay struct {
+	Type        string `json:"@type,omitempty"` // NDay, if set
+	Day         string `json:"day"`
+	NthOfPeriod int    `json:"nthOfPeriod,omitempty"`
+}
+
+// ToJSCalendar returns the pattern as a JSCalendar (RFC 8984) RecurrenceRule.
+// Until is written in the location of Dtstart, which should be the time zone
+// of the event, unless it is floating. Extensions are not included.
+func (rrule RRule) ToJSCalendar() JSCalendarRule {
+	rule := JSCalendarRule{
+		Type:          "RecurrenceRule",
+		Frequency:     strings.ToLower(rrule.Frequency.String()),
+		ByMonthDay:    rrule.ByMonthDays,
+		ByYearDay:     rrule.ByYearDays,
+		ByWeekNo:      rrule.ByWeekNumbers,
+		ByHour:        rrule.ByHours,
+		ByMinute:      rrule.ByMinutes,
+		BySecond:      rrule.BySeconds,
+		BySetPosition: rrule.BySetPos,
+		Count:         rrule.Count,
+	}
+
+	if rrule.Interval > 1 {
+		rule.Interval = rrule.Interval
+	}
+	if rrule.RScale != Gregorian {
+		rule.RScale = strings.ToLower(rrule.RScale.String())
+	}
+	if rrule.InvalidBehavior != OmitInvalid {
+		rule.Skip = strings.ToLower(skipString(rrule.InvalidBehavior))
+	}
+	rule.FirstDayOfWeek = strings.ToLower(rrule.WeekStart.String())
+
+	for _, wd := range rrule.ByWeekdays {
+		rule.ByDay = append(rule.ByDay, JSCalendarNDay{
+			Type:        "NDay",
+			Day:         strings.ToLower(weekdayString(wd.WD)),
+			NthOfPeriod: wd.N,
+		})
+	}
+
+	for _, m := range rrule.ByMonths {
+		rule.ByMonth = append(rule.ByMonth, strconv.Itoa(int(m)))
+	}
+	for _, m := range rrule.ByLeapMonths {
+		rule.ByMonth = append(rule.ByMonth, strconv.Itoa(m)+"L")
+	}
+
+	if !rrule.Until.IsZero() {
+		until := rrule.Until
+		if !rrule.UntilFloating && !rrule.Dtstart.IsZero() {
+			until = until.In(rrule.Dtstart.Location())
+		}
+		rule.Until = until.Format(recurDateTime)
+	}
+
+	return rule
+}
+
+// FromJSCalendar returns the pattern of a JSCalendar (RFC 8984)
+// RecurrenceRule. Until is interpreted in loc, which should be the time zone
+// of the event, or as a floating time if loc is nil.
+func FromJSCalendar(rule JSCalendarRule, loc *time.Location) (RRule, error) {
+	if rule.Type != "" && rule.Type != "RecurrenceRule" {
+		return RRule{}, fmt.Errorf("JSCalendar @type %q is not RecurrenceRule", rule.Type)
+	}
+
+	rrule := RRule{
+		ByMonthDays:   rule.ByMonthDay,
+		ByYearDays:    rule.ByYearDay,
+		ByWeekNumbers: rule.ByWeekNo,
+		ByHours:       rule.ByHour,
+		ByMinutes:     rule.ByMinute,
+		BySeconds:     rule.BySecond,
+		BySetPos:      rule.BySetPosition,
+		Count:         rule.Count,
+		Interval:      rule.Interval,
+	}
+
+	days := make([]string, len(rule.ByDay))
+	for i, nday := range rule.ByDay {
+		days[i] = strings.ToUpper(nday.Day)
+		if nday.NthOfPeriod != 0 {
+			days[i] = strconv.Itoa(nday.NthOfPeriod) + days[i]
+		}
+	}
+
+	parts := []struct{ directive, value string }{
+		{"FREQ", strings.ToUpper(rule.Frequency)},
+		{"RSCALE", strings.ToUpper(rule.RScale)},
+		{"SKIP", strings.ToUpper(rule.Skip)},
+		{"WKST", strings.ToUpper(rule.FirstDayOfWeek)},
+		{"BYDAY", strings.Join(days, ",")},
+		{"BYMONTH", strings.ToUpper(strings.Join(rule.ByMonth, ","))},
+	}
+	for _, part := range parts {
+		if part.value == "" {
+			continue
+		}
+		if err := rrule.setPart(part.directive, part.value); err != nil {
+			return rrule, fmt.Errorf("JSCalendar %s: %v", part.directive, err)
+		}
+	}
+
+	if rule.Until != "" {
+		if loc == nil {
+			until, err := time.Parse(recurDateTime, rule.Until)
+			if err != nil {
+				return rrule, fmt.Errorf("JSCalendar until: %v", err)
+			}
+			rrule.Until, rrule.UntilFloating = until, true
+		} else {
+			until, err := time.ParseInLocation(recurDateTime, rule.Until, loc)
+			if err != nil {
+				return rrule, fmt.Errorf("JSCalendar until: %v", err)
+			}
+			rrule.Until = until
+		}
+	}
+
+	return rrule, rrule.Validate()
+}
@@ -0,0 +1,75 @@
+package rrule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSCalendar(t *testing.T) {
+	rrule := RRule{
+		Frequency:  Monthly,
+		Interval:   2,
+		Dtstart:    time.Date(2018, time.January, 1, 9, 0, 0, 0, NewYork()),
+		Until:      time.Date(2019, time.March, 1, 14, 0, 0, 0, time.UTC),
+		ByWeekdays: []QualifiedWeekday{{N: -1, WD: time.Friday}, {WD: time.Monday}},
+		ByMonths:   []time.Month{time.June},
+		ByHours:    []int{9},
+		WeekStart:  WeekStartOn(time.Sunday),
+		RScale:     Chinese,
+	}
+
+	rule := rrule.ToJSCalendar()
+	b, err := json.Marshal(rule)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"@type": "RecurrenceRule",
+		"frequency": "monthly",
+		"interval": 2,
+		"rscale": "chinese",
+		"firstDayOfWeek": "su",
+		"byDay": [{"@type": "NDay", "day": "fr", "nthOfPeriod": -1}, {"@type": "NDay", "day": "mo"}],
+		"byMonth": ["6"],
+		"byHour": [9],
+		"until": "2019-03-01T09:00:00"
+	}`, string(b))
+
+	decoded, err := FromJSCalendar(rule, NewYork())
+	require.NoError(t, err)
+	assert.True(t, rrule.Until.Equal(decoded.Until))
+	assert.Equal(t, "FREQ=MONTHLY;UNTIL=20190301T140000Z;INTERVAL=2;BYHOUR=9;BYDAY=-1FR,MO;BYMONTH=6;WKST=SU;RSCALE=CHINESE", decoded.String())
+}
+
+func TestFromJSCalendar(t *testing.T) {
+	var rule JSCalendarRule
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"@type": "RecurrenceRule",
+		"frequency": "yearly",
+		"skip": "forward",
+		"rscale": "chinese",
+		"byMonth": ["5L"],
+		"byMonthDay": [1],
+		"until": "2030-01-01T00:00:00"
+	}`), &rule))
+
+	rrule, err := FromJSCalendar(rule, nil)
+	require.NoError(t, err)
+	assert.True(t, rrule.UntilFloating)
+	assert.Equal(t, "FREQ=YEARLY;UNTIL=20300101T000000;BYMONTHDAY=1;BYMONTH=5L;SKIP=FORWARD;RSCALE=CHINESE", rrule.String())
+	assert.Equal(t, rule, rrule.ToJSCalendar())
+
+	_, err = FromJSCalendar(JSCalendarRule{Type: "Event", Frequency: "daily"}, nil)
+	assert.Error(t, err)
+
+	_, err = FromJSCalendar(JSCalendarRule{Frequency: "fortnightly"}, nil)
+	assert.Error(t, err)
+
+	_, err = FromJSCalendar(JSCalendarRule{Frequency: "daily", Until: "2019-03-01"}, nil)
+	assert.Error(t, err)
+
+	_, err = FromJSCalendar(JSCalendarRule{Frequency: "daily", Count: 3, Until: "2019-03-01T00:00:00"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package rrule
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LeapSecondBehavior specifies how to behave when a pattern generates the
+// 60th second of a minute, which RFC 5545 allows in BYSECOND for leap
+// seconds. Go's times don't have leap seconds, so such times never exist.
+type LeapSecondBehavior int
+
+const (
+	// SkipLeapSecond omits times in the 60th second.
+	SkipLeapSecond LeapSecondBehavior = iota
+
+	// ClampLeapSecond moves times in the 60th second back to the 59th.
+	ClampLeapSecond
+)
+
+// withoutLeapSeconds returns the pattern with 60 in BYSECOND replaced as
+// LeapSecond decides.
+func (rrule RRule) withoutLeapSeconds() RRule {
+	seconds := make([]int, 0, len(rrule.BySeconds))
+	has59 := false
+	for _, s := range rrule.BySeconds {
+		has59 = has59 || s == 59
+	}
+	for _, s := range rrule.BySeconds {
+		if s == 60 {
+			if rrule.LeapSecond != ClampLeapSecond || has59 {
+				continue
+			}
+			s, has59 = 59, true
+		}
+		seconds = append(seconds, s)
+	}
+
+	rrule.BySeconds = seconds
+	return rrule
+}
+
+// hasLeapSecond reports whether BYSECOND includes 60.
+func (rrule RRule) hasLeapSecond() bool {
+	for _, s := range rrule.BySeconds {
+		if s == 60 {
+			return true
+		}
+	}
+	return false
+}
+
+func leapSecondString(leapSecond LeapSecondBehavior) string {
+	switch leapSecond {
+	case SkipLeapSecond:
+		return "SKIP"
+	case ClampLeapSecond:
+		return "CLAMP"
+	}
+	return ""
+}
+
+func parseLeapSecond(str string) (LeapSecondBehavior, error) {
+	switch strings.ToLower(str) {
+	case "skip":
+		return SkipLeapSecond, nil
+	case "clamp":
+		return ClampLeapSecond, nil
+	}
+	return SkipLeapSecond, fmt.Errorf("leap second behavior %v is not valid", str)
+}
@@ -1,70 +1,37 @@
 package rrule
 
 import (
-	"sort"
 	"time"
 )
 
+// limitBySetPos keeps the times of sorted tt at the positions of setpos, in
+// place.
 func limitBySetPos(tt []time.Time, setpos []int) []time.Time {
 	if len(setpos) == 0 {
 		return tt
 	}
 
-	// a map of tt indexes to include
-	include := map[int]bool{}
-
-	for _, sp := range setpos {
-		if sp < 0 {
-			sp = len(tt) + sp
-		} else {
-			sp-- // setpos is 1-indexed in the rrule. adjust here
-		}
-
-		include[sp] = true
-	}
-
-	ret := make([]time.Time, 0, len(include))
-	for included := range include {
-		if len(tt) > included {
-			ret = append(ret, tt[included])
-		}
+	// positions are in order, so each is at or after where it's copied to.
+	var buf [8]int
+	positions := appendSetPositions(buf[:0], len(tt), setpos)
+	for i, pos := range positions {
+		tt[i] = tt[pos]
 	}
-
-	sort.Slice(ret, func(i, j int) bool {
-		return ret[i].Before(ret[j])
-	})
-
-	return ret
+	return tt[:len(positions)]
 }
 
+// limitInstancesBySetPos is like limitBySetPos, for days of the month.
 func limitInstancesBySetPos(tt []int, setpos []int) []int {
 	if len(setpos) == 0 {
 		return tt
 	}
 
-	// a map of tt indexes to include
-	include := make(map[int]bool, len(setpos))
-
-	for _, sp := range setpos {
-		if sp < 0 {
-			sp = len(tt) + sp
-		} else {
-			sp-- // setpos is 1-indexed in the rrule. adjust here
-		}
-
-		include[sp] = true
+	var buf [8]int
+	positions := appendSetPositions(buf[:0], len(tt), setpos)
+	for i, pos := range positions {
+		tt[i] = tt[pos]
 	}
-
-	ret := make([]int, 0, len(include))
-	for included := range include {
-		if len(tt) > included {
-			ret = append(ret, tt[included])
-		}
-	}
-
-	sort.Ints(ret)
-
-	return ret
+	return tt[:len(positions)]
 }
 
 func combineLimiters(ll ...validFunc) func(t *time.Time) bool {
@@ -86,3 +53,34 @@ func checkLimiters(t *time.Time, ll ...validFunc) bool {
 	}
 	return true
 }
+
+// appendSetPositions appends the 0-based positions that setpos picks from a set
+// of n instances to dst, in order. The few positions usually picked fit in a
+// buffer on the caller's stack.
+func appendSetPositions(dst []int, n int, setpos []int) []int {
+	first := len(dst)
+
+next:
+	for _, sp := range setpos {
+		if sp < 0 {
+			sp = n + sp
+		} else {
+			sp-- // setpos is 1-indexed in the rrule. adjust here
+		}
+		if sp < 0 || sp >= n {
+			continue
+		}
+
+		// there are few positions, so they're kept in order by insertion.
+		i := len(dst)
+		for ; i > first && dst[i-1] >= sp; i-- {
+			if dst[i-1] == sp {
+				continue next
+			}
+		}
+		dst = append(dst, 0)
+		copy(dst[i+1:], dst[i:])
+		dst[i] = sp
+	}
+	return dst
+}
@@ -0,0 +1,170 @@
+package rrule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severity is how serious a Finding is.
+type Severity int
+
+const (
+	// SeverityInfo findings describe behavior that may be surprising, like
+	// BYMONTHDAY=31 skipping shorter months.
+	SeverityInfo Severity = iota
+
+	// SeverityWarning findings describe rule parts that are almost certainly
+	// mistakes, like an UNTIL before DTSTART.
+	SeverityWarning
+)
+
+// String returns the lower case name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	}
+	return ""
+}
+
+// Finding is something suspicious about a valid pattern, found by Lint.
+type Finding struct {
+	Severity Severity
+
+	// Part is the name of the rule part the finding is about, like UNTIL.
+	Part string
+
+	Message string
+}
+
+// String returns the finding as severity, part and message, like
+// "warning: UNTIL: UNTIL is before DTSTART".
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Part, f.Message)
+}
+
+// Lint returns findings about parts of the pattern that are legal but
+// suspicious, like an UNTIL before DTSTART or BYDAY entries that can never
+// fall on any of the days in BYMONTHDAY. Checks that need DTSTART are skipped
+// if it is zero. Lint doesn't report the problems Validate does, like parts
+// that leave the pattern with no occurrences at all.
+func (rrule RRule) Lint() []Finding {
+	var findings []Finding
+	add := func(severity Severity, part, format string, args ...interface{}) {
+		findings = append(findings, Finding{Severity: severity, Part: part, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !rrule.Dtstart.IsZero() && !rrule.Until.IsZero() {
+		until := rrule.Until
+		if rrule.UntilFloating {
+			until = wallClockIn(until, rrule.Dtstart.Location())
+		}
+
+		if until.Before(rrule.Dtstart) {
+			add(SeverityWarning, "UNTIL", "UNTIL is before DTSTART, so there are no occurrences")
+		} else if rrule.Interval > 1 && rrule.nextPeriod(rrule.Dtstart).After(until) {
+			add(SeverityWarning, "INTERVAL", "INTERVAL=%d reaches past UNTIL, so occurrences are only in the first %s", rrule.Interval, rrule.periodName())
+		}
+	}
+
+	if len(rrule.ByWeekNumbers) > 0 && rrule.Frequency != Yearly {
+		add(SeverityWarning, "BYWEEKNO", "BYWEEKNO is only meaningful in YEARLY rules")
+	}
+	if len(rrule.ByYearDays) > 0 && (rrule.Frequency == Daily || rrule.Frequency == Weekly || rrule.Frequency == Monthly) {
+		add(SeverityWarning, "BYYEARDAY", "BYYEARDAY is not meaningful in %s rules", rrule.Frequency)
+	}
+
+	if rrule.RScale != Gregorian {
+		return findings
+	}
+
+	// Numbered weekdays are counted within months in MONTHLY rules, and in
+	// YEARLY rules with BYMONTH.
+	if len(rrule.ByMonthDays) > 0 && (rrule.Frequency == Monthly || rrule.Frequency == Yearly && len(rrule.ByMonths) > 0) {
+		for _, wd := range rrule.ByWeekdays {
+			if wd.N == 0 {
+				continue
+			}
+
+			possible := false
+			for _, day := range rrule.ByMonthDays {
+				possible = possible || monthDayCanBeOrdinal(day, wd.N)
+			}
+			if !possible {
+				add(SeverityWarning, "BYDAY", "BYDAY=%s never falls on BYMONTHDAY=%s", qualifiedWeekdayString(wd), intlist(rrule.ByMonthDays))
+			}
+		}
+	}
+
+	if rrule.InvalidBehavior == OmitInvalid && len(rrule.ByLeapMonths) == 0 {
+		months := rrule.ByMonths
+		if len(months) == 0 {
+			for m := time.January; m <= time.December; m++ {
+				months = append(months, m)
+			}
+		}
+
+		for _, day := range rrule.ByMonthDays {
+			if day < 0 {
+				day = -day
+			}
+
+			var never, sometimes []string
+			for _, m := range months {
+				min, max := monthLengths(m)
+				switch {
+				case day > max:
+					never = append(never, m.String())
+				case day > min:
+					sometimes = append(sometimes, m.String())
+				}
+			}
+
+			switch {
+			case len(never) == len(months):
+				add(SeverityWarning, "BYMONTHDAY", "BYMONTHDAY=%d never falls in BYMONTH=%s", day, monthlist(rrule.ByMonths, nil))
+			case len(never) > 0 || len(sometimes) > 0:
+				add(SeverityInfo, "BYMONTHDAY", "BYMONTHDAY=%d is skipped in %s", day, strings.Join(append(never, sometimes...), ", "))
+			}
+		}
+	}
+
+	return findings
+}
+
+// nextPeriod returns t moved forward by the pattern's interval.
+func (rrule RRule) nextPeriod(t time.Time) time.Time {
+	n := rrule.Interval
+	switch rrule.Frequency {
+	case Secondly:
+		return t.Add(time.Duration(n) * time.Second)
+	case Minutely:
+		return t.Add(time.Duration(n) * time.Minute)
+	case Hourly:
+		return t.Add(time.Duration(n) * time.Hour)
+	case Daily:
+		return t.AddDate(0, 0, n)
+	case Weekly:
+		return t.AddDate(0, 0, 7*n)
+	case Monthly:
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(n, 0, 0)
+	}
+}
+
+// periodName returns the name of the pattern's period, like month.
+func (rrule RRule) periodName() string {
+	return map[Frequency]string{
+		Secondly: "second",
+		Minutely: "minute",
+		Hourly:   "hour",
+		Daily:    "day",
+		Weekly:   "week",
+		Monthly:  "month",
+		Yearly:   "year",
+	}[rrule.Frequency]
+}
@@ -0,0 +1,74 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	dtstart := time.Date(2018, time.August, 25, 9, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		RRule    RRule
+		Findings []string
+	}{
+		{
+			RRule{Frequency: Weekly, Dtstart: dtstart, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}},
+			nil,
+		},
+		{
+			RRule{Frequency: Daily, Dtstart: dtstart, Until: dtstart.AddDate(0, 0, -1)},
+			[]string{"warning: UNTIL: UNTIL is before DTSTART, so there are no occurrences"},
+		},
+		{
+			RRule{Frequency: Daily, Dtstart: dtstart, Until: time.Date(2018, time.August, 25, 8, 0, 0, 0, time.UTC), UntilFloating: true},
+			[]string{"warning: UNTIL: UNTIL is before DTSTART, so there are no occurrences"},
+		},
+		{
+			RRule{Frequency: Monthly, Dtstart: dtstart, Interval: 6, Until: dtstart.AddDate(0, 3, 0)},
+			[]string{"warning: INTERVAL: INTERVAL=6 reaches past UNTIL, so occurrences are only in the first month"},
+		},
+		{
+			RRule{Frequency: Monthly, ByWeekNumbers: []int{1}, ByYearDays: []int{1}},
+			[]string{
+				"warning: BYWEEKNO: BYWEEKNO is only meaningful in YEARLY rules",
+				"warning: BYYEARDAY: BYYEARDAY is not meaningful in MONTHLY rules",
+			},
+		},
+		{
+			RRule{Frequency: Monthly, ByWeekdays: []QualifiedWeekday{{N: 2, WD: time.Monday}, {N: -1, WD: time.Friday}, {WD: time.Sunday}}, ByMonthDays: []int{1, 2, 3}},
+			[]string{
+				"warning: BYDAY: BYDAY=2MO never falls on BYMONTHDAY=1,2,3",
+				"warning: BYDAY: BYDAY=-1FR never falls on BYMONTHDAY=1,2,3",
+			},
+		},
+		{
+			RRule{Frequency: Monthly, ByWeekdays: []QualifiedWeekday{{N: -1, WD: time.Friday}, {N: 1, WD: time.Friday}}, ByMonthDays: []int{-3, 5}},
+			nil,
+		},
+		{
+			RRule{Frequency: Yearly, ByMonths: []time.Month{time.February, time.April}, ByMonthDays: []int{1, 31}},
+			[]string{"warning: BYMONTHDAY: BYMONTHDAY=31 never falls in BYMONTH=2,4"},
+		},
+		{
+			RRule{Frequency: Monthly, ByMonthDays: []int{30}},
+			[]string{"info: BYMONTHDAY: BYMONTHDAY=30 is skipped in February"},
+		},
+		{
+			RRule{Frequency: Monthly, ByMonthDays: []int{-31}},
+			[]string{"info: BYMONTHDAY: BYMONTHDAY=31 is skipped in February, April, June, September, November"},
+		},
+		{
+			RRule{Frequency: Monthly, ByMonthDays: []int{31}, InvalidBehavior: PrevInvalid},
+			nil,
+		},
+	} {
+		var findings []string
+		for _, f := range tc.RRule.Lint() {
+			findings = append(findings, f.String())
+		}
+		assert.Equal(t, tc.Findings, findings, tc.RRule.String())
+	}
+}
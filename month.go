@@ -40,3 +40,33 @@ func monthDiff(a, b time.Time) int {
 	absolute := years*12 + months
 	return absolute
 }
+
+// monthNumber numbers the month of t, counting from January of year 0, so that
+// months can be stepped through by adding to it.
+func monthNumber(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+// monthOf returns the year and month numbered n by monthNumber.
+func monthOf(n int) (int, time.Month) {
+	year, month := n/12, n%12
+	if month < 0 {
+		year--
+		month += 12
+	}
+	return year, time.Month(month + 1)
+}
+
+// daysInMonth returns the number of days in month m of year.
+func daysInMonth(year int, m time.Month) int {
+	switch m {
+	case time.February:
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 29
+		}
+		return 28
+	case time.April, time.June, time.September, time.November:
+		return 30
+	}
+	return 31
+}
@@ -0,0 +1,176 @@
+package rrule
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Normalize returns the pattern in a canonical form, so that patterns that
+// differ only in how their parts are written compare equal and render
+// identically:
+//
+//   - BYXXX values are sorted in ascending order, with duplicates removed,
+//     and empty lists are nil. BYDAY is sorted by ordinal, then from Sunday.
+//   - An Interval of 1 is 0 and a WeekStart of MO is unset, as those are the
+//     RFC 5545 defaults. WKST=MO is kept if DefaultWeekStart is changed.
+//   - Empty Extensions are nil.
+//   - Dtstart and Until have no monotonic clock reading, like times
+//     returned by time.Now have, which would make otherwise equal times
+//     compare unequal with ==.
+//
+// The pattern is otherwise unchanged, and no longer renders as it was parsed
+// with StringOptions.Preserve.
+func (rrule RRule) Normalize() RRule {
+	rrule.BySeconds = normalizeInts(rrule.BySeconds)
+	rrule.ByMinutes = normalizeInts(rrule.ByMinutes)
+	rrule.ByHours = normalizeInts(rrule.ByHours)
+	rrule.ByMonthDays = normalizeInts(rrule.ByMonthDays)
+	rrule.ByWeekNumbers = normalizeInts(rrule.ByWeekNumbers)
+	rrule.ByLeapMonths = normalizeInts(rrule.ByLeapMonths)
+	rrule.ByYearDays = normalizeInts(rrule.ByYearDays)
+	rrule.BySetPos = normalizeInts(rrule.BySetPos)
+
+	months := append([]time.Month(nil), rrule.ByMonths...)
+	sort.Slice(months, func(i, j int) bool { return months[i] < months[j] })
+	rrule.ByMonths = nil
+	for i, m := range months {
+		if i == 0 || m != months[i-1] {
+			rrule.ByMonths = append(rrule.ByMonths, m)
+		}
+	}
+
+	wds := append([]QualifiedWeekday(nil), rrule.ByWeekdays...)
+	sort.Slice(wds, func(i, j int) bool {
+		if wds[i].N != wds[j].N {
+			return wds[i].N < wds[j].N
+		}
+		return wds[i].WD < wds[j].WD
+	})
+	rrule.ByWeekdays = nil
+	for i, wd := range wds {
+		if i == 0 || wd != wds[i-1] {
+			rrule.ByWeekdays = append(rrule.ByWeekdays, wd)
+		}
+	}
+
+	if rrule.Interval == 1 {
+		rrule.Interval = 0
+	}
+	if rrule.WeekStart.implied() {
+		rrule.WeekStart = WeekStart{}
+	}
+
+	rrule.Dtstart = rrule.Dtstart.Round(0)
+	rrule.Until = rrule.Until.Round(0)
+
+	if len(rrule.Extensions) == 0 {
+		rrule.Extensions = nil
+	}
+
+	rrule.source = nil
+	return rrule
+}
+
+// normalizeInts returns a sorted copy of ints without duplicates, or nil if
+// it is empty.
+func normalizeInts(ints []int) []int {
+	sorted := append([]int(nil), ints...)
+	sort.Ints(sorted)
+
+	var normalized []int
+	for i, n := range sorted {
+		if i == 0 || n != sorted[i-1] {
+			normalized = append(normalized, n)
+		}
+	}
+	return normalized
+}
+
+// Equal reports whether the patterns are the same once normalized, so that
+// the order of BYXXX values and whether defaults like INTERVAL=1 and WKST=MO
+// are explicit don't matter. Dtstart and Until are equal if they are the same
+// instant; Dtstart must also be in the same location, as that affects the
+// time of day of occurrences.
+func (rrule RRule) Equal(other RRule) bool {
+	a, b := rrule.Normalize(), other.Normalize()
+
+	if !a.Dtstart.Equal(b.Dtstart) || a.Dtstart.Location().String() != b.Dtstart.Location().String() {
+		return false
+	}
+	if !a.Until.Equal(b.Until) || a.UntilFloating != b.UntilFloating {
+		return false
+	}
+
+	a.Dtstart, b.Dtstart = time.Time{}, time.Time{}
+	a.Until, b.Until = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
+
+// Simplify returns the normalized pattern without the rule parts that are
+// implied by Dtstart and the frequency, like BYDAY=SA in a WEEKLY pattern
+// that starts on a Saturday, or WKST where weeks are never counted. The
+// result has the same occurrences, with as few parts as possible. Patterns
+// without a Dtstart, or with BYSETPOS, only lose their implied WKST.
+func (rrule RRule) Simplify() RRule {
+	rrule = rrule.Normalize()
+
+	// WKST only matters to WEEKLY patterns that skip weeks or pick BYSETPOS
+	// positions from each week, and to BYWEEKNO.
+	weekly := rrule.Frequency == Weekly && (rrule.Interval > 1 || len(rrule.BySetPos) > 0)
+	if !weekly && len(rrule.ByWeekNumbers) == 0 {
+		rrule.WeekStart = WeekStart{}
+	}
+
+	if rrule.Dtstart.IsZero() || len(rrule.BySetPos) > 0 {
+		return rrule
+	}
+	start := rrule.Dtstart
+
+	// Each part defaults to Dtstart's value for frequencies that are coarser
+	// than it.
+	if rrule.Frequency > Secondly && isOnly(rrule.BySeconds, start.Second()) {
+		rrule.BySeconds = nil
+	}
+	if rrule.Frequency > Minutely && isOnly(rrule.ByMinutes, start.Minute()) {
+		rrule.ByMinutes = nil
+	}
+	if rrule.Frequency > Hourly && isOnly(rrule.ByHours, start.Hour()) {
+		rrule.ByHours = nil
+	}
+
+	if rrule.RScale != Gregorian || len(rrule.ByLeapMonths) > 0 || len(rrule.ByWeekNumbers) > 0 || len(rrule.ByYearDays) > 0 {
+		return rrule
+	}
+
+	switch rrule.Frequency {
+	case Weekly:
+		if len(rrule.ByWeekdays) == 1 && rrule.ByWeekdays[0] == (QualifiedWeekday{WD: start.Weekday()}) {
+			rrule.ByWeekdays = nil
+		}
+
+	case Monthly:
+		if len(rrule.ByWeekdays) == 0 && isOnly(rrule.ByMonthDays, start.Day()) {
+			rrule.ByMonthDays = nil
+		}
+
+	case Yearly:
+		if len(rrule.ByWeekdays) > 0 {
+			break
+		}
+		if len(rrule.ByMonths) == 1 && rrule.ByMonths[0] == start.Month() && (len(rrule.ByMonthDays) == 0 || isOnly(rrule.ByMonthDays, start.Day())) {
+			rrule.ByMonths = nil
+			rrule.ByMonthDays = nil
+		}
+		if len(rrule.ByMonths) == 0 && isOnly(rrule.ByMonthDays, start.Day()) {
+			rrule.ByMonthDays = nil
+		}
+	}
+
+	return rrule
+}
+
+// isOnly reports whether ints holds n and nothing else.
+func isOnly(ints []int, n int) bool {
+	return len(ints) == 1 && ints[0] == n
+}
@@ -0,0 +1,117 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	rrule := RRule{
+		Frequency:   Monthly,
+		Interval:    1,
+		WeekStart:   WeekStartOn(time.Monday),
+		ByWeekdays:  []QualifiedWeekday{{N: 1, WD: time.Friday}, {WD: time.Tuesday}, {N: -1, WD: time.Monday}, {WD: time.Sunday}, {WD: time.Tuesday}},
+		ByMonthDays: []int{15, -1, 1, 15},
+		ByMonths:    []time.Month{time.December, time.January, time.December},
+		ByHours:     []int{},
+		Extensions:  map[string]string{},
+	}
+
+	normalized := rrule.Normalize()
+	assert.Equal(t, RRule{
+		Frequency:   Monthly,
+		ByWeekdays:  []QualifiedWeekday{{N: -1, WD: time.Monday}, {WD: time.Sunday}, {WD: time.Tuesday}, {N: 1, WD: time.Friday}},
+		ByMonthDays: []int{-1, 1, 15},
+		ByMonths:    []time.Month{time.January, time.December},
+	}, normalized)
+	assert.Equal(t, "FREQ=MONTHLY;BYDAY=-1MO,SU,TU,1FR;BYMONTHDAY=-1,1,15;BYMONTH=1,12", normalized.String())
+
+	// The original is unchanged.
+	assert.Equal(t, []int{15, -1, 1, 15}, rrule.ByMonthDays)
+	assert.Equal(t, time.Friday, rrule.ByWeekdays[0].WD)
+
+	start := time.Now()
+	assert.Equal(t, start.Round(0), RRule{Frequency: Daily, Dtstart: start}.Normalize().Dtstart)
+
+	a, err := ParseRRulePreserving("FREQ=WEEKLY;INTERVAL=1;BYDAY=FR,MO,MO;WKST=MO")
+	require.NoError(t, err)
+	b, err := ParseRRule("FREQ=WEEKLY;BYDAY=MO,FR")
+	require.NoError(t, err)
+	assert.Equal(t, a.Normalize(), b.Normalize())
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,FR", a.Normalize().Format(StringOptions{Preserve: true}))
+}
+
+func TestEqual(t *testing.T) {
+	dtstart := time.Date(2018, time.August, 25, 9, 0, 0, 0, NewYork())
+
+	base := RRule{Frequency: Weekly, Dtstart: dtstart, Until: dtstart.AddDate(0, 1, 0), ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Friday}}}
+
+	for name, other := range map[string]RRule{
+		"identical":         base,
+		"weekday order":     {Frequency: Weekly, Dtstart: dtstart, Until: dtstart.AddDate(0, 1, 0), ByWeekdays: []QualifiedWeekday{{WD: time.Friday}, {WD: time.Monday}, {WD: time.Friday}}},
+		"explicit defaults": {Frequency: Weekly, Dtstart: dtstart, Until: dtstart.AddDate(0, 1, 0), ByWeekdays: base.ByWeekdays, Interval: 1, WeekStart: WeekStartOn(time.Monday)},
+		"until in UTC":      {Frequency: Weekly, Dtstart: dtstart, Until: dtstart.AddDate(0, 1, 0).UTC(), ByWeekdays: base.ByWeekdays},
+	} {
+		assert.True(t, base.Equal(other), name)
+		assert.True(t, other.Equal(base), name)
+	}
+
+	for name, other := range map[string]RRule{
+		"frequency":        {Frequency: Daily, Dtstart: dtstart, Until: dtstart.AddDate(0, 1, 0), ByWeekdays: base.ByWeekdays},
+		"weekdays":         {Frequency: Weekly, Dtstart: dtstart, Until: dtstart.AddDate(0, 1, 0), ByWeekdays: base.ByWeekdays[:1]},
+		"week start":       {Frequency: Weekly, Dtstart: dtstart, Until: dtstart.AddDate(0, 1, 0), ByWeekdays: base.ByWeekdays, WeekStart: WeekStartOn(time.Sunday)},
+		"until":            {Frequency: Weekly, Dtstart: dtstart, Until: dtstart.AddDate(0, 2, 0), ByWeekdays: base.ByWeekdays},
+		"floating until":   {Frequency: Weekly, Dtstart: dtstart, Until: dtstart.AddDate(0, 1, 0), UntilFloating: true, ByWeekdays: base.ByWeekdays},
+		"dtstart":          {Frequency: Weekly, Dtstart: dtstart.Add(time.Hour), Until: dtstart.AddDate(0, 1, 0), ByWeekdays: base.ByWeekdays},
+		"dtstart in UTC":   {Frequency: Weekly, Dtstart: dtstart.UTC(), Until: dtstart.AddDate(0, 1, 0), ByWeekdays: base.ByWeekdays},
+		"extensions":       {Frequency: Weekly, Dtstart: dtstart, Until: dtstart.AddDate(0, 1, 0), ByWeekdays: base.ByWeekdays, Extensions: map[string]string{"X-NAME": "value"}},
+		"invalid behavior": {Frequency: Weekly, Dtstart: dtstart, Until: dtstart.AddDate(0, 1, 0), ByWeekdays: base.ByWeekdays, InvalidBehavior: NextInvalid},
+	} {
+		assert.False(t, base.Equal(other), name)
+		assert.False(t, other.Equal(base), name)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	dtstart := time.Date(2018, time.August, 25, 9, 30, 15, 0, NewYork()) // a Saturday
+
+	for _, tc := range []struct {
+		RRule      RRule
+		Simplified string
+	}{
+		{RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}}}, "FREQ=WEEKLY;COUNT=12"},
+		{RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}, {WD: time.Sunday}}}, "FREQ=WEEKLY;COUNT=12;BYDAY=SU,SA"},
+		{RRule{Frequency: Weekly, Interval: 1, WeekStart: WeekStartOn(time.Sunday)}, "FREQ=WEEKLY;COUNT=12"},
+		{RRule{Frequency: Weekly, Interval: 2, WeekStart: WeekStartOn(time.Sunday), ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}, {WD: time.Monday}}}, "FREQ=WEEKLY;COUNT=12;INTERVAL=2;BYDAY=MO,SA;WKST=SU"},
+		{RRule{Frequency: Weekly, Interval: 2, WeekStart: WeekStartOn(time.Monday)}, "FREQ=WEEKLY;COUNT=12;INTERVAL=2"},
+		{RRule{Frequency: Daily, BySeconds: []int{15}, ByMinutes: []int{30}, ByHours: []int{9}}, "FREQ=DAILY;COUNT=12"},
+		{RRule{Frequency: Daily, ByHours: []int{9, 17}}, "FREQ=DAILY;COUNT=12;BYHOUR=9,17"},
+		{RRule{Frequency: Hourly, ByHours: []int{9}, ByMinutes: []int{30}}, "FREQ=HOURLY;COUNT=12;BYHOUR=9"},
+		{RRule{Frequency: Minutely, BySeconds: []int{15}}, "FREQ=MINUTELY;COUNT=12"},
+		{RRule{Frequency: Secondly, BySeconds: []int{15}}, "FREQ=SECONDLY;COUNT=12;BYSECOND=15"},
+		{RRule{Frequency: Monthly, ByMonthDays: []int{25}}, "FREQ=MONTHLY;COUNT=12"},
+		{RRule{Frequency: Monthly, ByMonthDays: []int{25}, ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}}}, "FREQ=MONTHLY;COUNT=12;BYDAY=SA;BYMONTHDAY=25"},
+		{RRule{Frequency: Yearly, ByMonths: []time.Month{time.August}}, "FREQ=YEARLY;COUNT=12"},
+		{RRule{Frequency: Yearly, ByMonths: []time.Month{time.August}, ByMonthDays: []int{25}}, "FREQ=YEARLY;COUNT=12"},
+		{RRule{Frequency: Yearly, ByMonthDays: []int{25}}, "FREQ=YEARLY;COUNT=12"},
+		{RRule{Frequency: Yearly, ByMonths: []time.Month{time.August}, ByMonthDays: []int{1}}, "FREQ=YEARLY;COUNT=12;BYMONTHDAY=1;BYMONTH=8"},
+		{RRule{Frequency: Monthly, ByMonthDays: []int{25}, ByHours: []int{9, 10}, BySetPos: []int{1}}, "FREQ=MONTHLY;COUNT=12;BYHOUR=9,10;BYMONTHDAY=25;BYSETPOS=1"},
+		{MustRRule("FREQ=WEEKLY;BYDAY=MO,SU;BYSETPOS=1;WKST=SU"), "FREQ=WEEKLY;COUNT=12;BYDAY=SU,MO;BYSETPOS=1;WKST=SU"},
+	} {
+		t.Run(tc.Simplified, func(t *testing.T) {
+			rrule := tc.RRule
+			rrule.Dtstart = dtstart
+			rrule.Count = 12
+
+			simplified := rrule.Simplify()
+			assert.Equal(t, tc.Simplified, simplified.String())
+			assert.Equal(t, rfcAll(All(rrule.Iterator(), 0)), rfcAll(All(simplified.Iterator(), 0)))
+		})
+	}
+
+	// Without Dtstart, only WKST can be removed.
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=SA", RRule{Frequency: Weekly, WeekStart: WeekStartOn(time.Sunday), ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}}}.Simplify().String())
+}
@@ -0,0 +1,71 @@
+package rrule
+
+import (
+	"time"
+)
+
+// OccursOn reports whether the pattern has an occurrence on the calendar day
+// of date, from midnight to midnight in loc, as when marking the days of a
+// month view. The year, month and day of date are used as they are, so it may
+// be a civil date in any location; pass date.In(loc) for the day an instant
+// falls on. A nil loc uses the location of Dtstart.
+//
+// Rather than iterating from Dtstart, the pattern is skipped ahead to the day,
+// as by AnyBetween.
+//
+// The pattern must be valid or OccursOn will panic.
+func (rrule RRule) OccursOn(date time.Time, loc *time.Location) bool {
+	if loc == nil {
+		loc = rrule.dtstart().Location()
+	}
+	year, month, day := date.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	end := time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+	return rrule.AnyBetween(start, end)
+}
+
+// AnyBetween reports whether the pattern has an occurrence at or after start
+// and before end, as Expand would return, but stops at the first one rather
+// than collecting them. Simple patterns, as described by At, are answered
+// without iterating, and others are skipped ahead to start where they can.
+//
+// The pattern must be valid or AnyBetween will panic.
+func (rrule RRule) AnyBetween(start, end time.Time) bool {
+	if !start.Before(end) {
+		return false
+	}
+
+	if rrule.simple() {
+		if err := rrule.Validate(); err != nil {
+			panic(err)
+		}
+		rrule.Dtstart = rrule.dtstart()
+		n := rrule.indexSimple(start)
+		if max, ok := rrule.lenSimple(); ok && n >= max {
+			return false
+		}
+		return rrule.nthSimple(n).Before(end)
+	}
+
+	return anyBetween(rrule.Iterator(), start, end)
+}
+
+// AnyBetween reports whether the recurrence has an instance at or after start
+// and before end, as Expand would return, stopping at the first one.
+func (r Recurrence) AnyBetween(start, end time.Time) bool {
+	if !start.Before(end) {
+		return false
+	}
+	return anyBetween(r.Iterator(), start, end)
+}
+
+// anyBetween reports whether it has a time at or after start and before end,
+// then releases it. The search stops at end rather than at the next time, which
+// may be far off.
+func anyBetween(it Iterator, start, end time.Time) bool {
+	defer release(it)
+	withBound(it, end)
+	it.Seek(start)
+	next := it.Peek()
+	return next != nil && next.Before(end)
+}
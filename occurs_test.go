@@ -0,0 +1,81 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOccursOn(t *testing.T) {
+	dtstart := time.Date(2025, time.January, 31, 23, 30, 0, 0, NewYork())
+	rrules := []RRule{
+		{Frequency: Daily, Interval: 3, Dtstart: dtstart},
+		{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}, {WD: time.Saturday}}, Dtstart: dtstart},
+		{Frequency: Monthly, ByMonthDays: []int{-1}, Count: 5, Dtstart: dtstart},
+		{Frequency: Hourly, Interval: 30, Until: dtstart.AddDate(0, 2, 0), Dtstart: dtstart},
+	}
+
+	for _, rrule := range rrules {
+		for _, loc := range []*time.Location{nil, time.UTC} {
+			observed := loc
+			if observed == nil {
+				observed = NewYork()
+			}
+
+			for day := 0; day < 120; day++ {
+				date := time.Date(2025, time.January, 20+day, 0, 0, 0, 0, time.UTC)
+				start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, observed)
+				want := len(rrule.Expand(start, start.AddDate(0, 0, 1))) > 0
+				assert.Equal(t, want, rrule.OccursOn(date, loc), "%s on %s in %s", rrule, date.Format("2006-01-02"), observed)
+			}
+		}
+	}
+}
+
+func TestAnyBetween(t *testing.T) {
+	dtstart := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	rrules := []RRule{
+		{Frequency: Daily, Interval: 10, Dtstart: dtstart},
+		{Frequency: Monthly, ByWeekdays: []QualifiedWeekday{{N: -1, WD: time.Friday}}, Count: 4, Dtstart: dtstart},
+		{Frequency: Weekly, Until: dtstart.AddDate(0, 1, 0), UntilExclusive: true, Dtstart: dtstart},
+	}
+
+	for _, rrule := range rrules {
+		for start := dtstart.AddDate(0, 0, -5); start.Before(dtstart.AddDate(0, 6, 0)); start = start.Add(17 * time.Hour) {
+			for _, span := range []time.Duration{0, time.Hour, 24 * time.Hour, 9 * 24 * time.Hour} {
+				end := start.Add(span)
+				want := len(rrule.Expand(start, end)) > 0
+				assert.Equal(t, want, rrule.AnyBetween(start, end), "%s from %s to %s", rrule, start, end)
+			}
+		}
+	}
+
+	r := Recurrence{
+		Dtstart: dtstart,
+		RRules:  []RRule{{Frequency: Daily}},
+		ExDates: []time.Time{dtstart.AddDate(0, 0, 1)},
+	}
+	assert.True(t, r.AnyBetween(dtstart, dtstart.Add(time.Hour)))
+	assert.False(t, r.AnyBetween(dtstart.Add(time.Hour), dtstart.AddDate(0, 0, 1).Add(time.Hour)))
+	assert.True(t, r.AnyBetween(dtstart.Add(time.Hour), dtstart.AddDate(0, 0, 2).Add(time.Hour)))
+	assert.False(t, r.AnyBetween(dtstart, dtstart))
+
+	// no year has a 366th day until 2028, but the search stops at end.
+	sparse := MustRRule("FREQ=SECONDLY;BYYEARDAY=366")
+	sparse.Dtstart = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.False(t, sparse.AnyBetween(dtstart, dtstart.AddDate(0, 0, 1)))
+	assert.False(t, sparse.OccursOn(dtstart, nil))
+	assert.False(t, Recurrence{Dtstart: sparse.Dtstart, RRules: []RRule{sparse}}.AnyBetween(dtstart, dtstart.AddDate(0, 0, 1)))
+}
+
+func TestAnyBetweenDaylightSaving(t *testing.T) {
+	// clocks in New York spring forward on 2019-03-10, moving that day's
+	// occurrence, but not the ones after it.
+	rrule := RRule{Frequency: Daily, Count: 4, Dtstart: time.Date(2019, time.March, 9, 2, 30, 0, 0, NewYork())}
+	for _, occurrence := range All(rrule.Iterator(), 0) {
+		assert.True(t, rrule.AnyBetween(occurrence, occurrence.Add(time.Minute)), occurrence.String())
+		assert.False(t, rrule.AnyBetween(occurrence.Add(-time.Hour), occurrence), occurrence.String())
+		assert.True(t, rrule.OccursOn(occurrence, nil), occurrence.String())
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"strings"
 	"time"
 	"unicode"
-	"unicode/utf8"
 )
 
 // ParseRecurrence parses a whole recurrence from an iCalendar object. iCalendar
@@ -56,6 +55,7 @@ func ParseRecurrence(src []byte, loc *time.Location) (*Recurrence, error) {
 			}
 			recurrence.Dtstart = t
 			recurrence.FloatingLocation = floating
+			recurrence.AllDay = isDate(text)
 
 		case "RRULE":
 			rrule, err := ParseRRule(propVal)
@@ -69,20 +69,13 @@ func ParseRecurrence(src []byte, loc *time.Location) (*Recurrence, error) {
 				return nil, err
 			}
 			recurrence.ExRules = append(recurrence.ExRules, rrule)
-		case "RDATE":
-			t, _, err := parseTime(propVal, loc)
+		case "RDATE", "EXDATE":
+			list, err := ParseDateList(text, loc)
 			if err != nil {
 				return nil, err
 			}
 
-			recurrence.RDates = append(recurrence.RDates, t)
-		case "EXDATE":
-			t, _, err := parseTime(propVal, loc)
-			if err != nil {
-				return nil, err
-			}
-
-			recurrence.ExDates = append(recurrence.ExDates, t)
+			recurrence.AddDateList(list)
 		}
 	}
 
@@ -91,182 +84,483 @@ func ParseRecurrence(src []byte, loc *time.Location) (*Recurrence, error) {
 	return recurrence, nil
 }
 
-// ParseRRule parses a single RRule pattern.
-func ParseRRule(str string) (RRule, error) {
-	scanner := bufio.NewScanner(bytes.NewBufferString(str))
-	scanner.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-		if atEOF && len(data) == 0 {
-			return 0, nil, nil
+// ParseContentLine parses a single RRule pattern from its iCalendar property,
+// optionally preceded by a DTSTART property on its own line:
+//
+//	DTSTART;TZID=America/New_York:19970902T090000
+//	RRULE:FREQ=DAILY;COUNT=10
+//
+// The DTSTART property, if present, populates Dtstart in its specified
+// location, which is also where a floating UNTIL is observed. A DATE value,
+// like DTSTART;VALUE=DATE:19970902, makes the pattern all-day. loc is used for
+// a floating DTSTART, as in ParseRecurrence. If nil, time.UTC will be used.
+func ParseContentLine(str string, loc *time.Location) (RRule, error) {
+	var dtstart time.Time
+	var allDay bool
+	var rrule *RRule
+
+	for _, line := range strings.Split(str, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
 		}
 
-		if idx := bytes.Index(data, []byte{';'}); idx >= 0 {
-			return idx + 1, data[:idx], nil
+		colonIdx := strings.IndexAny(line, ":;")
+		if colonIdx < 0 || len(line)-1 == colonIdx {
+			return RRule{}, fmt.Errorf("misformatted line %q", line)
 		}
 
-		if atEOF {
-			return len(data), data, nil
+		switch propName := line[:colonIdx]; propName {
+		case "DTSTART":
+			t, _, err := parseTime(line, loc)
+			if err != nil {
+				return RRule{}, err
+			}
+			dtstart = t
+			allDay = isDate(line)
+		case "RRULE":
+			if rrule != nil {
+				return RRule{}, errors.New("only one RRULE may be specified")
+			}
+			parsed, err := ParseRRule(line[colonIdx+1:])
+			if err != nil {
+				return RRule{}, err
+			}
+			rrule = &parsed
+		default:
+			return RRule{}, fmt.Errorf("unexpected property %q", propName)
 		}
+	}
+
+	if rrule == nil {
+		return RRule{}, errors.New("no RRULE specified")
+	}
+
+	rrule.Dtstart = dtstart
+	if !dtstart.IsZero() {
+		rrule.AllDay = allDay
+		*rrule = rrule.withFloatingUntilIn(dtstart.Location())
+	}
+	return *rrule, nil
+}
+
+// ParseError describes a problem with one part of an RRULE string.
+type ParseError struct {
+	// Part is the name of the rule part, like BYDAY. It is empty if the
+	// name itself could not be parsed.
+	Part string
 
-		return 0, nil, nil
-	})
+	// Offset is the byte offset in the source string where the part begins.
+	Offset int
+
+	// Value is the part's value, or the whole part if its name could not be
+	// parsed.
+	Value string
+
+	// Reason describes the problem.
+	Reason string
+
+	// Err is the kind of the problem, like ErrUnsupportedFrequency, if it is
+	// one of the kinds of errors in this package.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Part == "" {
+		return fmt.Sprintf("rrule segment %q at offset %d: %s", e.Value, e.Offset, e.Reason)
+	}
+	return fmt.Sprintf("%s at offset %d: %s", e.Part, e.Offset, e.Reason)
+}
+
+// Unwrap returns the kind of the problem, for errors.Is.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseRRule parses a single RRule pattern. The pattern may be given as a
+// whole RRULE property, like RRULE:FREQ=DAILY, and whitespace around the
+// pattern, its parts and their list items is ignored, as are empty parts.
+// Part names and values are case-insensitive, so freq=weekly;byday=mo is
+// accepted. An UNTIL given as a DATE includes the whole of that day, so it
+// becomes a floating time at the last second of that day, observed in the
+// location of Dtstart. Problems with individual parts of the pattern are
+// reported as a *ParseError.
+func ParseRRule(str string) (RRule, error) {
+	rrule, _, err := parseRRule(str, parseOptions{})
+	return rrule, err
+}
+
+// ParseRRuleStrict parses a single RRule pattern like ParseRRule, but
+// requires the input to be exactly an RRULE value, with no property name,
+// extra whitespace or empty parts, and with part names and values in
+// uppercase. Values of X- parts may be in any case.
+func ParseRRuleStrict(str string) (RRule, error) {
+	rrule, _, err := parseRRule(str, parseOptions{strict: true})
+	return rrule, err
+}
+
+// ParseRRuleLenient parses a single RRule pattern like ParseRRule, but
+// tolerates quirks common in real-world calendar feeds:
+//
+//   - empty and malformed parts, like FREQ=DAILY;;COUNT=3, are ignored
+//   - parts with empty values, like BYDAY=, are ignored
+//   - unsupported parts are ignored
+//   - duplicated parts are allowed, and the last one wins
+//   - if both COUNT and UNTIL are present, COUNT is ignored
+//   - COUNT=0, which ParseRRule rejects, is ignored
+//
+// Each thing ignored is described by one of the returned warnings, which are
+// all of type *ParseError.
+func ParseRRuleLenient(str string) (RRule, []error, error) {
+	return parseRRule(str, parseOptions{lenient: true})
+}
+
+// ParseRRuleRFC2445 parses a single RRule pattern like ParseRRule, but
+// interprets it according to the older RFC 2445, normalizing constructs it
+// allowed into their RFC 5545 equivalents. An explicit WKST=MO, which some
+// producers always emit, is the default and so is dropped, unless
+// DefaultWeekStart has been changed. An UNTIL given as a DATE, which RFC 2445
+// allows even when DTSTART is a DATE-TIME, is treated as ParseRRule treats it.
+func ParseRRuleRFC2445(str string) (RRule, error) {
+	rrule, _, err := parseRRule(str, parseOptions{rfc2445: true})
+	return rrule, err
+}
+
+// ParseRRulePreserving parses a single RRule pattern like ParseRRule, but also
+// keeps the text it was parsed from, so that Format can write it as it was with
+// StringOptions.Preserve or ParsedOrder. The kept text makes the pattern
+// compare unequal, with == or reflect.DeepEqual, to one that wasn't parsed
+// from the same text, so other parse functions don't keep it.
+func ParseRRulePreserving(str string) (RRule, error) {
+	rrule, _, err := parseRRule(str, parseOptions{preserve: true})
+	return rrule, err
+}
+
+type parseOptions struct {
+	// lenient ignores problems that can be worked around, as described by
+	// ParseRRuleLenient.
+	lenient bool
+
+	// strict rejects property names, whitespace, empty parts and lowercase
+	// part names and values.
+	strict bool
+
+	// rfc2445 normalizes constructs from RFC 2445, as described by
+	// ParseRRuleRFC2445.
+	rfc2445 bool
+
+	// preserve keeps the parsed text, as described by
+	// ParseRRulePreserving.
+	preserve bool
+}
+
+func parseRRule(str string, opts parseOptions) (RRule, []error, error) {
+	lenient := opts.lenient
+
+	// offset is the byte offset of the current part within the original
+	// string, which may be ahead of its position in str once it's trimmed.
+	offset := 0
+	if !opts.strict {
+		trimmed := strings.TrimLeftFunc(str, unicode.IsSpace)
+		if colonIdx := strings.IndexByte(trimmed, ':'); colonIdx >= 0 {
+			switch strings.ToUpper(strings.TrimSpace(trimmed[:colonIdx])) {
+			case "RRULE", "EXRULE":
+				trimmed = trimmed[colonIdx+1:]
+			}
+		}
+		offset = len(str) - len(trimmed)
+		str = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	}
+
+	if opts.strict && strings.HasSuffix(str, ";") {
+		return RRule{}, nil, &ParseError{Offset: len(str), Reason: "empty part"}
+	}
 
 	rrule := RRule{}
+	var warnings []error
+	untilIsDate := false
 
-	for scanner.Scan() {
-		wholeComponent := scanner.Text()
-		parts := strings.SplitN(wholeComponent, "=", 2)
-		if len(parts) < 2 {
-			return rrule, fmt.Errorf("rrule segment %q is invalid", scanner.Text())
+	// offsets, by part name, are only needed to warn about repeated parts.
+	var offsets map[string]int
+	if lenient {
+		offsets = map[string]int{}
+	}
+
+	for rest, done := str, str == ""; !done; {
+		wholeComponent := rest
+		if idx := strings.IndexByte(rest, ';'); idx >= 0 {
+			wholeComponent, rest = rest[:idx], rest[idx+1:]
+			done = rest == ""
+		} else {
+			done = true
 		}
+		partOffset := offset
+		offset += len(wholeComponent) + 1
 
-		directive, value := parts[0], parts[1]
+		if !opts.strict && !lenient && strings.TrimSpace(wholeComponent) == "" {
+			continue
+		}
 
-		switch strings.ToUpper(directive) {
-		case "FREQ":
-			freq, err := strToFreq(value)
-			if err != nil {
-				return rrule, err
+		eqIdx := strings.IndexByte(wholeComponent, '=')
+		if eqIdx < 0 {
+			err := &ParseError{Offset: partOffset, Value: wholeComponent, Reason: "missing '='"}
+			if lenient {
+				warnings = append(warnings, err)
+				continue
 			}
-			rrule.Frequency = freq
-		case "UNTIL":
-			t, floating, err := parseTime(wholeComponent, nil)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.Until = t
-			rrule.UntilFloating = floating
+			return rrule, nil, err
+		}
 
-		case "COUNT":
-			i, err := strconv.Atoi(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.Count = uint64(i)
-		case "INTERVAL":
-			i, err := strconv.Atoi(value)
-			if err != nil {
-				return rrule, err
+		name, value := wholeComponent[:eqIdx], wholeComponent[eqIdx+1:]
+		directive := strings.ToUpper(name)
+		if opts.strict {
+			if directive != name {
+				return rrule, nil, &ParseError{Offset: partOffset, Value: wholeComponent, Reason: "part name must be uppercase"}
 			}
-			rrule.Interval = i
-		case "BYSECOND":
-			ints, err := parseInts(value, 0, 60, true)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.BySeconds = ints
-		case "BYMINUTE":
-			ints, err := parseInts(value, 0, 59, true)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByMinutes = ints
-		case "BYHOUR":
-			ints, err := parseInts(value, 0, 23, true)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByHours = ints
-		case "BYDAY":
-			wds, err := parseQualifiedWeekdays(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByWeekdays = wds
-		case "BYMONTHDAY":
-			ints, err := parseInts(value, -31, 31, false)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByMonthDays = ints
-		case "BYYEARDAY":
-			ints, err := parseInts(value, -366, 366, true)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByYearDays = ints
-		case "BYWEEKNO":
-			ints, err := parseInts(value, -53, 53, false)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByWeekNumbers = ints
-		case "BYMONTH":
-			months, err := parseMonths(value)
-			if err != nil {
-				return rrule, err
+			if !strings.HasPrefix(directive, "X-") && value != strings.ToUpper(value) {
+				return rrule, nil, &ParseError{Part: directive, Offset: partOffset, Value: value, Reason: "value must be uppercase"}
 			}
-			rrule.ByMonths = months
-		case "BYSETPOS":
-			ints, err := parseInts(value, -366, 366, false)
-			if err != nil {
-				return rrule, err
+		} else {
+			directive, value = strings.TrimSpace(directive), strings.TrimSpace(value)
+			if !strings.HasPrefix(directive, "X-") && strings.Contains(value, ",") && strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+				items := strings.Split(value, ",")
+				for i := range items {
+					items[i] = strings.TrimSpace(items[i])
+				}
+				value = strings.Join(items, ",")
 			}
-			rrule.BySetPos = ints
-		case "WKST":
-			wd, err := parseWeekday(value)
-			if err != nil {
-				return rrule, err
+		}
+
+		if lenient {
+			if value == "" {
+				warnings = append(warnings, &ParseError{Part: directive, Offset: partOffset, Reason: "empty value ignored"})
+				continue
 			}
-			rrule.WeekStart = &wd
-		case "SKIP":
-			skip, err := parseSkip(value)
-			if err != nil {
-				return rrule, err
+			if prev, ok := offsets[directive]; ok {
+				warnings = append(warnings, &ParseError{Part: directive, Offset: prev, Reason: "earlier value replaced by a repeated part"})
 			}
-			rrule.InvalidBehavior = skip
-		case "RSCALE":
-			err := parseRScale(value)
-			if err != nil {
-				return rrule, err
+			offsets[directive] = partOffset
+		}
+
+		if lenient && directive == "COUNT" {
+			// COUNT=0 was once accepted as no COUNT at all.
+			if n, err := strconv.Atoi(value); err == nil && n == 0 {
+				rrule.Count = 0
+				warnings = append(warnings, &ParseError{Part: directive, Offset: partOffset, Value: value, Reason: "COUNT=0 ignored"})
+				continue
 			}
+		}
 
-		default:
-			return rrule, fmt.Errorf("%q is not a supported RRULE part", directive)
+		if directive == "UNTIL" {
+			untilIsDate = len(value) == len(rfc5545Date)
+		}
+
+		err := rrule.setPart(directive, value)
+		if err == ErrUnsupportedPart && lenient {
+			warnings = append(warnings, &ParseError{Part: directive, Offset: partOffset, Value: value, Reason: "unsupported part ignored"})
+			continue
+		}
+		if err != nil {
+			return rrule, nil, &ParseError{Part: directive, Offset: partOffset, Value: value, Reason: err.Error(), Err: errorKind(err)}
 		}
 	}
 
+	if untilIsDate {
+		// the pattern is all-day only if DTSTART is a DATE, so UNTIL
+		// includes the whole of its day, whatever the times of day.
+		rrule.Until = rrule.Until.Add(24*time.Hour - time.Second)
+	}
+
+	if opts.rfc2445 {
+		if rrule.WeekStart.implied() {
+			rrule.WeekStart = WeekStart{}
+		}
+	}
+
+	if lenient && rrule.Count != 0 && !rrule.Until.IsZero() {
+		rrule.Count = 0
+		warnings = append(warnings, &ParseError{Part: "COUNT", Offset: offsets["COUNT"], Reason: "COUNT ignored because UNTIL is also present"})
+	}
+
 	err := rrule.Validate()
-	return rrule, err
+	if err == nil && opts.preserve {
+		// the canonical rendering is only kept when it differs, so most
+		// patterns are compared without allocating it.
+		var buf [128]byte
+		if canonical := rrule.appendFormat(buf[:0], StringOptions{}); string(canonical) != str {
+			rrule.source = &ruleSource{text: str, canonical: string(canonical)}
+		}
+	}
+	return rrule, warnings, err
+}
+
+// setPart sets one part of the pattern, like BYDAY=MO,TU, from its name and
+// string value.
+func (rrule *RRule) setPart(directive, value string) error {
+	switch directive {
+	case "FREQ":
+		freq, err := strToFreq(value)
+		if err != nil {
+			return err
+		}
+		rrule.Frequency = freq
+	case "UNTIL":
+		t, offsetFound, err := parseTimeValue(value, time.UTC)
+		if err != nil {
+			return err
+		}
+		rrule.Until = t
+		rrule.UntilFloating = !offsetFound
+
+	case "COUNT":
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		// Count is unsigned, so a negative COUNT would wrap around.
+		if i < 1 {
+			return kindErrorf(ErrOutOfRange, "COUNT must be positive")
+		}
+		rrule.Count = uint64(i)
+	case "INTERVAL":
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		rrule.Interval = i
+	case "BYSECOND":
+		ints, err := parseInts(value, 0, 60, true)
+		if err != nil {
+			return err
+		}
+		rrule.BySeconds = ints
+	case "BYMINUTE":
+		ints, err := parseInts(value, 0, 59, true)
+		if err != nil {
+			return err
+		}
+		rrule.ByMinutes = ints
+	case "BYHOUR":
+		ints, err := parseInts(value, 0, 23, true)
+		if err != nil {
+			return err
+		}
+		rrule.ByHours = ints
+	case "BYDAY":
+		wds, err := parseQualifiedWeekdays(value)
+		if err != nil {
+			return err
+		}
+		rrule.ByWeekdays = wds
+	case "BYMONTHDAY":
+		ints, err := parseInts(value, -31, 31, false)
+		if err != nil {
+			return err
+		}
+		rrule.ByMonthDays = ints
+	case "BYYEARDAY":
+		ints, err := parseInts(value, -366, 366, true)
+		if err != nil {
+			return err
+		}
+		rrule.ByYearDays = ints
+	case "BYWEEKNO":
+		ints, err := parseInts(value, -53, 53, false)
+		if err != nil {
+			return err
+		}
+		rrule.ByWeekNumbers = ints
+	case "BYMONTH":
+		months, leapMonths, err := parseMonths(value)
+		if err != nil {
+			return err
+		}
+		rrule.ByMonths = months
+		rrule.ByLeapMonths = leapMonths
+	case "BYSETPOS":
+		ints, err := parseInts(value, -366, 366, false)
+		if err != nil {
+			return err
+		}
+		rrule.BySetPos = ints
+	case "WKST":
+		wd, err := parseWeekday(value)
+		if err != nil {
+			return err
+		}
+		rrule.WeekStart = WeekStartOn(wd)
+	case "SKIP":
+		skip, err := parseSkip(value)
+		if err != nil {
+			return err
+		}
+		rrule.InvalidBehavior = skip
+	case "RSCALE":
+		rscale, err := parseRScale(value)
+		if err != nil {
+			return err
+		}
+		rrule.RScale = rscale
+
+	default:
+		if !strings.HasPrefix(directive, "X-") {
+			return ErrUnsupportedPart
+		}
+		if rrule.Extensions == nil {
+			rrule.Extensions = map[string]string{}
+		}
+		rrule.Extensions[directive] = value
+	}
+
+	return nil
 }
 
 func parseInts(str string, min, max int, allowZero bool) ([]int, error) {
 	if len(str) == 0 {
 		return nil, nil
 	}
-	var err error
-	parts := strings.Split(str, ",")
-	ints := make([]int, len(parts))
-	for i, p := range parts {
-		var currentInt int
+	ints := make([]int, 0, strings.Count(str, ",")+1)
+	for rest, done := str, false; !done; {
+		var p string
+		p, rest, done = nextItem(rest)
 
-		currentInt, err = strconv.Atoi(p)
+		currentInt, err := strconv.Atoi(p)
 		if err != nil {
 			return nil, err
 		}
 
 		if currentInt == 0 && !allowZero {
-			return nil, fmt.Errorf("zero is not valid")
+			return nil, kindErrorf(ErrOutOfRange, "zero is not valid")
 		}
 
 		if currentInt < min {
-			return nil, fmt.Errorf("%d is below minimum %d", currentInt, min)
+			return nil, kindErrorf(ErrOutOfRange, "%d is below minimum %d", currentInt, min)
 		}
 
 		if currentInt > max {
-			return nil, fmt.Errorf("%d is above maximum %d", currentInt, max)
+			return nil, kindErrorf(ErrOutOfRange, "%d is above maximum %d", currentInt, max)
 		}
 
-		ints[i] = currentInt
+		ints = append(ints, currentInt)
 	}
 
 	return ints, nil
 }
 
+// nextItem splits the first item off a comma-separated list, returning it, the
+// rest of the list and whether it was the last item.
+func nextItem(list string) (string, string, bool) {
+	if idx := strings.IndexByte(list, ','); idx >= 0 {
+		return list[:idx], list[idx+1:], false
+	}
+	return list, "", true
+}
+
 func parseQualifiedWeekdays(str string) ([]QualifiedWeekday, error) {
-	var err error
-	parts := strings.Split(str, ",")
-	wds := make([]QualifiedWeekday, len(parts))
-	for i, p := range parts {
+	wds := make([]QualifiedWeekday, 0, strings.Count(str, ",")+1)
+	for rest, done := str, false; !done; {
+		var p string
+		p, rest, done = nextItem(rest)
 		if len(p) == 0 {
 			return nil, errors.New("cannot have empty weekday segment in a comma-separated list")
 		}
@@ -278,15 +572,13 @@ func parseQualifiedWeekdays(str string) ([]QualifiedWeekday, error) {
 			idx++
 		}
 
-		for _, r := range p[idx:] {
-			if !unicode.IsDigit(r) {
-				break
-			}
-			idx += utf8.RuneLen(r)
+		for idx < len(p) && p[idx] >= '0' && p[idx] <= '9' {
+			idx++
 		}
 
 		var digit int
 		if idx > 0 {
+			var err error
 			digit, err = strconv.Atoi(p[:idx])
 			if err != nil {
 				return nil, err
@@ -298,86 +590,64 @@ func parseQualifiedWeekdays(str string) ([]QualifiedWeekday, error) {
 			return nil, err
 		}
 
-		wds[i] = QualifiedWeekday{N: digit, WD: wd}
+		wds = append(wds, QualifiedWeekday{N: digit, WD: wd})
 	}
 
 	return wds, nil
 }
 
 func parseWeekday(str string) (time.Weekday, error) {
-	switch strings.ToLower(str) {
-	case "mo":
-		return time.Monday, nil
-	case "tu":
-		return time.Tuesday, nil
-	case "we":
-		return time.Wednesday, nil
-	case "th":
-		return time.Thursday, nil
-	case "fr":
-		return time.Friday, nil
-	case "sa":
-		return time.Saturday, nil
-	case "su":
-		return time.Sunday, nil
-	default:
-		return time.Sunday, fmt.Errorf("invalid day of week %q", str)
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.EqualFold(str, weekdayString(wd)) {
+			return wd, nil
+		}
 	}
+	return time.Sunday, fmt.Errorf("invalid day of week %q", str)
 }
 
-func parseMonths(str string) ([]time.Month, error) {
-	parts := strings.Split(str, ",")
-	months := make([]time.Month, len(parts))
-	for i, p := range parts {
+// parseMonths parses a BYMONTH list, returning leap months, like 5L,
+// separately.
+func parseMonths(str string) ([]time.Month, []int, error) {
+	var months []time.Month
+	var leapMonths []int
+	for rest, done := str, false; !done; {
+		var p string
+		p, rest, done = nextItem(rest)
+		leap := strings.HasSuffix(p, "L") || strings.HasSuffix(p, "l")
+		if leap {
+			p = p[:len(p)-1]
+		}
+
 		parsedInt, err := strconv.Atoi(p)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		if leap {
+			leapMonths = append(leapMonths, parsedInt)
+		} else {
+			months = append(months, time.Month(parsedInt))
 		}
-		months[i] = time.Month(parsedInt)
 	}
 
-	return months, nil
+	return months, leapMonths, nil
 }
 
 func strToFreq(str string) (Frequency, error) {
-	switch strings.ToLower(str) {
-	case "secondly":
-		return Secondly, nil
-	case "minutely":
-		return Minutely, nil
-	case "hourly":
-		return Hourly, nil
-	case "daily":
-		return Daily, nil
-	case "weekly":
-		return Weekly, nil
-	case "monthly":
-		return Monthly, nil
-	case "yearly":
-		return Yearly, nil
-	default:
-		return Yearly, fmt.Errorf("frequency %q is not valid", str)
+	for freq := Secondly; freq <= Yearly; freq++ {
+		if strings.EqualFold(str, freq.String()) {
+			return freq, nil
+		}
 	}
+	return Yearly, kindErrorf(ErrUnsupportedFrequency, "frequency %q is not valid", str)
 }
 
 func parseSkip(str string) (InvalidBehavior, error) {
-	switch strings.ToLower(str) {
-	case "omit":
-		return OmitInvalid, nil
-	case "backward":
-		return PrevInvalid, nil
-	case "forward":
-		return NextInvalid, nil
+	for _, skip := range []InvalidBehavior{OmitInvalid, PrevInvalid, NextInvalid} {
+		if strings.EqualFold(str, skipString(skip)) {
+			return skip, nil
+		}
 	}
 
 	return OmitInvalid, fmt.Errorf("skip value %v is not valid", str)
 }
-
-func parseRScale(str string) error {
-	switch strings.ToLower(str) {
-	case "gregorian", "gregory":
-		return nil
-	default:
-		return fmt.Errorf("invalid rscale %q: only gregorian is supported", str)
-	}
-}
@@ -1,5 +1,268 @@
 package rrule
 
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
 func ExampleParseRRule() {
 	ParseRRule("FREQ=WEEKLY;BYDAY=1MO,2TU;COUNT=2")
 }
+
+func TestParseContentLine(t *testing.T) {
+	rrule, err := ParseContentLine("DTSTART;TZID=America/New_York:19970902T090000\r\nRRULE:FREQ=DAILY;COUNT=3\r\n", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;COUNT=3", rrule.String())
+	assert.Equal(t, NewYork(), rrule.Dtstart.Location())
+	assert.Equal(t, []string{"1997-09-02T09:00:00-04:00", "1997-09-03T09:00:00-04:00", "1997-09-04T09:00:00-04:00"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule, err = ParseContentLine("RRULE:FREQ=WEEKLY", nil)
+	require.NoError(t, err)
+	assert.True(t, rrule.Dtstart.IsZero())
+
+	rrule, err = ParseContentLine("DTSTART:19970902T090000\nRRULE:FREQ=WEEKLY", NewYork())
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(1997, time.September, 2, 9, 0, 0, 0, NewYork()), rrule.Dtstart)
+
+	// A floating UNTIL is observed where DTSTART is.
+	rrule, err = ParseContentLine("DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;UNTIL=19970904T090000", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;UNTIL=19970904T090000", rrule.String())
+	assert.Equal(t, []string{"1997-09-02T09:00:00-04:00", "1997-09-03T09:00:00-04:00", "1997-09-04T09:00:00-04:00"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	_, err = ParseContentLine("DTSTART:19970902T090000", nil)
+	assert.Error(t, err)
+
+	_, err = ParseContentLine("RRULE:FREQ=WEEKLY\nRRULE:FREQ=DAILY", nil)
+	assert.Error(t, err)
+
+	_, err = ParseContentLine("EXRULE:FREQ=WEEKLY", nil)
+	assert.Error(t, err)
+}
+
+func TestParseRRuleLenient(t *testing.T) {
+	str := "freq=weekly;;COUNT=3;INTERVAL;BYDAY=;byday=MO;X-NAME=value;BYDAY=TU;UNTIL=20181027T183615Z;"
+
+	_, err := ParseRRule(str)
+	assert.Error(t, err)
+
+	rrule, warnings, err := ParseRRuleLenient(str)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;UNTIL=20181027T183615Z;BYDAY=TU;X-NAME=value", rrule.String())
+	assert.Len(t, warnings, 5)
+
+	rrule, warnings, err = ParseRRuleLenient("FREQ=DAILY;COUNT=3")
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "FREQ=DAILY;COUNT=3", rrule.String())
+
+	rrule, warnings, err = ParseRRuleLenient("FREQ=DAILY;COUNT=3;COUNT=0")
+	require.NoError(t, err)
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, "FREQ=DAILY", rrule.String())
+	_, _, err = ParseRRuleLenient("FREQ=DAILY;COUNT=-1")
+	assert.Error(t, err)
+
+	_, _, err = ParseRRuleLenient("FREQ=DAILY;BYDAY=XX")
+	assert.Error(t, err)
+}
+
+func TestParseError(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Expected *ParseError
+	}{
+		{
+			Input:    "FREQ=DAILY;BYDAY=MO,XX",
+			Expected: &ParseError{Part: "BYDAY", Offset: 11, Value: "MO,XX", Reason: `invalid day of week "XX"`},
+		},
+		{
+			Input:    "FREQ=DAILY;COUNT",
+			Expected: &ParseError{Offset: 11, Value: "COUNT", Reason: "missing '='"},
+		},
+		{
+			Input:    "FREQ=DAILY;BYHOUR=1;FOO=bar",
+			Expected: &ParseError{Part: "FOO", Offset: 20, Value: "bar", Reason: "not a supported RRULE part", Err: ErrUnsupportedPart},
+		},
+		{
+			Input:    "FREQ=DAILY;COUNT=-1",
+			Expected: &ParseError{Part: "COUNT", Offset: 11, Value: "-1", Reason: "COUNT must be positive", Err: ErrOutOfRange},
+		},
+		{
+			Input:    "FREQ=DAILY;COUNT=0",
+			Expected: &ParseError{Part: "COUNT", Offset: 11, Value: "0", Reason: "COUNT must be positive", Err: ErrOutOfRange},
+		},
+		{
+			Input:    "FREQ=DAILY;BYHOUR=24",
+			Expected: &ParseError{Part: "BYHOUR", Offset: 11, Value: "24", Reason: "24 is above maximum 23", Err: ErrOutOfRange},
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := ParseRRule(tc.Input)
+		assert.Equal(t, tc.Expected, err, tc.Input)
+	}
+
+	// values out of range are reported as such, whether they're caught while
+	// parsing or validating.
+	for _, str := range []string{"FREQ=DAILY;BYHOUR=24", "FREQ=DAILY;BYSECOND=-1", "FREQ=MONTHLY;BYMONTHDAY=0", "FREQ=YEARLY;BYYEARDAY=367", "FREQ=YEARLY;BYMONTH=13"} {
+		_, err := ParseRRule(str)
+		assert.True(t, errors.Is(err, ErrOutOfRange), str)
+	}
+
+	_, warnings, err := ParseRRuleLenient("FREQ=DAILY;COUNT=3;BYHOUR=;UNTIL=20181027T183615Z")
+	require.NoError(t, err)
+	assert.Equal(t, []error{
+		&ParseError{Part: "BYHOUR", Offset: 19, Reason: "empty value ignored"},
+		&ParseError{Part: "COUNT", Offset: 11, Reason: "COUNT ignored because UNTIL is also present"},
+	}, warnings)
+	assert.EqualError(t, warnings[0], "BYHOUR at offset 19: empty value ignored")
+}
+
+func TestParseRRuleProperty(t *testing.T) {
+	for _, str := range []string{
+		"RRULE:FREQ=WEEKLY;BYDAY=MO",
+		"  rrule:FREQ=WEEKLY;BYDAY=MO\r\n",
+		"FREQ = WEEKLY ; BYDAY=MO ;",
+		"\tFREQ=WEEKLY;BYDAY=MO\n",
+	} {
+		rrule, err := ParseRRule(str)
+		require.NoError(t, err, str)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", rrule.String(), str)
+
+		_, err = ParseRRuleStrict(str)
+		assert.Error(t, err, str)
+	}
+
+	_, err := ParseRRuleStrict("FREQ=WEEKLY;BYDAY=MO")
+	assert.NoError(t, err)
+
+	_, err = ParseRRule(" RRULE:FREQ=WEEKLY;BYDAY=XX")
+	assert.Equal(t, &ParseError{Part: "BYDAY", Offset: 19, Value: "XX", Reason: `invalid day of week "XX"`}, err)
+}
+
+func TestParseRRuleExtensions(t *testing.T) {
+	rrule, err := ParseRRule("FREQ=DAILY;X-VENDOR-ID=abc;COUNT=2;x-other=1,2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-VENDOR-ID": "abc", "X-OTHER": "1,2"}, rrule.Extensions)
+
+	rrule.Count = 3
+	assert.Equal(t, "FREQ=DAILY;COUNT=3;X-OTHER=1,2;X-VENDOR-ID=abc", rrule.String())
+}
+
+func TestParseRRuleRFC2445(t *testing.T) {
+	rrule, err := ParseRRuleRFC2445("FREQ=DAILY;UNTIL=19971224;WKST=MO")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(1997, time.December, 24, 23, 59, 59, 0, time.UTC), rrule.Until)
+	assert.True(t, rrule.UntilFloating)
+	assert.False(t, rrule.WeekStart.IsSet())
+
+	rrule.Dtstart = time.Date(1997, time.December, 22, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"1997-12-22T09:00:00Z", "1997-12-23T09:00:00Z", "1997-12-24T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule, err = ParseRRuleRFC2445("FREQ=DAILY;UNTIL=19971224T000000Z;WKST=SU")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(1997, time.December, 24, 0, 0, 0, 0, time.UTC), rrule.Until)
+	assert.Equal(t, WeekStartOn(time.Sunday), rrule.WeekStart)
+}
+
+func TestParseRRuleCaseAndDelimiters(t *testing.T) {
+	for _, str := range []string{
+		"freq=weekly;byday=mo,we",
+		"Freq=Weekly;ByDay=Mo,We",
+		"FREQ=WEEKLY;;BYDAY=MO, WE;",
+		"FREQ=WEEKLY; BYDAY = MO ,WE",
+	} {
+		rrule, err := ParseRRule(str)
+		require.NoError(t, err, str)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE", rrule.String(), str)
+
+		_, err = ParseRRuleStrict(str)
+		assert.Error(t, err, str)
+	}
+
+	_, err := ParseRRuleStrict("freq=WEEKLY")
+	assert.Equal(t, &ParseError{Offset: 0, Value: "freq=WEEKLY", Reason: "part name must be uppercase"}, err)
+
+	_, err = ParseRRuleStrict("FREQ=WEEKLY;BYDAY=mo")
+	assert.Equal(t, &ParseError{Part: "BYDAY", Offset: 12, Value: "mo", Reason: "value must be uppercase"}, err)
+
+	rrule, err := ParseRRuleStrict("FREQ=WEEKLY;X-NAME=Mixed, Case")
+	require.NoError(t, err)
+	assert.Equal(t, "Mixed, Case", rrule.Extensions["X-NAME"])
+}
+
+func TestParseAllDay(t *testing.T) {
+	// a DATE UNTIL alone doesn't make the pattern all-day, but includes
+	// the whole of its day.
+	rrule, err := ParseRRule("FREQ=WEEKLY;UNTIL=20180917")
+	require.NoError(t, err)
+	assert.False(t, rrule.AllDay)
+	assert.True(t, rrule.UntilFloating)
+	assert.Equal(t, "FREQ=WEEKLY;UNTIL=20180917T235959", rrule.String())
+
+	for _, str := range []string{"FREQ=HOURLY;UNTIL=20240101", "FREQ=DAILY;UNTIL=20240101;BYHOUR=9"} {
+		rrule, err := ParseRRule(str)
+		require.NoError(t, err, str)
+		rrule.Dtstart = time.Date(2023, time.December, 31, 9, 0, 0, 0, NewYork())
+		all := All(rrule.Iterator(), 0)
+		require.NotEmpty(t, all, str)
+		assert.Equal(t, "2024-01-01", all[len(all)-1].Format("2006-01-02"), str)
+	}
+
+	rrule, err = ParseContentLine("DTSTART;VALUE=DATE:20180827\nRRULE:FREQ=WEEKLY;UNTIL=20180917", NewYork())
+	require.NoError(t, err)
+	assert.True(t, rrule.AllDay)
+	assert.Equal(t, "DTSTART;VALUE=DATE:20180827\nRRULE:FREQ=WEEKLY;UNTIL=20180917\n", rrule.ContentLine())
+	assert.Equal(t, []string{
+		"2018-08-27T00:00:00-04:00",
+		"2018-09-03T00:00:00-04:00",
+		"2018-09-10T00:00:00-04:00",
+		"2018-09-17T00:00:00-04:00",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	// occurrences are at midnight even when Dtstart has a time.
+	rrule.Dtstart = time.Date(2018, time.August, 27, 9, 0, 0, 0, NewYork())
+	assert.Equal(t, "2018-08-27T00:00:00-04:00", rfcAll(All(rrule.Iterator(), 1))[0])
+
+	// a DATE-TIME DTSTART makes the pattern timed, whatever UNTIL is.
+	rrule, err = ParseContentLine("DTSTART:20180827T090000\nRRULE:FREQ=WEEKLY;UNTIL=20180917", nil)
+	require.NoError(t, err)
+	assert.False(t, rrule.AllDay)
+	all := All(rrule.Iterator(), 0)
+	assert.Equal(t, "2018-09-17T09:00:00Z", rfcAll(all)[len(all)-1])
+
+	src := "DTSTART;VALUE=DATE:20180827\nRRULE:FREQ=DAILY;COUNT=3\nRDATE;VALUE=DATE:20180901\nEXDATE;VALUE=DATE:20180828\n"
+	r, err := ParseRecurrence([]byte(src), nil)
+	require.NoError(t, err)
+	assert.True(t, r.AllDay)
+	assert.True(t, r.RRules[0].AllDay)
+	assert.Equal(t, src, r.String())
+	assert.Equal(t, []string{
+		"2018-08-27T00:00:00Z",
+		"2018-08-29T00:00:00Z",
+		"2018-09-01T00:00:00Z",
+	}, rfcAll(All(r.Iterator(), 0)))
+
+	err = RRule{Frequency: Hourly, AllDay: true}.Validate()
+	assert.True(t, errors.Is(err, ErrAllDay))
+	err = RRule{Frequency: Daily, AllDay: true, ByHours: []int{9}}.Validate()
+	assert.True(t, errors.Is(err, ErrAllDay))
+}
+
+func BenchmarkParseRRule(b *testing.B) {
+	for _, str := range benchmarkPatterns {
+		b.Run(str, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseRRule(str); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
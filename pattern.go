@@ -0,0 +1,412 @@
+package rrule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PatternKind is how a Pattern repeats.
+type PatternKind int
+
+const (
+	// DailyPattern repeats every Interval days.
+	DailyPattern PatternKind = iota
+
+	// WeeklyPattern repeats on Weekdays every Interval weeks.
+	WeeklyPattern
+
+	// AbsoluteMonthlyPattern repeats on DayOfMonth every Interval months.
+	AbsoluteMonthlyPattern
+
+	// RelativeMonthlyPattern repeats on the Index-th of the Weekdays every
+	// Interval months.
+	RelativeMonthlyPattern
+
+	// AbsoluteYearlyPattern repeats on DayOfMonth of Month every Interval
+	// years.
+	AbsoluteYearlyPattern
+
+	// RelativeYearlyPattern repeats on the Index-th of the Weekdays in Month
+	// every Interval years.
+	RelativeYearlyPattern
+)
+
+// String returns the Microsoft Graph name of the kind, like relativeMonthly.
+func (k PatternKind) String() string {
+	switch k {
+	case DailyPattern:
+		return "daily"
+	case WeeklyPattern:
+		return "weekly"
+	case AbsoluteMonthlyPattern:
+		return "absoluteMonthly"
+	case RelativeMonthlyPattern:
+		return "relativeMonthly"
+	case AbsoluteYearlyPattern:
+		return "absoluteYearly"
+	case RelativeYearlyPattern:
+		return "relativeYearly"
+	}
+	return ""
+}
+
+// RangeEnd is how a Pattern ends.
+type RangeEnd int
+
+const (
+	// NoEnd patterns repeat forever.
+	NoEnd RangeEnd = iota
+
+	// EndByDate patterns end on EndDate.
+	EndByDate
+
+	// EndAfterOccurrences patterns end after Occurrences occurrences.
+	EndAfterOccurrences
+)
+
+// String returns the Microsoft Graph name of the range end, like noEnd.
+func (e RangeEnd) String() string {
+	switch e {
+	case NoEnd:
+		return "noEnd"
+	case EndByDate:
+		return "endDate"
+	case EndAfterOccurrences:
+		return "numbered"
+	}
+	return ""
+}
+
+// Pattern is a recurrence as calendar applications like Outlook present it: a
+// kind of pattern with a few settings, and a range that starts on a date and
+// may end. It is the model of Microsoft Graph and Exchange recurrences.
+//
+// Patterns convert to RRules with the same occurrences, except for a
+// DayOfMonth of 29 through 31: Outlook moves those to the last day of shorter
+// months, where the RRule skips them. Only some RRules can be converted to
+// Patterns: those that repeat once a day at most, at the time of Dtstart, with
+// at most one day of the month or one ordinal weekday per month, and yearly
+// ones only in a single month.
+type Pattern struct {
+	Kind PatternKind
+
+	// Interval is the number of days, weeks, months or years between
+	// repetitions. Zero is treated as 1.
+	Interval int
+
+	// Weekdays are the days a weekly pattern repeats on, or that a relative
+	// pattern picks the Index-th of.
+	Weekdays []time.Weekday
+
+	// Index is 1 through 4, or -1 for the last.
+	Index int
+
+	DayOfMonth int
+	Month      time.Month
+
+	// FirstDayOfWeek is the day weekly patterns count weeks from. Like
+	// Outlook, it is Sunday by default.
+	FirstDayOfWeek time.Weekday
+
+	// Start is the first date of the range, and the time of day and location
+	// of every occurrence.
+	Start time.Time
+
+	End RangeEnd
+
+	// EndDate is the last date of the range, which is inclusive. Only its
+	// date, as observed in its location, is used; Pattern sets it to midnight
+	// in Start's location.
+	EndDate time.Time
+
+	Occurrences int
+}
+
+// patternIndexes are the ordinals that relative patterns accept.
+var patternIndexes = map[int]bool{1: true, 2: true, 3: true, 4: true, -1: true}
+
+// RRule returns the pattern as an RRule, with Dtstart set to Start, or an
+// error if the pattern is invalid.
+func (p Pattern) RRule() (RRule, error) {
+	if p.Start.IsZero() {
+		return RRule{}, fmt.Errorf("pattern has no start")
+	}
+	if p.Interval < 0 {
+		return RRule{}, fmt.Errorf("pattern interval %d is negative", p.Interval)
+	}
+
+	rrule := RRule{Dtstart: p.Start}
+	if p.Interval > 1 {
+		rrule.Interval = p.Interval
+	}
+
+	switch p.Kind {
+	case DailyPattern:
+		rrule.Frequency = Daily
+
+	case WeeklyPattern:
+		rrule.Frequency = Weekly
+		if len(p.Weekdays) == 0 {
+			return RRule{}, fmt.Errorf("weekly pattern has no weekdays")
+		}
+		for _, wd := range p.Weekdays {
+			rrule.ByWeekdays = append(rrule.ByWeekdays, QualifiedWeekday{WD: wd})
+		}
+		if wkst := WeekStartOn(p.FirstDayOfWeek); !wkst.implied() {
+			rrule.WeekStart = wkst
+		}
+
+	case AbsoluteMonthlyPattern, AbsoluteYearlyPattern:
+		rrule.Frequency = Monthly
+		if p.DayOfMonth < 1 || p.DayOfMonth > 31 {
+			return RRule{}, fmt.Errorf("pattern day of month %d is not between 1 and 31", p.DayOfMonth)
+		}
+		rrule.ByMonthDays = []int{p.DayOfMonth}
+
+	case RelativeMonthlyPattern, RelativeYearlyPattern:
+		rrule.Frequency = Monthly
+		if len(p.Weekdays) == 0 {
+			return RRule{}, fmt.Errorf("%s pattern has no weekdays", p.Kind)
+		}
+		if !patternIndexes[p.Index] {
+			return RRule{}, fmt.Errorf("pattern index %d is not 1 through 4 or -1", p.Index)
+		}
+
+		if len(p.Weekdays) == 1 {
+			rrule.ByWeekdays = []QualifiedWeekday{{N: p.Index, WD: p.Weekdays[0]}}
+		} else {
+			for _, wd := range p.Weekdays {
+				rrule.ByWeekdays = append(rrule.ByWeekdays, QualifiedWeekday{WD: wd})
+			}
+			rrule.BySetPos = []int{p.Index}
+		}
+
+	default:
+		return RRule{}, fmt.Errorf("unknown pattern kind %d", p.Kind)
+	}
+
+	if p.Kind == AbsoluteYearlyPattern || p.Kind == RelativeYearlyPattern {
+		rrule.Frequency = Yearly
+		if p.Month < time.January || p.Month > time.December {
+			return RRule{}, fmt.Errorf("pattern month %d is not between 1 and 12", p.Month)
+		}
+		rrule.ByMonths = []time.Month{p.Month}
+	}
+
+	switch p.End {
+	case NoEnd:
+	case EndAfterOccurrences:
+		if p.Occurrences < 1 {
+			return RRule{}, fmt.Errorf("pattern occurrences %d is not positive", p.Occurrences)
+		}
+		rrule.Count = uint64(p.Occurrences)
+	case EndByDate:
+		if p.EndDate.IsZero() {
+			return RRule{}, fmt.Errorf("pattern has no end date")
+		}
+		y, m, d := p.EndDate.Date()
+		rrule.Until = time.Date(y, m, d+1, 0, 0, 0, 0, p.Start.Location()).Add(-time.Second)
+	default:
+		return RRule{}, fmt.Errorf("unknown pattern range end %d", p.End)
+	}
+
+	return rrule, rrule.Validate()
+}
+
+// Pattern returns the RRule as a Pattern, which requires a Dtstart, or a
+// *PatternError naming the rule part that a Pattern can't express.
+func (rrule RRule) Pattern() (Pattern, error) {
+	if rrule.Dtstart.IsZero() {
+		return Pattern{}, &PatternError{Part: "DTSTART", Reason: "patterns have a start date"}
+	}
+	if err := rrule.checkPatternParts(); err != nil {
+		return Pattern{}, err
+	}
+
+	start := rrule.Dtstart
+	p := Pattern{Interval: 1, Start: start}
+	if rrule.Interval > 1 {
+		p.Interval = rrule.Interval
+	}
+
+	for _, wd := range rrule.ByWeekdays {
+		p.Weekdays = append(p.Weekdays, wd.WD)
+	}
+
+	p.Month = start.Month()
+	switch len(rrule.ByMonths) {
+	case 0:
+	case 1:
+		p.Month = rrule.ByMonths[0]
+	default:
+		return Pattern{}, &PatternError{Part: "BYMONTH", Reason: "patterns repeat in a single month"}
+	}
+
+	switch rrule.Frequency {
+	case Daily:
+		p.Kind = DailyPattern
+		if len(rrule.ByWeekdays) > 0 {
+			if p.Interval != 1 {
+				return Pattern{}, &PatternError{Part: "INTERVAL", Reason: "patterns can't repeat on weekdays every few days"}
+			}
+			p.Kind = WeeklyPattern
+			p.FirstDayOfWeek = rrule.weekStart()
+		}
+		if len(rrule.ByMonthDays) > 0 || len(rrule.ByMonths) > 0 {
+			return Pattern{}, &PatternError{Part: "FREQ", Reason: "daily patterns can't be limited to days of the month or months"}
+		}
+		p.Month = 0
+
+	case Weekly:
+		p.Kind = WeeklyPattern
+		if len(rrule.ByWeekdays) == 0 {
+			p.Weekdays = []time.Weekday{start.Weekday()}
+		}
+		p.FirstDayOfWeek = rrule.weekStart()
+		if len(rrule.ByMonths) > 0 {
+			return Pattern{}, &PatternError{Part: "BYMONTH", Reason: "weekly patterns can't be limited to months"}
+		}
+		p.Month = 0
+
+	case Monthly, Yearly:
+		if rrule.Frequency == Monthly && len(rrule.ByMonths) > 0 {
+			return Pattern{}, &PatternError{Part: "BYMONTH", Reason: "monthly patterns can't be limited to months"}
+		}
+
+		index, err := rrule.patternIndex()
+		if err != nil {
+			return Pattern{}, err
+		}
+
+		if index == 0 {
+			p.Kind = AbsoluteMonthlyPattern
+			p.DayOfMonth = start.Day()
+			switch {
+			case len(rrule.ByWeekdays) > 0:
+				return Pattern{}, &PatternError{Part: "BYDAY", Reason: "patterns can't repeat on every one of some weekdays in a month or year"}
+			case len(rrule.ByMonthDays) == 1 && rrule.ByMonthDays[0] > 0:
+				p.DayOfMonth = rrule.ByMonthDays[0]
+			case len(rrule.ByMonthDays) > 0:
+				return Pattern{}, &PatternError{Part: "BYMONTHDAY", Reason: "patterns repeat on a single day of the month, counted from its start"}
+			}
+		} else {
+			p.Kind = RelativeMonthlyPattern
+			p.Index = index
+			if len(rrule.ByMonthDays) > 0 {
+				return Pattern{}, &PatternError{Part: "BYMONTHDAY", Reason: "patterns can't combine days of the month with weekdays"}
+			}
+		}
+
+		// Without BYMONTH, a yearly rule picks its day from every month, or
+		// from the whole year, rather than from Dtstart's month.
+		if rrule.Frequency == Yearly && len(rrule.ByMonths) == 0 {
+			switch {
+			case len(rrule.BySetPos) > 0:
+				return Pattern{}, &PatternError{Part: "BYSETPOS", Reason: "yearly patterns pick a weekday of the month given by BYMONTH"}
+			case index != 0:
+				return Pattern{}, &PatternError{Part: "BYDAY", Reason: "yearly patterns pick a weekday of the month given by BYMONTH"}
+			case len(rrule.ByMonthDays) > 0:
+				return Pattern{}, &PatternError{Part: "BYMONTHDAY", Reason: "yearly patterns repeat on a day of the month given by BYMONTH"}
+			}
+		}
+
+		switch {
+		case rrule.Frequency == Monthly:
+			p.Month = 0
+		case p.Kind == AbsoluteMonthlyPattern:
+			p.Kind = AbsoluteYearlyPattern
+		default:
+			p.Kind = RelativeYearlyPattern
+		}
+
+	default:
+		return Pattern{}, &PatternError{Part: "FREQ", Reason: fmt.Sprintf("patterns can't repeat %s", strings.ToLower(rrule.Frequency.String()))}
+	}
+
+	switch {
+	case rrule.Count > 0:
+		p.End = EndAfterOccurrences
+		p.Occurrences = int(rrule.Count)
+	case !rrule.Until.IsZero():
+		p.End = EndByDate
+		until := rrule.Until
+		if rrule.UntilFloating {
+			until = wallClockIn(until, start.Location())
+		}
+		until = until.In(start.Location())
+		if until.Before(start) {
+			return Pattern{}, &PatternError{Part: "UNTIL", Reason: "patterns end on or after their start"}
+		}
+		p.EndDate = time.Date(until.Year(), until.Month(), until.Day(), 0, 0, 0, 0, start.Location())
+	}
+
+	return p, nil
+}
+
+// checkPatternParts returns an error for rule parts that patterns never have.
+func (rrule RRule) checkPatternParts() error {
+	switch {
+	case len(rrule.BySeconds) > 0:
+		return &PatternError{Part: "BYSECOND", Reason: "patterns repeat at the time of their start"}
+	case len(rrule.ByMinutes) > 0:
+		return &PatternError{Part: "BYMINUTE", Reason: "patterns repeat at the time of their start"}
+	case len(rrule.ByHours) > 0:
+		return &PatternError{Part: "BYHOUR", Reason: "patterns repeat at the time of their start"}
+	case len(rrule.ByWeekNumbers) > 0:
+		return &PatternError{Part: "BYWEEKNO", Reason: "patterns have no week numbers"}
+	case len(rrule.ByYearDays) > 0:
+		return &PatternError{Part: "BYYEARDAY", Reason: "patterns have no days of the year"}
+	case len(rrule.ByLeapMonths) > 0:
+		return &PatternError{Part: "BYMONTH", Reason: "patterns have no leap months"}
+	case rrule.RScale != Gregorian:
+		return &PatternError{Part: "RSCALE", Reason: "patterns only use the Gregorian calendar"}
+	case rrule.InvalidBehavior != OmitInvalid:
+		return &PatternError{Part: "SKIP", Reason: "patterns can't choose how invalid dates are skipped"}
+	}
+	return nil
+}
+
+// patternIndex returns the index of a relative pattern, which picks one of its
+// weekdays in each month, or 0 for an absolute pattern.
+func (rrule RRule) patternIndex() (int, error) {
+	switch {
+	case len(rrule.BySetPos) > 1:
+		return 0, &PatternError{Part: "BYSETPOS", Reason: "patterns pick a single weekday"}
+	case len(rrule.BySetPos) == 1:
+		if !patternIndexes[rrule.BySetPos[0]] || len(rrule.ByWeekdays) == 0 {
+			return 0, &PatternError{Part: "BYSETPOS", Reason: "patterns pick the first through fourth or last of some weekdays"}
+		}
+		for _, wd := range rrule.ByWeekdays {
+			if wd.N != 0 {
+				return 0, &PatternError{Part: "BYDAY", Reason: "patterns can't combine BYSETPOS with numbered weekdays"}
+			}
+		}
+		return rrule.BySetPos[0], nil
+	case len(rrule.ByWeekdays) == 1 && rrule.ByWeekdays[0].N != 0:
+		if !patternIndexes[rrule.ByWeekdays[0].N] {
+			return 0, &PatternError{Part: "BYDAY", Reason: "patterns pick the first through fourth or last weekday"}
+		}
+		return rrule.ByWeekdays[0].N, nil
+	}
+
+	for _, wd := range rrule.ByWeekdays {
+		if wd.N != 0 {
+			return 0, &PatternError{Part: "BYDAY", Reason: "patterns pick a single numbered weekday"}
+		}
+	}
+	return 0, nil
+}
+
+// PatternError is returned when an RRule can't be expressed as a Pattern, or
+// in a format based on one.
+type PatternError struct {
+	// Part is the name of the rule part that can't be expressed, like BYHOUR.
+	Part string
+
+	Reason string
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("cannot convert %s to a pattern: %s", e.Part, e.Reason)
+}
@@ -0,0 +1,139 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern(t *testing.T) {
+	start := time.Date(2017, time.September, 4, 12, 0, 0, 0, NewYork()) // a Monday
+
+	for _, tc := range []struct {
+		Pattern Pattern
+		RRule   string
+	}{
+		{
+			Pattern{Kind: DailyPattern, Interval: 3, Start: start},
+			"FREQ=DAILY;INTERVAL=3",
+		},
+		{
+			Pattern{Kind: WeeklyPattern, Interval: 2, Weekdays: []time.Weekday{time.Monday, time.Friday}, Start: start, End: EndAfterOccurrences, Occurrences: 6},
+			"FREQ=WEEKLY;COUNT=6;INTERVAL=2;BYDAY=MO,FR;WKST=SU",
+		},
+		{
+			Pattern{Kind: WeeklyPattern, Interval: 1, Weekdays: []time.Weekday{time.Tuesday}, FirstDayOfWeek: time.Monday, Start: start},
+			"FREQ=WEEKLY;BYDAY=TU",
+		},
+		{
+			Pattern{Kind: AbsoluteMonthlyPattern, Interval: 1, DayOfMonth: 31, Start: start, End: EndByDate, EndDate: time.Date(2018, time.March, 31, 0, 0, 0, 0, NewYork())},
+			"FREQ=MONTHLY;UNTIL=20180401T035959Z;BYMONTHDAY=31",
+		},
+		{
+			Pattern{Kind: RelativeMonthlyPattern, Interval: 1, Weekdays: []time.Weekday{time.Thursday}, Index: 4, Start: start},
+			"FREQ=MONTHLY;BYDAY=4TH",
+		},
+		{
+			Pattern{Kind: RelativeMonthlyPattern, Interval: 1, Weekdays: []time.Weekday{time.Saturday, time.Sunday}, Index: -1, Start: start},
+			"FREQ=MONTHLY;BYDAY=SA,SU;BYSETPOS=-1",
+		},
+		{
+			Pattern{Kind: AbsoluteYearlyPattern, Interval: 1, DayOfMonth: 4, Month: time.September, Start: start},
+			"FREQ=YEARLY;BYMONTHDAY=4;BYMONTH=9",
+		},
+		{
+			Pattern{Kind: RelativeYearlyPattern, Interval: 2, Weekdays: []time.Weekday{time.Monday}, Index: 1, Month: time.September, Start: start},
+			"FREQ=YEARLY;INTERVAL=2;BYDAY=1MO;BYMONTH=9",
+		},
+	} {
+		t.Run(tc.RRule, func(t *testing.T) {
+			rrule, err := tc.Pattern.RRule()
+			require.NoError(t, err)
+			assert.Equal(t, tc.RRule, rrule.String())
+			assert.Equal(t, start, rrule.Dtstart)
+
+			// The conversion is lossless.
+			p, err := rrule.Pattern()
+			require.NoError(t, err)
+			assert.Equal(t, tc.Pattern, p)
+		})
+	}
+}
+
+func TestPatternFromRRule(t *testing.T) {
+	start := time.Date(2017, time.September, 4, 12, 0, 0, 0, time.UTC) // a Monday
+
+	p, err := RRule{Frequency: Weekly, Dtstart: start}.Pattern()
+	require.NoError(t, err)
+	assert.Equal(t, Pattern{Kind: WeeklyPattern, Interval: 1, Weekdays: []time.Weekday{time.Monday}, FirstDayOfWeek: time.Monday, Start: start}, p)
+
+	p, err = RRule{Frequency: Daily, Dtstart: start, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Wednesday}}}.Pattern()
+	require.NoError(t, err)
+	assert.Equal(t, WeeklyPattern, p.Kind)
+	assert.Equal(t, []time.Weekday{time.Monday, time.Wednesday}, p.Weekdays)
+
+	p, err = RRule{Frequency: Yearly, Dtstart: start, Until: time.Date(2020, time.September, 4, 0, 0, 0, 0, time.UTC), UntilFloating: true}.Pattern()
+	require.NoError(t, err)
+	assert.Equal(t, Pattern{Kind: AbsoluteYearlyPattern, Interval: 1, DayOfMonth: 4, Month: time.September, Start: start, End: EndByDate, EndDate: time.Date(2020, time.September, 4, 0, 0, 0, 0, time.UTC)}, p)
+
+	for part, rrule := range map[string]RRule{
+		"DTSTART":    {Frequency: Daily},
+		"FREQ":       {Frequency: Hourly, Dtstart: start},
+		"BYHOUR":     {Frequency: Daily, Dtstart: start, ByHours: []int{9}},
+		"INTERVAL":   {Frequency: Daily, Dtstart: start, Interval: 2, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}},
+		"BYDAY":      {Frequency: Monthly, Dtstart: start, ByWeekdays: []QualifiedWeekday{{N: 5, WD: time.Monday}}},
+		"BYMONTHDAY": {Frequency: Monthly, Dtstart: start, ByMonthDays: []int{1, 15}},
+		"BYSETPOS":   {Frequency: Monthly, Dtstart: start, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}, BySetPos: []int{5}},
+		"SKIP":       {Frequency: Monthly, Dtstart: start, InvalidBehavior: NextInvalid},
+		"UNTIL":      {Frequency: Daily, Dtstart: start, Until: start.AddDate(0, 0, -1)},
+	} {
+		_, err := rrule.Pattern()
+		require.Error(t, err, part)
+		require.IsType(t, &PatternError{}, err, part)
+		assert.Equal(t, part, err.(*PatternError).Part)
+	}
+
+	// yearly rules without BYMONTH pick from the whole year or every month,
+	// not from the month of Dtstart.
+	for str, part := range map[string]string{
+		"FREQ=YEARLY;BYDAY=1MO":              "BYDAY",
+		"FREQ=YEARLY;BYDAY=-1FR":             "BYDAY",
+		"FREQ=YEARLY;BYDAY=MO,TU;BYSETPOS=1": "BYSETPOS",
+		"FREQ=YEARLY;BYMONTHDAY=4":           "BYMONTHDAY",
+	} {
+		rrule := MustRRule(str)
+		rrule.Dtstart = start
+		_, err := rrule.Pattern()
+		require.Error(t, err, str)
+		require.IsType(t, &PatternError{}, err, str)
+		assert.Equal(t, part, err.(*PatternError).Part, str)
+	}
+
+	p, err = RRule{Frequency: Yearly, Dtstart: start, ByMonths: []time.Month{time.March}, ByWeekdays: []QualifiedWeekday{{N: 1, WD: time.Monday}}}.Pattern()
+	require.NoError(t, err)
+	assert.Equal(t, RelativeYearlyPattern, p.Kind)
+	assert.Equal(t, time.March, p.Month)
+	assert.Equal(t, 1, p.Index)
+}
+
+func TestPatternErrors(t *testing.T) {
+	start := time.Date(2017, time.September, 4, 12, 0, 0, 0, time.UTC)
+
+	for name, p := range map[string]Pattern{
+		"start":        {Kind: DailyPattern},
+		"interval":     {Kind: DailyPattern, Interval: -1, Start: start},
+		"kind":         {Kind: 42, Start: start},
+		"weekdays":     {Kind: WeeklyPattern, Start: start},
+		"day of month": {Kind: AbsoluteMonthlyPattern, DayOfMonth: 32, Start: start},
+		"index":        {Kind: RelativeMonthlyPattern, Weekdays: []time.Weekday{time.Monday}, Index: 5, Start: start},
+		"month":        {Kind: AbsoluteYearlyPattern, DayOfMonth: 1, Start: start},
+		"occurrences":  {Kind: DailyPattern, Start: start, End: EndAfterOccurrences},
+		"end date":     {Kind: DailyPattern, Start: start, End: EndByDate},
+		"end":          {Kind: DailyPattern, Start: start, End: 42},
+	} {
+		_, err := p.RRule()
+		assert.Error(t, err, name)
+	}
+}
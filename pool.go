@@ -0,0 +1,82 @@
+package rrule
+
+import (
+	"sync"
+	"time"
+)
+
+// timesPool holds the buffers steppers make the times of each key time in, as
+// *[]time.Time, so that the iterators of Expand, which are thrown away after
+// one call, don't grow new ones each time.
+var timesPool sync.Pool
+
+// maxPooledTimes is the largest buffer put back in timesPool, so a pattern with
+// very many times per key time doesn't pin their memory.
+const maxPooledTimes = 4096
+
+// timesBuf is the buffer a stepper makes the times of each key time in. It's
+// taken from timesPool when first needed, and put back by release.
+type timesBuf struct {
+	buf *[]time.Time
+}
+
+// reuse returns the buffer, emptied.
+func (b *timesBuf) reuse() []time.Time {
+	if b.buf == nil {
+		b.buf, _ = timesPool.Get().(*[]time.Time)
+		if b.buf == nil {
+			b.buf = new([]time.Time)
+		}
+	}
+	return (*b.buf)[:0]
+}
+
+// keep records tt, made from the buffer reuse returned, as the buffer, in
+// case it grew.
+func (b *timesBuf) keep(tt []time.Time) []time.Time {
+	*b.buf = tt
+	return tt
+}
+
+func (b *timesBuf) release() {
+	if b.buf != nil && cap(*b.buf) <= maxPooledTimes {
+		timesPool.Put(b.buf)
+	}
+	b.buf = nil
+}
+
+// releaser is an iterator or stepper with buffers it can put back in their
+// pools.
+type releaser interface {
+	release()
+}
+
+// release puts the buffers of it back in their pools. Only iterators made and
+// thrown away in this package, like those of Expand, are released, as it
+// mustn't be used afterward.
+func release(it Iterator) {
+	if r, ok := it.(releaser); ok {
+		r.release()
+	}
+}
+
+func (i *iterator) release() {
+	// the queue is held in the stepper's buffer.
+	i.queue = nil
+	if r, ok := i.steps.(releaser); ok {
+		r.release()
+	}
+}
+
+func (gi *groupIterator) release() {
+	for _, it := range gi.iters {
+		release(it)
+	}
+}
+
+func (ri *recurrenceIterator) release() {
+	ri.rrules.release()
+	ri.exrules.release()
+}
+
+func (li *locationIterator) release() { release(li.it) }
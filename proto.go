@@ -0,0 +1,594 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Protocol buffer wire types.
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+var errProtoTruncated = errors.New("protobuf message is truncated")
+
+// ToProto encodes the pattern as an RRule message, defined in rrule.proto, in
+// the protocol buffer wire format. The pattern is validated first, so that
+// decoders can rely on it.
+func (rrule RRule) ToProto() ([]byte, error) {
+	if err := rrule.Validate(); err != nil {
+		return nil, err
+	}
+	return rrule.appendProto(nil), nil
+}
+
+// FromProto decodes and validates a pattern from an RRule message, defined in
+// rrule.proto, in the protocol buffer wire format.
+func FromProto(b []byte) (RRule, error) {
+	rrule, err := rruleFromProto(b)
+	if err != nil {
+		return rrule, err
+	}
+	return rrule, rrule.Validate()
+}
+
+// ToProto encodes the recurrence as a Recurrence message, defined in
+// rrule.proto, in the protocol buffer wire format. Its patterns are validated
+// first.
+func (r *Recurrence) ToProto() ([]byte, error) {
+	var b []byte
+	if !r.Dtstart.IsZero() {
+		b = appendProtoBytes(b, 1, appendProtoTime(nil, r.Dtstart))
+	}
+	b = appendProtoBool(b, 2, r.FloatingLocation)
+	for _, rrule := range r.RRules {
+		if err := rrule.Validate(); err != nil {
+			return nil, err
+		}
+		b = appendProtoBytes(b, 3, rrule.appendProto(nil))
+	}
+	for _, t := range r.RDates {
+		b = appendProtoBytes(b, 4, appendProtoTime(nil, t))
+	}
+	for _, exrule := range r.ExRules {
+		if err := exrule.Validate(); err != nil {
+			return nil, err
+		}
+		b = appendProtoBytes(b, 5, exrule.appendProto(nil))
+	}
+	for _, t := range r.ExDates {
+		b = appendProtoBytes(b, 6, appendProtoTime(nil, t))
+	}
+	b = appendProtoBool(b, 7, r.AllDay)
+	return b, nil
+}
+
+// RecurrenceFromProto decodes a recurrence from a Recurrence message, defined
+// in rrule.proto, in the protocol buffer wire format. Its patterns are
+// validated.
+func RecurrenceFromProto(b []byte) (*Recurrence, error) {
+	r := &Recurrence{}
+	p := protoReader{b: b}
+	for !p.done() {
+		field, wt, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+
+		switch field {
+		case 1, 4, 6:
+			msg, err := p.bytes(wt)
+			if err != nil {
+				return nil, err
+			}
+			t, err := timeFromProto(msg)
+			if err != nil {
+				return nil, err
+			}
+			switch field {
+			case 1:
+				r.Dtstart = t
+			case 4:
+				r.RDates = append(r.RDates, t)
+			case 6:
+				r.ExDates = append(r.ExDates, t)
+			}
+		case 2:
+			v, err := p.varint(wt)
+			if err != nil {
+				return nil, err
+			}
+			r.FloatingLocation = v != 0
+		case 7:
+			v, err := p.varint(wt)
+			if err != nil {
+				return nil, err
+			}
+			r.AllDay = v != 0
+		case 3, 5:
+			msg, err := p.bytes(wt)
+			if err != nil {
+				return nil, err
+			}
+			rrule, err := FromProto(msg)
+			if err != nil {
+				return nil, err
+			}
+			if field == 3 {
+				r.RRules = append(r.RRules, rrule)
+			} else {
+				r.ExRules = append(r.ExRules, rrule)
+			}
+		default:
+			if err := p.skip(wt); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+func (rrule RRule) appendProto(b []byte) []byte {
+	b = appendProtoUint(b, 1, uint64(rrule.Frequency))
+	if !rrule.Until.IsZero() {
+		b = appendProtoBytes(b, 2, appendProtoTime(nil, rrule.Until))
+	}
+	b = appendProtoBool(b, 3, rrule.UntilFloating)
+	b = appendProtoUint(b, 4, rrule.Count)
+	if !rrule.Dtstart.IsZero() {
+		b = appendProtoBytes(b, 5, appendProtoTime(nil, rrule.Dtstart))
+	}
+	b = appendProtoUint(b, 6, uint64(int64(rrule.Interval)))
+
+	b = appendProtoSints(b, 7, rrule.BySeconds)
+	b = appendProtoSints(b, 8, rrule.ByMinutes)
+	b = appendProtoSints(b, 9, rrule.ByHours)
+	for _, wd := range rrule.ByWeekdays {
+		var msg []byte
+		msg = appendProtoUint(msg, 1, zigzag(wd.N))
+		msg = appendProtoUint(msg, 2, uint64(wd.WD)+1)
+		b = appendProtoBytes(b, 10, msg)
+	}
+	b = appendProtoSints(b, 11, rrule.ByMonthDays)
+	b = appendProtoSints(b, 12, rrule.ByWeekNumbers)
+	months := make([]int, len(rrule.ByMonths))
+	for i, m := range rrule.ByMonths {
+		months[i] = int(m)
+	}
+	b = appendProtoSints(b, 13, months)
+	b = appendProtoSints(b, 14, rrule.ByLeapMonths)
+	b = appendProtoSints(b, 15, rrule.ByYearDays)
+	b = appendProtoSints(b, 16, rrule.BySetPos)
+
+	b = appendProtoUint(b, 17, uint64(rrule.InvalidBehavior))
+	b = appendProtoUint(b, 18, uint64(rrule.RScale))
+	if wd, ok := rrule.WeekStart.Weekday(); ok {
+		b = appendProtoUint(b, 19, uint64(wd)+1)
+	}
+
+	names := make([]string, 0, len(rrule.Extensions))
+	for name := range rrule.Extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var entry []byte
+		entry = appendProtoString(entry, 1, name)
+		entry = appendProtoString(entry, 2, rrule.Extensions[name])
+		b = appendProtoBytes(b, 20, entry)
+	}
+
+	b = appendProtoUint(b, 21, uint64(rrule.Nonexistent))
+	b = appendProtoUint(b, 22, uint64(rrule.Ambiguous))
+	b = appendProtoUint(b, 23, uint64(rrule.Stepping))
+	b = appendProtoBool(b, 24, rrule.AllDay)
+	b = appendProtoBool(b, 25, rrule.UntilExclusive)
+	b = appendProtoUint(b, 26, uint64(rrule.LeapSecond))
+
+	return b
+}
+
+func rruleFromProto(b []byte) (RRule, error) {
+	rrule := RRule{}
+	p := protoReader{b: b}
+	for !p.done() {
+		field, wt, err := p.field()
+		if err != nil {
+			return rrule, err
+		}
+
+		var v uint64
+		var ints []int
+		var msg []byte
+		switch field {
+		case 1, 3, 4, 6, 17, 18, 19, 21, 22, 23, 24, 25, 26:
+			v, err = p.varint(wt)
+		case 7, 8, 9, 11, 12, 13, 14, 15, 16:
+			ints, err = p.sints(wt)
+		case 2, 5, 10, 20:
+			msg, err = p.bytes(wt)
+		default:
+			err = p.skip(wt)
+		}
+		if err != nil {
+			return rrule, err
+		}
+
+		switch field {
+		case 1:
+			rrule.Frequency = Frequency(v)
+		case 2:
+			rrule.Until, err = timeFromProto(msg)
+		case 3:
+			rrule.UntilFloating = v != 0
+		case 4:
+			rrule.Count = v
+		case 5:
+			rrule.Dtstart, err = timeFromProto(msg)
+		case 6:
+			rrule.Interval = int(int32(v))
+		case 7:
+			rrule.BySeconds = append(rrule.BySeconds, ints...)
+		case 8:
+			rrule.ByMinutes = append(rrule.ByMinutes, ints...)
+		case 9:
+			rrule.ByHours = append(rrule.ByHours, ints...)
+		case 10:
+			var wd QualifiedWeekday
+			wd, err = weekdayFromProto(msg)
+			rrule.ByWeekdays = append(rrule.ByWeekdays, wd)
+		case 11:
+			rrule.ByMonthDays = append(rrule.ByMonthDays, ints...)
+		case 12:
+			rrule.ByWeekNumbers = append(rrule.ByWeekNumbers, ints...)
+		case 13:
+			for _, m := range ints {
+				rrule.ByMonths = append(rrule.ByMonths, time.Month(m))
+			}
+		case 14:
+			rrule.ByLeapMonths = append(rrule.ByLeapMonths, ints...)
+		case 15:
+			rrule.ByYearDays = append(rrule.ByYearDays, ints...)
+		case 16:
+			rrule.BySetPos = append(rrule.BySetPos, ints...)
+		case 17:
+			rrule.InvalidBehavior = InvalidBehavior(v)
+		case 18:
+			rrule.RScale = RScale(v)
+		case 19:
+			if v != 0 {
+				rrule.WeekStart = WeekStartOn(time.Weekday(v - 1))
+			}
+		case 20:
+			var name, value string
+			name, value, err = extensionFromProto(msg)
+			if rrule.Extensions == nil {
+				rrule.Extensions = map[string]string{}
+			}
+			rrule.Extensions[name] = value
+		case 21:
+			rrule.Nonexistent = NonexistentBehavior(v)
+		case 22:
+			rrule.Ambiguous = AmbiguousBehavior(v)
+		case 23:
+			rrule.Stepping = Stepping(v)
+		case 24:
+			rrule.AllDay = v != 0
+		case 25:
+			rrule.UntilExclusive = v != 0
+		case 26:
+			rrule.LeapSecond = LeapSecondBehavior(v)
+		}
+		if err != nil {
+			return rrule, err
+		}
+	}
+
+	if rrule.Frequency > Yearly {
+		return rrule, fmt.Errorf("unknown frequency %d", rrule.Frequency)
+	}
+	if rrule.InvalidBehavior > PrevInvalid {
+		return rrule, fmt.Errorf("unknown skip %d", rrule.InvalidBehavior)
+	}
+	if rrule.Nonexistent > BackwardNonexistent {
+		return rrule, fmt.Errorf("unknown nonexistent time behavior %d", rrule.Nonexistent)
+	}
+	if rrule.Ambiguous > SecondAmbiguous {
+		return rrule, fmt.Errorf("unknown ambiguous time behavior %d", rrule.Ambiguous)
+	}
+	if rrule.Stepping > WallClockStepping {
+		return rrule, fmt.Errorf("unknown stepping %d", rrule.Stepping)
+	}
+	if rrule.LeapSecond > ClampLeapSecond {
+		return rrule, fmt.Errorf("unknown leap second behavior %d", rrule.LeapSecond)
+	}
+	if wd, ok := rrule.WeekStart.Weekday(); ok && wd > time.Saturday {
+		return rrule, fmt.Errorf("unknown week start %d", wd)
+	}
+	return rrule, nil
+}
+
+func weekdayFromProto(b []byte) (QualifiedWeekday, error) {
+	var wd QualifiedWeekday
+	var weekday uint64
+	p := protoReader{b: b}
+	for !p.done() {
+		field, wt, err := p.field()
+		if err != nil {
+			return wd, err
+		}
+
+		switch field {
+		case 1:
+			var v uint64
+			v, err = p.varint(wt)
+			wd.N = unzigzag(v)
+		case 2:
+			weekday, err = p.varint(wt)
+		default:
+			err = p.skip(wt)
+		}
+		if err != nil {
+			return wd, err
+		}
+	}
+
+	if weekday < 1 || weekday > 7 {
+		return wd, fmt.Errorf("unknown weekday %d", weekday)
+	}
+	wd.WD = time.Weekday(weekday - 1)
+	return wd, nil
+}
+
+func extensionFromProto(b []byte) (name, value string, err error) {
+	p := protoReader{b: b}
+	for !p.done() {
+		field, wt, err := p.field()
+		if err != nil {
+			return name, value, err
+		}
+
+		var s []byte
+		switch field {
+		case 1, 2:
+			s, err = p.bytes(wt)
+		default:
+			err = p.skip(wt)
+		}
+		if err != nil {
+			return name, value, err
+		}
+
+		switch field {
+		case 1:
+			name = string(s)
+		case 2:
+			value = string(s)
+		}
+	}
+	return name, value, nil
+}
+
+func appendProtoTime(b []byte, t time.Time) []byte {
+	b = appendProtoUint(b, 1, uint64(t.Unix()))
+	b = appendProtoUint(b, 2, uint64(t.Nanosecond()))
+	if loc := t.Location(); loc != time.UTC {
+		b = appendProtoString(b, 3, loc.String())
+	}
+	return b
+}
+
+func timeFromProto(b []byte) (time.Time, error) {
+	var seconds, nanos uint64
+	var zone []byte
+	p := protoReader{b: b}
+	for !p.done() {
+		field, wt, err := p.field()
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		switch field {
+		case 1:
+			seconds, err = p.varint(wt)
+		case 2:
+			nanos, err = p.varint(wt)
+		case 3:
+			zone, err = p.bytes(wt)
+		default:
+			err = p.skip(wt)
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	loc := time.UTC
+	if len(zone) > 0 {
+		var err error
+		if loc, err = LoadLocation(string(zone)); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Unix(int64(seconds), int64(int32(nanos))).In(loc), nil
+}
+
+func appendProtoVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendProtoTag(b []byte, field, wt int) []byte {
+	return appendProtoVarint(b, uint64(field)<<3|uint64(wt))
+}
+
+// appendProtoUint appends a varint field, omitting it if v is the default
+// zero value.
+func appendProtoUint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, protoVarint)
+	return appendProtoVarint(b, v)
+}
+
+func appendProtoBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return appendProtoUint(b, field, 1)
+}
+
+// appendProtoBytes appends a length-delimited field, like an embedded message.
+// Unlike the scalar fields, it is written even if empty.
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	b = appendProtoTag(b, field, protoBytes)
+	b = appendProtoVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendProtoString(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	return appendProtoBytes(b, field, []byte(v))
+}
+
+// appendProtoSints appends a packed repeated sint32 field.
+func appendProtoSints(b []byte, field int, vs []int) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, v := range vs {
+		packed = appendProtoVarint(packed, zigzag(v))
+	}
+	return appendProtoBytes(b, field, packed)
+}
+
+func zigzag(v int) uint64 {
+	return uint64(int64(v)<<1 ^ int64(v)>>63)
+}
+
+func unzigzag(v uint64) int {
+	return int(int32(v>>1) ^ -int32(v&1))
+}
+
+// protoReader reads the fields of a message in the protocol buffer wire
+// format.
+type protoReader struct {
+	b []byte
+}
+
+func (p *protoReader) done() bool {
+	return len(p.b) == 0
+}
+
+func (p *protoReader) field() (field, wt int, err error) {
+	tag, err := p.rawVarint()
+	if err != nil {
+		return 0, 0, err
+	}
+	if tag>>3 == 0 {
+		return 0, 0, errors.New("protobuf field number 0 is invalid")
+	}
+	return int(tag >> 3), int(tag & 7), nil
+}
+
+func (p *protoReader) rawVarint() (uint64, error) {
+	var v uint64
+	for i := 0; i < 10; i++ {
+		if i >= len(p.b) {
+			return 0, errProtoTruncated
+		}
+		c := p.b[i]
+		v |= uint64(c&0x7f) << (7 * uint(i))
+		if c < 0x80 {
+			p.b = p.b[i+1:]
+			return v, nil
+		}
+	}
+	return 0, errors.New("protobuf varint overflows 64 bits")
+}
+
+func (p *protoReader) rawBytes() ([]byte, error) {
+	n, err := p.rawVarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(p.b)) {
+		return nil, errProtoTruncated
+	}
+	v := p.b[:n]
+	p.b = p.b[n:]
+	return v, nil
+}
+
+func (p *protoReader) varint(wt int) (uint64, error) {
+	if wt != protoVarint {
+		return 0, fmt.Errorf("protobuf wire type %d is not a varint", wt)
+	}
+	return p.rawVarint()
+}
+
+func (p *protoReader) bytes(wt int) ([]byte, error) {
+	if wt != protoBytes {
+		return nil, fmt.Errorf("protobuf wire type %d is not length-delimited", wt)
+	}
+	return p.rawBytes()
+}
+
+// sints reads a repeated sint32 field, which may be packed or not.
+func (p *protoReader) sints(wt int) ([]int, error) {
+	if wt == protoVarint {
+		v, err := p.rawVarint()
+		return []int{unzigzag(v)}, err
+	}
+
+	packed, err := p.bytes(wt)
+	if err != nil {
+		return nil, err
+	}
+
+	var ints []int
+	r := protoReader{b: packed}
+	for !r.done() {
+		v, err := r.rawVarint()
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, unzigzag(v))
+	}
+	return ints, nil
+}
+
+// skip skips a field that isn't known, as protocol buffers allow.
+func (p *protoReader) skip(wt int) error {
+	switch wt {
+	case protoVarint:
+		_, err := p.rawVarint()
+		return err
+	case protoBytes:
+		_, err := p.rawBytes()
+		return err
+	case protoFixed64, protoFixed32:
+		n := 8
+		if wt == protoFixed32 {
+			n = 4
+		}
+		if len(p.b) < n {
+			return errProtoTruncated
+		}
+		p.b = p.b[n:]
+		return nil
+	}
+	return fmt.Errorf("protobuf wire type %d is not supported", wt)
+}
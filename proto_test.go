@@ -0,0 +1,79 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProto(t *testing.T) {
+	b, err := RRule{Frequency: Weekly, Count: 3, ByHours: []int{9, 17}}.ToProto()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x08, 0x04, 0x20, 0x03, 0x4a, 0x02, 0x12, 0x22}, b)
+
+	rrule := RRule{
+		Frequency:       Monthly,
+		Dtstart:         time.Date(1900, time.January, 1, 9, 0, 0, 500, NewYork()),
+		Until:           time.Date(2019, time.March, 1, 9, 0, 0, 0, time.UTC),
+		UntilFloating:   true,
+		Interval:        2,
+		ByWeekdays:      []QualifiedWeekday{{N: -1, WD: time.Friday}, {WD: time.Saturday}},
+		ByMonthDays:     []int{-1, 1},
+		ByMonths:        []time.Month{time.June},
+		ByLeapMonths:    []int{5},
+		BySetPos:        []int{-1},
+		InvalidBehavior: NextInvalid,
+		RScale:          Chinese,
+		WeekStart:       WeekStartOn(time.Sunday),
+		Extensions:      map[string]string{"X-NAME": "standup", "X-EMPTY": ""},
+	}
+
+	b, err = rrule.ToProto()
+	require.NoError(t, err)
+
+	decoded, err := FromProto(b)
+	require.NoError(t, err)
+	assert.Equal(t, rrule.String(), decoded.String())
+	assert.True(t, rrule.Dtstart.Equal(decoded.Dtstart))
+	assert.Equal(t, rrule.Dtstart.Location().String(), decoded.Dtstart.Location().String())
+	assert.Equal(t, rrule.Extensions, decoded.Extensions)
+
+	_, err = RRule{Frequency: Daily, Count: 2, Until: rrule.Until}.ToProto()
+	assert.Error(t, err)
+}
+
+func TestFromProto(t *testing.T) {
+	// unpacked BYHOUR values and an unknown fixed32 field
+	decoded, err := FromProto([]byte{0x08, 0x03, 0x48, 0x12, 0x48, 0x22, 0x9d, 0x06, 1, 2, 3, 4})
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;BYHOUR=9,17", decoded.String())
+
+	for _, b := range [][]byte{
+		{0x08},                               // truncated varint
+		{0x12, 0x05, 0x08},                   // truncated message
+		{0x08, 0x09},                         // unknown frequency
+		{0x0a, 0x00},                         // wrong wire type
+		{0x52, 0x02, 0x10, 0x09},             // unknown weekday
+		{0x08, 0x03, 0x22, 0x00, 0x20, 0x02}, // COUNT and UNTIL
+	} {
+		_, err := FromProto(b)
+		assert.Error(t, err, "%x", b)
+	}
+}
+
+func TestRecurrenceProto(t *testing.T) {
+	r := recurrenceCases[2].Recurrence
+
+	b, err := r.ToProto()
+	require.NoError(t, err)
+
+	decoded, err := RecurrenceFromProto(b)
+	require.NoError(t, err)
+	assert.Equal(t, r.String(), decoded.String())
+	assert.Equal(t, recurrenceCases[2].Dates, rfcAll(All(decoded.Iterator(), 0)))
+
+	_, err = (&Recurrence{ExRules: []RRule{{Frequency: Daily, BySetPos: []int{1}}}}).ToProto()
+	assert.Error(t, err)
+}
@@ -0,0 +1,242 @@
+package rrule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// quartzWeekdays is the Quartz day of week field, in which Sunday is 1.
+var quartzWeekdays = cronField{name: "day of week", min: 1, max: 7, names: map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}}
+
+// workdays are the weekdays matched by the Quartz W token.
+var workdays = []QualifiedWeekday{
+	{WD: time.Monday}, {WD: time.Tuesday}, {WD: time.Wednesday}, {WD: time.Thursday}, {WD: time.Friday},
+}
+
+// FromQuartz converts a Quartz scheduler cron expression to the pattern of its
+// schedule. The expression has six fields, seconds, minutes, hours, day of
+// month, month and day of week, and an optional seventh for the year, which
+// must be * if present. Days of the week are numbered from 1 for Sunday, and
+// one of the two day fields must be ?.
+//
+// Beyond the fields of FromCron, the day of month may be L for the last day,
+// L-3 for three days before it, 1W for the first weekday or LW for the last
+// weekday, and the day of week may be like MON#2 for the second Monday or 6L
+// for the last Friday. Other uses of W aren't supported, since an RRULE can't
+// express the weekday nearest to a date.
+func FromQuartz(spec string) (RRule, error) {
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 6:
+	case 7:
+		if fields[6] != "*" && fields[6] != "?" {
+			return RRule{}, fmt.Errorf("Quartz year %q is not supported", fields[6])
+		}
+		fields = fields[:6]
+	default:
+		return RRule{}, fmt.Errorf("Quartz expression %q must have 6 or 7 fields, not %d", spec, len(fields))
+	}
+
+	monthDay, weekday := fields[3], fields[5]
+	if monthDay != "?" && weekday != "?" {
+		return RRule{}, fmt.Errorf("Quartz expression %q must have ? for the day of month or day of week", spec)
+	}
+
+	// byMonthDays, byWeekdays and bySetPos hold the days given by Quartz
+	// tokens, which are left to FromCron as ?.
+	var byMonthDays []int
+	var byWeekdays []QualifiedWeekday
+	var bySetPos []int
+
+	switch up := strings.ToUpper(monthDay); {
+	case up == "L":
+		byMonthDays = []int{-1}
+	case strings.HasPrefix(up, "L-"):
+		n, err := strconv.Atoi(up[2:])
+		if err != nil || n < 0 || n > 30 {
+			return RRule{}, fmt.Errorf("Quartz day of month %q is not supported", monthDay)
+		}
+		byMonthDays = []int{-1 - n}
+	case up == "LW":
+		byWeekdays, bySetPos = workdays, []int{-1}
+	case up == "1W":
+		byWeekdays, bySetPos = workdays, []int{1}
+	case strings.HasSuffix(up, "W"):
+		return RRule{}, fmt.Errorf("Quartz day of month %q is not supported; only 1W and LW can be expressed", monthDay)
+	}
+	if byMonthDays != nil || byWeekdays != nil {
+		fields[3] = "?"
+	}
+
+	if weekday != "?" {
+		wd, err := parseQuartzWeekday(weekday)
+		if err != nil {
+			return RRule{}, err
+		}
+		switch {
+		case wd != nil && wd.N != 0:
+			byWeekdays = []QualifiedWeekday{*wd}
+			fields[5] = "?"
+		case wd != nil:
+			fields[5] = strconv.Itoa(int(wd.WD))
+		default:
+			days, err := parseCronField(weekday, quartzWeekdays)
+			if err != nil {
+				return RRule{}, err
+			}
+			for i := range days {
+				days[i]--
+			}
+			fields[5] = cronList(days)
+		}
+	}
+
+	rrule, err := FromCron(strings.Join(fields, " "))
+	if err != nil || (byMonthDays == nil && byWeekdays == nil) {
+		return rrule, err
+	}
+
+	// BYSETPOS and ordinal weekdays select from the whole month, so the
+	// pattern must fire once a day.
+	if bySetPos != nil || len(byWeekdays) > 0 && byWeekdays[0].N != 0 {
+		if rrule.Frequency != Daily || len(rrule.BySeconds)*len(rrule.ByMinutes)*len(rrule.ByHours) != 1 {
+			return RRule{}, fmt.Errorf("Quartz day %q must fire at a single time of day", spec)
+		}
+	}
+
+	if rrule.Frequency == Daily {
+		rrule.Frequency = Monthly
+	}
+	rrule.ByMonthDays = byMonthDays
+	rrule.ByWeekdays = byWeekdays
+	rrule.BySetPos = bySetPos
+
+	return rrule, rrule.Validate()
+}
+
+// parseQuartzWeekday parses a day of week field like MON#2 or 6L, returning nil
+// if it is a plain list of days.
+func parseQuartzWeekday(str string) (*QualifiedWeekday, error) {
+	up := strings.ToUpper(str)
+
+	day, n := up, 0
+	switch i := strings.Index(up, "#"); {
+	case i >= 0:
+		var err error
+		day = up[:i]
+		n, err = strconv.Atoi(up[i+1:])
+		if err != nil || n < 1 || n > 5 {
+			return nil, fmt.Errorf("Quartz day of week %q is not supported", str)
+		}
+	case strings.HasSuffix(up, "L") && len(up) > 1:
+		day, n = up[:len(up)-1], -1
+	case up == "L":
+		// L alone is the last day of the week.
+		return &QualifiedWeekday{WD: time.Saturday}, nil
+	default:
+		return nil, nil
+	}
+
+	v, err := parseCronValue(day, quartzWeekdays)
+	if err != nil {
+		return nil, err
+	}
+	return &QualifiedWeekday{N: n, WD: time.Weekday(v - 1)}, nil
+}
+
+// ToQuartz returns a Quartz scheduler cron expression for the pattern's
+// schedule, in the six field form, or a *CronError naming the rule part that
+// prevents it. It accepts what ToCron does, and also a single negative
+// BYMONTHDAY, a single BYDAY with an ordinal of 1 through 5 or -1, and the
+// first or last of BYDAY=MO,TU,WE,TH,FR by BYSETPOS, for MONTHLY patterns.
+func (rrule RRule) ToQuartz() (string, error) {
+	// monthDay and weekday are Quartz tokens for the day fields, which are
+	// replaced by placeholders for ToCron.
+	var monthDay, weekday string
+	plain := rrule
+
+	switch {
+	case len(rrule.ByMonthDays) == 1 && rrule.ByMonthDays[0] < 0:
+		monthDay = "L"
+		if n := -1 - rrule.ByMonthDays[0]; n > 0 {
+			monthDay += "-" + strconv.Itoa(n)
+		}
+		plain.ByMonthDays = []int{1}
+	case len(rrule.ByWeekdays) == 1 && rrule.ByWeekdays[0].N != 0:
+		wd := rrule.ByWeekdays[0]
+		switch {
+		case wd.N == -1:
+			weekday = strconv.Itoa(int(wd.WD)+1) + "L"
+		case wd.N >= 1 && wd.N <= 5:
+			weekday = fmt.Sprintf("%d#%d", int(wd.WD)+1, wd.N)
+		default:
+			return "", &CronError{Part: "BYDAY", Reason: fmt.Sprintf("Quartz has no %s", wd)}
+		}
+		plain.ByWeekdays = []QualifiedWeekday{{WD: wd.WD}}
+	case len(rrule.BySetPos) == 1 && len(rrule.ByMonthDays) == 0 && sameWeekdays(rrule.ByWeekdays, workdays):
+		switch rrule.BySetPos[0] {
+		case 1:
+			monthDay = "1W"
+		case -1:
+			monthDay = "LW"
+		default:
+			return "", &CronError{Part: "BYSETPOS", Reason: "Quartz W only selects the first or last weekday"}
+		}
+		plain.ByWeekdays, plain.BySetPos = nil, nil
+		plain.ByMonthDays = []int{1}
+	}
+
+	if (monthDay != "" || weekday != "") && rrule.Frequency != Monthly {
+		return "", &CronError{Part: "FREQ", Reason: "Quartz L, W and # tokens are only converted for MONTHLY patterns"}
+	}
+
+	spec, err := plain.ToCron()
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) == 5 {
+		fields = append([]string{"0"}, fields...)
+	}
+
+	if fields[5] != "*" {
+		days, err := parseCronField(fields[5], cronWeekdays)
+		if err != nil {
+			return "", err
+		}
+		for i := range days {
+			days[i]++
+		}
+		fields[5] = cronList(days)
+	}
+
+	switch {
+	case monthDay != "":
+		fields[3], fields[5] = monthDay, "?"
+	case weekday != "":
+		fields[3], fields[5] = "?", weekday
+	case fields[5] == "*":
+		fields[5] = "?"
+	default:
+		fields[3] = "?"
+	}
+
+	return strings.Join(fields, " "), nil
+}
+
+func sameWeekdays(a, b []QualifiedWeekday) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromQuartz(t *testing.T) {
+	for spec, want := range map[string]string{
+		"0 30 9 ? * MON-FRI":    "FREQ=WEEKLY;BYSECOND=0;BYMINUTE=30;BYHOUR=9;BYDAY=MO,TU,WE,TH,FR",
+		"0 30 9 ? * 2-6 *":      "FREQ=WEEKLY;BYSECOND=0;BYMINUTE=30;BYHOUR=9;BYDAY=MO,TU,WE,TH,FR",
+		"0 0 12 * * ?":          "FREQ=DAILY;BYSECOND=0;BYMINUTE=0;BYHOUR=12",
+		"0 0 12 1,15 * ?":       "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYMONTHDAY=1,15",
+		"0 0 12 ? * MON#2":      "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYDAY=2MO",
+		"0 0 12 ? * 6L":         "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYDAY=-1FR",
+		"0 0 12 ? JAN 1#1":      "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYDAY=1SU;BYMONTH=1",
+		"0 0 12 L * ?":          "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYMONTHDAY=-1",
+		"0 0 12 L-2 * ?":        "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYMONTHDAY=-3",
+		"0 0 12 LW * ?":         "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"0 0 12 1W * ?":         "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=0;BYHOUR=12;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1",
+		"0 * * L * ?":           "FREQ=MINUTELY;BYSECOND=0;BYMONTHDAY=-1",
+		"*/10 * * ? * *":        "FREQ=MINUTELY;BYSECOND=0,10,20,30,40,50",
+		"0 0 0 ? * L":           "FREQ=WEEKLY;BYSECOND=0;BYMINUTE=0;BYHOUR=0;BYDAY=SA",
+		"0 15 10 ? * SUN,7 *":   "FREQ=WEEKLY;BYSECOND=0;BYMINUTE=15;BYHOUR=10;BYDAY=SU,SA",
+		"0 15 10 ? 6-8 WED#3 ?": "FREQ=MONTHLY;BYSECOND=0;BYMINUTE=15;BYHOUR=10;BYDAY=3WE;BYMONTH=6,7,8",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			rrule, err := FromQuartz(spec)
+			require.NoError(t, err)
+			assert.Equal(t, want, rrule.String())
+		})
+	}
+}
+
+func TestFromQuartzInstances(t *testing.T) {
+	rrule, err := FromQuartz("0 0 12 LW * ?")
+	require.NoError(t, err)
+
+	rrule.Dtstart = time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{
+		"2018-08-31T12:00:00Z",
+		"2018-09-28T12:00:00Z",
+		"2018-10-31T12:00:00Z",
+	}, rfcAll(All(rrule.Iterator(), 3)))
+}
+
+func TestFromQuartzErrors(t *testing.T) {
+	for _, spec := range []string{
+		"0 0 12 * *",
+		"0 0 12 * * MON",
+		"0 0 12 15W * ?",
+		"0 0 12 ? * MON#6",
+		"0 0 12 ? * 0",
+		"0 0 12 ? * * 2019",
+		"0 0 9,17 LW * ?",
+		"0 0 * ? * MON#2",
+		"0 0 12 L-40 * ?",
+	} {
+		_, err := FromQuartz(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestToQuartz(t *testing.T) {
+	dtstart := time.Date(2018, time.August, 25, 9, 30, 0, 0, time.UTC)
+
+	for want, rrule := range map[string]RRule{
+		"0 30 9 * * ?":       {Frequency: Daily, Dtstart: dtstart},
+		"0 30 9 ? * 2-6":     {Frequency: Weekly, Dtstart: dtstart, ByWeekdays: workdays},
+		"0 30 9 ? * 7":       {Frequency: Weekly, Dtstart: dtstart},
+		"0 30 9 25 * ?":      {Frequency: Monthly, Dtstart: dtstart},
+		"0 30 9 L * ?":       {Frequency: Monthly, Dtstart: dtstart, ByMonthDays: []int{-1}},
+		"0 30 9 L-2 * ?":     {Frequency: Monthly, Dtstart: dtstart, ByMonthDays: []int{-3}},
+		"0 30 9 ? * 2#2":     {Frequency: Monthly, Dtstart: dtstart, ByWeekdays: []QualifiedWeekday{{N: 2, WD: time.Monday}}},
+		"0 30 9 ? 1,7 6L":    {Frequency: Monthly, Dtstart: dtstart, ByWeekdays: []QualifiedWeekday{{N: -1, WD: time.Friday}}, ByMonths: []time.Month{time.January, time.July}},
+		"0 30 9 LW * ?":      {Frequency: Monthly, Dtstart: dtstart, ByWeekdays: workdays, BySetPos: []int{-1}},
+		"0 30 9 1W * ?":      {Frequency: Monthly, Dtstart: dtstart, ByWeekdays: workdays, BySetPos: []int{1}},
+		"0,20,40 * * ? * 1":  {Frequency: Secondly, BySeconds: []int{0, 20, 40}, ByWeekdays: []QualifiedWeekday{{WD: time.Sunday}}},
+		"15 30 9 1,15 * ?":   {Frequency: Monthly, Dtstart: dtstart.Add(15 * time.Second), ByMonthDays: []int{1, 15}},
+		"0 30 9 25 8 ?":      {Frequency: Yearly, Dtstart: dtstart},
+		"0 0,30 * ? * 1,7":   {Frequency: Hourly, BySeconds: []int{0}, ByMinutes: []int{0, 30}, ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}, {WD: time.Sunday}}},
+		"0 0,30 9 ? * 1-3,5": {Frequency: Daily, Dtstart: dtstart, ByMinutes: []int{0, 30}, ByWeekdays: []QualifiedWeekday{{WD: time.Sunday}, {WD: time.Monday}, {WD: time.Tuesday}, {WD: time.Thursday}}},
+	} {
+		t.Run(want, func(t *testing.T) {
+			spec, err := rrule.ToQuartz()
+			require.NoError(t, err)
+			assert.Equal(t, want, spec)
+
+			converted, err := FromQuartz(spec)
+			require.NoError(t, err)
+			converted.Dtstart = rrule.Dtstart
+			if rrule.Dtstart.IsZero() {
+				converted.Dtstart, rrule.Dtstart = dtstart, dtstart
+			}
+			assert.Equal(t, rfcAll(All(rrule.Iterator(), 10)), rfcAll(All(converted.Iterator(), 10)))
+		})
+	}
+}
+
+func TestToQuartzErrors(t *testing.T) {
+	for part, rrule := range map[string]RRule{
+		"BYDAY":      {Frequency: Monthly, ByWeekdays: []QualifiedWeekday{{N: -2, WD: time.Monday}}},
+		"BYSETPOS":   {Frequency: Monthly, ByWeekdays: workdays, BySetPos: []int{2}},
+		"FREQ":       {Frequency: Yearly, ByMonthDays: []int{-1}},
+		"BYMONTHDAY": {Frequency: Monthly, ByMonthDays: []int{1, -1}},
+		"COUNT":      {Frequency: Monthly, Count: 2, ByMonthDays: []int{-1}},
+	} {
+		_, err := rrule.ToQuartz()
+		require.Error(t, err, part)
+		require.IsType(t, &CronError{}, err, part)
+		assert.Equal(t, part, err.(*CronError).Part)
+	}
+}
@@ -1,6 +1,9 @@
 package rrule
 
 import (
+	"context"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -8,7 +11,7 @@ import (
 // Recurrence expresses a complex pattern of repeating events composed of individual
 // patterns and extra days that are filtered by exclusion patterns and days.
 type Recurrence struct {
-	// Dtstart specifies the time to begin recurrence. If zero, time.Now is
+	// Dtstart specifies the time to begin recurrence. If zero, Now is
 	// used when an iterator is generated.  The location of Dtstart is the
 	// location that will be used to process the recurrence, which is
 	// particularly relevant for calculations affected by Daylight Savings.
@@ -18,9 +21,15 @@ type Recurrence struct {
 	// If true, Dtstart, RDates, and ExDates will be written in local time,
 	// excluding the offset or timezone indicator, to represent a local time
 	// independent of timezone. See ParseRecurrence or RFC 5545 for more
-	// detail.
+	// detail. Use InLocation to expand such a recurrence in a given
+	// location.
 	FloatingLocation bool `json:"floating_location"`
 
+	// AllDay is true when Dtstart is a DATE rather than a DATE-TIME. Dtstart,
+	// RDates and ExDates are then written as DATE values, and the patterns
+	// follow it, generating all-day occurrences; see RRule.AllDay.
+	AllDay bool `json:"all_day,omitempty"`
+
 	// Patterns and instances to include. Repeated instances are included only
 	// once, even if defined by multiple patterns.
 	//
@@ -42,8 +51,15 @@ type Recurrence struct {
 // newline delimited format.
 func (r *Recurrence) String() string {
 	b := &strings.Builder{}
+	format := func(prefix string, t time.Time) string {
+		if r.AllDay {
+			return formatDate(prefix, t)
+		}
+		return formatTime(prefix, t, r.FloatingLocation)
+	}
+
 	if !r.Dtstart.IsZero() {
-		b.WriteString(formatTime("DTSTART", r.Dtstart, r.FloatingLocation))
+		b.WriteString(format("DTSTART", r.Dtstart))
 		b.WriteString("\n")
 	}
 	for _, rrule := range r.RRules {
@@ -57,11 +73,11 @@ func (r *Recurrence) String() string {
 		b.WriteString("\n")
 	}
 	for _, rdate := range r.RDates {
-		b.WriteString(formatTime("RDATE", rdate, r.FloatingLocation))
+		b.WriteString(format("RDATE", rdate))
 		b.WriteString("\n")
 	}
 	for _, exdate := range r.ExDates {
-		b.WriteString(formatTime("EXDATE", exdate, r.FloatingLocation))
+		b.WriteString(format("EXDATE", exdate))
 		b.WriteString("\n")
 	}
 
@@ -71,11 +87,17 @@ func (r *Recurrence) String() string {
 func (r *Recurrence) setDtstart() {
 	for i, rr := range r.RRules {
 		rr.Dtstart = r.Dtstart
-		r.RRules[i] = rr
+		if !r.Dtstart.IsZero() {
+			rr.AllDay = r.AllDay
+		}
+		r.RRules[i] = rr.withFloatingUntilIn(r.Dtstart.Location())
 	}
 	for i, rr := range r.ExRules {
 		rr.Dtstart = r.Dtstart
-		r.ExRules[i] = rr
+		if !r.Dtstart.IsZero() {
+			rr.AllDay = r.AllDay
+		}
+		r.ExRules[i] = rr.withFloatingUntilIn(r.Dtstart.Location())
 	}
 }
 
@@ -83,22 +105,99 @@ func (r *Recurrence) setDtstart() {
 // number. If the limit is 0, all instances are returned, which will include all
 // instances until (roughly) Go's maximum useful time.Time, in the year 219248499.
 func All(it Iterator, limit int) []time.Time {
-	var all []time.Time
+	return AppendAll(nil, it, limit)
+}
+
+// AppendAll appends the instances All would return to dst and returns the
+// extended slice. Passing a slice of an earlier result, like tt[:0], reuses its
+// memory, sparing callers that expand many patterns an allocation each time.
+func AppendAll(dst []time.Time, it Iterator, limit int) []time.Time {
+	n := 0
 	for {
 		next := it.Next()
 		if next == nil {
 			break
 		}
-		all = append(all, *next)
-		if limit > 0 && len(all) == limit {
+		if n == 0 {
+			if size := presize(it, limit); cap(dst)-len(dst) < size {
+				dst = append(make([]time.Time, 0, len(dst)+size), dst...)
+			}
+		}
+		dst = append(dst, *next)
+		n++
+		if limit > 0 && n == limit {
 			break
 		}
 	}
-	return all
+	return dst
+}
+
+// ForEach calls fn with each instance from the beginning of the iterator until
+// the iterator ends or fn returns false. Unlike All, instances are not
+// retained.
+func ForEach(it Iterator, fn func(time.Time) bool) {
+	for {
+		next := it.Next()
+		if next == nil || !fn(*next) {
+			return
+		}
+	}
+}
+
+// MaxOccurrences is the default hard cap used by AllContext when it is called
+// with a limit of 0, and the default limit of OccurrencesBetween. It may be set
+// to 0 to leave AllContext bounded only by its context.
+var MaxOccurrences = 100000
+
+// LimitExceededError is returned by AllContext when an iterator produces more
+// instances than the allowed maximum.
+type LimitExceededError struct {
+	Limit int
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("recurrence produces more than %d instances", e.Limit)
+}
+
+// AllContext returns all instances from the beginning of the iterator, like
+// All, but treats limit as a hard cap rather than a truncation point. If the
+// iterator produces more than limit instances, the first limit instances are
+// returned with a *LimitExceededError. A limit of 0 uses MaxOccurrences.
+//
+// If ctx is done before the iterator ends, the instances collected so far are
+// returned along with ctx.Err(). Likewise, if the iterator ends early, as one
+// limited by WithBudget can, they are returned along with IteratorErr.
+func AllContext(ctx context.Context, it Iterator, limit int) ([]time.Time, error) {
+	if limit == 0 {
+		limit = MaxOccurrences
+	}
+
+	var all []time.Time
+	for {
+		if err := ctx.Err(); err != nil {
+			return all, err
+		}
+
+		next := it.Next()
+		if next == nil {
+			return all, IteratorErr(it)
+		}
+		if limit > 0 && len(all) == limit {
+			return all, &LimitExceededError{Limit: limit}
+		}
+		if all == nil {
+			all = make([]time.Time, 0, presize(it, limit))
+		}
+		all = append(all, *next)
+	}
 }
 
 // Iterator returns an iterator for the recurrence.
 func (r Recurrence) Iterator() Iterator {
+	// copy the patterns so setting their Dtstart doesn't modify the
+	// caller's recurrence.
+	r.RRules = append([]RRule(nil), r.RRules...)
+	r.ExRules = append([]RRule(nil), r.ExRules...)
 	r.setDtstart()
 
 	ri := &recurrenceIterator{
@@ -106,15 +205,50 @@ func (r Recurrence) Iterator() Iterator {
 		exrules: groupIteratorFromRRules(r.ExRules),
 	}
 
-	ri.rrules.iters = append(ri.rrules.iters, &iterator{queue: r.RDates})
-	ri.exrules.iters = append(ri.exrules.iters, &iterator{queue: r.ExDates})
+	ri.rrules.iters = append(ri.rrules.iters, dateIterator(r.RDates))
+	ri.exrules.iters = append(ri.exrules.iters, dateIterator(r.ExDates))
 
 	return ri
 }
 
+// dateIterator returns an iterator over a fixed list of dates, in order.
+func dateIterator(dates []time.Time) *iterator {
+	dates = append([]time.Time(nil), dates...)
+	sort.SliceStable(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return &iterator{steps: &dateSteps{dates: dates}, size: len(dates)}
+}
+
+// dateSteps steps through a fixed list of dates, each its own occurrence.
+type dateSteps struct {
+	dates []time.Time
+	n     int
+	buf   [1]time.Time
+}
+
+func (s *dateSteps) next() (time.Time, bool) {
+	if s.n == len(s.dates) {
+		return time.Time{}, false
+	}
+	s.n++
+	return s.dates[s.n-1], true
+}
+
+func (s *dateSteps) valid(t time.Time) bool { return true }
+
+func (s *dateSteps) variations(t time.Time) []time.Time {
+	s.buf[0] = t
+	return s.buf[:]
+}
+
+func (s *dateSteps) reset() { s.n = 0 }
+
 type recurrenceIterator struct {
 	rrules  *groupIterator
 	exrules *groupIterator
+
+	// index is the position of the next time in the series, or -1 if
+	// unknown.
+	index int
 }
 
 func (ri *recurrenceIterator) Peek() *time.Time {
@@ -126,6 +260,10 @@ func (ri *recurrenceIterator) Peek() *time.Time {
 		}
 
 		nextException := ri.exrules.Peek()
+		if nextException == nil && ri.exrules.err() != nil {
+			// next might have been excluded.
+			return nil
+		}
 
 		if nextException != nil && nextException.Before(*next) {
 			ri.exrules.Next()
@@ -133,7 +271,8 @@ func (ri *recurrenceIterator) Peek() *time.Time {
 		}
 
 		if nextException != nil && nextException.Equal(*next) {
-			next = ri.rrules.Next()
+			ri.rrules.Next()
+			next = ri.rrules.Peek()
 
 			continue
 		}
@@ -147,5 +286,30 @@ func (ri *recurrenceIterator) Peek() *time.Time {
 func (ri *recurrenceIterator) Next() *time.Time {
 	t := ri.Peek()
 	ri.rrules.Next()
+	if t != nil && ri.index >= 0 {
+		ri.index++
+	}
 	return t
 }
+
+func (ri *recurrenceIterator) NextIndexed() (int, *time.Time) {
+	return nextIndexed(ri, ri.index)
+}
+
+func (ri *recurrenceIterator) Seek(t time.Time) {
+	if next := ri.Peek(); next != nil && next.Before(t) {
+		ri.index = -1
+	}
+	ri.rrules.Seek(t)
+	ri.exrules.Seek(t)
+}
+
+func (ri *recurrenceIterator) Skip(n int) { skip(ri, n) }
+
+func (ri *recurrenceIterator) Take(n int) []time.Time { return take(ri, n) }
+
+func (ri *recurrenceIterator) Reset() {
+	ri.index = 0
+	ri.rrules.Reset()
+	ri.exrules.Reset()
+}
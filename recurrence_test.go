@@ -1,6 +1,7 @@
 package rrule
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -56,7 +57,7 @@ var recurrenceCases = []struct {
 		},
 		ExDates: []time.Time{time.Date(2018, time.September, 2, 9, 8, 7, 0, time.UTC)},
 	},
-	Dates:  []string{"2018-08-26T09:08:07Z", "2018-08-28T09:08:07Z", "2018-08-31T09:08:07Z", "2018-09-04T09:08:07Z", "2018-09-08T09:08:07Z"},
+	Dates:  []string{"2018-08-26T09:08:07Z", "2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z", "2018-08-31T09:08:07Z", "2018-09-04T09:08:07Z", "2018-09-08T09:08:07Z"},
 	String: "DTSTART:20180825T090807Z\nRRULE:FREQ=DAILY;COUNT=4\nRRULE:FREQ=DAILY;COUNT=8;INTERVAL=2\nEXRULE:FREQ=DAILY;INTERVAL=4\nEXRULE:FREQ=DAILY;INTERVAL=8\nRDATE:20180902T090807Z\nRDATE:20180902T090807Z\nEXDATE:20180902T090807Z\n",
 }}
 
@@ -81,3 +82,76 @@ func TestRecurrence(t *testing.T) {
 		})
 	}
 }
+
+func TestRecurrenceExcludedInstance(t *testing.T) {
+	r := &Recurrence{
+		Dtstart: now,
+		RRules:  []RRule{{Frequency: Daily, Count: 4}},
+		ExDates: []time.Time{now.AddDate(0, 0, 1)},
+	}
+
+	// the instance after the excluded one is kept.
+	assert.Equal(t, []string{"2018-08-25T09:08:07Z", "2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z"}, rfcAll(All(r.Iterator(), 0)))
+}
+
+func TestAllContext(t *testing.T) {
+	rrule := RRule{Frequency: Daily, Dtstart: now}
+
+	all, err := AllContext(context.Background(), rrule.Iterator(), 3)
+	assert.Len(t, all, 3)
+	require.Error(t, err)
+	assert.Equal(t, &LimitExceededError{Limit: 3}, err)
+
+	rrule.Count = 3
+	all, err = AllContext(context.Background(), rrule.Iterator(), 3)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	// a pattern that never ends stops at the default cap.
+	rrule.Count = 0
+	all, err = AllContext(context.Background(), rrule.Iterator(), 0)
+	assert.Len(t, all, MaxOccurrences)
+	assert.Equal(t, &LimitExceededError{Limit: MaxOccurrences}, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	all, err = AllContext(ctx, rrule.Iterator(), 0)
+	assert.Empty(t, all)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestAppendAll(t *testing.T) {
+	rrule := RRule{Frequency: Daily, Dtstart: now, Count: 5}
+
+	buf := make([]time.Time, 0, 8)
+	tt := AppendAll(buf, rrule.Iterator(), 3)
+	assert.Equal(t, []string{"2018-08-25T09:08:07Z", "2018-08-26T09:08:07Z", "2018-08-27T09:08:07Z"}, rfcAll(tt))
+	assert.Equal(t, &buf[:1][0], &tt[0], "the buffer is reused")
+
+	tt = AppendAll(tt, rrule.Iterator(), 0)
+	assert.Len(t, tt, 8)
+	assert.Equal(t, rfcAll(All(rrule.Iterator(), 0)), rfcAll(tt[3:]))
+
+	// a full buffer grows once, by the size of the series.
+	tt = AppendAll(tt, rrule.Iterator(), 0)
+	assert.Len(t, tt, 13)
+	assert.Equal(t, 13, cap(tt))
+
+	assert.Equal(t, tt[:0], AppendAll(tt[:0], RRule{Frequency: Daily, Dtstart: now, Until: now.Add(-time.Hour)}.Iterator(), 0))
+}
+
+func TestForEach(t *testing.T) {
+	var got []time.Time
+	ForEach(RRule{Frequency: Daily, Dtstart: now}.Iterator(), func(t time.Time) bool {
+		got = append(got, t)
+		return len(got) < 3
+	})
+	assert.Equal(t, []string{"2018-08-25T09:08:07Z", "2018-08-26T09:08:07Z", "2018-08-27T09:08:07Z"}, rfcAll(got))
+
+	got = nil
+	ForEach(recurrenceCases[0].Recurrence.Iterator(), func(t time.Time) bool {
+		got = append(got, t)
+		return true
+	})
+	assert.Equal(t, recurrenceCases[0].Dates, rfcAll(got))
+}
@@ -4,15 +4,17 @@
 //
 // would generate occurrences every other week on Monday.
 //
-// RFC 7529 is partially implemented. The SKIP and RSCALE clauses are supported, but
-// only Gregorian is implemented. As such, months with the L indicator are also
-// not supported, since these have no use in Gregorian.
+// RFC 7529 is partially implemented. The SKIP and RSCALE clauses are
+// supported, with the Gregorian, Islamic civil, Chinese, Buddhist, Japanese and
+// Ethiopic calendar scales. Months with the L indicator can be used with any of them,
+// but in calendars without leap months they never occur, so SKIP decides
+// whether the month before or after is used instead.
 package rrule
 
 import (
-	"errors"
 	"fmt"
-	"sort"
+	"math"
+	"strings"
 	"time"
 )
 
@@ -25,13 +27,23 @@ type RRule struct {
 	// If true, the RRule will encode using local time (no offset).
 	UntilFloating bool `json:"until_floating"`
 
+	// UntilExclusive excludes an occurrence at exactly Until, which RFC 5545
+	// includes, for patterns from providers that treat UNTIL as exclusive.
+	// It is not part of the RFC 5545 representation.
+	UntilExclusive bool `json:"until_exclusive,omitempty"`
+
+	// AllDay is true when DTSTART and UNTIL are DATE values rather than
+	// DATE-TIMEs. Occurrences are at midnight of each date in the location
+	// of Dtstart, and UNTIL is written as a DATE.
+	AllDay bool `json:"all_day,omitempty"`
+
 	Count uint64 `json:"count"`
 
 	// Dtstart is not actually part of the RRule when
 	// encoded, but it's included here as a field because
 	// it's required when expading the pattern.
 	//
-	// If zero, time.Now is used when an iterator is generated.
+	// If zero, Now is used when an iterator is generated.
 	Dtstart time.Time `json:"dtstart"`
 
 	// 0 means the default value, which is 1.
@@ -44,35 +56,139 @@ type RRule struct {
 	ByMonthDays   []int              `json:"by_month_days,omitempty"`   // 1 to 31
 	ByWeekNumbers []int              `json:"by_week_numbers,omitempty"` // 1 to 53
 	ByMonths      []time.Month       `json:"by_months,omitempty"`
-	ByYearDays    []int              `json:"by_year_days,omitempty"` // 1 to 366
-	BySetPos      []int              `json:"by_set_pos,omitempty"`   // -366 to 366
+	ByLeapMonths  []int              `json:"by_leap_months,omitempty"` // months with the L suffix, like 5 for 5L
+	ByYearDays    []int              `json:"by_year_days,omitempty"`   // 1 to 366
+	BySetPos      []int              `json:"by_set_pos,omitempty"`     // -366 to 366
 
 	// InvalidBehavior defines how to behave when a generated date wouldn't
 	// exist, like February 31st.
 	InvalidBehavior InvalidBehavior `json:"invalid_behavior"`
 
-	WeekStart *time.Weekday `json:"week_start,omitempty"` // if nil, Monday
+	// Nonexistent and Ambiguous define how to behave when a generated time
+	// is skipped or repeated by a daylight saving transition in the location
+	// of Dtstart. They apply to DAILY and coarser patterns, and to finer
+	// patterns with WallClockStepping.
+	Nonexistent NonexistentBehavior `json:"nonexistent,omitempty"`
+	Ambiguous   AmbiguousBehavior   `json:"ambiguous,omitempty"`
+
+	// LeapSecond defines how to behave when BYSECOND includes 60, the leap
+	// second, which Go's times can't represent.
+	LeapSecond LeapSecondBehavior `json:"leap_second,omitempty"`
+
+	// Stepping defines whether HOURLY, MINUTELY and SECONDLY patterns
+	// advance by elapsed or clock time.
+	Stepping Stepping `json:"stepping,omitempty"`
+
+	// RScale is the calendar scale in which months and days are counted.
+	// InvalidBehavior is applied in that calendar before dates are converted
+	// to Gregorian.
+	RScale RScale `json:"rscale,omitempty"`
+
+	WeekStart WeekStart `json:"week_start,omitempty"` // if unset, DefaultWeekStart
+
+	// Extensions holds non-standard rule parts, whose names begin with X-,
+	// keyed by their upper case names. They have no effect on the pattern,
+	// but are preserved when parsing and encoding to string.
+	Extensions map[string]string `json:"extensions,omitempty"`
+
+	// source is set by ParseRRulePreserving when the parsed text isn't
+	// exactly how the pattern renders, so that Format can preserve it.
+	source *ruleSource
 }
 
-// Validate checks that the pattern is valid.
+// Validate checks that the pattern is valid, returning the first problem
+// found.
 func (rrule RRule) Validate() error {
+	return rrule.ValidateWith(ValidationOptions{})
+}
+
+// ValidateAll checks that the pattern is valid, like Validate, but returns
+// every problem found as ValidationErrors, so that they can all be shown at
+// once.
+func (rrule RRule) ValidateAll() error {
+	return rrule.ValidateWith(ValidationOptions{All: true})
+}
+
+// ValidationOptions controls the checks made by RRule.ValidateWith.
+type ValidationOptions struct {
+	// All returns every problem found as ValidationErrors, rather than just
+	// the first.
+	All bool
+
+	// UntilBeforeDtstart rejects patterns whose UNTIL is before Dtstart,
+	// which are otherwise valid but have no occurrences, with an
+	// ErrNoOccurrences error. Patterns without a Dtstart are not checked.
+	UntilBeforeDtstart bool
+}
+
+// ValidateWith checks that the pattern is valid, as controlled by opts.
+func (rrule RRule) ValidateWith(opts ValidationOptions) error {
+	errs := rrule.validate()
+
+	if opts.UntilBeforeDtstart && !rrule.Dtstart.IsZero() && !rrule.Until.IsZero() {
+		until := rrule.Until
+		if rrule.UntilFloating {
+			until = wallClockIn(until, rrule.Dtstart.Location())
+		}
+		if until.Before(rrule.Dtstart) {
+			errs = append(errs, kindErrorf(ErrNoOccurrences, "UNTIL must not be before DTSTART"))
+		}
+	}
+
+	switch {
+	case len(errs) == 0:
+		return nil
+	case opts.All:
+		return errs
+	default:
+		return errs[0]
+	}
+}
+
+// maxCount is the largest COUNT that Validate accepts.
+const maxCount = math.MaxInt32
+
+// ValidationErrors holds every problem found with a pattern by ValidateAll.
+type ValidationErrors []error
+
+// Unwrap returns the problems, so that errors.Is and errors.As find them.
+func (errs ValidationErrors) Unwrap() []error {
+	return errs
+}
+
+// Error returns the problems' messages, separated by semicolons.
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// validate returns the problems with the pattern, in the order Validate
+// reports them.
+func (rrule RRule) validate() ValidationErrors {
+	var errs ValidationErrors
+
 	if rrule.Frequency != Yearly && rrule.Frequency != Monthly {
 		for _, wd := range rrule.ByWeekdays {
 			if wd.N != 0 {
-				return errors.New("BYDAY entries may only specify a numeric component when the frequency is YEARLY or MONTHLY")
+				errs = append(errs, kindErrorf(ErrInvalidByDay, "BYDAY entries may only specify a numeric component when the frequency is YEARLY or MONTHLY"))
+				break
 			}
 		}
 	}
 	if rrule.Frequency == Yearly && len(rrule.ByWeekNumbers) > 0 {
 		for _, wd := range rrule.ByWeekdays {
 			if wd.N != 0 {
-				return errors.New("BYDAY entries must not specify a numeric component when the frequency is YEARLY and a BYWEEKNO rule is present")
+				errs = append(errs, kindErrorf(ErrInvalidByDay, "BYDAY entries must not specify a numeric component when the frequency is YEARLY and a BYWEEKNO rule is present"))
+				break
 			}
 		}
 	}
 
 	if rrule.Frequency == Weekly && len(rrule.ByMonthDays) > 0 {
-		return errors.New("WEEKLY recurrences must not include BYMONTHDAY")
+		errs = append(errs, ErrWeeklyByMonthDay)
 	}
 
 	if len(rrule.BySetPos) != 0 {
@@ -83,502 +199,994 @@ func (rrule RRule) Validate() error {
 			len(rrule.ByMonthDays) == 0 &&
 			len(rrule.ByWeekNumbers) == 0 &&
 			len(rrule.ByMonths) == 0 &&
+			len(rrule.ByLeapMonths) == 0 &&
 			len(rrule.ByYearDays) == 0 {
-			return errors.New("BYSETPOS rules must be used in conjunction with at least one other BYXXX rule part")
+			errs = append(errs, kindErrorf(ErrInvalidBySetPos, "BYSETPOS rules must be used in conjunction with at least one other BYXXX rule part"))
 		}
 	}
 
 	if rrule.Count != 0 && !rrule.Until.IsZero() {
-		return errors.New("COUNT and UNTIL must not appear in the same RRULE")
+		errs = append(errs, ErrCountAndUntil)
 	}
 
-	for _, sp := range rrule.BySetPos {
-		if sp == 0 || sp < -366 || sp > 366 {
-			return errors.New("BYSETPOS values must be between [-366,-1] or [1,366]")
+	if rrule.AllDay && (rrule.Frequency < Daily || len(rrule.ByHours) > 0 || len(rrule.ByMinutes) > 0 || len(rrule.BySeconds) > 0) {
+		errs = append(errs, ErrAllDay)
+	}
+
+	if rrule.Interval < 0 {
+		errs = append(errs, kindErrorf(ErrOutOfRange, "INTERVAL must not be negative"))
+	}
+
+	// Larger counts would overflow the int that occurrences are counted in
+	// on some platforms, and could never be reached anyway.
+	if rrule.Count > maxCount {
+		errs = append(errs, kindErrorf(ErrOutOfRange, "COUNT must not be more than %d", maxCount))
+	}
+
+	if err := rrule.validateRScale(); err != nil {
+		errs = append(errs, err)
+	}
+
+	var ordinals []int
+	for _, wd := range rrule.ByWeekdays {
+		if wd.WD < time.Sunday || wd.WD > time.Saturday {
+			errs = append(errs, kindErrorf(ErrInvalidByDay, "BYDAY weekday %d is not a day of the week", wd.WD))
+			break
+		}
+		if wd.N != 0 {
+			ordinals = append(ordinals, wd.N)
 		}
 	}
 
+	// Ethiopic years have a thirteenth month.
+	maxMonth := 12
+	if rrule.RScale == Ethiopic {
+		maxMonth = 13
+	}
+	months := make([]int, 0, len(rrule.ByMonths))
+	for _, m := range rrule.ByMonths {
+		months = append(months, int(m))
+	}
+
+	for _, r := range []struct {
+		part     string
+		values   []int
+		min, max int
+		kind     error
+	}{
+		{"BYSECOND", rrule.BySeconds, 0, 60, nil},
+		{"BYMINUTE", rrule.ByMinutes, 0, 59, nil},
+		{"BYHOUR", rrule.ByHours, 0, 23, nil},
+		{"BYDAY ordinal", ordinals, -53, 53, ErrInvalidByDay},
+		{"BYWEEKNO", rrule.ByWeekNumbers, -53, 53, nil},
+		{"BYMONTHDAY", rrule.ByMonthDays, -31, 31, nil},
+		{"BYYEARDAY", rrule.ByYearDays, -366, 366, nil},
+		{"BYMONTH", months, 1, maxMonth, nil},
+		{"BYMONTH leap month", rrule.ByLeapMonths, 1, 12, nil},
+		{"BYSETPOS", rrule.BySetPos, -366, 366, ErrInvalidBySetPos},
+	} {
+		if err := checkRange(r.part, r.values, r.min, r.max, r.kind); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Parts that exclude each other are only worth checking when they're in
+	// range.
+	if len(errs) == 0 {
+		if err := rrule.validateOccurrences(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// checkRange returns an ErrOutOfRange error, which is also of kind if that
+// isn't nil, if any of the values of a rule part are outside min to max.
+// Parts that allow negative values don't allow zero.
+func checkRange(part string, values []int, min, max int, kind error) error {
+	for _, v := range values {
+		if min < 0 && v == 0 || v < min || v > max {
+			err := &kindError{kinds: []error{ErrOutOfRange}}
+			if kind != nil {
+				err.kinds = append(err.kinds, kind)
+			}
+			if min < 0 {
+				err.msg = fmt.Sprintf("%s values must be between [%d,-1] or [1,%d]", part, min, max)
+			} else {
+				err.msg = fmt.Sprintf("%s values must be between %d and %d", part, min, max)
+			}
+			return err
+		}
+	}
 	return nil
 }
 
 // Iterator returns an Iterator for the pattern. The pattern must be valid or Iterator will panic.
 func (rrule RRule) Iterator() Iterator {
-	err := rrule.Validate()
+	it, err := rrule.IteratorE()
 	if err != nil {
 		panic(err)
 	}
+	return it
+}
+
+// IteratorE returns an Iterator for the pattern, or an error if the pattern is
+// invalid.
+func (rrule RRule) IteratorE() (Iterator, error) {
+	err := rrule.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// a floating UNTIL is observed where the pattern is; otherwise the
+	// instants are compared, whatever their locations.
+	rrule = rrule.withFloatingUntilIn(rrule.dtstart().Location())
+
+	var it *iterator
+	if rrule.hasLocalTimePolicy() {
+		it, err = rrule.withLocalTimePolicy(RRule.newIterator)
+	} else {
+		it, err = rrule.newIterator()
+	}
+	if err != nil {
+		return nil, err
+	}
+	it.untilExclusive = rrule.UntilExclusive
+	if rrule.IsFinite() {
+		it.size = rrule.sizeHint(rrule.dtstart(), absoluteMaxTime)
+	}
+	return it, nil
+}
+
+// newIterator returns an Iterator for the valid pattern.
+func (rrule RRule) newIterator() (*iterator, error) {
+	if rrule.hasLeapSecond() {
+		rrule = rrule.withoutLeapSeconds()
+		if len(rrule.BySeconds) == 0 {
+			// every second was a leap second, and skipped. The iterator
+			// still has steps, if none, as groups require.
+			return &iterator{steps: &dateSteps{}}, nil
+		}
+	}
+
+	cal := rrule.RScale.calendar()
+	if cal != nil && (rrule.Frequency == Yearly || rrule.Frequency == Monthly) {
+		return setCalendar(rrule, cal), nil
+	}
+
+	if cal == nil && len(rrule.ByLeapMonths) > 0 {
+		rrule = rrule.withoutLeapMonths()
+		if len(rrule.ByMonths) == 0 {
+			// every month was a leap month, and omitted. The iterator
+			// still has steps, if none, as groups require.
+			return &iterator{steps: &dateSteps{}}, nil
+		}
+	}
+
+	if rrule.simple() {
+		return setSimple(rrule), nil
+	}
 
 	switch rrule.Frequency {
 	case Secondly:
-		return setSecondly(rrule)
+		return setSecondly(rrule), nil
 	case Minutely:
-		return setMinutely(rrule)
+		return setMinutely(rrule), nil
 	case Hourly:
-		return setHourly(rrule)
+		return setHourly(rrule), nil
 	case Daily:
-		return setDaily(rrule)
+		return setDaily(rrule), nil
 	case Weekly:
-		return setWeekly(rrule)
+		return setWeekly(rrule), nil
 	case Monthly:
-		return setMonthly(rrule)
+		return setMonthly(rrule), nil
 	case Yearly:
-		return setYearly(rrule)
+		return setYearly(rrule), nil
 	default:
-		panic(fmt.Sprintf("invalid frequency %v", rrule.Frequency))
+		return nil, kindErrorf(ErrUnsupportedFrequency, "invalid frequency %d", rrule.Frequency)
 	}
 }
 
-func setSecondly(rrule RRule) *iterator {
-	start := rrule.Dtstart
-	if start.IsZero() {
-		start = time.Now()
+// setSimple returns an iterator for a simple pattern, one with only FREQ,
+// INTERVAL, COUNT and UNTIL. Each occurrence is computed from its position, so
+// Seek can jump straight to any of them, however far from Dtstart, and still
+// honor COUNT.
+func setSimple(rrule RRule) *iterator {
+	rrule.Dtstart = rrule.dtstart()
+
+	return &iterator{
+		minTime:  rrule.Dtstart,
+		maxTime:  timeOrMax(rrule.Until),
+		queueCap: rrule.Count,
+		steps:    &simpleSteps{rrule: rrule},
 	}
+}
 
-	interval := 1
-	if rrule.Interval != 0 {
-		interval = rrule.Interval
+// simpleSteps steps through the occurrences of a simple pattern, each its own
+// key time.
+type simpleSteps struct {
+	rrule RRule
+
+	// n is the position of the next occurrence in the series.
+	n   int
+	buf [1]time.Time
+}
+
+func (s *simpleSteps) next() (time.Time, bool) {
+	// each occurrence is computed from Dtstart, rather than stepped to from
+	// the last, so that one moved out of a daylight saving gap doesn't move
+	// those after it, and the series is the same however it's reached, as
+	// by At, Expand or Seek.
+	t := s.rrule.nthSimple(s.n)
+	s.n++
+	return t, true
+}
+
+func (s *simpleSteps) jump(t time.Time) int {
+	m := s.rrule.indexSimple(t)
+	if m <= s.n {
+		return 0
 	}
+	skipped := m - s.n
+	s.n = m
+	return skipped
+}
 
-	current := start
+func (s *simpleSteps) valid(t time.Time) bool { return true }
+
+func (s *simpleSteps) variations(t time.Time) []time.Time {
+	s.buf[0] = t
+	return s.buf[:]
+}
 
-	nextFn := func() *time.Time {
-		ret := current // copy current
-		current = current.Add(time.Duration(interval) * time.Second)
-		return &ret
+func (s *simpleSteps) reset() {
+	s.n = 0
+}
+
+// timesOfDay expands days into their occurrences by the sorted values of
+// BYHOUR, BYMINUTE and BYSECOND.
+type timesOfDay struct {
+	hours, minutes, seconds []int
+}
+
+func newTimesOfDay(rrule RRule) timesOfDay {
+	return timesOfDay{
+		hours:   sortedClock(rrule.ByHours, 24),
+		minutes: sortedClock(rrule.ByMinutes, 60),
+		seconds: sortedClock(rrule.BySeconds, 60),
 	}
+}
 
-	// An rrule with Interval of 1 and BySeconds will potentially cycle through
-	// many seconds that get skipped. This is a fairly expensive case, but can be
-	// short-circuited by skipping to each subsequent BySeconds point instead of
-	// each second.
-	if interval == 1 && len(rrule.BySeconds) > 0 {
-		seconds := []int{}
-		for _, s := range rrule.BySeconds {
-			if s < 0 {
-				s += 60
-			}
-			seconds = append(seconds, s)
-		}
+func (c timesOfDay) expand(tt []time.Time) []time.Time {
+	tt = expandByHours(tt, c.hours...)
+	tt = expandByMinutes(tt, c.minutes...)
+	return expandBySeconds(tt, c.seconds...)
+}
 
-		sort.Ints(seconds)
-		initialSecond := start.Second()
-		loopIdx := 0
-		wentPastInitial := false
-		var firstDiff time.Duration
-
-		var secondsLooper []time.Duration
-		for i, s := range seconds {
-			if !wentPastInitial && s > initialSecond {
-				wentPastInitial = true
-				loopIdx = i
-				firstDiff = time.Duration(s-initialSecond) * time.Second
-			}
+// single reports whether each day has at most one time.
+func (c timesOfDay) single() bool {
+	return len(c.hours) <= 1 && len(c.minutes) <= 1 && len(c.seconds) <= 1
+}
 
-			nextIdx := i + 1
-			if nextIdx == len(seconds) {
-				secondsLooper = append(secondsLooper, time.Duration(60+seconds[0]-seconds[i])*time.Second)
-			} else {
-				secondsLooper = append(secondsLooper, time.Duration(seconds[nextIdx]-seconds[i])*time.Second)
-			}
+// perDay returns the number of times each day is expanded into.
+func (c timesOfDay) perDay() int {
+	n := 1
+	for _, values := range [][]int{c.hours, c.minutes, c.seconds} {
+		if len(values) > 0 {
+			n *= len(values)
 		}
+	}
+	return n
+}
 
-		if !wentPastInitial {
-			// all the BySecond terms are lower numbers than the start time second, so we need to wrap around for the first diff
-			firstDiff = time.Duration(seconds[0]+60-initialSecond) * time.Second
-		}
+// at returns the i-th of the times that expand makes of t.
+func (c timesOfDay) at(t time.Time, i int) time.Time {
+	// expand orders the times by hour, then minute, then second.
+	var minute, second int
+	if n := len(c.seconds); n > 0 {
+		second, i = i%n, i/n
+	}
+	if n := len(c.minutes); n > 0 {
+		minute, i = i%n, i/n
+	}
 
-		secondsLooperFn := func() *time.Time {
-			ret := current // copy
-			current = current.Add(secondsLooper[loopIdx])
-			loopIdx++
-			if loopIdx >= len(secondsLooper) {
-				loopIdx = 0
-			}
-			return &ret
-		}
+	if len(c.hours) > 0 {
+		t = atHour(t, c.hours[i])
+	}
+	if len(c.minutes) > 0 {
+		t = atMinute(t, c.minutes[minute])
+	}
+	if len(c.seconds) > 0 {
+		t = atSecond(t, c.seconds[second])
+	}
+	return t
+}
 
-		var afterFirst bool
+// expandBySetPos expands days into their times, like expand, keeping only
+// those at the positions of setpos. Only the times kept are made, in place of
+// the days.
+func (c timesOfDay) expandBySetPos(days []time.Time, setpos []int) []time.Time {
+	if len(setpos) == 0 {
+		return c.expand(days)
+	}
 
-		// return an initial function that does the first initial
-		nextFn = func() *time.Time {
-			if afterFirst {
-				return secondsLooperFn()
-			}
+	perDay := c.perDay()
+	var buf [8]int
+	positions := appendSetPositions(buf[:0], len(days)*perDay, setpos)
 
-			ret := current // copy
-			current = current.Add(firstDiff)
-			afterFirst = true
-			return &ret
-		}
+	// the times kept are made after the days, then moved over them.
+	n := len(days)
+	tt := days
+	for _, pos := range positions {
+		tt = append(tt, c.at(days[pos/perDay], pos%perDay))
 	}
+	return append(tt[:0], tt[n:]...)
+}
 
-	return &iterator{
-		minTime:  start,
-		maxTime:  timeOrMax(rrule.Until),
-		queueCap: rrule.Count,
-		setpos:   rrule.BySetPos,
-		next:     nextFn,
+func setSecondly(rrule RRule) *iterator {
+	start := rrule.dtstart()
+	tables := newYearTables(rrule.weekStart())
 
-		valid: combineLimiters(
+	interval := 1
+	if rrule.Interval > 0 {
+		interval = rrule.Interval
+	}
+
+	s := &secondlySteps{
+		start:    start,
+		current:  start,
+		months:   rrule.ByMonths,
+		interval: time.Duration(interval) * time.Second,
+		step:     time.Duration(interval) * time.Second,
+		limit: combineLimiters(
 			validSecond(rrule.BySeconds),
 			validMinute(rrule.ByMinutes),
 			validHour(rrule.ByHours),
 			validWeekday(rrule.ByWeekdays),
-			validMonthDay(rrule.ByMonthDays),
+			validMonthDay(rrule.ByMonthDays, tables),
 			validMonth(rrule.ByMonths),
-			validWeek(rrule.ByWeekNumbers),
-			validYearDay(rrule.ByYearDays),
+			validWeek(rrule.ByWeekNumbers, tables),
+			validYearDay(rrule.ByYearDays, tables),
 		),
-
-		variations: func(t *time.Time) []time.Time {
-			if t == nil {
-				return nil
-			}
-			return []time.Time{*t}
-		},
 	}
-}
 
-func setMinutely(rrule RRule) *iterator {
-	start := rrule.Dtstart
-	if start.IsZero() {
-		start = time.Now()
-	}
+	// An rrule with Interval of 1 and BySeconds will potentially cycle through
+	// many seconds that get skipped. This is a fairly expensive case, but can be
+	// short-circuited by skipping to each subsequent BySeconds point instead of
+	// each second.
+	if interval == 1 && len(rrule.BySeconds) > 0 {
+		s.seconds = newCycle(sortedClock(rrule.BySeconds, 60), start.Second(), 60)
 
-	interval := 1
-	if rrule.Interval != 0 {
-		interval = rrule.Interval
+		// the cycle repeats every minute, so jumps must preserve that phase.
+		s.step = time.Minute
 	}
 
-	current := start
-
 	return &iterator{
 		minTime:  start,
 		maxTime:  timeOrMax(rrule.Until),
-		setpos:   rrule.BySetPos,
 		queueCap: rrule.Count,
-		next: func() *time.Time {
-			ret := current // copy current
-			current = current.Add(time.Duration(interval) * time.Minute)
-			return &ret
-		},
+		steps:    s,
+	}
+}
+
+// secondlySteps steps through the seconds of a SECONDLY pattern, each its own
+// occurrence.
+type secondlySteps struct {
+	start, current time.Time
+	months         []time.Month
+	interval       time.Duration
+
+	// seconds, if set, cycles through the seconds of BYSECOND, which are
+	// then stepped between directly.
+	seconds *cycle
+
+	// step is the whole number of intervals jumps move by.
+	step time.Duration
+
+	limit func(t *time.Time) bool
+	buf   [1]time.Time
+}
+
+func (s *secondlySteps) next() (time.Time, bool) {
+	// times in months BYMONTH excludes are jumped over rather than
+	// stepped through.
+	if month, ok := nextMonthIn(s.current, s.months); ok {
+		s.current, _ = forwardDuration(s.current, month, s.step)
+	}
+
+	t := s.current
+	if s.seconds != nil {
+		s.current = s.current.Add(time.Duration(s.seconds.next()) * time.Second)
+	} else {
+		s.current = s.current.Add(s.interval)
+	}
+	return t, true
+}
+
+func (s *secondlySteps) fastForward(t time.Time) (moved bool) {
+	s.current, moved = forwardDuration(s.current, t, s.step)
+	return
+}
 
-		valid: combineLimiters(
+func (s *secondlySteps) valid(t time.Time) bool { return s.limit(&t) }
+
+func (s *secondlySteps) variations(t time.Time) []time.Time {
+	s.buf[0] = t
+	return s.buf[:]
+}
+
+func (s *secondlySteps) reset() {
+	s.current = s.start
+	if s.seconds != nil {
+		s.seconds.reset()
+	}
+}
+
+func setMinutely(rrule RRule) *iterator {
+	start := rrule.dtstart()
+	tables := newYearTables(rrule.weekStart())
+
+	interval := 1
+	if rrule.Interval > 0 {
+		interval = rrule.Interval
+	}
+
+	s := &clockSteps{
+		start:   start,
+		current: start,
+		months:  rrule.ByMonths,
+		unit:    time.Minute,
+		step:    time.Duration(interval) * time.Minute,
+		clock:   time.Time.Minute,
+		setpos:  rrule.BySetPos,
+		times:   timesOfDay{seconds: sortedClock(rrule.BySeconds, 60)},
+		limit: combineLimiters(
 			validMonth(rrule.ByMonths),
-			validWeek(rrule.ByWeekNumbers),
-			validYearDay(rrule.ByYearDays),
-			validMonthDay(rrule.ByMonthDays),
+			validWeek(rrule.ByWeekNumbers, tables),
+			validYearDay(rrule.ByYearDays, tables),
+			validMonthDay(rrule.ByMonthDays, tables),
 			validWeekday(rrule.ByWeekdays),
 			validHour(rrule.ByHours),
 			validMinute(rrule.ByMinutes),
 		),
+	}
 
-		variations: func(t *time.Time) []time.Time {
-			if t == nil {
-				return nil
-			}
-			tt := expandBySeconds([]time.Time{*t}, rrule.BySeconds...)
-			tt = limitBySetPos(tt, rrule.BySetPos)
-			return tt
-		},
+	// with an interval of 1, BYMINUTE rejects most minutes. Moving from each
+	// minute straight to the next skips them, like setSecondly does with
+	// BYSECOND.
+	if interval == 1 && len(rrule.ByMinutes) > 0 {
+		s.gaps = clockGaps(rrule.ByMinutes, 60)
+	}
+
+	return &iterator{
+		minTime:  start,
+		maxTime:  timeOrMax(rrule.Until),
+		queueCap: rrule.Count,
+		steps:    s,
 	}
 }
 
 func setHourly(rrule RRule) *iterator {
-	start := rrule.Dtstart
-	if start.IsZero() {
-		start = time.Now()
-	}
+	start := rrule.dtstart()
+	tables := newYearTables(rrule.weekStart())
 
 	interval := 1
-	if rrule.Interval != 0 {
+	if rrule.Interval > 0 {
 		interval = rrule.Interval
 	}
 
-	current := start
+	times := newTimesOfDay(rrule)
+	times.hours = nil
+
+	s := &clockSteps{
+		start:   start,
+		current: start,
+		months:  rrule.ByMonths,
+		unit:    time.Hour,
+		step:    time.Duration(interval) * time.Hour,
+		clock:   time.Time.Hour,
+		setpos:  rrule.BySetPos,
+		times:   times,
+		limit: combineLimiters(
+			validMonth(rrule.ByMonths),
+			validWeek(rrule.ByWeekNumbers, tables),
+			validYearDay(rrule.ByYearDays, tables),
+			validMonthDay(rrule.ByMonthDays, tables),
+			validWeekday(rrule.ByWeekdays),
+			validHour(rrule.ByHours),
+		),
+	}
+
+	// with an interval of 1, BYHOUR rejects most hours. Moving from each
+	// hour straight to the next skips them, like setSecondly does with
+	// BYSECOND.
+	if interval == 1 && len(rrule.ByHours) > 0 {
+		s.gaps = clockGaps(rrule.ByHours, 24)
+	}
 
 	return &iterator{
 		minTime:  start,
 		maxTime:  timeOrMax(rrule.Until),
-		setpos:   rrule.BySetPos,
 		queueCap: rrule.Count,
-		next: func() *time.Time {
-			ret := current // copy current
-			current = current.Add(time.Duration(interval) * time.Hour)
-			return &ret
-		},
+		steps:    s,
+	}
+}
 
-		valid: combineLimiters(
-			validMonth(rrule.ByMonths),
-			validWeek(rrule.ByWeekNumbers),
-			validYearDay(rrule.ByYearDays),
-			validMonthDay(rrule.ByMonthDays),
-			validWeekday(rrule.ByWeekdays),
-			validHour(rrule.ByHours),
-		),
+// clockSteps steps through the minutes of a MINUTELY pattern, or the hours of
+// an HOURLY one.
+type clockSteps struct {
+	start, current time.Time
+	months         []time.Month
 
-		variations: func(t *time.Time) []time.Time {
-			if t == nil {
-				return nil
-			}
-			tt := expandByMinutes([]time.Time{*t}, rrule.ByMinutes...)
-			tt = expandBySeconds(tt, rrule.BySeconds...)
-			tt = limitBySetPos(tt, rrule.BySetPos)
-			return tt
-		},
+	// unit is a minute or an hour, and step the interval in them.
+	unit, step time.Duration
+
+	// clock returns the minute or the hour of a time.
+	clock func(time.Time) int
+
+	// gaps, if set, holds the units from each value of the clock to the
+	// next of BYMINUTE or BYHOUR, which are then stepped between directly.
+	gaps []int
+
+	times  timesOfDay
+	setpos []int
+	limit  func(t *time.Time) bool
+
+	timesBuf
+}
+
+func (s *clockSteps) next() (time.Time, bool) {
+	if month, ok := nextMonthIn(s.current, s.months); ok {
+		s.current, _ = forwardDuration(s.current, month, s.step)
+	}
+
+	t := s.current
+	if s.gaps != nil {
+		s.current = stepClock(s.current, time.Duration(s.gaps[s.clock(s.current)])*s.unit, s.unit)
+	} else {
+		s.current = s.current.Add(s.step)
 	}
+	return t, true
+}
+
+func (s *clockSteps) fastForward(t time.Time) (moved bool) {
+	s.current, moved = forwardDuration(s.current, t, s.step)
+	return
+}
+
+func (s *clockSteps) valid(t time.Time) bool { return s.limit(&t) }
+
+func (s *clockSteps) variations(t time.Time) []time.Time {
+	return s.keep(s.times.expandBySetPos(append(s.reuse(), t), s.setpos))
+}
+
+func (s *clockSteps) reset() {
+	s.current = s.start
 }
 
 func setMonthly(rrule RRule) *iterator {
-	start := rrule.Dtstart
-	if start.IsZero() {
-		start = time.Now()
+	start := rrule.dtstart()
+
+	// when BYMONTHDAY or BYDAY pick the days, only the month of each key time
+	// matters. Stepping from the 1st keeps a start late in the month from
+	// jumping over shorter months.
+	first := start
+	if len(rrule.ByMonthDays) > 0 || len(rrule.ByWeekdays) > 0 {
+		first = firstOfMonth(start)
 	}
 
-	current := start
-
 	interval := 1
-	if rrule.Interval != 0 {
+	if rrule.Interval > 0 {
 		interval = rrule.Interval
 	}
 
-	checkLeapDay := current.Day() >= 29
-
 	return &iterator{
 		minTime:  start,
 		maxTime:  timeOrMax(rrule.Until),
-		setpos:   rrule.BySetPos,
 		queueCap: rrule.Count,
-		next: func() *time.Time {
-			ret := current // copy current
-
-			current = current.AddDate(0, interval, 0)
-
-			// check that we advanced the correct
-			// number of months, e.g. if we meant to hit
-			// a feb 29th, but it's not a leap year.
-			//
-			// because we only support gregorian, we only
-			// need this logic on rules that key on the 29th,
-			// 30th, or 31st of a month
-			if checkLeapDay {
-				diff := monthDiff(ret, current)
-				if diff%interval != 0 {
-					switch rrule.InvalidBehavior {
-					case PrevInvalid:
-						current = current.AddDate(0, 0, -1)
-					case NextInvalid:
-						// time.AddDate already behaves this way.
-					case OmitInvalid:
-						mult := 1
-						for diff%interval != 0 {
-							mult++
-							current = ret.AddDate(0, interval*mult, 0)
-							diff = monthDiff(ret, current)
-						}
-					}
-				}
-			}
-
-			return &ret
+		steps: &monthlySteps{
+			first:     first,
+			clock:     first,
+			month:     monthNumber(first),
+			interval:  interval,
+			invalid:   rrule.InvalidBehavior,
+			monthDays: rrule.ByMonthDays,
+			weekdays:  rrule.ByWeekdays,
+			times:     newTimesOfDay(rrule),
+			setpos:    rrule.BySetPos,
+			months:    rrule.ByMonths,
 		},
+	}
+}
 
-		valid: func(t *time.Time) bool {
-			if t == nil {
-				return false
-			}
-			if len(rrule.ByMonthDays) > 0 {
-				return checkLimiters(t,
-					validMonth(rrule.ByMonths),
-					validWeekday(rrule.ByWeekdays),
-				)
-			}
-			return checkLimiters(t,
-				validMonth(rrule.ByMonths),
-			)
-		},
+// monthlySteps steps through the months of a MONTHLY pattern.
+type monthlySteps struct {
+	// first is the first key time, whose day of the month the others fall
+	// on. Each takes its time of day from clock, the one before, so that a
+	// clock time time.Date moved out of a daylight saving gap is kept, as
+	// with other patterns.
+	first, clock time.Time
+
+	// month is the month of the next key time, as numbered by
+	// monthNumber. keyMonth is the month of the last, which BYMONTH
+	// limits, as SKIP=FORWARD may have moved it into the next.
+	month    int
+	keyMonth time.Month
+	interval int
+
+	invalid   InvalidBehavior
+	monthDays []int
+	weekdays  []QualifiedWeekday
+	times     timesOfDay
+	setpos    []int
+	months    []time.Month
+
+	timesBuf
+}
 
-		variations: func(t *time.Time) []time.Time {
-			if t == nil {
-				return nil
-			}
-			tt := expandBySeconds([]time.Time{*t}, rrule.BySeconds...)
-			tt = expandByMinutes(tt, rrule.ByMinutes...)
-			tt = expandByHours(tt, rrule.ByHours...)
-			if len(rrule.ByMonthDays) > 0 {
-				tt = expandByMonthDays(tt, rrule.ByMonthDays...)
-			} else if len(rrule.ByWeekdays) > 0 {
-				tt = expandMonthByWeekdays(tt, rrule.InvalidBehavior, rrule.BySetPos, rrule.ByWeekdays...)
+func (s *monthlySteps) next() (time.Time, bool) {
+	for {
+		year, month := monthOf(s.month)
+		s.month += s.interval
+
+		// a day the month doesn't have, like the 31st of April, is
+		// resolved in that month alone, so later months still fall
+		// on the day of first.
+		day, ok := invalidDay(s.first.Day(), daysInMonth(year, month), s.invalid)
+		if ok {
+			c := s.clock
+			s.keyMonth = month
+			s.clock = time.Date(year, month, day, c.Hour(), c.Minute(), c.Second(), c.Nanosecond(), c.Location())
+			return s.clock, true
+		}
+	}
+}
+
+func (s *monthlySteps) fastForward(t time.Time) bool {
+	// stop at least one key time short of t, which a day past the end of
+	// its month may have been moved into.
+	n := (monthNumber(t.In(s.first.Location())) - s.month) / s.interval
+	if n < 2 {
+		return false
+	}
+	s.month += (n - 1) * s.interval
+	return true
+}
+
+func (s *monthlySteps) valid(t time.Time) bool {
+	return len(s.months) == 0 || hasMonth(s.months, s.keyMonth)
+}
+
+func (s *monthlySteps) variations(t time.Time) []time.Time {
+	setpos := s.setpos
+	tt := s.reuse()
+	if len(s.monthDays) > 0 && len(s.weekdays) > 0 {
+		// BYDAY limits the days BYMONTHDAY picks.
+		var days daySet
+		length := lastOfMonth(t).Day()
+		for _, md := range s.monthDays {
+			if day, ok := invalidDay(md, length, s.invalid); ok {
+				days.add(day)
 			}
-			return tt
-		},
+		}
+		days.intersect(monthWeekdaySet(t, s.weekdays, s.invalid))
+
+		var buf [31]int
+		for _, day := range days.appendDays(buf[:0]) {
+			tt = append(tt, time.Date(t.Year(), t.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()))
+		}
+	} else if len(s.monthDays) > 0 {
+		tt = expandByMonthDays(tt, t, s.invalid, s.monthDays...)
+	} else if len(s.weekdays) > 0 {
+		if s.times.single() {
+			// with one time a day, BYSETPOS picks days before they're
+			// made.
+			tt = appendWeekdaysInMonth(tt, t, s.weekdays, setpos, s.invalid)
+			setpos = nil
+		} else {
+			tt = appendWeekdaysInMonth(tt, t, s.weekdays, nil, s.invalid)
+		}
+	} else {
+		tt = append(tt, t)
 	}
+	return s.keep(s.times.expandBySetPos(tt, setpos))
+}
+
+func (s *monthlySteps) reset() {
+	s.clock, s.month = s.first, monthNumber(s.first)
 }
 
 func setDaily(rrule RRule) *iterator {
-	start := rrule.Dtstart
-	if start.IsZero() {
-		start = time.Now()
-	}
+	start := rrule.dtstart()
+	tables := newYearTables(rrule.weekStart())
 
 	interval := 1
-	if rrule.Interval != 0 {
+	if rrule.Interval > 0 {
 		interval = rrule.Interval
 	}
 
-	current := start
+	s := &dailySteps{
+		start:    start,
+		current:  start,
+		months:   rrule.ByMonths,
+		interval: interval,
+		step:     interval,
+		times:    newTimesOfDay(rrule),
+		setpos:   rrule.BySetPos,
+		limit: combineLimiters(
+			validMonth(rrule.ByMonths),
+			validMonthDay(rrule.ByMonthDays, tables),
+			validWeekday(rrule.ByWeekdays),
+		),
+	}
+
+	// with an interval of 1, BYDAY rejects most days. Moving from each
+	// weekday straight to the next skips them, like setSecondly does with
+	// BYSECOND.
+	if interval == 1 && len(rrule.ByWeekdays) > 0 {
+		days := make([]int, len(rrule.ByWeekdays))
+		for i, wd := range rrule.ByWeekdays {
+			days[i] = int(wd.WD)
+		}
+		s.weekdays = newCycle(days, int(start.Weekday()), 7)
+
+		// the cycle repeats every week, so jumps must preserve that phase.
+		s.step = 7
+	}
 
 	return &iterator{
 		minTime:  start,
 		maxTime:  timeOrMax(rrule.Until),
-		setpos:   rrule.BySetPos,
 		queueCap: rrule.Count,
-		next: func() *time.Time {
-			ret := current // copy current
-			current = current.AddDate(0, 0, interval)
-			return &ret
-		},
+		steps:    s,
+	}
+}
 
-		valid: combineLimiters(
-			validMonth(rrule.ByMonths),
-			validMonthDay(rrule.ByMonthDays),
-			validWeekday(rrule.ByWeekdays),
-		),
+// dailySteps steps through the days of a DAILY pattern.
+type dailySteps struct {
+	start, current time.Time
+	months         []time.Month
+	interval       int
 
-		variations: func(t *time.Time) []time.Time {
-			if t == nil {
-				return nil
-			}
-			tt := expandBySeconds([]time.Time{*t}, rrule.BySeconds...)
-			tt = expandByMinutes(tt, rrule.ByMinutes...)
-			tt = expandByHours(tt, rrule.ByHours...)
-			tt = limitBySetPos(tt, rrule.BySetPos)
-			return tt
-		},
+	// weekdays, if set, cycles through the weekdays of BYDAY, which are
+	// then stepped between directly.
+	weekdays *cycle
+
+	// step is the whole number of days jumps move by.
+	step int
+
+	times  timesOfDay
+	setpos []int
+	limit  func(t *time.Time) bool
+
+	timesBuf
+}
+
+func (s *dailySteps) next() (time.Time, bool) {
+	// days in months BYMONTH excludes are jumped over rather than stepped
+	// through.
+	if month, ok := nextMonthIn(s.current, s.months); ok {
+		s.current, _ = forwardDays(s.current, month, s.step)
 	}
+
+	t := s.current
+	if s.weekdays != nil {
+		s.current = s.current.AddDate(0, 0, s.weekdays.next())
+	} else {
+		s.current = s.current.AddDate(0, 0, s.interval)
+	}
+	return t, true
 }
 
-func setWeekly(rrule RRule) *iterator {
-	start := rrule.Dtstart
-	if start.IsZero() {
-		start = time.Now()
+func (s *dailySteps) fastForward(t time.Time) (moved bool) {
+	s.current, moved = forwardDays(s.current, t, s.step)
+	return
+}
+
+func (s *dailySteps) valid(t time.Time) bool { return s.limit(&t) }
+
+func (s *dailySteps) variations(t time.Time) []time.Time {
+	return s.keep(s.times.expandBySetPos(append(s.reuse(), t), s.setpos))
+}
+
+func (s *dailySteps) reset() {
+	s.current = s.start
+	if s.weekdays != nil {
+		s.weekdays.reset()
 	}
+}
+
+func setWeekly(rrule RRule) *iterator {
+	start := rrule.dtstart()
 
 	interval := 1
-	if rrule.Interval != 0 {
+	if rrule.Interval > 0 {
 		interval = rrule.Interval
 	}
 
-	current := start
-
 	return &iterator{
 		minTime:  start,
 		maxTime:  timeOrMax(rrule.Until),
-		setpos:   rrule.BySetPos,
 		queueCap: rrule.Count,
-		next: func() *time.Time {
-			ret := current // copy current
-			current = current.AddDate(0, 0, interval*7)
-			return &ret
+		steps: &weeklySteps{
+			start:     start,
+			current:   start,
+			months:    rrule.ByMonths,
+			days:      interval * 7,
+			weekStart: rrule.weekStart(),
+			weekdays:  sortedWeekdays(rrule.ByWeekdays, rrule.weekStart()),
+			times:     newTimesOfDay(rrule),
+			setpos:    rrule.BySetPos,
 		},
+	}
+}
 
-		valid: combineLimiters(
-			validMonth(rrule.ByMonths),
-		),
+// weeklySteps steps through the weeks of a WEEKLY pattern.
+type weeklySteps struct {
+	start, current time.Time
+	months         []time.Month
 
-		variations: func(t *time.Time) []time.Time {
-			if t == nil {
-				return nil
-			}
-			tt := expandBySeconds([]time.Time{*t}, rrule.BySeconds...)
-			tt = expandByMinutes(tt, rrule.ByMinutes...)
-			tt = expandByHours(tt, rrule.ByHours...)
-			tt = limitBySetPos(tt, rrule.BySetPos)
-			tt = expandByWeekdays(tt, rrule.weekStart(), rrule.ByWeekdays...)
-			return tt
-		},
+	// days is the interval in days.
+	days int
+
+	weekStart time.Weekday
+	weekdays  []QualifiedWeekday
+	times     timesOfDay
+	setpos    []int
+
+	timesBuf
+}
+
+func (s *weeklySteps) next() (time.Time, bool) {
+	if month, ok := nextMonthIn(s.current, s.months); ok {
+		s.current, _ = forwardDays(s.current, month, s.days)
 	}
+
+	t := s.current
+	s.current = s.current.AddDate(0, 0, s.days)
+	return t, true
+}
+
+func (s *weeklySteps) fastForward(t time.Time) (moved bool) {
+	s.current, moved = forwardDays(s.current, t, s.days)
+	return
+}
+
+func (s *weeklySteps) valid(t time.Time) bool {
+	return len(s.months) == 0 || hasMonth(s.months, t.Month())
+}
+
+func (s *weeklySteps) variations(t time.Time) []time.Time {
+	tt := expandByWeekdays(append(s.reuse(), t), s.weekStart, s.weekdays...)
+	return s.keep(s.times.expandBySetPos(tt, s.setpos))
+}
+
+func (s *weeklySteps) reset() {
+	s.current = s.start
 }
 
 func setYearly(rrule RRule) *iterator {
-	start := rrule.Dtstart
-	if start.IsZero() {
-		start = time.Now()
-	}
+	start := rrule.dtstart()
 
 	interval := 1
-	if rrule.Interval != 0 {
+	if rrule.Interval > 0 {
 		interval = rrule.Interval
 	}
 
-	current := start
-
-	plainByDay := plainWeekdays(rrule.ByWeekdays)
+	// the key times are the first of each year, and the days of the year
+	// the pattern picks are computed once for each kind of year.
+	first := time.Date(start.Year(), time.January, 1, 0, 0, 0, 0, start.Location())
 
 	return &iterator{
 		minTime:  start,
 		maxTime:  timeOrMax(rrule.Until),
-		setpos:   rrule.BySetPos,
 		queueCap: rrule.Count,
-		next: func() *time.Time {
-			ret := current // copy current
-			current = current.AddDate(interval, 0, 0)
-			return &ret
+		steps: &yearlySteps{
+			rrule:    rrule,
+			start:    start,
+			first:    first,
+			year:     first.Year(),
+			interval: interval,
+			clocks:   rrule.dayClocks(start),
+			kinds:    make(map[yearKind][]int, 14),
 		},
+	}
+}
 
-		valid: func(t *time.Time) bool {
-			if t == nil {
-				return false
-			}
+// yearlySteps steps through the years of a YEARLY pattern.
+type yearlySteps struct {
+	rrule        RRule
+	start, first time.Time
 
-			// see note 2 on page 44 of RFC 5545, including erratum 3747.
-			if len(rrule.ByYearDays) > 0 || len(rrule.ByMonthDays) > 0 {
-				return checkLimiters(t,
-					validMonth(rrule.ByMonths),
-					validWeekday(rrule.ByWeekdays),
-				)
-			}
+	// year is the year of the next key time.
+	year     int
+	interval int
 
-			return checkLimiters(t,
-				validMonth(rrule.ByMonths),
-			)
-		},
+	// clocks are the seconds of the day of each time, and kinds the days
+	// of the year for each kind of year.
+	clocks []int
+	kinds  map[yearKind][]int
 
-		variations: func(t *time.Time) []time.Time {
-			if t == nil {
-				return nil
-			}
+	// barren counts the consecutive key years without days. The calendar
+	// repeats, so a whole cycle of them means the pattern never occurs.
+	barren int
 
-			tt := expandBySeconds([]time.Time{*t}, rrule.BySeconds...)
-			tt = expandByMinutes(tt, rrule.ByMinutes...)
-			tt = expandByHours(tt, rrule.ByHours...)
-
-			tt = expandByMonthDays(tt, rrule.ByMonthDays...)
-			tt = expandByYearDays(tt, rrule.InvalidBehavior, rrule.ByYearDays...)
-			tt = expandByMonths(tt, rrule.InvalidBehavior, rrule.ByMonths...)
-
-			// see note 2 on page 44 of RFC 5545, including erratum 3779.
-			if len(rrule.ByYearDays) == 0 && len(rrule.ByMonthDays) == 0 {
-				if len(rrule.ByMonths) != 0 {
-					tt = expandMonthByWeekdays(tt, rrule.InvalidBehavior, nil, rrule.ByWeekdays...)
-				} else if len(rrule.ByWeekNumbers) != 0 {
-					tt = expandByWeekNumbers(tt, rrule.InvalidBehavior, rrule.weekStart(), plainByDay, rrule.ByWeekNumbers...)
-				} else {
-					tt = expandYearByWeekdays(tt, rrule.InvalidBehavior, rrule.ByWeekdays...)
-				}
-			}
+	timesBuf
+}
 
-			tt = limitBySetPos(tt, rrule.BySetPos)
-			return tt
-		},
+func (s *yearlySteps) yearDays(year int) []int {
+	kind := kindOfYear(year)
+	days, ok := s.kinds[kind]
+	if !ok {
+		days = s.rrule.yearDays(year, s.start)
+		s.kinds[kind] = days
 	}
+	return days
 }
 
-func (rrule *RRule) weekStart() time.Weekday {
-	if rrule.WeekStart == nil {
-		return time.Monday
+func (s *yearlySteps) next() (time.Time, bool) {
+	if s.barren*s.interval >= gregorianCycle {
+		return time.Time{}, false
+	}
+	t := time.Date(s.year, time.January, 1, 0, 0, 0, 0, s.first.Location())
+	s.year += s.interval
+	return t, true
+}
+
+func (s *yearlySteps) fastForward(t time.Time) bool {
+	n := (t.In(s.first.Location()).Year() - s.year) / s.interval
+	if n < 2 {
+		return false
 	}
-	return *rrule.WeekStart
+	s.year += (n - 1) * s.interval
+	return true
+}
+
+func (s *yearlySteps) valid(t time.Time) bool {
+	// a year is barren without days, or when BYSETPOS picks none of them.
+	var buf [8]int
+	n := len(s.yearDays(t.Year())) * len(s.clocks)
+	if n == 0 || len(s.rrule.BySetPos) > 0 && len(appendSetPositions(buf[:0], n, s.rrule.BySetPos)) == 0 {
+		s.barren++
+		return false
+	}
+	s.barren = 0
+	return true
+}
+
+func (s *yearlySteps) variations(t time.Time) []time.Time {
+	year := t.Year()
+	days := s.yearDays(year)
+	at := func(pos int) time.Time {
+		day, clock := days[pos/len(s.clocks)], s.clocks[pos%len(s.clocks)]
+		return time.Date(year, time.January, day, clock/(60*60), clock/60%60, clock%60, s.start.Nanosecond(), t.Location())
+	}
+
+	// BYSETPOS picks from days and clocks without making times for the
+	// rest.
+	tt := s.reuse()
+	if len(s.rrule.BySetPos) > 0 {
+		var buf [8]int
+		for _, pos := range appendSetPositions(buf[:0], len(days)*len(s.clocks), s.rrule.BySetPos) {
+			tt = append(tt, at(pos))
+		}
+	} else {
+		for pos := 0; pos < len(days)*len(s.clocks); pos++ {
+			tt = append(tt, at(pos))
+		}
+	}
+	return s.keep(tt)
+}
+
+func (s *yearlySteps) reset() {
+	s.year = s.first.Year()
+	s.barren = 0
+}
+
+func (rrule *RRule) weekStart() time.Weekday {
+	wd, _ := rrule.WeekStart.Weekday()
+	return wd
 }
 
 func timeOrMax(t time.Time) time.Time {
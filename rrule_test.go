@@ -1,6 +1,9 @@
 package rrule
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
 	"testing"
 	"time"
 
@@ -64,6 +67,19 @@ var cases = []struct {
 		Terminal: true,
 	},
 
+	{
+		Name:   "daily by weekday",
+		String: "FREQ=DAILY;COUNT=5;BYDAY=MO,WE,FR",
+		RRule: RRule{
+			Frequency:  Daily,
+			Count:      5,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Wednesday}, {WD: time.Friday}},
+		},
+		Dates:    []string{"2018-08-27T09:08:07Z", "2018-08-29T09:08:07Z", "2018-08-31T09:08:07Z", "2018-09-03T09:08:07Z", "2018-09-05T09:08:07Z"},
+		Terminal: true,
+	},
+
 	{
 		Name: "secondly setpos",
 		RRule: RRule{
@@ -118,6 +134,63 @@ var cases = []struct {
 		Dates:    []string{"2018-08-26T01:08:07Z", "2018-08-26T03:08:07Z", "2018-08-27T01:08:07Z", "2018-08-27T03:08:07Z"},
 		Terminal: true,
 	},
+
+	{
+		Name:   "daily by hour and minute",
+		String: "FREQ=DAILY;COUNT=4;BYMINUTE=0,30;BYHOUR=9,17",
+		RRule: RRule{
+			Frequency: Daily,
+			Count:     4,
+			Dtstart:   now,
+			ByHours:   []int{9, 17},
+			ByMinutes: []int{0, 30},
+		},
+		Dates:    []string{"2018-08-25T09:30:07Z", "2018-08-25T17:00:07Z", "2018-08-25T17:30:07Z", "2018-08-26T09:00:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "monthly by monthday setpos",
+		String: "FREQ=MONTHLY;COUNT=3;BYHOUR=9,17;BYMONTHDAY=1,15;BYSETPOS=2",
+		RRule: RRule{
+			Frequency:   Monthly,
+			Count:       3,
+			Dtstart:     now,
+			ByHours:     []int{9, 17},
+			ByMonthDays: []int{1, 15},
+			BySetPos:    []int{2},
+		},
+		Dates:    []string{"2018-09-01T17:08:07Z", "2018-10-01T17:08:07Z", "2018-11-01T17:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "monthly by weekday and hour setpos",
+		String: "FREQ=MONTHLY;COUNT=3;BYHOUR=9,10;BYDAY=MO;BYSETPOS=2",
+		RRule: RRule{
+			Frequency:  Monthly,
+			Count:      3,
+			Dtstart:    now,
+			ByHours:    []int{9, 10},
+			ByWeekdays: []QualifiedWeekday{{WD: time.Monday}},
+			BySetPos:   []int{2},
+		},
+		Dates:    []string{"2018-09-03T10:08:07Z", "2018-10-01T10:08:07Z", "2018-11-05T10:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "weekly by repeated weekday",
+		String: "FREQ=WEEKLY;COUNT=3;BYDAY=MO,MO",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      3,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Monday}},
+		},
+		Dates:    []string{"2018-08-27T09:08:07Z", "2018-09-03T09:08:07Z", "2018-09-10T09:08:07Z"},
+		Terminal: true,
+	},
 	{
 		Name:   "weekly setpos",
 		String: "FREQ=WEEKLY;COUNT=4;BYHOUR=1,2,3;BYMONTH=8,9;BYSETPOS=1,3,-1",
@@ -245,6 +318,20 @@ var cases = []struct {
 		Terminal: true,
 	},
 
+	{
+		Name:   "weekly by weekday and minute",
+		String: "FREQ=WEEKLY;COUNT=5;BYMINUTE=0,30;BYDAY=SU,TU",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      5,
+			Dtstart:    now,
+			ByMinutes:  []int{0, 30},
+			ByWeekdays: []QualifiedWeekday{{WD: time.Sunday}, {WD: time.Tuesday}},
+		},
+		Dates:    []string{"2018-08-26T09:00:07Z", "2018-08-26T09:30:07Z", "2018-08-28T09:00:07Z", "2018-08-28T09:30:07Z", "2018-09-02T09:00:07Z"},
+		Terminal: true,
+	},
+
 	{
 		Name:   "yearly by weekday",
 		String: "FREQ=YEARLY;COUNT=4;BYDAY=TU,35WE,-17MO",
@@ -397,6 +484,68 @@ var cases = []struct {
 		NoTeambitionComparison: true,
 	},
 
+	{
+		Name: "month end monthly omit",
+		RRule: RRule{
+			Frequency: Monthly,
+			Dtstart:   time.Date(2019, time.August, 31, 9, 0, 0, 0, time.UTC),
+			Count:     5,
+		},
+		String:   "FREQ=MONTHLY;COUNT=5",
+		Terminal: true,
+		Dates: []string{
+			"2019-08-31T09:00:00Z",
+			"2019-10-31T09:00:00Z",
+			"2019-12-31T09:00:00Z",
+			"2020-01-31T09:00:00Z",
+			"2020-03-31T09:00:00Z",
+		},
+	},
+
+	{
+		Name: "month end monthly prev",
+		RRule: RRule{
+			Frequency:       Monthly,
+			Dtstart:         time.Date(2019, time.August, 31, 9, 0, 0, 0, time.UTC),
+			Count:           7,
+			InvalidBehavior: PrevInvalid,
+		},
+		String:   "FREQ=MONTHLY;COUNT=7;SKIP=BACKWARD;RSCALE=GREGORIAN",
+		Terminal: true,
+		Dates: []string{
+			"2019-08-31T09:00:00Z",
+			"2019-09-30T09:00:00Z",
+			"2019-10-31T09:00:00Z",
+			"2019-11-30T09:00:00Z",
+			"2019-12-31T09:00:00Z",
+			"2020-01-31T09:00:00Z",
+			"2020-02-29T09:00:00Z",
+		},
+		NoTeambitionComparison: true,
+	},
+
+	{
+		Name: "month end monthly next",
+		RRule: RRule{
+			Frequency:       Monthly,
+			Dtstart:         time.Date(2019, time.August, 31, 9, 0, 0, 0, time.UTC),
+			Count:           7,
+			InvalidBehavior: NextInvalid,
+		},
+		String:   "FREQ=MONTHLY;COUNT=7;SKIP=FORWARD;RSCALE=GREGORIAN",
+		Terminal: true,
+		Dates: []string{
+			"2019-08-31T09:00:00Z",
+			"2019-10-01T09:00:00Z",
+			"2019-10-31T09:00:00Z",
+			"2019-12-01T09:00:00Z",
+			"2019-12-31T09:00:00Z",
+			"2020-01-31T09:00:00Z",
+			"2020-03-01T09:00:00Z",
+		},
+		NoTeambitionComparison: true,
+	},
+
 	{
 		Name: "leap year day 366 omit",
 		RRule: RRule{
@@ -458,6 +607,173 @@ var cases = []struct {
 		NoTeambitionComparison: true,
 	},
 
+	{
+		Name: "month day 31 omit",
+		RRule: RRule{
+			Frequency:   Monthly,
+			Dtstart:     time.Date(2023, time.January, 31, 9, 0, 0, 0, time.UTC),
+			Count:       4,
+			ByMonthDays: []int{31},
+		},
+		String:   "FREQ=MONTHLY;COUNT=4;BYMONTHDAY=31",
+		Terminal: true,
+		Dates: []string{
+			"2023-01-31T09:00:00Z",
+			"2023-03-31T09:00:00Z",
+			"2023-05-31T09:00:00Z",
+			"2023-07-31T09:00:00Z",
+		},
+	},
+
+	{
+		Name: "month day 31 prev",
+		RRule: RRule{
+			Frequency:       Monthly,
+			Dtstart:         time.Date(2023, time.January, 31, 9, 0, 0, 0, time.UTC),
+			Count:           4,
+			ByMonthDays:     []int{31},
+			InvalidBehavior: PrevInvalid,
+		},
+		String:   "FREQ=MONTHLY;COUNT=4;BYMONTHDAY=31;SKIP=BACKWARD;RSCALE=GREGORIAN",
+		Terminal: true,
+		Dates: []string{
+			"2023-01-31T09:00:00Z",
+			"2023-02-28T09:00:00Z",
+			"2023-03-31T09:00:00Z",
+			"2023-04-30T09:00:00Z",
+		},
+		NoTeambitionComparison: true,
+	},
+
+	{
+		Name: "month day 31 next",
+		RRule: RRule{
+			Frequency:       Monthly,
+			Dtstart:         time.Date(2023, time.January, 31, 9, 0, 0, 0, time.UTC),
+			Count:           4,
+			ByMonthDays:     []int{31},
+			InvalidBehavior: NextInvalid,
+		},
+		String:   "FREQ=MONTHLY;COUNT=4;BYMONTHDAY=31;SKIP=FORWARD;RSCALE=GREGORIAN",
+		Terminal: true,
+		Dates: []string{
+			"2023-01-31T09:00:00Z",
+			"2023-03-01T09:00:00Z",
+			"2023-03-31T09:00:00Z",
+			"2023-05-01T09:00:00Z",
+		},
+		NoTeambitionComparison: true,
+	},
+
+	{
+		Name: "month days 30 and 31 prev",
+		RRule: RRule{
+			Frequency:       Monthly,
+			Dtstart:         time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC),
+			Count:           4,
+			ByMonthDays:     []int{31, 30},
+			InvalidBehavior: PrevInvalid,
+		},
+		String:   "FREQ=MONTHLY;COUNT=4;BYMONTHDAY=31,30;SKIP=BACKWARD;RSCALE=GREGORIAN",
+		Terminal: true,
+		Dates: []string{
+			"2024-02-29T09:00:00Z",
+			"2024-03-30T09:00:00Z",
+			"2024-03-31T09:00:00Z",
+			"2024-04-30T09:00:00Z",
+		},
+		NoTeambitionComparison: true,
+	},
+
+	{
+		Name: "last day of month",
+		RRule: RRule{
+			Frequency:   Monthly,
+			Dtstart:     time.Date(2023, time.January, 31, 9, 0, 0, 0, time.UTC),
+			Count:       3,
+			ByMonthDays: []int{-1},
+		},
+		String:   "FREQ=MONTHLY;COUNT=3;BYMONTHDAY=-1",
+		Terminal: true,
+		Dates: []string{
+			"2023-01-31T09:00:00Z",
+			"2023-02-28T09:00:00Z",
+			"2023-03-31T09:00:00Z",
+		},
+	},
+
+	{
+		Name: "hourly last day of month",
+		RRule: RRule{
+			Frequency:   Hourly,
+			Dtstart:     now,
+			Count:       3,
+			ByHours:     []int{9},
+			ByMonthDays: []int{-1},
+		},
+		String:   "FREQ=HOURLY;COUNT=3;BYHOUR=9;BYMONTHDAY=-1",
+		Terminal: true,
+		Dates: []string{
+			"2018-08-31T09:08:07Z",
+			"2018-09-30T09:08:07Z",
+			"2018-10-31T09:08:07Z",
+		},
+	},
+
+	{
+		Name: "minutely last day of year",
+		RRule: RRule{
+			Frequency:  Minutely,
+			Dtstart:    now,
+			Count:      2,
+			ByMinutes:  []int{0, 30},
+			ByHours:    []int{9},
+			ByYearDays: []int{-1},
+		},
+		String:   "FREQ=MINUTELY;COUNT=2;BYMINUTE=0,30;BYHOUR=9;BYYEARDAY=-1",
+		Terminal: true,
+		Dates: []string{
+			"2018-12-31T09:00:07Z",
+			"2018-12-31T09:30:07Z",
+		},
+	},
+
+	{
+		Name: "hourly last week",
+		RRule: RRule{
+			Frequency:     Hourly,
+			Dtstart:       now,
+			Count:         2,
+			ByHours:       []int{9},
+			ByWeekdays:    []QualifiedWeekday{{WD: time.Monday}},
+			ByWeekNumbers: []int{-1},
+		},
+		String:   "FREQ=HOURLY;COUNT=2;BYHOUR=9;BYDAY=MO;BYWEEKNO=-1",
+		Terminal: true,
+		Dates: []string{
+			"2018-12-24T09:08:07Z",
+			"2019-12-23T09:08:07Z",
+		},
+	},
+
+	{
+		Name: "monthly setpos past the weekdays",
+		RRule: RRule{
+			Frequency:  Monthly,
+			Dtstart:    now,
+			Count:      3,
+			ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Friday}},
+			BySetPos:   []int{-20, -2},
+		},
+		String:   "FREQ=MONTHLY;COUNT=3;BYDAY=MO,FR;BYSETPOS=-20,-2",
+		Terminal: true,
+		Dates: []string{
+			"2018-08-27T09:08:07Z",
+			"2018-09-24T09:08:07Z",
+			"2018-10-26T09:08:07Z",
+		},
+	},
+
 	{
 		Name: "rfc weekno",
 		RRule: RRule{
@@ -473,6 +789,186 @@ var cases = []struct {
 			"1998-05-11T09:00:00-04:00",
 			"1999-05-17T09:00:00-04:00",
 		},
+		Terminal: true,
+	},
+
+	{
+		Name: "weekno without weekdays",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Dtstart:       time.Date(1997, 5, 12, 9, 0, 0, 0, NewYork()),
+			Count:         9,
+			ByWeekNumbers: []int{20},
+		},
+		String: "FREQ=YEARLY;COUNT=9;BYWEEKNO=20",
+		Dates: []string{
+			"1997-05-12T09:00:00-04:00",
+			"1997-05-13T09:00:00-04:00",
+			"1997-05-14T09:00:00-04:00",
+			"1997-05-15T09:00:00-04:00",
+			"1997-05-16T09:00:00-04:00",
+			"1997-05-17T09:00:00-04:00",
+			"1997-05-18T09:00:00-04:00",
+			"1998-05-11T09:00:00-04:00",
+			"1998-05-12T09:00:00-04:00",
+		},
+		Terminal: true,
+	},
+
+	{
+		Name: "weekno with setpos",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Dtstart:       time.Date(2020, 10, 7, 14, 46, 0, 0, NewYork()),
+			Count:         15,
+			ByMinutes:     []int{15},
+			ByHours:       []int{19},
+			ByWeekNumbers: []int{-21},
+			BySetPos:      []int{-2, -3},
+			WeekStart:     WeekStartOn(time.Saturday),
+		},
+		String: "FREQ=YEARLY;COUNT=15;BYMINUTE=15;BYHOUR=19;BYWEEKNO=-21;BYSETPOS=-2,-3;WKST=SA",
+		Dates: []string{
+			"2021-08-11T19:15:00-04:00",
+			"2021-08-12T19:15:00-04:00",
+			"2022-08-10T19:15:00-04:00",
+			"2022-08-11T19:15:00-04:00",
+			"2023-08-09T19:15:00-04:00",
+			"2023-08-10T19:15:00-04:00",
+			"2024-08-14T19:15:00-04:00",
+			"2024-08-15T19:15:00-04:00",
+			"2025-08-13T19:15:00-04:00",
+			"2025-08-14T19:15:00-04:00",
+			"2026-08-12T19:15:00-04:00",
+			"2026-08-13T19:15:00-04:00",
+			"2027-08-11T19:15:00-04:00",
+			"2027-08-12T19:15:00-04:00",
+			"2028-08-09T19:15:00-04:00",
+		},
+		Terminal: true,
+	},
+
+	{
+		Name: "yearly by month before dtstart",
+		RRule: RRule{
+			Frequency: Yearly,
+			Dtstart:   now,
+			Count:     4,
+			ByMonths:  []time.Month{time.March, time.October},
+			ByHours:   []int{9, 17},
+		},
+		String: "FREQ=YEARLY;COUNT=4;BYHOUR=9,17;BYMONTH=3,10",
+		Dates: []string{
+			"2018-10-25T09:08:07Z",
+			"2018-10-25T17:08:07Z",
+			"2019-03-25T09:08:07Z",
+			"2019-03-25T17:08:07Z",
+		},
+		Terminal: true,
+	},
+
+	{
+		Name: "yearly by year day and month",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Dtstart:    now,
+			Count:      3,
+			ByYearDays: []int{60, -1},
+			ByMonths:   []time.Month{time.March},
+		},
+		String: "FREQ=YEARLY;COUNT=3;BYYEARDAY=60,-1;BYMONTH=3",
+		Dates: []string{
+			"2019-03-01T09:08:07Z",
+			"2021-03-01T09:08:07Z",
+			"2022-03-01T09:08:07Z",
+		},
+		Terminal: true,
+	},
+
+	{
+		Name: "monthly friday 13th",
+		RRule: RRule{
+			Frequency:   Monthly,
+			Dtstart:     now,
+			Count:       3,
+			ByWeekdays:  []QualifiedWeekday{{WD: time.Friday}},
+			ByMonthDays: []int{13},
+		},
+		String: "FREQ=MONTHLY;COUNT=3;BYDAY=FR;BYMONTHDAY=13",
+		Dates: []string{
+			"2019-09-13T09:08:07Z",
+			"2019-12-13T09:08:07Z",
+			"2020-03-13T09:08:07Z",
+		},
+		Terminal: true,
+	},
+
+	{
+		Name: "yearly friday 13th",
+		RRule: RRule{
+			Frequency:   Yearly,
+			Dtstart:     now,
+			Count:       3,
+			ByWeekdays:  []QualifiedWeekday{{WD: time.Friday}},
+			ByMonthDays: []int{13},
+			ByMonths:    []time.Month{time.February, time.May},
+		},
+		String: "FREQ=YEARLY;COUNT=3;BYDAY=FR;BYMONTHDAY=13;BYMONTH=2,5",
+		Dates: []string{
+			"2022-05-13T09:08:07Z",
+			"2026-02-13T09:08:07Z",
+			"2032-02-13T09:08:07Z",
+		},
+		Terminal: true,
+	},
+
+	{
+		Name: "yearly last week",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Dtstart:       now,
+			Count:         3,
+			ByWeekNumbers: []int{-1},
+			ByWeekdays:    []QualifiedWeekday{{WD: time.Thursday}},
+		},
+		String: "FREQ=YEARLY;COUNT=3;BYDAY=TH;BYWEEKNO=-1",
+		Dates: []string{
+			"2018-12-27T09:08:07Z",
+			"2019-12-26T09:08:07Z",
+			"2020-12-31T09:08:07Z",
+		},
+		Terminal: true,
+	},
+
+	{
+		Name: "yearly setpos by hour",
+		RRule: RRule{
+			Frequency: Yearly,
+			Dtstart:   now,
+			Count:     2,
+			ByHours:   []int{8, 20},
+			ByMinutes: []int{0, 30},
+			BySetPos:  []int{2},
+		},
+		String: "FREQ=YEARLY;COUNT=2;BYMINUTE=0,30;BYHOUR=8,20;BYSETPOS=2",
+		Dates: []string{
+			"2019-08-25T08:30:07Z",
+			"2020-08-25T08:30:07Z",
+		},
+		Terminal: true,
+	},
+
+	{
+		Name: "yearly never",
+		RRule: RRule{
+			Frequency:   Yearly,
+			Dtstart:     now,
+			ByWeekdays:  []QualifiedWeekday{{N: 1, WD: time.Monday}},
+			ByMonthDays: []int{8},
+		},
+		String:      "FREQ=YEARLY;BYDAY=1MO;BYMONTHDAY=8",
+		Dates:       []string{},
+		NoBenchmark: true,
 	},
 }
 
@@ -542,6 +1038,7 @@ func BenchmarkRRule(b *testing.B) {
 		}
 
 		b.Run(tc.Name, func(b *testing.B) {
+			b.ReportAllocs()
 			for i := 0; i < b.N; i++ {
 				All(tc.RRule.Iterator(), 0)
 			}
@@ -549,6 +1046,73 @@ func BenchmarkRRule(b *testing.B) {
 	}
 }
 
+// BenchmarkVariations measures dense patterns, whose cost is mostly in making
+// the times of each key time.
+func BenchmarkVariations(b *testing.B) {
+	for _, str := range []string{
+		"FREQ=HOURLY;BYMINUTE=0,15,30,45",
+		"FREQ=DAILY;BYHOUR=9,12,17;BYMINUTE=0,30",
+		"FREQ=WEEKLY;BYDAY=MO,WE,FR;BYHOUR=9,17",
+		"FREQ=MONTHLY;BYMONTHDAY=1,15;BYHOUR=9,17;BYSETPOS=1,-1",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"FREQ=YEARLY;BYMONTH=3,11;BYDAY=-1SU;BYHOUR=1,2",
+	} {
+		rrule := MustRRule(str)
+		rrule.Dtstart = now
+
+		b.Run(str, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				rrule.Iterator().Take(1000)
+			}
+		})
+	}
+}
+
+// BenchmarkSetPos measures MONTHLY and YEARLY patterns that pick a few
+// positions from large sets of candidates.
+func BenchmarkSetPos(b *testing.B) {
+	for _, str := range []string{
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1,-1",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,12,17;BYMINUTE=0,30;BYSETPOS=1,-1",
+		"FREQ=MONTHLY;BYMONTHDAY=1,2,3,4,5,6,7,8,9,10;BYHOUR=9,17;BYSETPOS=2,-2",
+		"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=8,9,10,11,12,13,14,15,16,17;BYSETPOS=-1",
+		"FREQ=YEARLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1,-1",
+		"FREQ=YEARLY;BYMONTH=1,4,7,10;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,17;BYSETPOS=-1",
+	} {
+		rrule := MustRRule(str)
+		rrule.Dtstart = now
+
+		b.Run(str, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				rrule.Iterator().Take(100)
+			}
+		})
+	}
+}
+
+// BenchmarkLimits measures sparse patterns, whose cost is mostly in checking
+// each key time against the BYxxx parts that limit it.
+func BenchmarkLimits(b *testing.B) {
+	for _, str := range []string{
+		"FREQ=HOURLY;BYMONTHDAY=1,15;BYHOUR=9",
+		"FREQ=HOURLY;BYMONTHDAY=-1;BYHOUR=9",
+		"FREQ=HOURLY;BYWEEKNO=1,-1;BYHOUR=9",
+		"FREQ=MINUTELY;BYYEARDAY=1,-1;BYHOUR=9;BYMINUTE=0",
+	} {
+		rrule := MustRRule(str)
+		rrule.Dtstart = now
+
+		b.Run(str, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				rrule.Iterator().Take(20)
+			}
+		})
+	}
+}
+
 func rfcAll(times []time.Time) []string {
 	strs := make([]string, len(times))
 	for i, t := range times {
@@ -556,3 +1120,265 @@ func rfcAll(times []time.Time) []string {
 	}
 	return strs
 }
+
+func TestIteratorE(t *testing.T) {
+	it, err := RRule{Frequency: Daily, Count: 1, Dtstart: now}.IteratorE()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2018-08-25T09:08:07Z"}, rfcAll(All(it, 0)))
+
+	_, err = RRule{Frequency: Daily, Count: 1, Until: now}.IteratorE()
+	assert.Error(t, err)
+
+	_, err = RRule{Frequency: Frequency(42)}.IteratorE()
+	assert.EqualError(t, err, "invalid frequency 42")
+
+	assert.Panics(t, func() { RRule{Frequency: Frequency(42)}.Iterator() })
+}
+
+func TestValidateAll(t *testing.T) {
+	assert.NoError(t, RRule{Frequency: Weekly, Count: 3}.ValidateAll())
+
+	rrule := RRule{
+		Frequency:   Weekly,
+		Count:       3,
+		Until:       now,
+		ByWeekdays:  []QualifiedWeekday{{N: 1, WD: time.Monday}, {N: 2, WD: time.Tuesday}},
+		ByMonthDays: []int{1},
+		BySetPos:    []int{0, 400},
+	}
+
+	err := rrule.ValidateAll()
+	require.IsType(t, ValidationErrors{}, err)
+	assert.Len(t, err.(ValidationErrors), 4)
+	assert.EqualError(t, err, "BYDAY entries may only specify a numeric component when the frequency is YEARLY or MONTHLY; "+
+		"WEEKLY recurrences must not include BYMONTHDAY; "+
+		"COUNT and UNTIL must not appear in the same RRULE; "+
+		"BYSETPOS values must be between [-366,-1] or [1,366]")
+
+	// Validate reports the first of them.
+	assert.EqualError(t, rrule.Validate(), "BYDAY entries may only specify a numeric component when the frequency is YEARLY or MONTHLY")
+}
+
+func TestValidateRanges(t *testing.T) {
+	for msg, rrule := range map[string]RRule{
+		"BYSECOND values must be between 0 and 60":                                                      {Frequency: Minutely, BySeconds: []int{0, 61}},
+		"BYMINUTE values must be between 0 and 59":                                                      {Frequency: Hourly, ByMinutes: []int{-1}},
+		"BYHOUR values must be between 0 and 23":                                                        {Frequency: Daily, ByHours: []int{24}},
+		"BYDAY weekday 7 is not a day of the week":                                                      {Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: 7}}},
+		"BYDAY ordinal values must be between [-53,-1] or [1,53]":                                       {Frequency: Yearly, ByWeekdays: []QualifiedWeekday{{N: 54, WD: time.Monday}}},
+		"BYWEEKNO values must be between [-53,-1] or [1,53]":                                            {Frequency: Yearly, ByWeekNumbers: []int{0}},
+		"BYMONTHDAY values must be between [-31,-1] or [1,31]":                                          {Frequency: Monthly, ByMonthDays: []int{-32}},
+		"BYYEARDAY values must be between [-366,-1] or [1,366]":                                         {Frequency: Yearly, ByYearDays: []int{367}},
+		"BYMONTH values must be between 1 and 12":                                                       {Frequency: Yearly, ByMonths: []time.Month{13}},
+		"BYMONTH leap month values must be between 1 and 12":                                            {Frequency: Yearly, ByLeapMonths: []int{0}},
+		"BYSETPOS values must be between [-366,-1] or [1,366]":                                          {Frequency: Monthly, ByMonthDays: []int{1}, BySetPos: []int{0}},
+		"BYMONTH values must be between 1 and 12; BYSETPOS values must be between [-366,-1] or [1,366]": {Frequency: Monthly, ByMonths: []time.Month{0}, BySetPos: []int{-400}},
+	} {
+		assert.EqualError(t, rrule.ValidateAll(), msg)
+	}
+
+	assert.NoError(t, RRule{Frequency: Yearly, ByMonths: []time.Month{13}, RScale: Ethiopic}.Validate())
+	assert.NoError(t, RRule{Frequency: Minutely, BySeconds: []int{60}}.Validate())
+}
+
+func TestValidateGuards(t *testing.T) {
+	err := RRule{Frequency: Daily, Interval: -1}.Validate()
+	assert.EqualError(t, err, "INTERVAL must not be negative")
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+
+	assert.NoError(t, RRule{Frequency: Daily, Count: math.MaxInt32}.Validate())
+	err = RRule{Frequency: Daily, Count: math.MaxUint64}.Validate()
+	assert.EqualError(t, err, "COUNT must not be more than 2147483647")
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+
+	// UNTIL before DTSTART is only rejected when asked for, and has no
+	// occurrences either way.
+	rrule := RRule{Frequency: Daily, Dtstart: now, Until: now.Add(-time.Second)}
+	assert.NoError(t, rrule.Validate())
+	assert.Empty(t, All(rrule.Iterator(), 0))
+
+	err = rrule.ValidateWith(ValidationOptions{UntilBeforeDtstart: true})
+	assert.EqualError(t, err, "UNTIL must not be before DTSTART")
+	assert.True(t, errors.Is(err, ErrNoOccurrences))
+
+	rrule.Until = time.Date(2018, time.August, 25, 9, 8, 8, 0, time.UTC)
+	rrule.UntilFloating = true
+	assert.NoError(t, rrule.ValidateWith(ValidationOptions{UntilBeforeDtstart: true}))
+	assert.NoError(t, RRule{Frequency: Daily, Until: now}.ValidateWith(ValidationOptions{UntilBeforeDtstart: true}))
+
+	err = RRule{Frequency: Daily, Interval: -1, Count: 1, Until: now}.ValidateWith(ValidationOptions{All: true})
+	assert.Len(t, err, 2)
+}
+
+func TestNowDtstart(t *testing.T) {
+	defer func(prev func() time.Time) { Now = prev }(Now)
+	Now = func() time.Time { return now }
+
+	dates := All(RRule{Frequency: Daily, Count: 2}.Iterator(), 0)
+	assert.Equal(t, []string{"2018-08-25T09:08:07Z", "2018-08-26T09:08:07Z"}, rfcAll(dates))
+}
+
+func TestUntil(t *testing.T) {
+	// an absolute UNTIL is the same instant in any location.
+	rrule := RRule{
+		Frequency: Daily,
+		Dtstart:   time.Date(2018, time.January, 1, 9, 0, 0, 0, NewYork()),
+		Until:     time.Date(2018, time.January, 3, 14, 0, 0, 0, time.UTC),
+	}
+	assert.Len(t, All(rrule.Iterator(), 0), 3)
+	_, ok := rrule.At(2)
+	assert.True(t, ok)
+
+	// a floating UNTIL is observed in the location of Dtstart.
+	rrule.Until = time.Date(2018, time.January, 3, 9, 0, 0, 0, time.UTC)
+	rrule.UntilFloating = true
+	assert.Len(t, All(rrule.Iterator(), 0), 3)
+	_, ok = rrule.At(2)
+	assert.True(t, ok)
+
+	rrule.UntilExclusive = true
+	assert.Equal(t, []string{
+		"2018-01-01T09:00:00-05:00",
+		"2018-01-02T09:00:00-05:00",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+	_, ok = rrule.At(2)
+	assert.False(t, ok)
+
+	b, err := rrule.ToProto()
+	require.NoError(t, err)
+	decoded, err := FromProto(b)
+	require.NoError(t, err)
+	assert.True(t, decoded.UntilExclusive)
+
+	rrule.Until = rrule.Until.Add(time.Second)
+	assert.Len(t, All(rrule.Iterator(), 0), 3)
+}
+
+func TestLeapSecond(t *testing.T) {
+	rrule, err := ParseRRule("FREQ=MINUTELY;COUNT=3;BYSECOND=30,60")
+	require.NoError(t, err)
+	rrule.Dtstart = time.Date(2016, time.December, 31, 23, 58, 0, 0, time.UTC)
+	assert.Equal(t, []string{
+		"2016-12-31T23:58:30Z",
+		"2016-12-31T23:59:30Z",
+		"2017-01-01T00:00:30Z",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule.LeapSecond = ClampLeapSecond
+	assert.Equal(t, []string{
+		"2016-12-31T23:58:30Z",
+		"2016-12-31T23:58:59Z",
+		"2016-12-31T23:59:30Z",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	secondly := RRule{Frequency: Secondly, Count: 2, BySeconds: []int{60}, Dtstart: rrule.Dtstart}
+	assert.Empty(t, All(secondly.Iterator(), 0))
+
+	minutely := MustRRule("FREQ=MINUTELY;BYSECOND=60;COUNT=3")
+	minutely.Dtstart = rrule.Dtstart
+	assert.Empty(t, All(Recurrence{Dtstart: minutely.Dtstart, RRules: []RRule{minutely}}.Iterator(), 0))
+	assert.Len(t, All(Recurrence{Dtstart: minutely.Dtstart, RRules: []RRule{{Frequency: Daily}}, ExRules: []RRule{minutely}}.Iterator(), 2), 2)
+
+	secondly.LeapSecond = ClampLeapSecond
+	assert.Equal(t, []string{
+		"2016-12-31T23:58:59Z",
+		"2016-12-31T23:59:59Z",
+	}, rfcAll(All(secondly.Iterator(), 0)))
+
+	b, err := json.Marshal(secondly)
+	require.NoError(t, err)
+	var decoded RRule
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, ClampLeapSecond, decoded.LeapSecond)
+
+	b, err = secondly.ToProto()
+	require.NoError(t, err)
+	decoded, err = FromProto(b)
+	require.NoError(t, err)
+	assert.Equal(t, ClampLeapSecond, decoded.LeapSecond)
+}
+
+func TestByMonthSkipAhead(t *testing.T) {
+	for _, tc := range []struct {
+		RRule RRule
+		Dates []string
+	}{
+		{
+			RRule: RRule{Frequency: Daily, Interval: 3, Count: 3, ByMonths: []time.Month{time.December}},
+			Dates: []string{"2018-12-02T09:08:07Z", "2018-12-05T09:08:07Z", "2018-12-08T09:08:07Z"},
+		},
+		{
+			RRule: RRule{Frequency: Weekly, Interval: 2, Count: 3, ByMonths: []time.Month{time.February, time.December}},
+			Dates: []string{"2018-12-01T09:08:07Z", "2018-12-15T09:08:07Z", "2018-12-29T09:08:07Z"},
+		},
+		{
+			RRule: RRule{Frequency: Hourly, Interval: 5, Count: 2, ByMonths: []time.Month{time.January}},
+			Dates: []string{"2019-01-01T03:08:07Z", "2019-01-01T08:08:07Z"},
+		},
+		{
+			RRule: RRule{Frequency: Minutely, Interval: 7, Count: 2, ByMonths: []time.Month{time.January}},
+			Dates: []string{"2019-01-01T00:01:07Z", "2019-01-01T00:08:07Z"},
+		},
+		{
+			RRule: RRule{Frequency: Secondly, Count: 3, BySeconds: []int{0, 30}, ByMonths: []time.Month{time.March}},
+			Dates: []string{"2019-03-01T00:00:00Z", "2019-03-01T00:00:30Z", "2019-03-01T00:01:00Z"},
+		},
+	} {
+		tc.RRule.Dtstart = now
+		assert.Equal(t, tc.Dates, rfcAll(All(tc.RRule.Iterator(), 0)), tc.RRule.String())
+	}
+}
+
+func TestClockGaps(t *testing.T) {
+	// clocks in New York go back from 02:00 to 01:00 on 2018-11-04, so 01:00
+	// happens twice.
+	rrule := RRule{Frequency: Hourly, Count: 4, ByHours: []int{1, 3}, Dtstart: time.Date(2018, time.November, 3, 2, 0, 0, 0, NewYork())}
+	assert.Equal(t, []string{
+		"2018-11-03T03:00:00-04:00",
+		"2018-11-04T01:00:00-04:00",
+		"2018-11-04T01:00:00-05:00",
+		"2018-11-04T03:00:00-05:00",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+
+	rrule = RRule{Frequency: Minutely, Count: 3, ByMinutes: []int{59, 15}, Dtstart: time.Date(2018, time.March, 11, 1, 30, 0, 0, NewYork())}
+	assert.Equal(t, []string{
+		"2018-03-11T01:59:00-05:00",
+		"2018-03-11T03:15:00-04:00",
+		"2018-03-11T03:59:00-04:00",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+}
+
+func TestDefaultWeekStart(t *testing.T) {
+	defer func(wd time.Weekday) { DefaultWeekStart = wd }(DefaultWeekStart)
+
+	// a Sunday.
+	dtstart := time.Date(2019, time.June, 2, 9, 0, 0, 0, time.UTC)
+	rrule := MustRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO;COUNT=4")
+	rrule.Dtstart = dtstart
+	assert.Equal(t, []string{"2019-06-02T09:00:00Z", "2019-06-10T09:00:00Z", "2019-06-16T09:00:00Z", "2019-06-24T09:00:00Z"}, rfcAll(All(rrule.Iterator(), 0)))
+
+	DefaultWeekStart = time.Sunday
+	sunday := []string{"2019-06-02T09:00:00Z", "2019-06-03T09:00:00Z", "2019-06-16T09:00:00Z", "2019-06-17T09:00:00Z"}
+	assert.Equal(t, sunday, rfcAll(All(rrule.Iterator(), 0)))
+
+	// an explicit WKST is kept, whether it's the RFC 5545 default or the
+	// package's, as other implementations don't share the package's.
+	monday := MustRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO;COUNT=4;WKST=MO")
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=4;INTERVAL=2;BYDAY=SU,MO;WKST=MO", monday.Normalize().String())
+	sundays := MustRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO;COUNT=4;WKST=SU")
+	sundays.Dtstart = dtstart
+	assert.Equal(t, sunday, rfcAll(All(sundays.Normalize().Iterator(), 0)))
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=4;INTERVAL=2;BYDAY=SU,MO;WKST=SU", sundays.Normalize().String())
+
+	rfc2445, err := ParseRRuleRFC2445("FREQ=WEEKLY;INTERVAL=2;WKST=MO")
+	require.NoError(t, err)
+	assert.Equal(t, WeekStartOn(time.Monday), rfc2445.WeekStart)
+	rfc2445, err = ParseRRuleRFC2445("FREQ=WEEKLY;INTERVAL=2;WKST=SU")
+	require.NoError(t, err)
+	assert.Equal(t, WeekStartOn(time.Sunday), rfc2445.WeekStart)
+
+	pattern := Pattern{Kind: WeeklyPattern, Interval: 2, Weekdays: []time.Weekday{time.Monday}, FirstDayOfWeek: time.Sunday, Start: dtstart}
+	fromPattern, err := pattern.RRule()
+	require.NoError(t, err)
+	assert.Equal(t, WeekStartOn(time.Sunday), fromPattern.WeekStart)
+}
@@ -1,7 +1,242 @@
 package rrule
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RScale is a calendar scale, as defined by RFC 7529, in which a pattern's
+// months and days are counted.
+//
+// As RFC 7529 requires, UNTIL is always a Gregorian date or time, whatever the
+// calendar scale. Date and Time convert between it and dates in the calendar
+// scale.
 type RScale int
 
 const (
+	// Gregorian is the default calendar scale.
 	Gregorian RScale = iota
+
+	// IslamicCivil is the tabular Islamic (Hijri) calendar with the civil
+	// epoch. It is named ISLAMIC-CIVIL.
+	IslamicCivil
+
+	// Chinese is the Chinese lunisolar calendar, which has leap months. Only
+	// the years 1900 through 2100 are supported.
+	Chinese
+
+	// Buddhist is the Thai solar calendar. Its months and days are those of
+	// the Gregorian calendar, but its years are numbered from 543 BCE.
+	Buddhist
+
+	// Japanese is the Japanese calendar. Its months and days are those of the
+	// Gregorian calendar, but its years are numbered within imperial eras.
+	Japanese
+
+	// Ethiopic is the Ethiopian calendar, with twelve months of 30 days and a
+	// thirteenth month of 5 or 6 days. Its years are counted in the Amete
+	// Mihret era.
+	Ethiopic
 )
+
+// String returns the RSCALE name of the calendar scale.
+func (rs RScale) String() string {
+	switch rs {
+	case Gregorian:
+		return "GREGORIAN"
+	case IslamicCivil:
+		return "ISLAMIC-CIVIL"
+	case Chinese:
+		return "CHINESE"
+	case Buddhist:
+		return "BUDDHIST"
+	case Japanese:
+		return "JAPANESE"
+	case Ethiopic:
+		return "ETHIOPIC"
+	}
+	return ""
+}
+
+// CalendarDate is a date in a calendar scale.
+type CalendarDate struct {
+	// Era names the era of Year in calendars that have more than one. It is
+	// only used by the Japanese calendar.
+	Era string `json:"era,omitempty"`
+
+	Year  int `json:"year"`
+	Month int `json:"month"`
+
+	// Leap is set for leap months, which share their number with the month
+	// before them.
+	Leap bool `json:"leap,omitempty"`
+
+	Day int `json:"day"`
+}
+
+// String returns the date as year-month-day, with an L after leap months and
+// the era, if any, before the year.
+func (d CalendarDate) String() string {
+	leap := ""
+	if d.Leap {
+		leap = "L"
+	}
+
+	era := ""
+	if d.Era != "" {
+		era = d.Era + " "
+	}
+
+	return fmt.Sprintf("%s%04d-%02d%s-%02d", era, d.Year, d.Month, leap, d.Day)
+}
+
+// Date returns the date of t, as observed in t's location, in the calendar
+// scale. Chinese years are numbered by the Gregorian year in which they begin,
+// and dates outside the supported years are zero. Japanese years before the
+// Meiji era are numbered as Gregorian years.
+func (rs RScale) Date(t time.Time) CalendarDate {
+	y, m, d := t.Date()
+
+	switch rs {
+	case Buddhist:
+		return CalendarDate{Year: y + 543, Month: int(m), Day: d}
+	case Japanese:
+		era, year := japaneseYear(t)
+		return CalendarDate{Era: era, Year: year, Month: int(m), Day: d}
+	}
+
+	if cal := rs.calendar(); cal != nil {
+		date := cal.date(julianDay(t))
+		return CalendarDate{Year: date.year, Month: date.month.number, Leap: date.month.leap, Day: date.day}
+	}
+
+	return CalendarDate{Year: y, Month: int(m), Day: d}
+}
+
+// Time returns the time at the given clock time in loc on date d of the
+// calendar scale, or an error if the calendar has no such date.
+func (rs RScale) Time(d CalendarDate, hour, min, sec int, loc *time.Location) (time.Time, error) {
+	if cal := rs.calendar(); cal != nil {
+		month := calendarMonth{number: d.Month, leap: d.Leap}
+		if monthIndex(cal, d.Year, month) < 0 {
+			return time.Time{}, fmt.Errorf("%s has no month %s", rs, d)
+		}
+		if d.Day < 1 || d.Day > cal.monthLength(d.Year, month) {
+			return time.Time{}, fmt.Errorf("%s has no date %s", rs, d)
+		}
+
+		clock := time.Date(2000, time.January, 1, hour, min, sec, 0, loc)
+		return fromJulianDay(cal.monthStart(d.Year, month)+d.Day-1, clock), nil
+	}
+
+	year := d.Year
+	switch rs {
+	case Buddhist:
+		year -= 543
+	case Japanese:
+		var err error
+		year, err = japaneseToGregorianYear(d.Era, d.Year)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	t := time.Date(year, time.Month(d.Month), d.Day, hour, min, sec, 0, loc)
+	if d.Leap || t.Month() != time.Month(d.Month) || t.Day() != d.Day {
+		return time.Time{}, fmt.Errorf("%s has no date %s", rs, d)
+	}
+
+	// Japanese years must fall within their era.
+	if got := rs.Date(t); got.Year != d.Year || !strings.EqualFold(got.Era, d.Era) {
+		return time.Time{}, fmt.Errorf("%s has no date %s", rs, d)
+	}
+	return t, nil
+}
+
+// Year returns the year of t in the calendar scale, along with the name of its
+// era for calendars that have more than one, as in Date.
+func (rs RScale) Year(t time.Time) (era string, year int) {
+	d := rs.Date(t)
+	return d.Era, d.Year
+}
+
+// calendar returns the calendar that counts months and days in rs, or nil
+// for calendars whose months and days are those of the Gregorian calendar.
+func (rs RScale) calendar() calendar {
+	switch rs {
+	case IslamicCivil:
+		return islamicCivil{}
+	case Chinese:
+		return chinese{}
+	case Ethiopic:
+		return ethiopic{}
+	}
+	return nil
+}
+
+// withoutLeapMonths returns the pattern with its leap months replaced as SKIP
+// decides, for calendar scales like Gregorian that don't have leap months.
+func (rrule RRule) withoutLeapMonths() RRule {
+	months := append([]time.Month(nil), rrule.ByMonths...)
+	for _, m := range rrule.ByLeapMonths {
+		switch rrule.InvalidBehavior {
+		case PrevInvalid:
+			months = append(months, time.Month(m))
+		case NextInvalid:
+			if m < 12 {
+				months = append(months, time.Month(m+1))
+			}
+		}
+	}
+
+	rrule.ByMonths = months
+	rrule.ByLeapMonths = nil
+	return rrule
+}
+
+func parseRScale(str string) (RScale, error) {
+	switch strings.ToLower(str) {
+	case "gregorian", "gregory":
+		return Gregorian, nil
+	case "islamic-civil":
+		return IslamicCivil, nil
+	case "chinese":
+		return Chinese, nil
+	case "buddhist":
+		return Buddhist, nil
+	case "japanese":
+		return Japanese, nil
+	case "ethiopic":
+		return Ethiopic, nil
+	default:
+		return Gregorian, kindErrorf(ErrUnsupportedRScale, "invalid rscale %q", str)
+	}
+}
+
+// validateRScale checks that the pattern only uses rule parts that are
+// supported in its calendar scale.
+func (rrule RRule) validateRScale() error {
+	if rrule.RScale.String() == "" {
+		return kindErrorf(ErrUnsupportedRScale, "unknown RSCALE %d", rrule.RScale)
+	}
+	if rrule.RScale.calendar() == nil {
+		return nil
+	}
+
+	switch rrule.Frequency {
+	case Yearly, Monthly:
+		if len(rrule.ByWeekNumbers) > 0 || len(rrule.ByYearDays) > 0 {
+			return kindErrorf(ErrUnsupportedRScale, "BYWEEKNO and BYYEARDAY are not supported with RSCALE=%s", rrule.RScale)
+		}
+		if rrule.Frequency == Yearly && len(rrule.ByWeekdays) > 0 && len(rrule.ByMonths) == 0 && len(rrule.ByLeapMonths) == 0 {
+			return kindErrorf(ErrUnsupportedRScale, "YEARLY rules with BYDAY must also include BYMONTH with RSCALE=%s", rrule.RScale)
+		}
+	default:
+		if len(rrule.ByMonths) > 0 || len(rrule.ByLeapMonths) > 0 || len(rrule.ByMonthDays) > 0 || len(rrule.ByWeekNumbers) > 0 || len(rrule.ByYearDays) > 0 {
+			return kindErrorf(ErrUnsupportedRScale, "%s rules may not include BYMONTH, BYMONTHDAY, BYWEEKNO or BYYEARDAY with RSCALE=%s", rrule.Frequency, rrule.RScale)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package rrule
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Value stores the pattern as its RFC 5545 representation, as String does.
+// Dtstart is not included.
+func (rrule RRule) Value() (driver.Value, error) {
+	return rrule.String(), nil
+}
+
+// Scan parses a pattern stored by Value. To allow NULL, scan into a **RRule.
+func (rrule *RRule) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case string:
+		return rrule.UnmarshalText([]byte(src))
+	case []byte:
+		return rrule.UnmarshalText(src)
+	case nil:
+		return fmt.Errorf("cannot scan NULL into RRule")
+	default:
+		return fmt.Errorf("cannot scan %T into RRule", src)
+	}
+}
@@ -0,0 +1,33 @@
+package rrule
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRRuleSQL(t *testing.T) {
+	rrule := RRule{Frequency: Daily, Count: 2}
+
+	v, err := rrule.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;COUNT=2", v)
+
+	var scanned RRule
+	require.NoError(t, scanned.Scan("FREQ=DAILY;COUNT=2"))
+	assert.Equal(t, rrule, scanned)
+
+	scanned = RRule{}
+	require.NoError(t, scanned.Scan([]byte("FREQ=DAILY;COUNT=2")))
+	assert.Equal(t, rrule, scanned)
+
+	assert.Error(t, scanned.Scan(nil))
+	assert.Error(t, scanned.Scan(42))
+	assert.Error(t, scanned.Scan("FREQ=NEVER"))
+
+	var _ driver.Valuer = rrule
+	var _ sql.Scanner = &scanned
+}
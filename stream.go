@@ -0,0 +1,144 @@
+package rrule
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"time"
+)
+
+// OccurrenceFormat is a format OccurrenceWriter writes times in. Each time is
+// written in RFC 3339, with fractional seconds if it has any, as
+// time.Time.MarshalJSON writes it.
+type OccurrenceFormat int
+
+const (
+	// JSONArray writes the times as the strings of one JSON array.
+	JSONArray OccurrenceFormat = iota
+
+	// JSONLines writes each time as a JSON string on its own line.
+	JSONLines
+
+	// CSV writes a "time" header, then each time as a record of its own.
+	CSV
+)
+
+// OccurrenceWriter writes times to an io.Writer as they're found, rather than
+// collecting them first, so an expansion of any length can be exported in
+// constant memory. Its writes are buffered, and Close must be called to end
+// the output.
+type OccurrenceWriter struct {
+	w      *bufio.Writer
+	format OccurrenceFormat
+	count  int
+	buf    []byte
+
+	// err is the first error writing to w, after which nothing more is
+	// written.
+	err error
+}
+
+// NewOccurrenceWriter returns an OccurrenceWriter writing to w in format.
+func NewOccurrenceWriter(w io.Writer, format OccurrenceFormat) *OccurrenceWriter {
+	return &OccurrenceWriter{w: bufio.NewWriter(w), format: format}
+}
+
+// Write writes t. It returns the first error writing to the underlying
+// writer, if any.
+func (ow *OccurrenceWriter) Write(t time.Time) error {
+	b := ow.buf[:0]
+	if ow.count == 0 {
+		switch ow.format {
+		case JSONArray:
+			b = append(b, '[')
+		case CSV:
+			b = append(b, "time\n"...)
+		}
+	} else if ow.format == JSONArray {
+		b = append(b, ',')
+	}
+
+	if ow.format == CSV {
+		b = t.AppendFormat(b, time.RFC3339Nano)
+	} else {
+		b = append(b, '"')
+		b = t.AppendFormat(b, time.RFC3339Nano)
+		b = append(b, '"')
+	}
+	if ow.format != JSONArray {
+		b = append(b, '\n')
+	}
+
+	ow.buf = b
+	if err := ow.write(b); err != nil {
+		return err
+	}
+	ow.count++
+	return nil
+}
+
+// Count returns the number of times written.
+func (ow *OccurrenceWriter) Count() int { return ow.count }
+
+// Close ends the output, closing the JSON array or writing the CSV header if
+// no times were written, and flushes it to the underlying writer, which it
+// leaves open.
+func (ow *OccurrenceWriter) Close() error {
+	switch {
+	case ow.format == JSONArray && ow.count == 0:
+		ow.write([]byte("[]\n"))
+	case ow.format == JSONArray:
+		ow.write([]byte("]\n"))
+	case ow.format == CSV && ow.count == 0:
+		ow.write([]byte("time\n"))
+	}
+	return ow.flush()
+}
+
+func (ow *OccurrenceWriter) write(b []byte) error {
+	if ow.err == nil {
+		_, ow.err = ow.w.Write(b)
+	}
+	return ow.err
+}
+
+func (ow *OccurrenceWriter) flush() error {
+	if ow.err == nil {
+		ow.err = ow.w.Flush()
+	}
+	return ow.err
+}
+
+// WriteOccurrences writes the times of it at or after start and before end to
+// w in format, as Expand would return them, and returns how many it wrote. A
+// zero end writes every time until the iterator ends.
+//
+// If ctx is done or the iterator ends early, as one limited by WithBudget can,
+// the times written so far are flushed and the error is returned, without
+// ending the output, so that it can't be mistaken for the whole expansion.
+func WriteOccurrences(ctx context.Context, w io.Writer, format OccurrenceFormat, it Iterator, start, end time.Time) (int, error) {
+	ow := NewOccurrenceWriter(w, format)
+	it.Seek(start)
+	for {
+		if err := ctx.Err(); err != nil {
+			ow.flush()
+			return ow.Count(), err
+		}
+
+		next := it.Next()
+		if next == nil {
+			if err := IteratorErr(it); err != nil {
+				ow.flush()
+				return ow.Count(), err
+			}
+			break
+		}
+		if !end.IsZero() && !next.Before(end) {
+			break
+		}
+		if err := ow.Write(*next); err != nil {
+			return ow.Count(), err
+		}
+	}
+	return ow.Count(), ow.Close()
+}
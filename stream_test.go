@@ -0,0 +1,130 @@
+package rrule
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOccurrences(t *testing.T) {
+	rrule := MustRRule("FREQ=DAILY;BYHOUR=9;BYSECOND=0,30")
+	rrule.Dtstart = now.Truncate(time.Second).In(NewYork())
+	start := time.Date(2018, time.September, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 3)
+	want := rrule.Expand(start, end)
+	require.Len(t, want, 6)
+
+	var buf bytes.Buffer
+	n, err := WriteOccurrences(context.Background(), &buf, JSONArray, rrule.Iterator(), start, end)
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+	var tt []time.Time
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &tt))
+	assert.Equal(t, rfcAll(want), rfcAll(tt))
+
+	buf.Reset()
+	_, err = WriteOccurrences(context.Background(), &buf, JSONLines, rrule.Iterator(), start, end)
+	require.NoError(t, err)
+	tt = nil
+	for lines := bufio.NewScanner(&buf); lines.Scan(); {
+		var t2 time.Time
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &t2))
+		tt = append(tt, t2)
+	}
+	assert.Equal(t, rfcAll(want), rfcAll(tt))
+
+	buf.Reset()
+	_, err = WriteOccurrences(context.Background(), &buf, CSV, rrule.Iterator(), start, end)
+	require.NoError(t, err)
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 7)
+	assert.Equal(t, []string{"time"}, records[0])
+	assert.Equal(t, []string{"2018-09-01T09:08:00-04:00"}, records[1])
+}
+
+func TestWriteOccurrencesEmpty(t *testing.T) {
+	rrule := MustRRule("FREQ=DAILY;COUNT=2")
+	rrule.Dtstart = now.Truncate(time.Second)
+
+	for format, want := range map[OccurrenceFormat]string{JSONArray: "[]\n", JSONLines: "", CSV: "time\n"} {
+		var buf bytes.Buffer
+		n, err := WriteOccurrences(context.Background(), &buf, format, rrule.Iterator(), now.AddDate(1, 0, 0), time.Time{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, want, buf.String(), "format %d", format)
+	}
+
+	var buf bytes.Buffer
+	_, err := WriteOccurrences(context.Background(), &buf, JSONArray, rrule.Iterator(), time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, `["2018-08-25T09:08:07Z","2018-08-26T09:08:07Z"]`+"\n", buf.String())
+}
+
+func TestWriteOccurrencesEarlyEnd(t *testing.T) {
+	rrule := MustRRule("FREQ=SECONDLY")
+	rrule.Dtstart = now.Truncate(time.Second)
+
+	// the output isn't ended, so it can't be mistaken for all the times.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var buf bytes.Buffer
+	n, err := WriteOccurrences(ctx, &buf, JSONArray, rrule.Iterator(), now, time.Time{})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, n)
+	assert.Empty(t, buf.String())
+
+	sparse := MustRRule("FREQ=YEARLY;BYYEARDAY=366")
+	sparse.Dtstart = time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+	it := WithBudget(sparse.Iterator(), Budget{MaxCandidates: 3})
+	n, err = WriteOccurrences(context.Background(), &buf, JSONArray, it, time.Time{}, time.Time{})
+	assert.True(t, errors.Is(err, ErrIterationBudgetExceeded), "%v", err)
+	assert.Equal(t, 0, n)
+	assert.Empty(t, buf.String())
+
+	// writing stops at the first error.
+	w := &failingWriter{limit: 4096}
+	n, err = WriteOccurrences(context.Background(), w, JSONLines, rrule.Iterator(), rrule.Dtstart, time.Time{})
+	assert.Equal(t, errWriteFailed, err)
+	assert.True(t, n > 0)
+	assert.Equal(t, 4096, w.written)
+	assert.True(t, strings.HasPrefix(string(w.buf), `"2018-08-25T09:08:07Z"`))
+}
+
+var errWriteFailed = errors.New("write failed")
+
+// failingWriter fails writes after limit bytes.
+type failingWriter struct {
+	limit, written int
+	buf            []byte
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if w.written+len(b) > w.limit {
+		return 0, errWriteFailed
+	}
+	w.written += len(b)
+	w.buf = append(w.buf, b...)
+	return len(b), nil
+}
+
+func BenchmarkWriteOccurrences(b *testing.B) {
+	rrule := MustRRule("FREQ=HOURLY")
+	rrule.Dtstart = now.Truncate(time.Second)
+	end := now.AddDate(1, 0, 0)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		WriteOccurrences(context.Background(), ioutil.Discard, JSONLines, rrule.Iterator(), now, end)
+	}
+}
@@ -1,140 +1,349 @@
 package rrule
 
 import (
+	"bytes"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// String returns the RFC 5545 representation of the RRule.
+// StringOptions controls how RRule.Format renders a pattern.
+type StringOptions struct {
+	// ExplicitSkip writes SKIP=OMIT, rather than leaving it out as the
+	// default.
+	ExplicitSkip bool
+
+	// OmitGregorianRScale leaves out RSCALE=GREGORIAN, which is otherwise
+	// written alongside SKIP as RFC 7529 requires. Some consumers accept SKIP
+	// but reject RSCALE.
+	OmitGregorianRScale bool
+
+	// Preserve renders a pattern returned by ParseRRulePreserving exactly as
+	// it was parsed, including its part order and letter case, as
+	// long as it hasn't been changed since. The other options, but Dtstart,
+	// don't apply in that case.
+	Preserve bool
+
+	// Order is the order parts are written in.
+	Order PartOrder
+
+	// Dtstart writes the pattern as an RRULE property, preceded by a
+	// DTSTART property on its own line unless Dtstart is zero, as
+	// ContentLine does, but without a final newline:
+	//
+	//	DTSTART:19970902T090000Z
+	//	RRULE:FREQ=DAILY;COUNT=10
+	Dtstart bool
+}
+
+// PartOrder is an order in which Format writes rule parts. Unless they were
+// parsed in another order, X- parts are written after the others, sorted by
+// name, so that equal patterns, such as those returned by Normalize, are
+// always written the same.
+type PartOrder int
+
+const (
+	// CanonicalOrder is the order String writes parts in: FREQ, UNTIL,
+	// COUNT, INTERVAL, BYSECOND, BYMINUTE, BYHOUR, BYDAY, BYWEEKNO,
+	// BYMONTHDAY, BYYEARDAY, BYMONTH, BYSETPOS, WKST, SKIP and RSCALE.
+	CanonicalOrder PartOrder = iota
+
+	// RFCOrder is the order RFC 5545 lists parts in, followed by RSCALE and
+	// SKIP, as RFC 7529 lists them. It differs from CanonicalOrder by
+	// writing BYWEEKNO after BYYEARDAY, and RSCALE before SKIP.
+	RFCOrder
+
+	// ParsedOrder writes the parts of a pattern returned by
+	// ParseRRulePreserving in the order they were parsed, including X-
+	// parts, followed by any parts set since in CanonicalOrder. Unlike
+	// Preserve, the pattern may have been changed, and it is written in
+	// upper case. Other patterns, including those returned by Normalize,
+	// are written in CanonicalOrder.
+	ParsedOrder
+)
+
+// ruleSource records the text a pattern was parsed from, when it differs from
+// how the pattern would be rendered.
+type ruleSource struct {
+	text string
+
+	// canonical is the pattern's rendering when it was parsed, used to tell
+	// whether it has been changed since.
+	canonical string
+}
+
+// String returns the RFC 5545 representation of the RRule. UNTIL is written
+// in UTC, as RFC 5545 requires, unless it is floating. Dtstart is not
+// included; see ContentLine.
 func (rrule RRule) String() string {
-	str := &strings.Builder{}
-	str.WriteString("FREQ=")
-	str.WriteString(rrule.Frequency.String())
+	return rrule.Format(StringOptions{})
+}
 
-	if !rrule.Until.IsZero() {
-		str.WriteString(";UNTIL=")
-		if rrule.UntilFloating {
-			str.WriteString(rrule.Until.Format(rfc5545WithoutOffset))
-		} else {
-			str.WriteString(rrule.Until.Format(rfc5545WithOffset))
+// Format returns the RFC 5545 representation of the RRule, as controlled by
+// opts.
+func (rrule RRule) Format(opts StringOptions) string {
+	if opts.Preserve && !opts.Dtstart && rrule.source != nil && rrule.isSource() {
+		return rrule.source.text
+	}
+
+	// most patterns fit, so rendering them allocates only the string.
+	var buf [128]byte
+	return string(rrule.appendFormat(buf[:0], opts))
+}
+
+// isSource reports whether the pattern is unchanged since it was parsed.
+func (rrule RRule) isSource() bool {
+	var buf [128]byte
+	return string(rrule.appendFormat(buf[:0], StringOptions{})) == rrule.source.canonical
+}
+
+func (rrule RRule) appendFormat(b []byte, opts StringOptions) []byte {
+	if opts.Dtstart {
+		if !rrule.Dtstart.IsZero() {
+			if rrule.AllDay {
+				b = append(b, formatDate("DTSTART", rrule.Dtstart)...)
+			} else {
+				b = append(b, formatTime("DTSTART", rrule.Dtstart, false)...)
+			}
+			b = append(b, '\n')
 		}
+		b = append(b, "RRULE:"...)
 	}
 
-	if rrule.Count != 0 {
-		panicOnWriteErr(str.WriteString(";COUNT="))
-		str.WriteString(strconv.FormatUint(rrule.Count, 10))
+	if opts.Preserve && rrule.source != nil && rrule.isSource() {
+		return append(b, rrule.source.text...)
 	}
 
-	if rrule.Interval != 0 && rrule.Interval != 1 {
-		str.WriteString(";INTERVAL=")
-		str.WriteString(strconv.Itoa(rrule.Interval))
+	switch {
+	case opts.Order == RFCOrder:
+		var buf [128]byte
+		return appendInOrder(b, rrule.appendParts(buf[:0], opts), rfcOrder)
+	case opts.Order == ParsedOrder && rrule.source != nil:
+		var buf [128]byte
+		return appendInOrder(b, rrule.appendParts(buf[:0], opts), rrule.source.text)
 	}
+	return rrule.appendParts(b, opts)
+}
+
+// appendParts appends the parts of the pattern in CanonicalOrder.
+func (rrule RRule) appendParts(b []byte, opts StringOptions) []byte {
+	b = append(b, "FREQ="...)
+	b = append(b, rrule.Frequency.String()...)
 
-	if len(rrule.BySeconds) > 0 {
-		str.WriteString(";BYSECOND=")
-		str.WriteString(intlist(rrule.BySeconds))
+	if !rrule.Until.IsZero() {
+		b = append(b, ";UNTIL="...)
+		if rrule.AllDay {
+			b = rrule.Until.AppendFormat(b, rfc5545Date)
+		} else if rrule.UntilFloating {
+			b = rrule.Until.AppendFormat(b, rfc5545WithoutOffset)
+		} else {
+			b = rrule.Until.UTC().AppendFormat(b, rfc5545WithOffset)
+		}
 	}
 
-	if len(rrule.ByMinutes) > 0 {
-		str.WriteString(";BYMINUTE=")
-		str.WriteString(intlist(rrule.ByMinutes))
+	if rrule.Count != 0 {
+		b = append(b, ";COUNT="...)
+		b = strconv.AppendUint(b, rrule.Count, 10)
 	}
 
-	if len(rrule.ByHours) > 0 {
-		str.WriteString(";BYHOUR=")
-		str.WriteString(intlist(rrule.ByHours))
+	if rrule.Interval != 0 && rrule.Interval != 1 {
+		b = append(b, ";INTERVAL="...)
+		b = strconv.AppendInt(b, int64(rrule.Interval), 10)
 	}
 
+	b = appendIntPart(b, ";BYSECOND=", rrule.BySeconds)
+	b = appendIntPart(b, ";BYMINUTE=", rrule.ByMinutes)
+	b = appendIntPart(b, ";BYHOUR=", rrule.ByHours)
+
 	if len(rrule.ByWeekdays) > 0 {
-		str.WriteString(";BYDAY=")
-		str.WriteString(weekdaylist(rrule.ByWeekdays))
+		b = append(b, ";BYDAY="...)
+		b = appendWeekdayList(b, rrule.ByWeekdays)
 	}
 
-	if len(rrule.ByWeekNumbers) > 0 {
-		str.WriteString(";BYWEEKNO=")
-		str.WriteString(intlist(rrule.ByWeekNumbers))
+	b = appendIntPart(b, ";BYWEEKNO=", rrule.ByWeekNumbers)
+	b = appendIntPart(b, ";BYMONTHDAY=", rrule.ByMonthDays)
+	b = appendIntPart(b, ";BYYEARDAY=", rrule.ByYearDays)
+
+	if len(rrule.ByMonths) > 0 || len(rrule.ByLeapMonths) > 0 {
+		b = append(b, ";BYMONTH="...)
+		b = appendMonthList(b, rrule.ByMonths, rrule.ByLeapMonths)
 	}
 
-	if len(rrule.ByMonthDays) > 0 {
-		str.WriteString(";BYMONTHDAY=")
-		str.WriteString(intlist(rrule.ByMonthDays))
+	b = appendIntPart(b, ";BYSETPOS=", rrule.BySetPos)
+
+	if rrule.WeekStart.IsSet() {
+		b = append(b, ";WKST="...)
+		b = append(b, rrule.WeekStart.String()...)
 	}
 
-	if len(rrule.ByYearDays) > 0 {
-		str.WriteString(";BYYEARDAY=")
-		str.WriteString(intlist(rrule.ByYearDays))
+	var wroteSkip bool
+	if rrule.InvalidBehavior != OmitInvalid || opts.ExplicitSkip {
+		b = append(b, ";SKIP="...)
+		b = append(b, skipString(rrule.InvalidBehavior)...)
+		wroteSkip = true
 	}
 
-	if len(rrule.ByMonths) > 0 {
-		str.WriteString(";BYMONTH=")
-		str.WriteString(monthlist(rrule.ByMonths))
+	if (wroteSkip && !opts.OmitGregorianRScale) || rrule.RScale != Gregorian {
+		b = append(b, ";RSCALE="...)
+		b = append(b, rrule.RScale.String()...)
 	}
 
-	if len(rrule.BySetPos) > 0 {
-		str.WriteString(";BYSETPOS=")
-		str.WriteString(intlist(rrule.BySetPos))
+	if len(rrule.Extensions) > 0 {
+		names := make([]string, 0, len(rrule.Extensions))
+		for name := range rrule.Extensions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			b = append(b, ';')
+			b = append(b, name...)
+			b = append(b, '=')
+			b = append(b, rrule.Extensions[name]...)
+		}
 	}
 
-	if rrule.WeekStart != nil {
-		str.WriteString(";WKST=")
-		str.WriteString(weekdayString(*rrule.WeekStart))
+	return b
+}
+
+// rfcOrder lists the rule parts in the order the grammars of RFC 5545 and RFC
+// 7529 list them, as a pattern lists its parts.
+const rfcOrder = "FREQ;UNTIL;COUNT;INTERVAL;BYSECOND;BYMINUTE;BYHOUR;BYDAY;BYMONTHDAY;BYYEARDAY;BYWEEKNO;BYMONTH;BYSETPOS;WKST;RSCALE;SKIP"
+
+// appendInOrder appends the parts of canonical, a pattern as appendParts
+// writes it, with those named in order first, in that order. order is a list
+// of parts like a pattern, separated by semicolons, of which only the names
+// are used, in any case. The other parts follow in their canonical order.
+func appendInOrder(b, canonical []byte, order string) []byte {
+	var buf [24][]byte
+	parts := buf[:0]
+	for rest := canonical; len(rest) > 0; {
+		part := rest
+		if idx := bytes.IndexByte(rest, ';'); idx >= 0 {
+			part, rest = rest[:idx], rest[idx+1:]
+		} else {
+			rest = nil
+		}
+		parts = append(parts, part)
 	}
 
-	var wroteSkip bool
-	if rrule.InvalidBehavior != OmitInvalid {
-		str.WriteString(";SKIP=")
-		str.WriteString(skipString(rrule.InvalidBehavior))
-		wroteSkip = true
+	start := len(b)
+	for rest := order; rest != ""; {
+		name := rest
+		if idx := strings.IndexByte(rest, ';'); idx >= 0 {
+			name, rest = rest[:idx], rest[idx+1:]
+		} else {
+			rest = ""
+		}
+		if idx := strings.IndexByte(name, '='); idx >= 0 {
+			name = name[:idx]
+		}
+		name = strings.TrimSpace(name)
+
+		for i, part := range parts {
+			if part != nil && len(part) > len(name) && part[len(name)] == '=' && strings.EqualFold(string(part[:len(name)]), name) {
+				b = appendPartTo(b, start, part)
+				parts[i] = nil
+				break
+			}
+		}
+	}
+	for _, part := range parts {
+		if part != nil {
+			b = appendPartTo(b, start, part)
+		}
 	}
+	return b
+}
 
-	if wroteSkip {
-		str.WriteString(";RSCALE=GREGORIAN")
+// appendPartTo appends part to a pattern begun at start in b, after a
+// semicolon unless it's the first.
+func appendPartTo(b []byte, start int, part []byte) []byte {
+	if len(b) > start {
+		b = append(b, ';')
 	}
+	return append(b, part...)
+}
 
-	return str.String()
+// ContentLine returns the pattern as an RRULE property, preceded by a DTSTART
+// property with the TZID of Dtstart's location, or in UTC, unless Dtstart is
+// zero. Each property ends with a newline. It is the inverse of
+// ParseContentLine:
+//
+//	DTSTART;TZID=America/New_York:19970902T090000
+//	RRULE:FREQ=DAILY;COUNT=10
+func (rrule RRule) ContentLine() string {
+	return rrule.Format(StringOptions{Dtstart: true}) + "\n"
 }
 
 func intlist(ints []int) string {
-	b := &strings.Builder{}
+	return string(appendIntList(nil, ints))
+}
+
+// appendIntPart appends a part with a list of ints, like ;BYHOUR=9,17, unless
+// the list is empty.
+func appendIntPart(b []byte, prefix string, ints []int) []byte {
+	if len(ints) == 0 {
+		return b
+	}
+	return appendIntList(append(b, prefix...), ints)
+}
+
+func appendIntList(b []byte, ints []int) []byte {
 	for i, n := range ints {
 		if i != 0 {
-			b.WriteString(",")
+			b = append(b, ',')
 		}
-		b.WriteString(strconv.Itoa(n))
+		b = strconv.AppendInt(b, int64(n), 10)
 	}
-	return b.String()
+	return b
 }
 
 func weekdaylist(wds []QualifiedWeekday) string {
-	b := &strings.Builder{}
+	return string(appendWeekdayList(nil, wds))
+}
+
+func appendWeekdayList(b []byte, wds []QualifiedWeekday) []byte {
 	for i, wd := range wds {
 		if i != 0 {
-			b.WriteString(",")
+			b = append(b, ',')
 		}
-		b.WriteString(qualifiedWeekdayString(wd))
+		b = appendQualifiedWeekday(b, wd)
 	}
-	return b.String()
+	return b
 }
 
-func monthlist(months []time.Month) string {
-	b := &strings.Builder{}
+func monthlist(months []time.Month, leapMonths []int) string {
+	return string(appendMonthList(nil, months, leapMonths))
+}
+
+func appendMonthList(b []byte, months []time.Month, leapMonths []int) []byte {
 	for i, n := range months {
 		if i != 0 {
-			b.WriteString(",")
+			b = append(b, ',')
 		}
-		b.WriteString(strconv.Itoa(int(n)))
+		b = strconv.AppendInt(b, int64(n), 10)
 	}
-	return b.String()
+	for i, n := range leapMonths {
+		if i != 0 || len(months) != 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendInt(b, int64(n), 10)
+		b = append(b, 'L')
+	}
+	return b
 }
 
 func qualifiedWeekdayString(wd QualifiedWeekday) string {
-	str := ""
+	return string(appendQualifiedWeekday(nil, wd))
+}
+
+func appendQualifiedWeekday(b []byte, wd QualifiedWeekday) []byte {
 	if wd.N != 0 {
-		str += strconv.Itoa(wd.N)
+		b = strconv.AppendInt(b, int64(wd.N), 10)
 	}
-	str += weekdayString(wd.WD)
-	return str
+	return append(b, weekdayString(wd.WD)...)
 }
 
 func weekdayString(wd time.Weekday) string {
@@ -169,9 +378,3 @@ func skipString(skip InvalidBehavior) string {
 	}
 	return ""
 }
-
-func panicOnWriteErr(n int, err error) {
-	if err != nil {
-		panic(err)
-	}
-}
@@ -0,0 +1,171 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	rrule := RRule{Frequency: Monthly, ByMonthDays: []int{31}}
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=31", rrule.Format(StringOptions{}))
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=31;SKIP=OMIT;RSCALE=GREGORIAN", rrule.Format(StringOptions{ExplicitSkip: true}))
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=31;SKIP=OMIT", rrule.Format(StringOptions{ExplicitSkip: true, OmitGregorianRScale: true}))
+
+	rrule.InvalidBehavior = PrevInvalid
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=31;SKIP=BACKWARD;RSCALE=GREGORIAN", rrule.String())
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=31;SKIP=BACKWARD", rrule.Format(StringOptions{OmitGregorianRScale: true}))
+
+	rrule.RScale = IslamicCivil
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=31;SKIP=BACKWARD;RSCALE=ISLAMIC-CIVIL", rrule.Format(StringOptions{OmitGregorianRScale: true}))
+}
+
+func TestFormatPreserve(t *testing.T) {
+	src := "RSCALE=GREGORIAN;FREQ=monthly;BYMONTHDAY=31;SKIP=OMIT"
+
+	rrule, err := ParseRRulePreserving(src)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=31", rrule.String())
+	assert.Equal(t, src, rrule.Format(StringOptions{Preserve: true}))
+
+	copied := rrule
+	assert.Equal(t, src, copied.Format(StringOptions{Preserve: true}))
+
+	copied.Count = 3
+	assert.Equal(t, "FREQ=MONTHLY;COUNT=3;BYMONTHDAY=31", copied.Format(StringOptions{Preserve: true}))
+
+	rrule, err = ParseRRulePreserving("RRULE:FREQ=DAILY;COUNT=2")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;COUNT=2", rrule.Format(StringOptions{Preserve: true}))
+	assert.Equal(t, RRule{Frequency: Daily, Count: 2}, rrule)
+
+	// other parse functions don't keep the text, so their patterns compare
+	// equal however they were written.
+	rrule, err = ParseRRule(src)
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=31", rrule.Format(StringOptions{Preserve: true}))
+	assert.Equal(t, RRule{Frequency: Monthly, ByMonthDays: []int{31}}, rrule)
+	assert.Equal(t, RRule{Frequency: Daily}, MustRRule("rrule:freq=daily"))
+}
+
+func TestContentLine(t *testing.T) {
+	rrule := RRule{
+		Frequency: Daily,
+		Dtstart:   time.Date(1997, time.September, 2, 9, 0, 0, 0, NewYork()),
+		Until:     time.Date(1997, time.September, 4, 9, 0, 0, 0, NewYork()),
+	}
+
+	// UNTIL is in UTC, while DTSTART keeps its zone.
+	line := rrule.ContentLine()
+	assert.Equal(t, "DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;UNTIL=19970904T130000Z\n", line)
+
+	parsed, err := ParseContentLine(line, nil)
+	require.NoError(t, err)
+	assert.Equal(t, rrule.Dtstart, parsed.Dtstart)
+	assert.True(t, rrule.Until.Equal(parsed.Until))
+	assert.Equal(t, rfcAll(All(rrule.Iterator(), 0)), rfcAll(All(parsed.Iterator(), 0)))
+
+	rrule.Dtstart = rrule.Dtstart.UTC()
+	assert.Equal(t, "DTSTART:19970902T130000Z\nRRULE:FREQ=DAILY;UNTIL=19970904T130000Z\n", rrule.ContentLine())
+
+	assert.Equal(t, "RRULE:FREQ=WEEKLY\n", RRule{Frequency: Weekly}.ContentLine())
+}
+
+func TestFormatOrder(t *testing.T) {
+	rrule := RRule{Frequency: Yearly, ByWeekNumbers: []int{1}, ByYearDays: []int{1}, InvalidBehavior: NextInvalid, Extensions: map[string]string{"X-B": "2", "X-A": "1"}}
+	assert.Equal(t, "FREQ=YEARLY;BYWEEKNO=1;BYYEARDAY=1;SKIP=FORWARD;RSCALE=GREGORIAN;X-A=1;X-B=2", rrule.String())
+	assert.Equal(t, "FREQ=YEARLY;BYYEARDAY=1;BYWEEKNO=1;RSCALE=GREGORIAN;SKIP=FORWARD;X-A=1;X-B=2", rrule.Format(StringOptions{Order: RFCOrder}))
+
+	// patterns that weren't parsed have no parsed order.
+	assert.Equal(t, rrule.String(), rrule.Format(StringOptions{Order: ParsedOrder}))
+
+	parsed, err := ParseRRulePreserving("count=3; x-b=Two ;byday=mo;FREQ=weekly")
+	require.NoError(t, err)
+	assert.Equal(t, "COUNT=3;X-B=Two;BYDAY=MO;FREQ=WEEKLY", parsed.Format(StringOptions{Order: ParsedOrder}))
+	parsed.Count = 5
+	parsed.ByHours = []int{9}
+	parsed.Extensions["X-A"] = "One"
+	assert.Equal(t, "COUNT=5;X-B=Two;BYDAY=MO;FREQ=WEEKLY;BYHOUR=9;X-A=One", parsed.Format(StringOptions{Order: ParsedOrder}))
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=5;BYHOUR=9;BYDAY=MO;X-A=One;X-B=Two", parsed.Format(StringOptions{Order: RFCOrder}))
+}
+
+func TestFormatNormalized(t *testing.T) {
+	// equal patterns, however they're written, are written the same once
+	// normalized, in any order.
+	var want [3]string
+	for i, src := range []string{
+		"FREQ=WEEKLY;INTERVAL=1;BYDAY=FR,MO,MO;WKST=MO;X-ID=7;COUNT=4",
+		"x-id=7;count=4;byday=mo,fr;freq=weekly",
+		"FREQ=WEEKLY;COUNT=4;BYDAY=MO,FR;X-ID=7",
+	} {
+		rrule, err := ParseRRule(src)
+		require.NoError(t, err)
+		normalized := rrule.Normalize()
+		for j, order := range []PartOrder{CanonicalOrder, RFCOrder, ParsedOrder} {
+			str := normalized.Format(StringOptions{Order: order})
+			if i == 0 {
+				want[j] = str
+			}
+			assert.Equal(t, want[j], str, src)
+
+			reparsed, err := ParseRRule(str)
+			require.NoError(t, err)
+			assert.Equal(t, str, reparsed.Normalize().Format(StringOptions{Order: order}))
+		}
+	}
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=4;BYDAY=MO,FR;X-ID=7", want[0])
+}
+
+func TestFormatDtstart(t *testing.T) {
+	rrule := RRule{Frequency: Daily, Count: 2, Dtstart: time.Date(1997, time.September, 2, 9, 0, 0, 0, NewYork())}
+	str := rrule.Format(StringOptions{Dtstart: true})
+	assert.Equal(t, "DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;COUNT=2", str)
+	parsed, err := ParseContentLine(str, nil)
+	require.NoError(t, err)
+	assert.Equal(t, rrule.Dtstart, parsed.Dtstart)
+
+	rrule.AllDay = true
+	rrule.Dtstart = time.Date(1997, time.September, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "DTSTART;VALUE=DATE:19970902\nRRULE:FREQ=DAILY;COUNT=2", rrule.Format(StringOptions{Dtstart: true}))
+
+	assert.Equal(t, "RRULE:FREQ=WEEKLY", RRule{Frequency: Weekly}.Format(StringOptions{Dtstart: true}))
+
+	parsed, err = ParseRRulePreserving("count=2;freq=daily")
+	require.NoError(t, err)
+	parsed.Dtstart = time.Date(1997, time.September, 2, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, "DTSTART:19970902T090000Z\nRRULE:count=2;freq=daily", parsed.Format(StringOptions{Dtstart: true, Preserve: true}))
+}
+
+var benchmarkPatterns = []string{
+	"FREQ=DAILY",
+	"FREQ=WEEKLY;COUNT=10;BYDAY=MO,WE,FR",
+	"FREQ=MONTHLY;UNTIL=20201231T235959Z;BYDAY=-1FR;BYHOUR=9,17;BYMINUTE=30",
+	"FREQ=YEARLY;INTERVAL=2;BYDAY=1SU,-1SU;BYMONTH=3,11;WKST=SU;SKIP=BACKWARD;RSCALE=GREGORIAN",
+}
+
+func BenchmarkString(b *testing.B) {
+	for _, str := range benchmarkPatterns {
+		rrule := MustRRule(str)
+		b.Run(str, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = rrule.String()
+			}
+		})
+	}
+}
+
+func BenchmarkAppendText(b *testing.B) {
+	for _, str := range benchmarkPatterns {
+		rrule := MustRRule(str)
+		buf := make([]byte, 0, 128)
+		b.Run(str, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf, _ = rrule.AppendText(buf[:0])
+			}
+		})
+	}
+}
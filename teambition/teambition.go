@@ -0,0 +1,123 @@
+// Package teambition converts patterns to and from the options of
+// github.com/teambition/rrule-go, so that they can be expanded by that package
+// too, or taken from code that uses it. It is kept apart from package rrule so
+// that only users of the conversion depend on that package.
+package teambition
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stephens2424/rrule"
+	rrulego "github.com/teambition/rrule-go"
+)
+
+// weekdays are the weekdays of github.com/teambition/rrule-go, indexed by
+// time.Weekday.
+var weekdays = [...]rrulego.Weekday{
+	rrulego.SU, rrulego.MO, rrulego.TU, rrulego.WE, rrulego.TH, rrulego.FR, rrulego.SA,
+}
+
+// ToROption returns the options of the pattern for
+// github.com/teambition/rrule-go. It returns an error for the parts of the
+// pattern that package can't represent: RSCALE, SKIP, leap months, a floating,
+// exclusive or all day UNTIL, and behaviors other than the defaults around
+// daylight saving transitions, leap seconds and stepping. Extensions are not
+// included.
+func ToROption(r rrule.RRule) (rrulego.ROption, error) {
+	switch {
+	case r.RScale != rrule.Gregorian:
+		return rrulego.ROption{}, fmt.Errorf("RSCALE=%s is not supported by teambition/rrule-go", r.RScale)
+	case r.InvalidBehavior != rrule.OmitInvalid:
+		return rrulego.ROption{}, errors.New("SKIP is not supported by teambition/rrule-go")
+	case len(r.ByLeapMonths) > 0:
+		return rrulego.ROption{}, errors.New("leap months are not supported by teambition/rrule-go")
+	case r.UntilFloating:
+		return rrulego.ROption{}, errors.New("a floating UNTIL is not supported by teambition/rrule-go")
+	case r.UntilExclusive:
+		return rrulego.ROption{}, errors.New("an exclusive UNTIL is not supported by teambition/rrule-go")
+	case r.AllDay:
+		return rrulego.ROption{}, errors.New("all day patterns are not supported by teambition/rrule-go")
+	case r.Nonexistent != rrule.DefaultNonexistent || r.Ambiguous != rrule.DefaultAmbiguous:
+		return rrulego.ROption{}, errors.New("daylight saving behaviors are not supported by teambition/rrule-go")
+	case r.LeapSecond != rrule.SkipLeapSecond:
+		return rrulego.ROption{}, errors.New("leap second behaviors are not supported by teambition/rrule-go")
+	case r.Stepping != rrule.ElapsedStepping:
+		return rrulego.ROption{}, errors.New("stepping behaviors are not supported by teambition/rrule-go")
+	}
+
+	opt := rrulego.ROption{
+		Freq:       rrulego.Frequency(rrule.Yearly - r.Frequency),
+		Dtstart:    r.Dtstart,
+		Interval:   r.Interval,
+		Count:      int(r.Count),
+		Until:      r.Until,
+		Bysetpos:   r.BySetPos,
+		Bymonthday: r.ByMonthDays,
+		Byyearday:  r.ByYearDays,
+		Byweekno:   r.ByWeekNumbers,
+		Byhour:     r.ByHours,
+		Byminute:   r.ByMinutes,
+		Bysecond:   r.BySeconds,
+	}
+
+	wkst, _ := r.WeekStart.Weekday()
+	opt.Wkst = weekdays[wkst]
+	for _, m := range r.ByMonths {
+		opt.Bymonth = append(opt.Bymonth, int(m))
+	}
+	for _, wd := range r.ByWeekdays {
+		opt.Byweekday = append(opt.Byweekday, weekdays[wd.WD].Nth(wd.N))
+	}
+
+	return opt, nil
+}
+
+// FromROption returns the pattern of options for
+// github.com/teambition/rrule-go. It returns an error for BYEASTER, which
+// package rrule doesn't support, and for invalid patterns.
+func FromROption(opt rrulego.ROption) (rrule.RRule, error) {
+	if opt.Freq < rrulego.YEARLY || opt.Freq > rrulego.SECONDLY {
+		return rrule.RRule{}, fmt.Errorf("unknown teambition/rrule-go frequency %d", opt.Freq)
+	}
+	if len(opt.Byeaster) > 0 {
+		return rrule.RRule{}, errors.New("BYEASTER is not supported")
+	}
+	if opt.Count < 0 {
+		return rrule.RRule{}, fmt.Errorf("COUNT %d is negative", opt.Count)
+	}
+
+	r := rrule.RRule{
+		Frequency:     rrule.Yearly - rrule.Frequency(opt.Freq),
+		Dtstart:       opt.Dtstart,
+		Interval:      opt.Interval,
+		Count:         uint64(opt.Count),
+		Until:         opt.Until,
+		BySetPos:      opt.Bysetpos,
+		ByMonthDays:   opt.Bymonthday,
+		ByYearDays:    opt.Byyearday,
+		ByWeekNumbers: opt.Byweekno,
+		ByHours:       opt.Byhour,
+		ByMinutes:     opt.Byminute,
+		BySeconds:     opt.Bysecond,
+	}
+
+	// teambition/rrule-go has no unset Wkst, so it's always kept, as the
+	// default of package rrule may not be Monday.
+	r.WeekStart = rrule.WeekStartOn(weekday(opt.Wkst))
+	for _, m := range opt.Bymonth {
+		r.ByMonths = append(r.ByMonths, time.Month(m))
+	}
+	for _, wd := range opt.Byweekday {
+		r.ByWeekdays = append(r.ByWeekdays, rrule.QualifiedWeekday{N: wd.N(), WD: weekday(wd)})
+	}
+
+	return r, r.Validate()
+}
+
+// weekday converts a teambition/rrule-go weekday, which counts from Monday, to
+// a time.Weekday.
+func weekday(wd rrulego.Weekday) time.Weekday {
+	return time.Weekday((wd.Day() + 1) % 7)
+}
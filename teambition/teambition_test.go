@@ -0,0 +1,65 @@
+package teambition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stephens2424/rrule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rrulego "github.com/teambition/rrule-go"
+)
+
+var now = time.Date(2018, 8, 25, 9, 8, 7, 6, time.UTC)
+
+func TestROption(t *testing.T) {
+	r := rrule.RRule{
+		Frequency:  rrule.Monthly,
+		Dtstart:    now,
+		Interval:   2,
+		Count:      5,
+		ByWeekdays: []rrule.QualifiedWeekday{{N: -1, WD: time.Friday}, {WD: time.Sunday}},
+		ByMonths:   []time.Month{time.January, time.August},
+		ByHours:    []int{9},
+		WeekStart:  rrule.WeekStartOn(time.Sunday),
+	}
+
+	opt, err := ToROption(r)
+	require.NoError(t, err)
+	assert.Equal(t, rrulego.MONTHLY, opt.Freq)
+	assert.Equal(t, rrulego.SU, opt.Wkst)
+	assert.Equal(t, []rrulego.Weekday{rrulego.FR.Nth(-1), rrulego.SU}, opt.Byweekday)
+
+	decoded, err := FromROption(opt)
+	require.NoError(t, err)
+	assert.Equal(t, r, decoded)
+
+	decoded, err = FromROption(rrulego.ROption{Freq: rrulego.WEEKLY, Interval: 2})
+	require.NoError(t, err)
+	assert.Equal(t, rrule.WeekStartOn(time.Monday), decoded.WeekStart)
+
+	_, err = FromROption(rrulego.ROption{Freq: rrulego.YEARLY, Byeaster: []int{0}})
+	assert.Error(t, err)
+	_, err = FromROption(rrulego.ROption{Freq: rrulego.DAILY, Count: 2, Until: now})
+	assert.Error(t, err)
+}
+
+func TestROptionUnsupported(t *testing.T) {
+	// parts that teambition/rrule-go can't represent are errors, rather
+	// than being dropped.
+	for name, r := range map[string]rrule.RRule{
+		"rscale":          {RScale: rrule.Chinese},
+		"skip":            {InvalidBehavior: rrule.NextInvalid},
+		"leap months":     {ByLeapMonths: []int{5}},
+		"floating until":  {Until: now, UntilFloating: true},
+		"exclusive until": {Until: now, UntilExclusive: true},
+		"all day":         {AllDay: true},
+		"nonexistent":     {Nonexistent: rrule.SkipNonexistent},
+		"leap second":     {LeapSecond: rrule.ClampLeapSecond},
+		"stepping":        {Stepping: rrule.WallClockStepping},
+	} {
+		r.Frequency = rrule.Monthly
+		_, err := ToROption(r)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,32 @@
+package rrule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rrulego "github.com/teambition/rrule-go"
+)
+
+func TestTeambitionComparison(t *testing.T) {
+	for _, tc := range cases {
+		if tc.NoTeambitionComparison || tc.NoTest || !tc.Terminal {
+			continue
+		}
+
+		t.Run(tc.Name, func(t *testing.T) {
+			// the pattern goes by its string, so that this package's
+			// tests don't import the teambition package, which imports
+			// this one.
+			opt, err := rrulego.StrToROption(tc.RRule.String())
+			if err != nil {
+				t.Skip(err)
+			}
+			opt.Dtstart = tc.RRule.Dtstart
+
+			other, err := rrulego.NewRRule(*opt)
+			require.NoError(t, err)
+			assert.Equal(t, rfcAll(All(tc.RRule.Iterator(), 0)), rfcAll(other.All()))
+		})
+	}
+}
@@ -3,7 +3,6 @@ package rrule
 import (
 	"errors"
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 )
@@ -11,6 +10,7 @@ import (
 const (
 	rfc5545WithOffset    = "20060102T150405Z0700"
 	rfc5545WithoutOffset = "20060102T150405"
+	rfc5545Date          = "20060102"
 )
 
 // parseTime parses the time. the boolean is true if the time was in "local" (aka "floating")
@@ -41,11 +41,19 @@ func parseTime(str string, defaultLoc *time.Location) (time.Time, bool, error) {
 
 		tzidFound = true
 		str = str[locEnd+1:]
-	} else {
-		colonIdx := strings.IndexAny(str, ":=")
+	} else if colonIdx := strings.LastIndex(str, ":"); colonIdx >= 0 {
 		str = str[colonIdx+1:]
+	} else {
+		str = str[strings.Index(str, "=")+1:]
 	}
 
+	t, offsetFound, err := parseTimeValue(str, loc)
+	return t, !(tzidFound || offsetFound), err
+}
+
+// parseTimeValue parses a DATE or DATE-TIME value, like 19970902T090000Z, in
+// loc unless it has an offset. The boolean is true if it has one.
+func parseTimeValue(str string, loc *time.Location) (time.Time, bool, error) {
 	offsetFound := true
 
 	t, err := time.ParseInLocation(rfc5545WithOffset, str, loc)
@@ -53,6 +61,9 @@ func parseTime(str string, defaultLoc *time.Location) (time.Time, bool, error) {
 		offsetFound = false
 		t, err = time.ParseInLocation(rfc5545WithoutOffset, str, loc)
 	}
+	if err != nil {
+		t, err = time.ParseInLocation(rfc5545Date, str, loc)
+	}
 
 	// From RFC 5545:
 	//
@@ -77,14 +88,71 @@ func parseTime(str string, defaultLoc *time.Location) (time.Time, bool, error) {
 	// in the 2am range, but the parsed time is less than 2 o'clock, advance an hour.
 	if tMinusHour := t.Add(-1 * time.Hour); t.Hour() == tMinusHour.Hour() {
 		t = tMinusHour
-	} else if twoAMRegex.MatchString(str) {
+	} else if isTwoAM(str) && t.Hour() != 2 {
 		t = t.Add(1 * time.Hour)
 	}
 
-	return t, !(tzidFound || offsetFound), err
+	return t, offsetFound, err
+}
+
+// isTwoAM reports whether a DATE-TIME value, like 20070311T023000, is in the
+// 2 o'clock hour. It may be followed by Z or a four-digit offset.
+func isTwoAM(str string) bool {
+	endsTwoAM := func(str string) bool {
+		return strings.HasSuffix(str[:len(str)-4], "T02") && isDigits(str[len(str)-4:])
+	}
+
+	if len(str) < 7 {
+		return false
+	}
+	if endsTwoAM(str) {
+		return true
+	}
+	if strings.HasSuffix(str, "Z") {
+		return len(str) >= 8 && endsTwoAM(str[:len(str)-1])
+	}
+	return len(str) >= 11 && isDigits(str[len(str)-4:]) && endsTwoAM(str[:len(str)-4])
+}
+
+func isDigits(str string) bool {
+	for i := 0; i < len(str); i++ {
+		if str[i] < '0' || str[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// withFloatingUntilIn returns the pattern with a floating Until moved to the
+// same clock time in loc, where it is observed, as a floating Dtstart is.
+func (rrule RRule) withFloatingUntilIn(loc *time.Location) RRule {
+	if rrule.UntilFloating && !rrule.Until.IsZero() {
+		rrule.Until = wallClockIn(rrule.Until, loc)
+	}
+	return rrule
+}
+
+// isDate reports whether a property, like DTSTART;VALUE=DATE:19970714, holds
+// a DATE rather than a DATE-TIME.
+func isDate(line string) bool {
+	return len(line)-strings.LastIndex(line, ":")-1 == len(rfc5545Date)
 }
 
-var twoAMRegex = regexp.MustCompile("T02[0-9]{4}(Z|[0-9]{4})?$")
+// formatDate formats the date of t as a DATE value, like
+// DTSTART;VALUE=DATE:19970714.
+func formatDate(prefix string, t time.Time) string {
+	return fmt.Sprintf("%s;VALUE=DATE:%s", prefix, t.Format(rfc5545Date))
+}
+
+// pastUntil reports whether t is after the end of the pattern set by Until.
+// A floating Until is observed in the location of Dtstart.
+func (rrule RRule) pastUntil(t time.Time) bool {
+	if rrule.Until.IsZero() {
+		return false
+	}
+	until := rrule.withFloatingUntilIn(rrule.dtstart().Location()).Until
+	return t.After(until) || rrule.UntilExclusive && t.Equal(until)
+}
 
 func formatTime(prefix string, t time.Time, floatingLocation bool) string {
 	if floatingLocation {
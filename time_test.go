@@ -59,6 +59,16 @@ func TestParseTime(t *testing.T) {
 			Expected:         time.Date(2007, time.March, 11, 3, 30, 0, 0, NewYork()),
 			ExpectedFloating: false,
 		},
+		{
+			Input:            "DTSTART;TZID=America/New_York:20181027T023615",
+			Expected:         time.Date(2018, time.October, 27, 2, 36, 15, 00, NewYork()),
+			ExpectedFloating: false,
+		},
+		{
+			Input:            "RDATE:20181027T023615Z",
+			Expected:         time.Date(2018, time.October, 27, 2, 36, 15, 00, time.UTC),
+			ExpectedFloating: false,
+		},
 	}
 
 	for _, tc := range cases {
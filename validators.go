@@ -71,7 +71,9 @@ func validWeekday(weekdays []QualifiedWeekday) validFunc {
 	}
 }
 
-func validMonthDay(monthdays []int) validFunc {
+// validMonthDay counts negative monthdays back from the end of the month, with
+// the month's length looked up in tables.
+func validMonthDay(monthdays []int, tables *yearTables) validFunc {
 	if len(monthdays) == 0 {
 		return alwaysValid
 	}
@@ -82,11 +84,17 @@ func validMonthDay(monthdays []int) validFunc {
 		if t == nil {
 			return false
 		}
-		return m[t.Day()]
+		year, month, day := t.Date()
+		if m[day] {
+			return true
+		}
+		return m[day-tables.year(year).monthLength(month)-1]
 	}
 }
 
-func validWeek(weeks []int) validFunc {
+// validWeek checks week numbers, where weeks start on the week start of tables
+// and the first of each year has at least 4 of its days.
+func validWeek(weeks []int, tables *yearTables) validFunc {
 	if len(weeks) == 0 {
 		return alwaysValid
 	}
@@ -97,7 +105,8 @@ func validWeek(weeks []int) validFunc {
 		if t == nil {
 			return false
 		}
-		return m[1+t.YearDay()/7]
+		yt, day := tables.year(t.Year()), t.YearDay()
+		return m[int(yt.weeks[day])] || m[int(yt.weeksFromEnd[day])]
 	}
 }
 
@@ -116,7 +125,9 @@ func validMonth(months []time.Month) validFunc {
 	}
 }
 
-func validYearDay(yeardays []int) validFunc {
+// validYearDay counts negative yeardays back from the end of the year, with
+// the year's length looked up in tables.
+func validYearDay(yeardays []int, tables *yearTables) validFunc {
 	if len(yeardays) == 0 {
 		return alwaysValid
 	}
@@ -127,6 +138,10 @@ func validYearDay(yeardays []int) validFunc {
 		if t == nil {
 			return false
 		}
-		return m[t.YearDay()]
+		day := t.YearDay()
+		if m[day] {
+			return true
+		}
+		return m[day-tables.year(t.Year()).days-1]
 	}
 }
@@ -0,0 +1,217 @@
+package rrule
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a recurring event parsed from an iCalendar VEVENT component.
+type Event struct {
+	// Recurrence holds the event's DTSTART, RRULE, EXRULE, RDATE and EXDATE
+	// properties, and generates the start time of each instance.
+	Recurrence
+
+	// Duration is the length of each instance, from either the DTEND or
+	// DURATION property. It is zero if neither was specified.
+	Duration time.Duration `json:"duration"`
+
+	// RecurrenceID identifies the instance of another event's recurrence
+	// that this event overrides. It is zero if the event is not an
+	// override.
+	RecurrenceID time.Time `json:"recurrence_id"`
+}
+
+// ParseVEvent parses the first VEVENT component in src. The surrounding
+// BEGIN:VEVENT and END:VEVENT lines may be omitted, in which case all of src
+// is treated as the event's properties. Properties not relating to the
+// event's timing are ignored, as are nested components like VALARM.
+//
+// loc defines what "local" means to the parsed times, as in ParseRecurrence.
+// If nil, time.UTC will be used.
+func ParseVEvent(src []byte, loc *time.Location) (*Event, error) {
+	var props []*Property
+
+	pr := NewPropertyReader(bytes.NewReader(src))
+	for {
+		prop, err := pr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VEVENT") {
+			props = props[:0]
+			continue
+		}
+		if prop.Name == "END" && strings.EqualFold(prop.Value, "VEVENT") {
+			break
+		}
+		props = append(props, prop)
+	}
+
+	event := &Event{}
+	var dtend time.Time
+	depth := 0
+
+	for _, prop := range props {
+		switch prop.Name {
+		case "BEGIN":
+			depth++
+			continue
+		case "END":
+			depth--
+			continue
+		}
+
+		if depth > 0 {
+			continue
+		}
+
+		switch prop.Name {
+		case "DTSTART":
+			t, floating, err := parsePropertyTime(prop, loc)
+			if err != nil {
+				return nil, err
+			}
+			event.Dtstart = t
+			event.FloatingLocation = floating
+			event.AllDay = isDate(prop.Value)
+		case "DTEND":
+			t, _, err := parsePropertyTime(prop, loc)
+			if err != nil {
+				return nil, err
+			}
+			dtend = t
+		case "DURATION":
+			d, err := parseDuration(prop.Value)
+			if err != nil {
+				return nil, err
+			}
+			event.Duration = d
+		case "RECURRENCE-ID":
+			t, _, err := parsePropertyTime(prop, loc)
+			if err != nil {
+				return nil, err
+			}
+			event.RecurrenceID = t
+		case "RRULE":
+			rrule, err := ParseRRule(prop.Value)
+			if err != nil {
+				return nil, err
+			}
+			event.RRules = append(event.RRules, rrule)
+		case "EXRULE":
+			rrule, err := ParseRRule(prop.Value)
+			if err != nil {
+				return nil, err
+			}
+			event.ExRules = append(event.ExRules, rrule)
+		case "RDATE", "EXDATE":
+			list, err := ParseDateList(prop.String(), loc)
+			if err != nil {
+				return nil, err
+			}
+			event.AddDateList(list)
+		}
+	}
+
+	if event.Dtstart.IsZero() {
+		return nil, errors.New("VEVENT has no DTSTART")
+	}
+
+	if !dtend.IsZero() {
+		if event.Duration != 0 {
+			return nil, errors.New("DTEND and DURATION must not appear in the same VEVENT")
+		}
+		event.Duration = dtend.Sub(event.Dtstart)
+	}
+
+	event.setDtstart()
+
+	return event, nil
+}
+
+// parsePropertyTime parses the DATE or DATE-TIME value of prop in the location
+// named by its TZID parameter, ignoring any other parameters.
+func parsePropertyTime(prop *Property, loc *time.Location) (time.Time, bool, error) {
+	// parseTime expects a whole property, so rebuild one with only the TZID.
+	prefix := "X:"
+	if tzid := prop.Param("TZID"); tzid != "" {
+		prefix = "X;TZID=" + tzid + ":"
+	}
+	return parseTime(prefix+prop.Value, loc)
+}
+
+// parseDuration parses an RFC 5545 duration value, like P1DT2H or -PT15M. Days
+// and weeks are treated as exactly 24 hours and 7 days long, respectively.
+func parseDuration(str string) (time.Duration, error) {
+	orig := str
+
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(str, "-"):
+		sign = -1
+		str = str[1:]
+	case strings.HasPrefix(str, "+"):
+		str = str[1:]
+	}
+
+	if !strings.HasPrefix(str, "P") || len(str) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	str = str[1:]
+
+	var d time.Duration
+	inTime := false
+	for len(str) > 0 {
+		if str[0] == 'T' {
+			if inTime {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			inTime = true
+			str = str[1:]
+			if len(str) == 0 {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(str, func(r rune) bool { return r < '0' || r > '9' })
+		if end <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+
+		n, err := strconv.Atoi(str[:end])
+		if err != nil {
+			return 0, err
+		}
+
+		var unit time.Duration
+		switch designator := str[end]; {
+		case designator == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case designator == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case designator == 'H' && inTime:
+			unit = time.Hour
+		case designator == 'M' && inTime:
+			unit = time.Minute
+		case designator == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+
+		d += time.Duration(n) * unit
+		str = str[end+1:]
+	}
+
+	return sign * d, nil
+}
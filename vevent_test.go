@@ -0,0 +1,89 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVEvent(t *testing.T) {
+	src := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:123@example.com\r\n" +
+		"SUMMARY:Team meeting\r\n" +
+		"DTSTART;TZID=America/New_York:20180825T090000\r\n" +
+		"DTEND;TZID=America/New_York:20180825T093000\r\n" +
+		"RRULE:FREQ=DAILY;COUNT=5;\r\n" +
+		" BYHOUR=9\r\n" +
+		"EXDATE;TZID=America/New_York:20180826T090000,20180828T090000\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"DURATION:PT1H\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:20190101T000000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	event, err := ParseVEvent([]byte(src), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 30*time.Minute, event.Duration)
+	assert.True(t, event.RecurrenceID.IsZero())
+	assert.Equal(t, []string{"2018-08-25T09:00:00-04:00", "2018-08-27T09:00:00-04:00", "2018-08-29T09:00:00-04:00"}, rfcAll(All(event.Iterator(), 0)))
+}
+
+func TestParseVEventOverride(t *testing.T) {
+	src := "DTSTART;VALUE=DATE:20180826\n" +
+		"DURATION:P1D\n" +
+		"RECURRENCE-ID:20180825T090000Z\n"
+
+	event, err := ParseVEvent([]byte(src), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2018, time.August, 26, 0, 0, 0, 0, time.UTC), event.Dtstart)
+	assert.Equal(t, 24*time.Hour, event.Duration)
+	assert.Equal(t, time.Date(2018, time.August, 25, 9, 0, 0, 0, time.UTC), event.RecurrenceID)
+
+	_, err = ParseVEvent([]byte("DTSTART:20180826T000000Z\nDTEND:20180826T010000Z\nDURATION:PT1H\n"), nil)
+	assert.Error(t, err)
+
+	_, err = ParseVEvent([]byte("BEGIN:VEVENT\nSUMMARY:nothing\nEND:VEVENT\n"), nil)
+	assert.Error(t, err)
+}
+
+func TestParseVEventParams(t *testing.T) {
+	src := "DTSTART;VALUE=DATE-TIME;TZID=America/New_York;X-SOURCE=outlook:19970902T090000\n" +
+		"DTEND;X-SOURCE=outlook;TZID=America/New_York:19970902T100000\n" +
+		"RECURRENCE-ID;RANGE=THISANDFUTURE;TZID=America/New_York:19970901T090000\n"
+
+	event, err := ParseVEvent([]byte(src), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1997-09-02T09:00:00-04:00", event.Dtstart.Format(time.RFC3339))
+	assert.False(t, event.AllDay)
+	assert.False(t, event.FloatingLocation)
+	assert.Equal(t, time.Hour, event.Duration)
+	assert.Equal(t, "1997-09-01T09:00:00-04:00", event.RecurrenceID.Format(time.RFC3339))
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"P15DT5H0M20S": 15*24*time.Hour + 5*time.Hour + 20*time.Second,
+		"P7W":          7 * 7 * 24 * time.Hour,
+		"-PT15M":       -15 * time.Minute,
+		"+PT1H30M":     90 * time.Minute,
+	}
+	for str, expected := range cases {
+		d, err := parseDuration(str)
+		require.NoError(t, err, str)
+		assert.Equal(t, expected, d, str)
+	}
+
+	for _, str := range []string{"", "P", "PT", "P1H", "PT1D", "P1DT", "1D", "P1D2"} {
+		_, err := parseDuration(str)
+		assert.Error(t, err, str)
+	}
+}
@@ -0,0 +1,417 @@
+package rrule
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vtimezoneHorizon is the last year through which recurring observances of a
+// VTIMEZONE are expanded into transitions. Later transitions are described by
+// a POSIX TZ rule when the observances can be written as one; otherwise the
+// last observance stays in effect.
+const vtimezoneHorizon = 2100
+
+// observance is a STANDARD or DAYLIGHT sub-component of a VTIMEZONE.
+type observance struct {
+	dst      bool
+	name     string
+	from, to int // offsets from UTC, in seconds
+
+	// start and rdates are clock times, in UTC.
+	start  time.Time
+	rrules []RRule
+	rdates []time.Time
+}
+
+// ParseVTimezone parses the first VTIMEZONE component in src, as found in .ics
+// files, into a location named by its TZID. The surrounding BEGIN:VTIMEZONE
+// and END:VTIMEZONE lines may be omitted, in which case all of src is treated
+// as the component's properties.
+//
+// The location follows the component's STANDARD and DAYLIGHT observances
+// rather than the IANA database, so it can be used for the Dtstart of
+// patterns from calendars that define their own time zones. To have parsed
+// TZIDs resolve to it, set LoadLocation to a function that returns it.
+func ParseVTimezone(src []byte) (*time.Location, error) {
+	var props []*Property
+
+	pr := NewPropertyReader(bytes.NewReader(src))
+	for {
+		prop, err := pr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VTIMEZONE") {
+			props = props[:0]
+			continue
+		}
+		if prop.Name == "END" && strings.EqualFold(prop.Value, "VTIMEZONE") {
+			break
+		}
+		props = append(props, prop)
+	}
+
+	var tzid string
+	var observances []*observance
+	var obs *observance
+	for _, prop := range props {
+		switch {
+		case prop.Name == "BEGIN" && obs == nil:
+			switch strings.ToUpper(prop.Value) {
+			case "STANDARD":
+				obs = &observance{}
+			case "DAYLIGHT":
+				obs = &observance{dst: true}
+			default:
+				return nil, fmt.Errorf("unexpected %s component in VTIMEZONE", prop.Value)
+			}
+		case prop.Name == "END" && obs != nil:
+			if obs.start.IsZero() {
+				return nil, fmt.Errorf("%s has no DTSTART", prop.Value)
+			}
+			observances = append(observances, obs)
+			obs = nil
+		case obs == nil:
+			if prop.Name == "TZID" {
+				tzid = prop.Value
+			}
+		default:
+			if err := obs.setProperty(prop); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if tzid == "" {
+		return nil, errors.New("VTIMEZONE has no TZID")
+	}
+	if len(observances) == 0 {
+		return nil, errors.New("VTIMEZONE has no STANDARD or DAYLIGHT observances")
+	}
+
+	return time.LoadLocationFromTZData(tzid, vtimezoneTZif(observances))
+}
+
+func (obs *observance) setProperty(prop *Property) error {
+	var err error
+	switch prop.Name {
+	case "DTSTART":
+		obs.start, err = time.ParseInLocation(rfc5545WithoutOffset, prop.Value, time.UTC)
+	case "TZOFFSETFROM":
+		obs.from, err = parseUTCOffset(prop.Value)
+	case "TZOFFSETTO":
+		obs.to, err = parseUTCOffset(prop.Value)
+	case "TZNAME":
+		obs.name = prop.Value
+	case "RRULE":
+		var rrule RRule
+		rrule, err = ParseRRule(prop.Value)
+		if err == nil {
+			err = rrule.Validate()
+		}
+		obs.rrules = append(obs.rrules, rrule)
+	case "RDATE":
+		var list *DateList
+		list, err = ParseDateList(prop.String(), time.UTC)
+		if err == nil {
+			obs.rdates = append(obs.rdates, list.Times...)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", prop.Name, err)
+	}
+	return nil
+}
+
+// parseUTCOffset parses an RFC 5545 UTC offset, like -0500 or +053000, into
+// seconds.
+func parseUTCOffset(str string) (int, error) {
+	if (len(str) != 5 && len(str) != 7) || (str[0] != '+' && str[0] != '-') {
+		return 0, fmt.Errorf("%q is not a UTC offset", str)
+	}
+
+	var parts [3]int
+	for i := 0; 1+2*i < len(str); i++ {
+		n, err := strconv.Atoi(str[1+2*i : 3+2*i])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%q is not a UTC offset", str)
+		}
+		parts[i] = n
+	}
+
+	offset := parts[0]*3600 + parts[1]*60 + parts[2]
+	if str[0] == '-' {
+		offset = -offset
+	}
+	return offset, nil
+}
+
+// zoneName returns the observance's TZNAME, or its offset if it has none.
+func (obs *observance) zoneName() string {
+	if obs.name == "" {
+		return formatUTCOffset(obs.to)
+	}
+	return obs.name
+}
+
+// onsets returns the times the observance begins, through
+// vtimezoneHorizon.
+func (obs *observance) onsets() []time.Time {
+	var onsets []time.Time
+	add := func(clock time.Time) {
+		onsets = append(onsets, clock.Add(-time.Duration(obs.from)*time.Second))
+	}
+
+	add(obs.start)
+	for _, rdate := range obs.rdates {
+		add(rdate)
+	}
+
+	for _, rrule := range obs.rrules {
+		until := rrule.Until
+		rrule.Until = time.Time{}
+		rrule.Dtstart = obs.start
+
+		// a YEARLY pattern only finds the months in BYMONTH from a start
+		// in one of them.
+		if rrule.Frequency == Yearly && len(rrule.ByMonths) > 0 && !validMonth(rrule.ByMonths)(&rrule.Dtstart) {
+			rrule.Dtstart = firstOfMonth(rrule.Dtstart)
+			for !validMonth(rrule.ByMonths)(&rrule.Dtstart) {
+				rrule.Dtstart = rrule.Dtstart.AddDate(0, 1, 0)
+			}
+		}
+
+		it := rrule.Iterator()
+		for {
+			clock := it.Next()
+			if clock == nil || clock.Year() > vtimezoneHorizon {
+				break
+			}
+			if clock.Before(obs.start) {
+				continue
+			}
+
+			// UNTIL is in UTC, unless it is floating.
+			if !until.IsZero() {
+				if rrule.UntilFloating && clock.After(until) ||
+					!rrule.UntilFloating && clock.Add(-time.Duration(obs.from)*time.Second).After(until) {
+					break
+				}
+			}
+			add(*clock)
+		}
+	}
+
+	return onsets
+}
+
+// vtimezoneTZif returns the observances as version 2 TZif data, as read by
+// time.LoadLocationFromTZData.
+func vtimezoneTZif(observances []*observance) []byte {
+	type zone struct {
+		offset int
+		dst    bool
+		name   string
+	}
+	type transition struct {
+		when int64
+		zone int
+	}
+
+	var zones []zone
+	var transitions []transition
+	zoneIndex := func(z zone) int {
+		// the first zone is the one in effect before any transition, and
+		// is kept apart so that time.Location uses it as such.
+		for i := 1; i < len(zones); i++ {
+			if zones[i] == z {
+				return i
+			}
+		}
+		zones = append(zones, z)
+		return len(zones) - 1
+	}
+
+	// the zone in effect before the first onset is the one it changes
+	// from.
+	first := observances[0]
+	for _, obs := range observances {
+		if obs.start.Add(-time.Duration(obs.from) * time.Second).Before(first.start.Add(-time.Duration(first.from) * time.Second)) {
+			first = obs
+		}
+	}
+	initial := zone{offset: first.from, name: formatUTCOffset(first.from)}
+	for _, obs := range observances {
+		if obs.to == first.from {
+			initial = zone{offset: obs.to, dst: obs.dst, name: obs.zoneName()}
+			break
+		}
+	}
+	zones = append(zones, initial)
+
+	for _, obs := range observances {
+		idx := zoneIndex(zone{offset: obs.to, dst: obs.dst, name: obs.zoneName()})
+		for _, onset := range obs.onsets() {
+			transitions = append(transitions, transition{when: onset.Unix(), zone: idx})
+		}
+	}
+	sort.SliceStable(transitions, func(i, j int) bool { return transitions[i].when < transitions[j].when })
+
+	var names []byte
+	nameIndex := map[string]int{}
+	for _, z := range zones {
+		if _, ok := nameIndex[z.name]; !ok {
+			nameIndex[z.name] = len(names)
+			names = append(append(names, z.name...), 0)
+		}
+	}
+
+	b := &bytes.Buffer{}
+	header := func(transitions, zones, names int) {
+		b.WriteString("TZif2")
+		b.Write(make([]byte, 15))
+		for _, n := range []int{0, 0, 0, transitions, zones, names} {
+			binary.Write(b, binary.BigEndian, uint32(n))
+		}
+	}
+
+	// the version 1 data, which readers of version 2 skip, is left empty.
+	header(0, 0, 0)
+	header(len(transitions), len(zones), len(names))
+	for _, tr := range transitions {
+		binary.Write(b, binary.BigEndian, tr.when)
+	}
+	for _, tr := range transitions {
+		b.WriteByte(byte(tr.zone))
+	}
+	for _, z := range zones {
+		binary.Write(b, binary.BigEndian, int32(z.offset))
+		if z.dst {
+			b.WriteByte(1)
+		} else {
+			b.WriteByte(0)
+		}
+		b.WriteByte(byte(nameIndex[z.name]))
+	}
+	b.Write(names)
+
+	b.WriteString("\n")
+	b.WriteString(posixTZ(observances))
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// posixTZ returns a POSIX TZ rule, like <EST>5<EDT>4,M3.2.0/2,M11.1.0/2, for
+// the observances that recur forever, or "" if they can't be written as one.
+func posixTZ(observances []*observance) string {
+	var std, dst *observance
+	for _, obs := range observances {
+		for _, rrule := range obs.rrules {
+			if rrule.Count != 0 || !rrule.Until.IsZero() {
+				continue
+			}
+			if obs.dst && dst == nil {
+				dst = obs
+			} else if !obs.dst && std == nil {
+				std = obs
+			} else {
+				return ""
+			}
+		}
+	}
+	if std == nil || dst == nil {
+		return ""
+	}
+
+	stdRule, ok := posixRule(std)
+	if !ok {
+		return ""
+	}
+	dstRule, ok := posixRule(dst)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s%s%s%s,%s,%s",
+		posixName(std), posixOffset(std.to),
+		posixName(dst), posixOffset(dst.to),
+		dstRule, stdRule)
+}
+
+// posixRule returns the POSIX TZ form of the observance's only recurring
+// pattern, like M3.2.0/2, if it is an ordinal weekday of a month every year.
+func posixRule(obs *observance) (string, bool) {
+	rrule := obs.rrules[len(obs.rrules)-1]
+	if rrule.Frequency != Yearly || rrule.Interval > 1 ||
+		len(rrule.ByMonths) != 1 || len(rrule.ByWeekdays) != 1 ||
+		len(rrule.BySeconds) > 0 || len(rrule.ByMinutes) > 0 || len(rrule.ByHours) > 0 ||
+		len(rrule.ByMonthDays) > 0 || len(rrule.ByWeekNumbers) > 0 || len(rrule.ByYearDays) > 0 ||
+		len(rrule.BySetPos) > 0 || rrule.RScale != Gregorian {
+		return "", false
+	}
+
+	week := rrule.ByWeekdays[0].N
+	switch {
+	case week == -1:
+		week = 5
+	case week < 1 || week > 4:
+		return "", false
+	}
+
+	start := obs.start
+	return fmt.Sprintf("M%d.%d.%d/%d:%02d:%02d",
+		rrule.ByMonths[0], week, rrule.ByWeekdays[0].WD,
+		start.Hour(), start.Minute(), start.Second()), true
+}
+
+// posixName returns the observance's name as a quoted POSIX TZ name, or its
+// offset if the name can't be used.
+func posixName(obs *observance) string {
+	name := obs.name
+	valid := len(name) >= 3
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '+' || r == '-') {
+			valid = false
+		}
+	}
+	if !valid {
+		name = formatUTCOffset(obs.to)
+	}
+	return "<" + name + ">"
+}
+
+// posixOffset returns the offset in POSIX TZ form, which counts westward.
+func posixOffset(offset int) string {
+	sign := ""
+	if offset > 0 {
+		sign = "-"
+	} else {
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%d:%02d:%02d", sign, offset/3600, offset/60%60, offset%60)
+}
+
+// formatUTCOffset returns the offset in the form of RFC 5545, like -0500.
+func formatUTCOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+	if offset%60 != 0 {
+		s += fmt.Sprintf("%02d", offset%60)
+	}
+	return s
+}
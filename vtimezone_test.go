@@ -0,0 +1,165 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// from RFC 5545, section 3.6.5.
+const newYorkVTimezone = `BEGIN:VCALENDAR
+BEGIN:VTIMEZONE
+TZID:America/New_York
+LAST-MODIFIED:20050809T050000Z
+BEGIN:DAYLIGHT
+DTSTART:19670430T020000
+RRULE:FREQ=YEARLY;BYMONTH=4;BYDAY=-1SU;UNTIL=19730429T070000Z
+TZOFFSETFROM:-0500
+TZOFFSETTO:-0400
+TZNAME:EDT
+END:DAYLIGHT
+BEGIN:STANDARD
+DTSTART:19671029T020000
+RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU;UNTIL=20061029T060000Z
+TZOFFSETFROM:-0400
+TZOFFSETTO:-0500
+TZNAME:EST
+END:STANDARD
+BEGIN:DAYLIGHT
+DTSTART:19740106T020000
+RDATE:19750223T020000
+TZOFFSETFROM:-0500
+TZOFFSETTO:-0400
+TZNAME:EDT
+END:DAYLIGHT
+BEGIN:DAYLIGHT
+DTSTART:19760425T020000
+RRULE:FREQ=YEARLY;BYMONTH=4;BYDAY=-1SU;UNTIL=19860427T070000Z
+TZOFFSETFROM:-0500
+TZOFFSETTO:-0400
+TZNAME:EDT
+END:DAYLIGHT
+BEGIN:DAYLIGHT
+DTSTART:19870405T020000
+RRULE:FREQ=YEARLY;BYMONTH=4;BYDAY=1SU;UNTIL=20060402T070000Z
+TZOFFSETFROM:-0500
+TZOFFSETTO:-0400
+TZNAME:EDT
+END:DAYLIGHT
+BEGIN:DAYLIGHT
+DTSTART:20070311T020000
+RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU
+TZOFFSETFROM:-0500
+TZOFFSETTO:-0400
+TZNAME:EDT
+END:DAYLIGHT
+BEGIN:STANDARD
+DTSTART:20071104T020000
+RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU
+TZOFFSETFROM:-0400
+TZOFFSETTO:-0500
+TZNAME:EST
+END:STANDARD
+END:VTIMEZONE
+END:VCALENDAR
+`
+
+func TestParseVTimezone(t *testing.T) {
+	loc, err := ParseVTimezone([]byte(newYorkVTimezone))
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", loc.String())
+
+	for _, utc := range []time.Time{
+		time.Date(1968, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1968, time.July, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1974, time.February, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1990, time.April, 1, 6, 59, 59, 0, time.UTC),
+		time.Date(1990, time.April, 1, 7, 0, 0, 0, time.UTC),
+		time.Date(2018, time.March, 11, 6, 59, 59, 0, time.UTC),
+		time.Date(2018, time.March, 11, 7, 0, 0, 0, time.UTC),
+		time.Date(2018, time.November, 4, 5, 59, 59, 0, time.UTC),
+		time.Date(2018, time.November, 4, 6, 0, 0, 0, time.UTC),
+		time.Date(2150, time.July, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2150, time.December, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		name, offset := utc.In(loc).Zone()
+		wantName, wantOffset := utc.In(NewYork()).Zone()
+		assert.Equal(t, wantName, name, utc.String())
+		assert.Equal(t, wantOffset, offset, utc.String())
+	}
+
+	rrule := RRule{Frequency: Daily, Count: 3, Dtstart: time.Date(2018, time.March, 10, 9, 0, 0, 0, loc)}
+	assert.Equal(t, []string{
+		"2018-03-10T09:00:00-05:00",
+		"2018-03-11T09:00:00-04:00",
+		"2018-03-12T09:00:00-04:00",
+	}, rfcAll(All(rrule.Iterator(), 0)))
+}
+
+func TestParseVTimezoneCustom(t *testing.T) {
+	// Outlook starts its observances in 1601, in months outside BYMONTH.
+	loc, err := ParseVTimezone([]byte(`TZID:Custom Standard Time
+BEGIN:STANDARD
+DTSTART:16010101T030000
+TZOFFSETFROM:+0230
+TZOFFSETTO:+0130
+RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10
+END:STANDARD
+BEGIN:DAYLIGHT
+DTSTART:16010101T020000
+TZOFFSETFROM:+0130
+TZOFFSETTO:+0230
+RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3
+END:DAYLIGHT
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "Custom Standard Time", loc.String())
+
+	name, _ := time.Date(2018, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	assert.Equal(t, "+0130", name)
+
+	for utc, want := range map[time.Time]int{
+		time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC):    90 * 60,
+		time.Date(2018, time.March, 25, 0, 29, 59, 0, time.UTC):   90 * 60,
+		time.Date(2018, time.March, 25, 0, 30, 0, 0, time.UTC):    150 * 60,
+		time.Date(2018, time.October, 28, 0, 29, 59, 0, time.UTC): 150 * 60,
+		time.Date(2018, time.October, 28, 0, 30, 0, 0, time.UTC):  90 * 60,
+		time.Date(2200, time.July, 1, 0, 0, 0, 0, time.UTC):       150 * 60,
+	} {
+		_, offset := utc.In(loc).Zone()
+		assert.Equal(t, want, offset, utc.String())
+	}
+}
+
+func TestParseVTimezoneFixed(t *testing.T) {
+	loc, err := ParseVTimezone([]byte(`BEGIN:VTIMEZONE
+TZID:Fixed
+BEGIN:STANDARD
+DTSTART:19700101T000000
+TZOFFSETFROM:+053000
+TZOFFSETTO:+053000
+TZNAME:IST
+END:STANDARD
+END:VTIMEZONE`))
+	require.NoError(t, err)
+
+	name, offset := time.Date(2018, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	assert.Equal(t, "IST", name)
+	assert.Equal(t, 5*3600+30*60, offset)
+}
+
+func TestParseVTimezoneErrors(t *testing.T) {
+	for _, src := range []string{
+		"BEGIN:VTIMEZONE\nEND:VTIMEZONE",
+		"BEGIN:VTIMEZONE\nTZID:X\nEND:VTIMEZONE",
+		"TZID:X\nBEGIN:STANDARD\nTZOFFSETFROM:+0100\nTZOFFSETTO:+0100\nEND:STANDARD",
+		"TZID:X\nBEGIN:STANDARD\nDTSTART:19700101T000000\nTZOFFSETFROM:0100\nEND:STANDARD",
+		"TZID:X\nBEGIN:VALARM\nEND:VALARM",
+		"TZID:X\nBEGIN:STANDARD\nDTSTART:19700101T000000\nRRULE:FREQ=YEARLY;BYMONTH=13\nEND:STANDARD",
+	} {
+		_, err := ParseVTimezone([]byte(src))
+		assert.Error(t, err, src)
+	}
+}
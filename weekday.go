@@ -1,7 +1,9 @@
 package rrule
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -24,6 +26,123 @@ func (wd QualifiedWeekday) String() string {
 	return fmt.Sprintf("%d%s", wd.N, wdStr)
 }
 
+// ParseQualifiedWeekday parses a weekday as written in BYDAY, like "MO", "2TU"
+// or "-1FR".
+func ParseQualifiedWeekday(str string) (QualifiedWeekday, error) {
+	if str == "" || strings.IndexByte(str, ',') >= 0 {
+		return QualifiedWeekday{}, fmt.Errorf("invalid weekday %q", str)
+	}
+	wds, err := parseQualifiedWeekdays(str)
+	if err != nil {
+		return QualifiedWeekday{}, err
+	}
+	return wds[0], nil
+}
+
+// MarshalText returns the weekday as written in BYDAY, like "2TU", as String
+// does.
+func (wd QualifiedWeekday) MarshalText() ([]byte, error) {
+	if wd.WD < time.Sunday || wd.WD > time.Saturday {
+		return nil, fmt.Errorf("weekday %d is not a day of the week", wd.WD)
+	}
+	return appendQualifiedWeekday(nil, wd), nil
+}
+
+// UnmarshalText parses the weekday as ParseQualifiedWeekday does.
+func (wd *QualifiedWeekday) UnmarshalText(text []byte) error {
+	parsed, err := ParseQualifiedWeekday(string(text))
+	if err != nil {
+		return err
+	}
+	*wd = parsed
+	return nil
+}
+
+// UnmarshalJSON decodes the string written by MarshalText, which JSON
+// encoding uses, like "2TU". It also accepts the original encoding, an object
+// like {"n":2,"wd":2}.
+func (wd *QualifiedWeekday) UnmarshalJSON(b []byte) error {
+	var text string
+	if err := json.Unmarshal(b, &text); err == nil {
+		return wd.UnmarshalText([]byte(text))
+	}
+
+	// plain doesn't have these methods, so it's decoded field by field.
+	type plain QualifiedWeekday
+	return json.Unmarshal(b, (*plain)(wd))
+}
+
+// DefaultWeekStart is the day weeks start on in patterns without WKST. RFC 5545
+// makes it Monday, but some products treat Sunday as the default. As it changes
+// the meaning of patterns, set it once, before any are used.
+var DefaultWeekStart = time.Monday
+
+// WeekStart is the day weeks start on, as WKST sets it. The zero value is
+// unset, in which case DefaultWeekStart is used. It is written as in RFC 5545,
+// like "SU", and as an empty string when unset.
+type WeekStart struct {
+	wd  time.Weekday
+	set bool
+}
+
+// WeekStartOn returns a WeekStart of wd.
+func WeekStartOn(wd time.Weekday) WeekStart {
+	return WeekStart{wd: wd, set: true}
+}
+
+// Weekday returns the day weeks start on, which is DefaultWeekStart if the week
+// start is unset, and whether it is set.
+func (ws WeekStart) Weekday() (time.Weekday, bool) {
+	if !ws.set {
+		return DefaultWeekStart, false
+	}
+	return ws.wd, true
+}
+
+// IsSet reports whether the week start is set.
+func (ws WeekStart) IsSet() bool {
+	return ws.set
+}
+
+// implied reports whether the week start is unset, or is MO, the RFC 5545
+// default, while DefaultWeekStart agrees. Only then can WKST be left out
+// without changing what the pattern means to other implementations.
+func (ws WeekStart) implied() bool {
+	return !ws.set || ws.wd == time.Monday && DefaultWeekStart == time.Monday
+}
+
+// String returns the week start as written in WKST, like "SU", or an empty
+// string if it is unset.
+func (ws WeekStart) String() string {
+	if !ws.set {
+		return ""
+	}
+	return weekdayString(ws.wd)
+}
+
+// MarshalText returns the week start as String does.
+func (ws WeekStart) MarshalText() ([]byte, error) {
+	if ws.set && (ws.wd < time.Sunday || ws.wd > time.Saturday) {
+		return nil, fmt.Errorf("week start %d is not a day of the week", ws.wd)
+	}
+	return []byte(ws.String()), nil
+}
+
+// UnmarshalText parses the week start as written in WKST, like "SU", in any
+// case. An empty string is unset.
+func (ws *WeekStart) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*ws = WeekStart{}
+		return nil
+	}
+	wd, err := parseWeekday(string(text))
+	if err != nil {
+		return err
+	}
+	*ws = WeekStartOn(wd)
+	return nil
+}
+
 // WeekdayString returns a weekday formatted as the two-letter string used in RFC5545.
 func WeekdayString(wd time.Weekday) string {
 	var wdStr string
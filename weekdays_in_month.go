@@ -17,12 +17,18 @@ import (
 // preceeding and following months if the requested weekdays go beyond the
 // bounds of the month.
 func weekdaysInMonth(t time.Time, weekdays []QualifiedWeekday, bySetPos []int, ib InvalidBehavior) []time.Time {
+	return appendWeekdaysInMonth(nil, t, weekdays, bySetPos, ib)
+}
+
+// appendWeekdaysInMonth is like weekdaysInMonth, but appends to dst.
+func appendWeekdaysInMonth(dst []time.Time, t time.Time, weekdays []QualifiedWeekday, bySetPos []int, ib InvalidBehavior) []time.Time {
 	firstDay := firstOfMonth(t)
 	firstWeekday := firstDay.Weekday()
 	lastDay := lastOfMonth(t)
 	lastDate := lastDay.Day()
 
-	dates := make([]int, 0, 30)
+	var buf [31]int
+	dates := buf[:0]
 	var addLastPrevMonth bool
 	var addFirstNextMonth bool
 
@@ -73,32 +79,16 @@ func weekdaysInMonth(t time.Time, weekdays []QualifiedWeekday, bySetPos []int, i
 	sort.Ints(dates)
 	dates = limitInstancesBySetPos(dates, bySetPos)
 
-	excessDates := 0
-	if addLastPrevMonth {
-		excessDates++
-	}
-	if addFirstNextMonth {
-		excessDates++
-	}
-
-	out := make([]time.Time, 0, len(dates)+excessDates)
+	out := dst
 	if addLastPrevMonth {
 		out = append(out, firstDay.AddDate(0, 0, -1))
 	}
 
-	// it's possible we get duplicates with invalid behavior. avoid that.
-	// but avoid the extra allocation when we don't need this.
-	var addedMap map[int]bool
-	if ib != OmitInvalid {
-		addedMap = make(map[int]bool, cap(out))
-	}
-
-	for _, date := range dates {
-		if addedMap != nil {
-			if has := addedMap[date]; has {
-				continue
-			}
-			addedMap[date] = true
+	for i, date := range dates {
+		// it's possible we get duplicates with invalid behavior, which
+		// are next to each other once sorted. avoid them.
+		if i > 0 && date == dates[i-1] {
+			continue
 		}
 
 		out = append(out, firstDay.AddDate(0, 0, date-1))
@@ -111,6 +101,23 @@ func weekdaysInMonth(t time.Time, weekdays []QualifiedWeekday, bySetPos []int, i
 	return out
 }
 
+// monthWeekdaySet returns the days of the month of t, numbered from 1, that
+// fall on weekdays. Numbered weekdays are counted within the month, as
+// weekdaysInMonth counts them.
+func monthWeekdaySet(t time.Time, weekdays []QualifiedWeekday, ib InvalidBehavior) daySet {
+	first := firstOfMonth(t)
+	for _, wd := range weekdays {
+		if wd.N != 0 {
+			var s daySet
+			for _, day := range weekdaysInMonth(first, weekdays, nil, ib) {
+				s.add(daysBetween(first, day) + 1)
+			}
+			return s
+		}
+	}
+	return weekdaySet(first.Weekday(), weekdays)
+}
+
 func firstOfMonth(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
 }
@@ -0,0 +1,77 @@
+package rrule
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// xcalNamespace is the XML namespace of xCal (RFC 6321) elements.
+const xcalNamespace = "urn:ietf:params:xml:ns:icalendar-2.0"
+
+// MarshalXML encodes the pattern as an xCal (RFC 6321) "recur" element, with
+// one child element per value. Dtstart is not included. When the pattern is
+// marshaled on its own, the element is named recur in the xCal namespace;
+// otherwise it takes the name it is given, as for a struct field.
+func (rrule RRule) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if start.Name.Local == "RRule" && start.Name.Space == "" {
+		start.Name = xml.Name{Space: xcalNamespace, Local: "recur"}
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, part := range rrule.recurParts() {
+		for _, value := range part.values {
+			if err := e.EncodeElement(fmt.Sprint(value), xml.StartElement{Name: xml.Name{Local: part.name}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML decodes the pattern from an xCal (RFC 6321) "recur" element, as
+// produced by MarshalXML. Repeated child elements give a part more than one
+// value.
+func (rrule *RRule) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var names []string
+	values := map[string][]string{}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &tok); err != nil {
+				return err
+			}
+
+			name := tok.Name.Local
+			if _, ok := values[name]; !ok {
+				names = append(names, name)
+			}
+			values[name] = append(values[name], strings.TrimSpace(value))
+		case xml.EndElement:
+			decoded := RRule{}
+			for _, name := range names {
+				if err := decoded.setRecurPart(name, strings.Join(values[name], ",")); err != nil {
+					return fmt.Errorf("xCal %s: %v", name, err)
+				}
+			}
+
+			if err := decoded.Validate(); err != nil {
+				return err
+			}
+
+			*rrule = decoded
+			return nil
+		}
+	}
+}
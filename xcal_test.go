@@ -0,0 +1,56 @@
+package rrule
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXCal(t *testing.T) {
+	rrule := RRule{
+		Frequency:  Weekly,
+		Until:      time.Date(2019, time.March, 1, 9, 0, 0, 0, time.UTC),
+		ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Wednesday}},
+		ByHours:    []int{9, 17},
+		Extensions: map[string]string{"X-NAME": "standup"},
+	}
+
+	b, err := xml.Marshal(rrule)
+	require.NoError(t, err)
+	assert.Equal(t, `<recur xmlns="urn:ietf:params:xml:ns:icalendar-2.0">`+
+		`<freq>WEEKLY</freq><until>2019-03-01T09:00:00Z</until>`+
+		`<byhour>9</byhour><byhour>17</byhour><byday>MO</byday><byday>WE</byday>`+
+		`<x-name>standup</x-name></recur>`, string(b))
+
+	var decoded RRule
+	require.NoError(t, xml.Unmarshal(b, &decoded))
+	assert.Equal(t, rrule.String(), decoded.String())
+	assert.Equal(t, rrule.Extensions, decoded.Extensions)
+}
+
+func TestXCalField(t *testing.T) {
+	type event struct {
+		XMLName xml.Name `xml:"vevent"`
+		RRule   RRule    `xml:"rrule>recur"`
+	}
+
+	b, err := xml.Marshal(event{RRule: RRule{Frequency: Daily, Count: 3}})
+	require.NoError(t, err)
+	assert.Equal(t, `<vevent><rrule><recur><freq>DAILY</freq><count>3</count></recur></rrule></vevent>`, string(b))
+
+	var decoded event
+	require.NoError(t, xml.Unmarshal([]byte(`<vevent><rrule><recur>
+		<freq>MONTHLY</freq>
+		<until>2019-03-01</until>
+		<bymonth>5L</bymonth>
+		<bymonth>6</bymonth>
+		<rscale>CHINESE</rscale>
+	</recur></rrule></vevent>`), &decoded))
+	assert.Equal(t, "FREQ=MONTHLY;UNTIL=20190301T000000;BYMONTH=6,5L;RSCALE=CHINESE", decoded.RRule.String())
+
+	assert.Error(t, xml.Unmarshal([]byte(`<vevent><rrule><recur><freq>HOURLY</freq><count>x</count></recur></rrule></vevent>`), &decoded))
+	assert.Error(t, xml.Unmarshal([]byte(`<recur><freq>DAILY</freq><count>2</count><until>2019-03-01</until></recur>`), &decoded.RRule))
+}
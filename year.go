@@ -1,6 +1,7 @@
 package rrule
 
 import (
+	"sort"
 	"time"
 )
 
@@ -19,3 +20,309 @@ func yearStart(t time.Time, wkstart time.Weekday) time.Time {
 	// otherwise we must go backward to the start of the first week
 	return backToWeekday(jan1, wkstart)
 }
+
+// gregorianCycle is the number of years after which the Gregorian calendar
+// repeats, weekdays included.
+const gregorianCycle = 400
+
+// yearKind identifies a year by its length and the weekday of its first day.
+// Years of the same kind have their days in the same places, so a YEARLY
+// pattern picks the same days of the year in each of them.
+type yearKind struct {
+	leap bool
+	jan1 time.Weekday
+}
+
+func kindOfYear(year int) yearKind {
+	return yearKind{
+		leap: daysInYear(year) == 366,
+		jan1: time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).Weekday(),
+	}
+}
+
+func daysInYear(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// yearDays returns the days of year that the YEARLY pattern picks, sorted and
+// without repeats, where 1 is January 1st. Days the pattern's InvalidBehavior
+// moves out of the year are before 1 or past the end of the year.
+//
+// One of the BYxxx parts that expand the pattern chooses the days, and the
+// others limit them, following note 2 on page 44 of RFC 5545. Days of the
+// month are in the month of start when there is no BYMONTH.
+func (rrule RRule) yearDays(year int, start time.Time) []int {
+	jan1 := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	dayOf := func(t time.Time) int {
+		return int(t.Sub(jan1)/(24*time.Hour)) + 1
+	}
+	date := func(day int) time.Time {
+		return time.Date(year, time.January, day, 0, 0, 0, 0, time.UTC)
+	}
+	monthDays := func(m time.Month) int {
+		return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	}
+
+	ib := rrule.InvalidBehavior
+	wkst := rrule.weekStart()
+
+	months := rrule.ByMonths
+	if len(months) == 0 {
+		months = []time.Month{start.Month()}
+	}
+
+	// the part that chooses the days adds them to days, and the others
+	// intersect it with the days they allow.
+	var days daySet
+
+	switch {
+	case len(rrule.ByYearDays) > 0:
+		for _, yd := range rrule.ByYearDays {
+			if day, ok := invalidDay(yd, daysInYear(year), ib); ok {
+				days.add(day)
+			}
+		}
+		if len(rrule.ByMonths) > 0 {
+			days.intersect(monthSet(year, rrule.ByMonths))
+		}
+		if len(rrule.ByMonthDays) > 0 {
+			days.intersect(monthDaySet(year, rrule.ByMonthDays))
+		}
+		if len(rrule.ByWeekdays) > 0 {
+			days.intersect(rrule.yearWeekdaySet(year, rrule.ByMonths, dayOf))
+		}
+		if len(rrule.ByWeekNumbers) > 0 {
+			days.intersect(rrule.weekNumberSet(year, dayOf))
+		}
+
+	case len(rrule.ByMonthDays) > 0:
+		for _, m := range months {
+			first := dayOf(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC))
+			for _, md := range rrule.ByMonthDays {
+				if day, ok := invalidDay(md, monthDays(m), ib); ok {
+					days.add(first + day - 1)
+				}
+			}
+		}
+		if len(rrule.ByWeekdays) > 0 {
+			days.intersect(rrule.yearWeekdaySet(year, months, dayOf))
+		}
+		if len(rrule.ByWeekNumbers) > 0 {
+			days.intersect(rrule.weekNumberSet(year, dayOf))
+		}
+
+	case len(rrule.ByWeekNumbers) > 0:
+		// see erratum 3779 on RFC 5545.
+		weekdays := plainWeekdays(rrule.ByWeekdays)
+		if len(weekdays) == 0 {
+			// a week number picks the whole week, as it does in
+			// rrule.js and python-dateutil, rather than the weekday of
+			// start, which would leave BYSETPOS little to choose from.
+			weekdays = []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+		}
+
+		first := dayOf(yearStart(jan1, wkst))
+		weeks := (dayOf(yearStart(date(daysInYear(year)+1), wkst)) - first) / 7
+		for _, w := range rrule.ByWeekNumbers {
+			week, ok := invalidDay(w, weeks, ib)
+			if !ok {
+				continue
+			}
+			for _, wd := range weekdays {
+				days.add(first + (week-1)*7 + daysTil(wkst, wd))
+			}
+		}
+		if len(rrule.ByMonths) > 0 {
+			days.intersect(monthSet(year, rrule.ByMonths))
+		}
+
+	case len(rrule.ByWeekdays) > 0:
+		for _, day := range rrule.weekdayDays(year, rrule.ByMonths, dayOf) {
+			days.add(day)
+		}
+
+	default:
+		for _, m := range months {
+			if day, ok := invalidDay(start.Day(), monthDays(m), ib); ok {
+				days.add(dayOf(time.Date(year, m, day, 0, 0, 0, 0, time.UTC)))
+			}
+		}
+	}
+
+	return days.days()
+}
+
+// eachMonth calls fn with the first day and the length of each month of year,
+// and of the months either side.
+func eachMonth(year int, fn func(m time.Month, first, length int)) {
+	jan1 := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for m := time.Month(0); m <= 13; m++ {
+		first := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+		length := time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		fn(first.Month(), int(first.Sub(jan1)/(24*time.Hour))+1, length)
+	}
+}
+
+// monthSet returns the days of year in months.
+func monthSet(year int, months []time.Month) daySet {
+	var s daySet
+	eachMonth(year, func(m time.Month, first, length int) {
+		if hasMonth(months, m) {
+			s.addRange(first, first+length)
+		}
+	})
+	return s
+}
+
+// monthDaySet returns the days of year that are monthdays of their month.
+// Unlike an expanding BYMONTHDAY, days that a month doesn't have are not
+// moved into it.
+func monthDaySet(year int, monthdays []int) daySet {
+	var s daySet
+	eachMonth(year, func(m time.Month, first, length int) {
+		for _, md := range monthdays {
+			if md < 0 {
+				md += length + 1
+			}
+			if md >= 1 && md <= length {
+				s.add(first + md - 1)
+			}
+		}
+	})
+	return s
+}
+
+// weekdayDays returns the days of year that BYDAY picks, counting numbered
+// weekdays within each of months, or within the year if there are none.
+func (rrule RRule) weekdayDays(year int, months []time.Month, dayOf func(time.Time) int) []int {
+	var days []int
+	if len(months) > 0 {
+		for _, m := range months {
+			first := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+			for _, t := range weekdaysInMonth(first, rrule.ByWeekdays, nil, rrule.InvalidBehavior) {
+				days = append(days, dayOf(t))
+			}
+		}
+		return days
+	}
+
+	jan1 := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for _, wd := range rrule.ByWeekdays {
+		for _, t := range weekdaysInYear(jan1, wd, rrule.InvalidBehavior) {
+			days = append(days, dayOf(t))
+		}
+	}
+	return days
+}
+
+// yearWeekdaySet returns the days that BYDAY allows of those other parts pick.
+// Weekdays without numbers only need to match, and numbered ones are counted
+// as weekdayDays does.
+func (rrule RRule) yearWeekdaySet(year int, months []time.Month, dayOf func(time.Time) int) daySet {
+	var s daySet
+	for _, wd := range rrule.ByWeekdays {
+		if wd.N != 0 {
+			for _, day := range rrule.weekdayDays(year, months, dayOf) {
+				s.add(day)
+			}
+			return s
+		}
+	}
+
+	jan1 := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return weekdaySet(jan1.Weekday(), rrule.ByWeekdays)
+}
+
+// weekNumberSet returns the days in the weeks of BYWEEKNO, where weeks start on
+// the pattern's week start and the first of each year has at least 4 of its
+// days.
+func (rrule RRule) weekNumberSet(year int, dayOf func(time.Time) int) daySet {
+	wkst := rrule.weekStart()
+	weekStart := func(year int) int {
+		return dayOf(yearStart(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), wkst))
+	}
+
+	// the weeks of the years either side can cover days near the ends of
+	// the year.
+	var s daySet
+	for y := year - 1; y <= year+1; y++ {
+		first := weekStart(y)
+		weeks := (weekStart(y+1) - first) / 7
+		for _, w := range rrule.ByWeekNumbers {
+			if w < 0 {
+				w += weeks + 1
+			}
+			if w >= 1 && w <= weeks {
+				s.addRange(first+(w-1)*7, first+w*7)
+			}
+		}
+	}
+	return s
+}
+
+// invalidDay returns the n-th of length days, counted from the end if n is
+// negative. If there is no such day, it is moved by ib to 0 or length+1, the
+// days either side, or ok is false if ib omits it.
+func invalidDay(n, length int, ib InvalidBehavior) (day int, ok bool) {
+	if n < 0 {
+		n += length + 1
+	}
+	if n >= 1 && n <= length {
+		return n, true
+	}
+
+	switch ib {
+	case PrevInvalid:
+		if n > length {
+			return length, true
+		}
+		return 0, true
+	case NextInvalid:
+		if n > length {
+			return length + 1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// dayClocks returns the times of day, in seconds, of the pattern's
+// occurrences, sorted and without repeats. Parts of the time without a BYxxx
+// part are those of start.
+func (rrule RRule) dayClocks(start time.Time) []int {
+	orDefault := func(values []int, def, max int) []int {
+		if len(values) == 0 {
+			return []int{def}
+		}
+		normalized := make([]int, len(values))
+		for i, v := range values {
+			if v < 0 {
+				v += max
+			}
+			normalized[i] = v
+		}
+		return normalized
+	}
+
+	hours := orDefault(rrule.ByHours, start.Hour(), 24)
+	minutes := orDefault(rrule.ByMinutes, start.Minute(), 60)
+	seconds := orDefault(rrule.BySeconds, start.Second(), 60)
+
+	clocks := make([]int, 0, len(hours)*len(minutes)*len(seconds))
+	for _, h := range hours {
+		for _, m := range minutes {
+			for _, s := range seconds {
+				clocks = append(clocks, h*60*60+m*60+s)
+			}
+		}
+	}
+
+	sort.Ints(clocks)
+	unique := clocks[:0]
+	for i, c := range clocks {
+		if i == 0 || c != clocks[i-1] {
+			unique = append(unique, c)
+		}
+	}
+	return unique
+}
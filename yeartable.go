@@ -0,0 +1,94 @@
+package rrule
+
+import (
+	"time"
+)
+
+// yearTable holds the facts about a year that the BYxxx limits check each time
+// against, indexed by day of the year, so they're looked up rather than
+// computed again for each time.
+type yearTable struct {
+	// days is the length of the year.
+	days int
+
+	// monthStarts holds the day of the year of the 1st of each month, with
+	// the day after the year at December + 1.
+	monthStarts [14]int
+
+	// weeks and weeksFromEnd hold the week number of each day, counted
+	// from the start and the end of the year the week belongs to, which is
+	// the year either side for some days near its ends.
+	weeks, weeksFromEnd [367]int8
+}
+
+func newYearTable(year int, wkst time.Weekday) *yearTable {
+	jan1 := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	dayOf := func(t time.Time) int {
+		return int(t.Sub(jan1)/(24*time.Hour)) + 1
+	}
+
+	yt := &yearTable{days: daysInYear(year)}
+	for m := time.January; m <= time.December+1; m++ {
+		yt.monthStarts[m] = dayOf(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC))
+	}
+
+	// the first week of each year is the first with at least 4 of its
+	// days, as in weekNumberSet.
+	weekStart := func(year int) int {
+		return dayOf(yearStart(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), wkst))
+	}
+	starts := [...]int{weekStart(year - 1), weekStart(year), weekStart(year + 1), weekStart(year + 2)}
+	for day := 1; day <= yt.days; day++ {
+		y := 0
+		for day >= starts[y+1] {
+			y++
+		}
+		week := (day-starts[y])/7 + 1
+		weeks := (starts[y+1] - starts[y]) / 7
+		yt.weeks[day] = int8(week)
+		yt.weeksFromEnd[day] = int8(week - weeks - 1)
+	}
+	return yt
+}
+
+// monthLength returns the number of days in month m.
+func (yt *yearTable) monthLength(m time.Month) int {
+	return yt.monthStarts[m+1] - yt.monthStarts[m]
+}
+
+// yearTables memoizes the yearTable of each year an iterator checks times
+// in, for the lifetime of the iterator.
+type yearTables struct {
+	wkst  time.Weekday
+	years map[int]*yearTable
+
+	// last is the year most recently looked up, which is usually the
+	// next one looked up too.
+	last     int
+	lastYear *yearTable
+}
+
+// maxYearTables bounds how many years are memoized, in case an iterator runs
+// through very many of them.
+const maxYearTables = 64
+
+func newYearTables(wkst time.Weekday) *yearTables {
+	return &yearTables{wkst: wkst}
+}
+
+func (yts *yearTables) year(year int) *yearTable {
+	if yts.lastYear != nil && yts.last == year {
+		return yts.lastYear
+	}
+
+	yt, ok := yts.years[year]
+	if !ok {
+		if yts.years == nil || len(yts.years) == maxYearTables {
+			yts.years = map[int]*yearTable{}
+		}
+		yt = newYearTable(year, yts.wkst)
+		yts.years[year] = yt
+	}
+	yts.last, yts.lastYear = year, yt
+	return yt
+}
@@ -0,0 +1,28 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYearTable(t *testing.T) {
+	tables := newYearTables(time.Monday)
+	for year := 1990; year <= 2040; year++ {
+		yt := tables.year(year)
+		assert.Equal(t, daysInYear(year), yt.days)
+		for m := time.January; m <= time.December; m++ {
+			assert.Equal(t, lastOfMonth(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)).Day(), yt.monthLength(m))
+		}
+
+		// with weeks starting on Monday, week numbers are ISO 8601's.
+		for day := 1; day <= yt.days; day++ {
+			weekYear, week := time.Date(year, time.January, day, 0, 0, 0, 0, time.UTC).ISOWeek()
+			_, weeks := time.Date(weekYear, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+			assert.Equal(t, week, int(yt.weeks[day]), "%d day %d", year, day)
+			assert.Equal(t, week-weeks-1, int(yt.weeksFromEnd[day]), "%d day %d", year, day)
+		}
+	}
+	assert.True(t, tables.year(2000) == tables.year(2000))
+}